@@ -19,6 +19,7 @@ import (
 	"github.com/donnyhardyanto/dxlib/utils/os"
 	"github.com/donnyhardyanto/dxlib/vault"
 	"github.com/donnyhardyanto/dxlib_module/module/oam"
+	"github.com/donnyhardyanto/dxlib_module/module/self"
 )
 
 var isAPISpec = false
@@ -33,11 +34,51 @@ func doOnDefineConfiguration() (err error) {
 			"address": os.GetEnvDefaultValue("SYSTEM_API_OAM_WEBADMIN_ADDRESS", "0.0.0.0:14000"),
 		},
 		"webadmin": map[string]any{
-			"nameid":  "webadmin",
-			"address": os.GetEnvDefaultValue("SYSTEM_API_WEBADMIN_ADDRESS", "0.0.0.0:15000"),
+			"nameid":                "webadmin",
+			"address":               os.GetEnvDefaultValue("SYSTEM_API_WEBADMIN_ADDRESS", "0.0.0.0:15000"),
+			"error-response-format": os.GetEnvDefaultValue("SYSTEM_API_WEBADMIN_ERROR_RESPONSE_FORMAT", "legacy"),
+			"error-production-mode": os.GetEnvDefaultValueAsBool("SYSTEM_API_WEBADMIN_ERROR_PRODUCTION_MODE", false),
+			"endpoint-timeout-sec":  os.GetEnvDefaultValueAsInt("SYSTEM_API_WEBADMIN_ENDPOINT_TIMEOUT_SEC", 60),
+			// uploads and downloads get a longer limit, it may exceed the server read and write timeouts
+			"stream-endpoint-timeout-sec":  os.GetEnvDefaultValueAsInt("SYSTEM_API_WEBADMIN_STREAM_ENDPOINT_TIMEOUT_SEC", 900),
+			"form-body-max-size-byte":      os.GetEnvDefaultValueAsInt("SYSTEM_API_WEBADMIN_FORM_BODY_MAX_SIZE_BYTE", 1<<20),
+			"multipart-body-max-size-byte": os.GetEnvDefaultValueAsInt("SYSTEM_API_WEBADMIN_MULTIPART_BODY_MAX_SIZE_BYTE", 64<<20),
+			"cors": map[string]any{
+				// the browser admin UI must be allowed, without an explicit list only its origin is
+				"allowed-origins":   os.GetEnvDefaultValue("SYSTEM_API_WEBADMIN_CORS_ALLOWED_ORIGINS", os.GetEnvDefaultValue("SYSTEM_API_WEBADMIN_UI_ORIGIN", "http://localhost")),
+				"allowed-headers":   []string{"Authorization", "Content-Type", "X-Var", "Idempotency-Key", "X-Request-Id", "traceparent"},
+				"exposed-headers":   []string{"X-Var", "Idempotent-Replayed", "X-Request-Id"},
+				"allow-credentials": os.GetEnvDefaultValueAsBool("SYSTEM_API_WEBADMIN_CORS_ALLOW_CREDENTIALS", false),
+				"max-age-sec":       os.GetEnvDefaultValueAsInt("SYSTEM_API_WEBADMIN_CORS_MAX_AGE_SEC", 600),
+			},
+			"idempotency": map[string]any{
+				"redis-nameid": os.GetEnvDefaultValue("SYSTEM_API_WEBADMIN_IDEMPOTENCY_REDIS_NAMEID", "session"),
+				"ttl-sec":      os.GetEnvDefaultValueAsInt("SYSTEM_API_WEBADMIN_IDEMPOTENCY_TTL_SEC", 86400),
+			},
+			"tls": map[string]any{
+				"cert-file":      os.GetEnvDefaultValue("SYSTEM_API_WEBADMIN_TLS_CERT_FILE", ""),
+				"key-file":       os.GetEnvDefaultValue("SYSTEM_API_WEBADMIN_TLS_KEY_FILE", ""),
+				"cert-pem":       app.App.InitVault.GetStringOrDefault("API_WEBADMIN_TLS_CERT_PEM", ""),
+				"key-pem":        app.App.InitVault.GetStringOrDefault("API_WEBADMIN_TLS_KEY_PEM", ""),
+				"client-ca-file": os.GetEnvDefaultValue("SYSTEM_API_WEBADMIN_TLS_CLIENT_CA_FILE", ""),
+				"min-version":    os.GetEnvDefaultValue("SYSTEM_API_WEBADMIN_TLS_MIN_VERSION", "1.2"),
+				// TLS 1.0 and 1.1 are rejected unless this is set
+				"allow-legacy-min-version": os.GetEnvDefaultValueAsBool("SYSTEM_API_WEBADMIN_TLS_ALLOW_LEGACY_MIN_VERSION", false),
+			},
 		},
 	}, []string{})
 
+	// Tracing
+	configuration.Manager.NewIfNotExistConfiguration("tracing", "tracing.json", "json", false, false, map[string]any{
+		"enabled":      os.GetEnvDefaultValueAsBool("SYSTEM_TRACING_ENABLED", false),
+		"service-name": os.GetEnvDefaultValue("OTEL_SERVICE_NAME", ""),
+		"protocol":     os.GetEnvDefaultValue("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf"),
+		"endpoint":     os.GetEnvDefaultValue("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		"insecure":     os.GetEnvDefaultValueAsBool("OTEL_EXPORTER_OTLP_INSECURE", false),
+		"headers":      os.GetEnvDefaultValue("OTEL_EXPORTER_OTLP_HEADERS", ""),
+		"sample-ratio": os.GetEnvDefaultValueAsFloat64("SYSTEM_TRACING_SAMPLE_RATIO", 1.0),
+	}, []string{})
+
 	return nil
 }
 
@@ -68,13 +109,19 @@ func doOnDefineAPIEndPoints() (err error) {
 		"/version", "GET", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, nil,
 		moduleInstance.VersionHandler, nil, nil, nil, nil, 0, "default",
 	)
-	moduleInstanceV1AuditLog.DefineAPIEndPoints(apiWebadmin)
+
+	// CMS endpoints are rate limited and require a logged user holding the endpoint privileges
+	apiWebadminCMS := apiWebadmin.Group("",
+		api.MiddlewareBefore(self.ModuleSelf.MiddlewareRequestRateLimitCheck),
+		api.MiddlewareBefore(self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck),
+	)
+	moduleInstanceV1AuditLog.DefineAPIEndPoints(apiWebadminCMS)
 	moduleInstanceV1Self.DefineAPIEndPoints(apiWebadmin)
-	moduleInstanceV1General.DefineAPIEndPoints(apiWebadmin)
-	moduleInstanceV1UserManagement.DefineAPIEndPoints(apiWebadmin)
-	moduleInstanceV1ExternalSystem.DefineAPIEndPoints(apiWebadmin)
+	moduleInstanceV1General.DefineAPIEndPoints(apiWebadminCMS)
+	moduleInstanceV1UserManagement.DefineAPIEndPoints(apiWebadmin, apiWebadminCMS)
+	moduleInstanceV1ExternalSystem.DefineAPIEndPoints(apiWebadminCMS)
 	//moduleInstanceV1Webapp.DefineAPIEndPoints(apiWebadmin)
-	moduleInstanceV1PushNotification.DefineAPIEndPoints(apiWebadmin)
+	moduleInstanceV1PushNotification.DefineAPIEndPoints(apiWebadminCMS)
 	return nil
 }
 
@@ -105,5 +152,7 @@ func main() {
 	app.App.OnDefineConfiguration = doOnDefineConfiguration
 	app.App.OnDefineSetVariables = infrastructure.DoOnDefineSetVariables
 	app.App.OnDefineAPIEndPoints = doOnDefineAPIEndPoints
+	app.App.ShutdownTimeoutSec = os.GetEnvDefaultValueAsInt("SYSTEM_SHUTDOWN_TIMEOUT_SEC", app.DXAppDefaultShutdownTimeoutSec)
+	app.App.ShutdownReadinessDelaySec = os.GetEnvDefaultValueAsInt("SYSTEM_SHUTDOWN_READINESS_DELAY_SEC", 5)
 	_ = app.App.Run()
 }
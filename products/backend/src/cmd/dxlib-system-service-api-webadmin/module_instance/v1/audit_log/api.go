@@ -5,11 +5,10 @@ import (
 	"github.com/donnyhardyanto/dxlib/table"
 	"github.com/donnyhardyanto/dxlib/utils/http"
 	"github.com/donnyhardyanto/dxlib_module/module/audit_log"
-	"github.com/donnyhardyanto/dxlib_module/module/self"
 )
 
-func DefineAPIEndPoints(anAPI *api.DXAPI) {
-	anAPI.NewEndPoint("AuditLogUserActivityLog.List.CMS",
+func DefineAPIEndPoints(cmsAPI *api.DXAPIGroup) {
+	cmsAPI.NewEndPoint("AuditLogUserActivityLog.List.CMS",
 		"",
 		"/v1/audit/user_activity_log/list", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "filter_where", Type: "string", Description: "", IsMustExist: true},
@@ -18,13 +17,10 @@ func DefineAPIEndPoints(anAPI *api.DXAPI) {
 			{NameId: "row_per_page", Type: "int64", Description: "", IsMustExist: true},
 			{NameId: "page_index", Type: "int64", Description: "", IsMustExist: true},
 			{NameId: "is_deleted", Type: "bool", Description: "", IsMustExist: false},
-		}, audit_log.ModuleAuditLog.UserActivityLog.RequestPagingList, nil, table.Manager.StandardOperationResponsePossibility["list"], []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"AUDIT_LOG.USER_ACTIVITY_LOG.LIST"}, 0, "default",
+		}, audit_log.ModuleAuditLog.UserActivityLog.RequestPagingList, nil, table.Manager.StandardOperationResponsePossibility["list"], nil, []string{"AUDIT_LOG.USER_ACTIVITY_LOG.LIST"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("AuditLogErrorLog.List.CMS",
+	cmsAPI.NewEndPoint("AuditLogErrorLog.List.CMS",
 		"",
 		"/v1/audit/error_log/list", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "filter_where", Type: "string", Description: "", IsMustExist: true},
@@ -33,9 +29,6 @@ func DefineAPIEndPoints(anAPI *api.DXAPI) {
 			{NameId: "row_per_page", Type: "int64", Description: "", IsMustExist: true},
 			{NameId: "page_index", Type: "int64", Description: "", IsMustExist: true},
 			{NameId: "is_deleted", Type: "bool", Description: "", IsMustExist: false},
-		}, audit_log.ModuleAuditLog.ErrorLog.RequestPagingList, nil, table.Manager.StandardOperationResponsePossibility["list"], []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"AUDIT_LOG.ERROR_LOG.LIST"}, 0, "default",
+		}, audit_log.ModuleAuditLog.ErrorLog.RequestPagingList, nil, table.Manager.StandardOperationResponsePossibility["list"], nil, []string{"AUDIT_LOG.ERROR_LOG.LIST"}, 0, "default",
 	)
 }
@@ -29,20 +29,64 @@ func DefineAPIEndPoints(anAPI *api.DXAPI) {
 
 	anAPI.NewEndPoint("User Login",
 		"User login",
-		"/v1/self/login", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
-			{NameId: "i", Type: "string", Description: "Pre-key index", IsMustExist: true},
-			{NameId: "d", Type: "string", Description: "Login data", IsMustExist: true},
-		}, self.ModuleSelf.SelfLogin, nil, nil, nil, []string{
+		"/v1/self/login", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfLoginParameter](),
+		self.ModuleSelf.SelfLogin, nil, nil, nil, []string{
 			"ACCESS.WEB_CMS",
 		}, 0, "/api-webadmin/login",
 	)
 
 	anAPI.NewEndPoint("User Login With Captcha",
 		"User login with captcha",
-		"/v1/self/login_captcha", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
-			{NameId: "i", Type: "string", Description: "Pre-key index", IsMustExist: true},
-			{NameId: "d", Type: "string", Description: "Login data", IsMustExist: true},
-		}, self.ModuleSelf.SelfLoginCaptcha, nil, nil, []api.DXAPIEndPointExecuteFunc{
+		"/v1/self/login_captcha", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfLoginParameter](),
+		self.ModuleSelf.SelfLoginCaptcha, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+		}, []string{"ACCESS.WEB_CMS"}, 0, "/api-webadmin/login",
+	)
+
+	anAPI.NewEndPoint("User Login TOTP",
+		"User login second step with a TOTP or recovery code",
+		"/v1/self/login_totp", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfLoginParameter](),
+		self.ModuleSelf.SelfLoginTOTP, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+		}, []string{"ACCESS.WEB_CMS"}, 0, "/api-webadmin/login",
+	)
+
+	anAPI.NewEndPoint("User Login TOTP Enroll",
+		"TOTP enrollment during login when a role requires TOTP",
+		"/v1/self/login_totp/enroll", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfLoginParameter](),
+		self.ModuleSelf.SelfLoginTOTPEnroll, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+		}, nil, 0, "/api-webadmin/login",
+	)
+
+	anAPI.NewEndPoint("User Password Reset Request",
+		"Email a password reset link to the user with the login id or email",
+		"/v1/self/password/reset/request", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfLoginParameter](),
+		self.ModuleSelf.SelfPasswordResetRequest, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+		}, nil, 0, "/api-webadmin/login",
+	)
+
+	anAPI.NewEndPoint("User Password Reset",
+		"Set a new password with the token of a password reset link, all sessions of the user are revoked",
+		"/v1/self/password/reset", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfLoginParameter](),
+		self.ModuleSelf.SelfPasswordReset, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+		}, nil, 0, "/api-webadmin/login",
+	)
+
+	anAPI.NewEndPoint("User Login OTP Request",
+		"Send a one-time login code by email or SMS",
+		"/v1/self/login_otp/request", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfLoginParameter](),
+		self.ModuleSelf.SelfLoginOTPRequest, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+		}, nil, 0, "/api-webadmin/login",
+	)
+
+	anAPI.NewEndPoint("User Login OTP",
+		"User login with a one-time code",
+		"/v1/self/login_otp", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfLoginParameter](),
+		self.ModuleSelf.SelfLoginOTP, nil, nil, []api.DXAPIEndPointExecuteFunc{
 			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
 		}, []string{"ACCESS.WEB_CMS"}, 0, "/api-webadmin/login",
 	)
@@ -64,6 +108,52 @@ func DefineAPIEndPoints(anAPI *api.DXAPI) {
 		}, self.ModuleSelf.SelfPasswordChange, nil, nil, []api.DXAPIEndPointExecuteFunc{
 			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
 			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
+			self.ModuleSelf.MiddlewareOTPStepUpCheck,
+		}, nil, 0, "default",
+	)
+
+	anAPI.NewEndPoint("Self TOTP Enroll",
+		"Start TOTP enrollment, returns the secret, otpauth URI and QR code PNG",
+		"/v1/self/totp/enroll", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, nil,
+		self.ModuleSelf.SelfTOTPEnroll, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
+		}, nil, 0, "default",
+	)
+
+	anAPI.NewEndPoint("Self TOTP Confirm",
+		"Confirm TOTP enrollment with a first code, returns the recovery codes",
+		"/v1/self/totp/confirm", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfTOTPCodeParameter](),
+		self.ModuleSelf.SelfTOTPConfirm, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
+		}, nil, 0, "default",
+	)
+
+	anAPI.NewEndPoint("Self TOTP Disable",
+		"Disable TOTP",
+		"/v1/self/totp/disable", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfTOTPCodeParameter](),
+		self.ModuleSelf.SelfTOTPDisable, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
+		}, nil, 0, "default",
+	)
+
+	anAPI.NewEndPoint("Self OTP Send",
+		"Send a step-up one-time code by email or SMS",
+		"/v1/self/otp/send", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfOTPSendParameter](),
+		self.ModuleSelf.SelfOTPSend, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
+		}, nil, 0, "default",
+	)
+
+	anAPI.NewEndPoint("Self OTP Verify",
+		"Verify a step-up one-time code before a sensitive operation",
+		"/v1/self/otp/verify", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, api.ParametersOf[self.SelfOTPVerifyParameter](),
+		self.ModuleSelf.SelfOTPVerify, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
 		}, nil, 0, "default",
 	)
 
@@ -87,6 +177,37 @@ func DefineAPIEndPoints(anAPI *api.DXAPI) {
 		}, nil, 0, "default",
 	)
 
+	anAPI.NewEndPoint("Self Avatar Update File",
+		"Self avatar update using multipart/form-data",
+		"/v1/self/avatar/update_file", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeMultiPartFormData, []api.DXAPIEndPointParameter{
+			{NameId: "file", Type: "file", Description: "Avatar image file", IsMustExist: true, FileMaxSize: 10 << 20, FileContentTypes: []string{"image/png", "image/jpeg"}},
+		},
+		self.ModuleSelf.SelfAvatarUpdateFile, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
+		}, nil, 0, "default",
+	)
+
+	anAPI.NewEndPoint("Self Stream Ticket Create",
+		"Creates a single use ticket for websocket and Server-Sent Events requests, browsers can not send the Authorization header on those. "+
+			"Pass it as ticket query parameter within its expired_in_second.",
+		"/v1/self/stream_ticket", "POST", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{},
+		self.ModuleSelf.SelfStreamTicketCreate, nil, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+			self.ModuleSelf.MiddlewareUserLogged,
+		}, nil, 0, "default",
+	)
+
+	anAPI.NewEndPoint("Self WebSocket",
+		"Self websocket for live updates. Send {\"type\":\"subscribe\",\"channel\":\"announcement\"} to receive announcement updates. "+
+			"Browsers pass a ticket from /v1/self/stream_ticket as ticket query parameter",
+		"/v1/self/ws", "GET", api.EndPointTypeWS, http.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{},
+		nil, self.ModuleSelf.SelfWSLoop, nil, []api.DXAPIEndPointExecuteFunc{
+			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
+			self.ModuleSelf.MiddlewareUserLogged,
+		}, nil, 0, "default",
+	)
+
 	anAPI.NewEndPoint("Self Avatar Download Source",
 		"Self avatar download source",
 		"/v1/self/avatar/source", "POST", api.EndPointTypeHTTPDownloadStream, http.ContentTypeApplicationJSON, nil,
@@ -4,13 +4,13 @@ import (
 	"github.com/donnyhardyanto/dxlib/api"
 )
 
-func DefineAPIEndPoints(anAPI *api.DXAPI) {
+func DefineAPIEndPoints(anAPI *api.DXAPI, cmsAPI *api.DXAPIGroup) {
 	defineAPITestUploadDownloadFile(anAPI)
-	defineAPIRole(anAPI)
-	defineAPIUserRoleMembership(anAPI)
-	defineAPIPrivilege(anAPI)
-	defineAPIRolePrivilege(anAPI)
-	defineAPIOrganization(anAPI)
-	defineAPIOrganizationRoles(anAPI)
-	defineAPIUser(anAPI)
+	defineAPIRole(cmsAPI)
+	defineAPIUserRoleMembership(cmsAPI)
+	defineAPIPrivilege(cmsAPI)
+	defineAPIRolePrivilege(cmsAPI)
+	defineAPIOrganization(cmsAPI)
+	defineAPIOrganizationRoles(cmsAPI)
+	defineAPIUser(cmsAPI)
 }
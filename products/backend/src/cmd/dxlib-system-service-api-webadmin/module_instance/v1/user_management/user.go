@@ -9,33 +9,43 @@ import (
 	"github.com/donnyhardyanto/dxlib_module/module/user_management"
 )
 
-func defineAPIUser(anAPI *api.DXAPI) {
+func defineAPIUser(cmsAPI *api.DXAPIGroup) {
 
-	anAPI.NewEndPoint("User.Upload.CMS",
+	cmsAPI.NewEndPoint("User.Upload.CMS",
 		"Upload file csv or Excel to creates some new Users in the system with validated information. "+
 			"Handles the registration process with proper data validation and standardization. "+
 			"Returns the created User records with assigned unique identifiers.",
 		"/v1/user/create_bulk", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{},
-		user_management.ModuleUserManagement.UserCreateBulk, nil, nil, []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER.UPLOAD"}, 0, "default",
+		user_management.ModuleUserManagement.UserCreateBulk, nil, nil, nil, []string{"USER.UPLOAD"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("User.Download.CMS",
+	cmsAPI.NewEndPoint("User.Upload.Job.CMS",
+		"Upload file csv or Excel to creates some new Users in the system, same as User.Upload.CMS, but in the background. "+
+			"Returns the job_uid to follow with User.Upload.Job.Progress.CMS.",
+		"/v1/user/create_bulk/job", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationOctetStream, []api.DXAPIEndPointParameter{},
+		user_management.ModuleUserManagement.UserCreateBulkJobStart, nil, nil, nil, []string{"USER.UPLOAD"}, 0, "default",
+	)
+
+	cmsAPI.NewEndPoint("User.Upload.Job.Progress.CMS",
+		"Streams the progress of a User.Upload.Job.CMS job as Server-Sent Events: progress per created row, then done or error with a stable code and the row. "+
+			"A reconnecting EventSource resumes after its Last-Event-ID, a new one after the last_event_id query parameter. Browsers pass a ticket from /v1/self/stream_ticket as ticket query parameter.",
+		"/v1/user/create_bulk/job/{job_uid}/progress", "GET", api.EndPointTypeSSE, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
+			{NameId: "job_uid", Type: "string", Description: "Job uid returned by User.Upload.Job.CMS", IsMustExist: true},
+		},
+		user_management.ModuleUserManagement.UserCreateBulkJobProgress, nil, nil, nil, []string{"USER.UPLOAD"}, 0, "default",
+	)
+
+	cmsAPI.NewEndPoint("User.Download.CMS",
 		"Download User to CSV or Excel file based on given filters",
 		"/v1/user/list/download", "POST", api.EndPointTypeHTTPDownloadStream, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "filter_where", Type: "string", Description: "", IsMustExist: true},
 			{NameId: "filter_order_by", Type: "string", Description: "", IsMustExist: true},
 			{NameId: "filter_key_values", Type: "json-passthrough", Description: "", IsMustExist: true},
 			{NameId: "format", Type: "protected-string", Description: "", IsMustExist: true},
-		}, user_management.ModuleUserManagement.User.RequestListDownload, nil, table.Manager.StandardOperationResponsePossibility["list"], []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER_LIST.DOWNLOAD"}, 0, "default",
+		}, user_management.ModuleUserManagement.User.RequestListDownload, nil, table.Manager.StandardOperationResponsePossibility["list"], nil, []string{"USER_LIST.DOWNLOAD"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("User.List.CMS",
+	cmsAPI.NewEndPoint("User.List.CMS",
 		"Retrieves a paginated list of User with filtering and sorting capabilities. "+
 			"Returns a structured list of User with their basic information"+
 			"filtering conditions and flexible result ordering.",
@@ -46,70 +56,42 @@ func defineAPIUser(anAPI *api.DXAPI) {
 			{NameId: "row_per_page", Type: "int64", Description: "", IsMustExist: true},
 			{NameId: "page_index", Type: "int64", Description: "", IsMustExist: true},
 			{NameId: "is_deleted", Type: "bool", Description: "", IsMustExist: false},
-		}, user_management.ModuleUserManagement.UserList, nil, table.Manager.StandardOperationResponsePossibility["list"], []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER.LIST"}, 0, "default",
+		}, user_management.ModuleUserManagement.UserList, nil, table.Manager.StandardOperationResponsePossibility["list"], nil, []string{"USER.LIST"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("User.Create.CMS",
+	userCreate := cmsAPI.NewEndPoint("User.Create.CMS",
 		"Creates a new User in the system with validated information. "+
 			"Handles the registration process with proper data validation and standardization. "+
 			"Returns the created User record with assigned unique identifier.",
-		"/v1/user/create", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
-			{NameId: "organization_id", Type: "int64", Description: "Organization that user belong to at first time create", IsMustExist: true},
-			{NameId: "role_id", Type: "int64", Description: "Role that user belong to at first time create", IsMustExist: true},
-			{NameId: "loginid", Type: "string", Description: "Loginid", IsMustExist: true},
-			{NameId: "email", Type: "email", Description: "Email", IsMustExist: true},
-			{NameId: "fullname", Type: "string", Description: "Fullname", IsMustExist: true},
-			{NameId: "phonenumber", Type: "phonenumber", Description: "Phonenumber", IsMustExist: true},
-			{NameId: "attribute", Type: "string", Description: "Attribute", IsMustExist: false},
-			{NameId: "identity_number", Type: "string", Description: "identity_number", IsMustExist: false},
-			{NameId: "identity_type", Type: "string", Description: "identity_type", IsMustExist: false},
-			{NameId: "gender", Type: "string", Description: "gender", IsMustExist: false},
-			{NameId: "address_on_identity_card", Type: "string", Description: "address_on_identity_card", IsMustExist: false},
-			{NameId: "membership_number", Type: "string", Description: "Attribute", IsMustExist: false},
-			{NameId: "password_i", Type: "string", Description: "Password block", IsMustExist: true},
-			{NameId: "password_d", Type: "string", Description: "Password block", IsMustExist: true},
-		}, user_management.ModuleUserManagement.UserCreate, nil, nil, []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER.CREATE"}, 0, "default",
-	)
-
-	anAPI.NewEndPoint("User.Read.CMS",
+		"/v1/user/create", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, api.ParametersOf[user_management.UserCreateParameter](),
+		user_management.ModuleUserManagement.UserCreate, nil, nil, nil, []string{"USER.CREATE"}, 0, "default",
+	)
+	userCreate.IsIdempotencyKeyEnabled = true
+
+	cmsAPI.NewEndPoint("User.Read.CMS",
 		"Retrieves detailed information for a specific User by ID. "+
 			"Returns comprehensive User data including specifications. "+
 			"Essential for User specification views and data verification.",
 		"/v1/user/read", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "id", Type: "int64", Description: "", IsMustExist: true},
-		}, user_management.ModuleUserManagement.UserRead, nil, table.Manager.StandardOperationResponsePossibility["read"], []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER.READ"}, 0, "default",
+		}, user_management.ModuleUserManagement.UserRead, nil, table.Manager.StandardOperationResponsePossibility["read"], nil, []string{"USER.READ"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("User.Activate.CMS",
+	cmsAPI.NewEndPoint("User.Activate.CMS",
 		"User Activation",
 		"/v1/user/activate", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "id", Type: "int64", Description: "", IsMustExist: true},
-		}, user_management.ModuleUserManagement.UserActivate, nil, table.Manager.StandardOperationResponsePossibility["read"], []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER.ACTIVATE"}, 0, "default",
+		}, user_management.ModuleUserManagement.UserActivate, nil, table.Manager.StandardOperationResponsePossibility["read"], nil, []string{"USER.ACTIVATE"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("User.Suspend.CMS",
+	cmsAPI.NewEndPoint("User.Suspend.CMS",
 		"User Suspend",
 		"/v1/user/suspend", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "id", Type: "int64", Description: "", IsMustExist: true},
-		}, user_management.ModuleUserManagement.UserSuspend, nil, table.Manager.StandardOperationResponsePossibility["read"], []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER.SUSPEND"}, 0, "default",
+		}, user_management.ModuleUserManagement.UserSuspend, nil, table.Manager.StandardOperationResponsePossibility["read"], nil, []string{"USER.SUSPEND"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("User.Edit.CMS",
+	cmsAPI.NewEndPoint("User.Edit.CMS",
 		"Updates User information with comprehensive data validation. "+
 			"Allows modification of User specifications while maintaining data integrity. "+
 			"Supports partial updates with selective field modifications.",
@@ -127,66 +109,70 @@ func defineAPIUser(anAPI *api.DXAPI) {
 				{NameId: "address_on_identity_card", Type: "string", Description: "address_on_identity_card", IsMustExist: false},
 				{NameId: "membership_number", Type: "string", Description: "Attribute", IsMustExist: false},
 			}},
-		}, user_management.ModuleUserManagement.UserEdit, nil, nil, []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER.UPDATE"}, 0, "default",
+		}, user_management.ModuleUserManagement.UserEdit, nil, nil, nil, []string{"USER.UPDATE"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("User.Delete.CMS",
+	cmsAPI.NewEndPoint("User.Delete.CMS",
 		"Permanently removes a User record from the system. "+
 			"Performs necessary validation checks before deletion. "+
 			"Returns confirmation of successful deletion operation.",
 		"/v1/user/delete", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "id", Type: "int64", Description: "", IsMustExist: true},
 		}, user_management.ModuleUserManagement.UserDelete, nil, table.Manager.StandardOperationResponsePossibility["delete"], []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
+			self.ModuleSelf.MiddlewareOTPStepUpCheck,
 		}, []string{"USER.DELETE"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("User.ResetPassword.CMS",
+	cmsAPI.NewEndPoint("User.ResetPassword.CMS",
 		"User Reset Password",
 		"/v1/user/password/reset", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "user_id", Type: "int64", Description: "", IsMustExist: true},
-		}, user_management.ModuleUserManagement.UserResetPassword, nil, nil, []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER.RESET_PASSWORD"}, 0, "default",
+		}, user_management.ModuleUserManagement.UserResetPassword, nil, nil, nil, []string{"USER.RESET_PASSWORD"}, 0, "default",
+	)
+
+	cmsAPI.NewEndPoint("User.Lock.Read.CMS",
+		"Read the login lockout and password expiry state of a User",
+		"/v1/user/lock/read", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
+			{NameId: "user_id", Type: "int64", Description: "", IsMustExist: true},
+		}, user_management.ModuleUserManagement.UserLockRead, nil, nil, nil, []string{"USER.READ"}, 0, "default",
+	)
+
+	cmsAPI.NewEndPoint("User.Unlock.CMS",
+		"Unlock a User locked out after failed logins",
+		"/v1/user/unlock", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
+			{NameId: "user_id", Type: "int64", Description: "", IsMustExist: true},
+		}, user_management.ModuleUserManagement.UserUnlock, nil, nil, nil, []string{"USER.UNLOCK"}, 0, "default",
+	)
+
+	cmsAPI.NewEndPoint("User.PasswordHash.Report.CMS",
+		"Count Users by the hashing scheme of their current password, legacy hashes are upgraded at the next login",
+		"/v1/user/password/hash_report", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{},
+		user_management.ModuleUserManagement.UserPasswordHashReport, nil, nil, nil, []string{"USER.PASSWORD_HASH.REPORT"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("User.IdentityCard.Update.CMS",
+	cmsAPI.NewEndPoint("User.IdentityCard.Update.CMS",
 		"Self Identity Card  update",
 		"/v1/user/identity_card/update", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "user_id", Type: "int64", Description: "", IsMustExist: true},
 			{NameId: "content_base64", Type: "string", Description: "File content in Base64", IsMustExist: true},
-		}, user_management2.UserIdentityCardUpdateFileContentBase64, nil, nil, []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER.ID_CARD.UPDATE"}, 0, "default",
+		}, user_management2.UserIdentityCardUpdateFileContentBase64, nil, nil, nil, []string{"USER.ID_CARD.UPDATE"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("UserIdentityCard.DownloadSource.CMS",
+	cmsAPI.NewEndPoint("UserIdentityCard.DownloadSource.CMS",
 		"User identity card download Source",
 		"/v1/user/identity_card/source", "POST", api.EndPointTypeHTTPDownloadStream, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "user_id", Type: "int64", Description: "", IsMustExist: true},
-		}, user_management2.UserIdentityCardDownloadSource, nil, nil, []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER.ID_CARD.DOWNLOAD"}, 0, "default",
+		}, user_management2.UserIdentityCardDownloadSource, nil, nil, nil, []string{"USER.ID_CARD.DOWNLOAD"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("User.IdentityCard.DownloadBig.CMS",
+	cmsAPI.NewEndPoint("User.IdentityCard.DownloadBig.CMS",
 		"User identity card download Big",
 		"/v1/user/identity_card/big", "POST", api.EndPointTypeHTTPDownloadStream, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "user_id", Type: "int64", Description: "", IsMustExist: true},
-		}, user_management2.UserIdentityCardDownloadBig, nil, nil, []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER.ID_CARD.DOWNLOAD"}, 0, "default",
+		}, user_management2.UserIdentityCardDownloadBig, nil, nil, nil, []string{"USER.ID_CARD.DOWNLOAD"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("UserMessage.List.CMS",
+	cmsAPI.NewEndPoint("UserMessage.List.CMS",
 		"User Message list",
 		"/v1/user_message/list", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "filter_where", Type: "string", Description: "", IsMustExist: true},
@@ -195,20 +181,14 @@ func defineAPIUser(anAPI *api.DXAPI) {
 			{NameId: "row_per_page", Type: "int64", Description: "", IsMustExist: true},
 			{NameId: "page_index", Type: "int64", Description: "", IsMustExist: true},
 			{NameId: "is_deleted", Type: "bool", Description: "", IsMustExist: false},
-		}, user_management.ModuleUserManagement.UserMessage.RequestPagingList, nil, table.Manager.StandardOperationResponsePossibility["list"], []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER_MESSAGE.LIST"}, 0, "default",
+		}, user_management.ModuleUserManagement.UserMessage.RequestPagingList, nil, table.Manager.StandardOperationResponsePossibility["list"], nil, []string{"USER_MESSAGE.LIST"}, 0, "default",
 	)
 
-	anAPI.NewEndPoint("UserMessage.RequestRead.CMS",
+	cmsAPI.NewEndPoint("UserMessage.RequestRead.CMS",
 		"User Message RequestRead",
 		"/v1/user_message/read", "POST", api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
 			{NameId: "id", Type: "int64", Description: "", IsMustExist: true},
-		}, user_management.ModuleUserManagement.UserMessage.RequestRead, nil, table.Manager.StandardOperationResponsePossibility["read"], []api.DXAPIEndPointExecuteFunc{
-			self.ModuleSelf.MiddlewareRequestRateLimitCheck,
-			self.ModuleSelf.MiddlewareUserLoggedAndPrivilegeCheck,
-		}, []string{"USER_MESSAGE.READ"}, 0, "default",
+		}, user_management.ModuleUserManagement.UserMessage.RequestRead, nil, table.Manager.StandardOperationResponsePossibility["read"], nil, []string{"USER_MESSAGE.READ"}, 0, "default",
 	)
 
 }
@@ -47,7 +47,7 @@ func Seed() (err error) {
 			"nameid":       "USER_RESET_PASSWORD",
 			"content_type": "text/html",
 			"subject":      "Reset Password <fullname>",
-			"body":         "Halo <fullname>,<br><br>Anda telah meminta reset password. Berikut adalah password baru Anda:<br><br>Password: <password><br><br>Terima kasih,<br>Admin",
+			"body":         "Halo <fullname>,<br><br>Anda telah meminta reset password. Silakan buka tautan berikut untuk membuat password baru, tautan berlaku <ttl_minute> menit dan hanya dapat digunakan sekali:<br><br><a href=\"<link>\"><link></a><br><br>Abaikan email ini jika Anda tidak meminta reset password.<br><br>Terima kasih,<br>Admin",
 		})
 		if err != nil {
 			return err
@@ -87,6 +87,11 @@ func DefineConfiguration() {
 			"max_pixels":                  app.App.InitVault.GetInt64OrDefault("MAX_PIXELS", 40000000), // ~40MP
 			"image_process_limit_seconds": app.App.InitVault.GetInt64OrDefault("IMAGE_PROCESS_LIMIT_SECOND", 5),
 		},
+		"password_hash": map[string]any{
+			"argon2id_memory_kib":  app.App.InitVault.GetInt64OrDefault("PASSWORD_HASH_ARGON2ID_MEMORY_KIB", 64*1024), // 64MB
+			"argon2id_time":        app.App.InitVault.GetInt64OrDefault("PASSWORD_HASH_ARGON2ID_TIME", 3),
+			"argon2id_parallelism": app.App.InitVault.GetInt64OrDefault("PASSWORD_HASH_ARGON2ID_PARALLELISM", 2),
+		},
 	}, nil)
 
 	configuration.Manager.NewIfNotExistConfiguration("storage", "storage.json", "json", false, false, map[string]any{
@@ -385,6 +390,12 @@ func DefineConfiguration() {
 			"time_window_in_minutes":    app.App.InitVault.GetIntOrDefault("API_ENDPOINT_RATE_LIMITER_API_WEBADMIN_LOGIN_TIME_WINDOW_IN_MINUTES", 1), // Per minute
 			"block_duration_in_minutes": app.App.InitVault.GetIntOrDefault("API_ENDPOINT_RATE_LIMITER_API_WEBADMIN_LOGIN_BLOCK_DURATION_IN_MINUTES", 5),
 		},
+		"/otp/send": utils.JSON{
+			"nameid":                    "/otp/send",
+			"max_attempts":              app.App.InitVault.GetIntOrDefault("API_ENDPOINT_RATE_LIMITER_OTP_SEND_MAX_ATTEMPTS", 5),            // codes sent per user
+			"time_window_in_minutes":    app.App.InitVault.GetIntOrDefault("API_ENDPOINT_RATE_LIMITER_OTP_SEND_TIME_WINDOW_IN_MINUTES", 15), // Per 15 minutes
+			"block_duration_in_minutes": app.App.InitVault.GetIntOrDefault("API_ENDPOINT_RATE_LIMITER_OTP_SEND_BLOCK_DURATION_IN_MINUTES", 30),
+		},
 		"/api-mobile/login": utils.JSON{
 			"nameid":                    "/api-mobile/login",
 			"max_attempts":              app.App.InitVault.GetIntOrDefault("API_ENDPOINT_RATE_LIMITER_API_MOBILE_LOGIN_MAX_ATTEMPTS", 200),         // Default 100 requests
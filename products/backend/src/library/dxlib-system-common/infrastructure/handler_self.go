@@ -2,13 +2,18 @@ package infrastructure
 
 import (
 	"fmt"
+	"github.com/donnyhardyanto/dxlib-system/common/infrastructure/base"
+	"github.com/donnyhardyanto/dxlib-system/common/infrastructure/configuration_settings"
 	"github.com/donnyhardyanto/dxlib/api"
 	"github.com/donnyhardyanto/dxlib/configuration"
 	"github.com/donnyhardyanto/dxlib/log"
 	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib_module/module/self"
 	"github.com/donnyhardyanto/dxlib_module/module/user_management"
 	"github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
 	"net/http"
+	"time"
 )
 
 func selfLoginToLDAP(l *log.DXLog, user utils.JSON, userPassword string, organizationAuthSource string, organizationAttribute string) (isSuccess bool, err error) {
@@ -144,3 +149,61 @@ func doOnCreateSessionObject(aepr *api.DXAPIEndPointRequest, user utils.JSON, us
 	}
 	return originalSessionObject, nil
 }
+
+// doOnOTPSend renders the email_template or sms_template named OTP_<PURPOSE>, e.g. OTP_LOGIN, with <fullname>, <loginid>, <code> and <ttl_minute>.
+// It may run after the response was written, so it only returns errors.
+func doOnOTPSend(l *log.DXLog, channel self.OTPChannel, user utils.JSON, purpose string, code string, ttl time.Duration) (err error) {
+	configExternalSystem := *configuration.Manager.Configurations["external_system"].Data
+	templateNameId := "OTP_" + purpose
+
+	data := utils.JSON{
+		"fullname":   user["fullname"],
+		"loginid":    user["loginid"],
+		"code":       code,
+		"ttl_minute": int(ttl.Minutes()),
+	}
+
+	switch channel {
+	case self.OTPChannelEmail:
+		smtpConfiguration, ok := configExternalSystem["SMTP1"].(utils.JSON)
+		if !ok {
+			return errors.New("OTP_SEND:SMTP_CONFIG_NOT_FOUND")
+		}
+		aUserEmail, _ := user["email"].(string)
+		if aUserEmail == "" {
+			return errors.New("OTP_SEND:USER_EMAIL_IS_EMPTY")
+		}
+		_, emailTemplate, err := configuration_settings.ModuleConfigurationSettings.EMailTemplate.ShouldGetByNameId(l, templateNameId)
+		if err != nil {
+			return errors.Wrapf(err, "OTP_SEND:%s_EMAIL_TEMPLATE_NOT_FOUND", templateNameId)
+		}
+		emailTemplateContentType := emailTemplate["content_type"].(string)
+		emailTemplateTitle := emailTemplate["subject"].(string)
+		emailTemplateBody := emailTemplate["body"].(string)
+
+		err = base.EmailSend(data, emailTemplateContentType, emailTemplateTitle, emailTemplateBody, smtpConfiguration, aUserEmail)
+		if err != nil {
+			return errors.Wrap(err, "OTP_SEND:SEND_MAIL_ERROR")
+		}
+	case self.OTPChannelSMS:
+		smsConfiguration, ok := configExternalSystem["SMS1"].(utils.JSON)
+		if !ok {
+			return errors.New("OTP_SEND:SMS_CONFIG_NOT_FOUND")
+		}
+		aUserPhoneNumber, _ := user["phonenumber"].(string)
+		if aUserPhoneNumber == "" {
+			return errors.New("OTP_SEND:USER_PHONENUMBER_IS_EMPTY")
+		}
+		_, smsTemplate, err := configuration_settings.ModuleConfigurationSettings.SMSTemplate.ShouldGetByNameId(l, templateNameId)
+		if err != nil {
+			return errors.Wrapf(err, "OTP_SEND:%s_SMS_TEMPLATE_NOT_FOUND", templateNameId)
+		}
+		smsTemplateBody := smsTemplate["body"].(string)
+
+		err = base.SMSSend(aUserPhoneNumber, data, smsTemplateBody, smsConfiguration)
+		if err != nil {
+			return errors.Wrap(err, "OTP_SEND:SEND_SMS_ERROR")
+		}
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/donnyhardyanto/dxlib/endpoint_rate_limiter"
 	"github.com/donnyhardyanto/dxlib/redis"
 	"github.com/donnyhardyanto/dxlib/utils"
+	security "github.com/donnyhardyanto/dxlib/utils/security"
 	"github.com/donnyhardyanto/dxlib_module/lib"
 	"github.com/donnyhardyanto/dxlib_module/module/audit_log"
 	"github.com/donnyhardyanto/dxlib_module/module/external_system"
@@ -16,6 +17,7 @@ import (
 	"github.com/donnyhardyanto/dxlib_module/module/self"
 	"github.com/donnyhardyanto/dxlib_module/module/user_management"
 	"github.com/pkg/errors"
+	"math"
 	"time"
 )
 
@@ -85,6 +87,7 @@ func DoOnDefineSetVariables() (err error) {
 	self.ModuleSelf.UserOrganizationMembershipType = user_management.UserOrganizationMembershipTypeSingleOrganizationPerUser
 	self.ModuleSelf.OnAuthenticateUser = doOnAuthenticateUser
 	self.ModuleSelf.OnCreateSessionObject = doOnCreateSessionObject
+	self.ModuleSelf.OnOTPSend = doOnOTPSend
 
 	configObjectStorage := *configuration.Manager.Configurations["object_storage"].Data
 
@@ -162,7 +165,34 @@ func DoOnDefineSetVariables() (err error) {
 
 	user_management.ModuleUserManagement.UserOrganizationMembershipType = user_management.UserOrganizationMembershipTypeSingleOrganizationPerUser
 	user_management.ModuleUserManagement.OnUserAfterCreate = user_management_handler.DoOnUserAfterCreate
-	user_management.ModuleUserManagement.OnUserResetPassword = user_management_handler.DoOnUserResetPassword
+	user_management.ModuleUserManagement.OnUserPasswordResetTokenSend = user_management_handler.DoOnUserPasswordResetTokenSend
+
+	configSecurityPasswordHash := configSecurity["password_hash"].(utils.JSON)
+	argon2idMemoryKiB := configSecurityPasswordHash["argon2id_memory_kib"].(int64)
+	argon2idTime := configSecurityPasswordHash["argon2id_time"].(int64)
+	argon2idParallelism := configSecurityPasswordHash["argon2id_parallelism"].(int64)
+	// checked before the conversion, a value out of range would wrap instead of failing
+	if (argon2idMemoryKiB < 1) || (argon2idMemoryKiB > math.MaxUint32) {
+		return errors.Errorf("CONFIGURATION_PASSWORD_HASH_ARGON2ID_MEMORY_KIB_OUT_OF_RANGE:%v", argon2idMemoryKiB)
+	}
+	if (argon2idTime < 1) || (argon2idTime > math.MaxUint32) {
+		return errors.Errorf("CONFIGURATION_PASSWORD_HASH_ARGON2ID_TIME_OUT_OF_RANGE:%v", argon2idTime)
+	}
+	if (argon2idParallelism < 1) || (argon2idParallelism > math.MaxUint8) {
+		return errors.Errorf("CONFIGURATION_PASSWORD_HASH_ARGON2ID_PARALLELISM_OUT_OF_RANGE:%v", argon2idParallelism)
+	}
+	passwordHashArgon2idParameter := security.Argon2idParameter{
+		MemoryKiB:   uint32(argon2idMemoryKiB),
+		Time:        uint32(argon2idTime),
+		Parallelism: uint8(argon2idParallelism),
+		SaltLength:  security.DefaultArgon2idParameter.SaltLength,
+		KeyLength:   security.DefaultArgon2idParameter.KeyLength,
+	}
+	err = passwordHashArgon2idParameter.Validate()
+	if err != nil {
+		return errors.Wrap(err, "CONFIGURATION_PASSWORD_HASH_ARGON2ID_INVALID")
+	}
+	user_management.ModuleUserManagement.PasswordHashArgon2idParameter = passwordHashArgon2idParameter
 
 	audit_log.ModuleAuditLog.Init(base.DatabaseNameIdAuditLog)
 	configuration_settings.ModuleConfigurationSettings.Init(base.DatabaseNameIdConfig)
@@ -7,7 +7,11 @@ import (
 	"github.com/donnyhardyanto/dxlib/app"
 	"github.com/donnyhardyanto/dxlib/configuration"
 	"github.com/donnyhardyanto/dxlib/database"
+	"github.com/donnyhardyanto/dxlib/log"
 	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib_module/module/general"
+	"github.com/pkg/errors"
+	"net/url"
 	"time"
 )
 
@@ -92,76 +96,50 @@ func DoOnUserAfterCreate(aepr *api.DXAPIEndPointRequest, dtx *database.DXDatabas
 	return nil
 }
 
-func DoOnUserResetPassword(aepr *api.DXAPIEndPointRequest, dtx *database.DXDatabaseTx, user utils.JSON, userPasswordNew string) (err error) {
+// DoOnUserPasswordResetTokenSend renders the USER_RESET_PASSWORD email_template with <fullname>, <loginid>, <link> and <ttl_minute>, the link is the PASSWORD_RESET_URL property with the token appended.
+// It may run after the response was written, so it only returns errors.
+func DoOnUserPasswordResetTokenSend(l *log.DXLog, user utils.JSON, resetToken string, ttl time.Duration) (err error) {
 	configExternalSystem := *configuration.Manager.Configurations["external_system"].Data
 
-	go func() {
-		smtpConfiguration, ok := configExternalSystem["SMTP1"].(utils.JSON)
-		if !ok {
-			aepr.Log.Warn("USER_AFTER_CREATE:SMTP_CONFIG_NOT_FOUND")
-			return
-		}
-
-		_, emailTemplate, err := configuration_settings.ModuleConfigurationSettings.EMailTemplate.ShouldGetByNameId(&aepr.Log, "USER_RESET_PASSWORD")
-		if err != nil {
-			aepr.Log.Warnf("USER_RESET_PASSWORD:USER_RESET_PASSWORD_EMAIL_TEMPLATE_NOT_FOUND:%s", err.Error())
-			return
-		}
-		emailTemplateContentType := emailTemplate["content_type"].(string)
-		emailTemplateTitle := emailTemplate["subject"].(string)
-		emailTemplateBody := emailTemplate["body"].(string)
-
-		aUserLoginId := user["loginid"].(string)
-		aUserEmail := user["email"].(string)
-		aUserFullname := user["fullname"].(string)
-
-		data := utils.JSON{
-			"fullname": aUserFullname,
-			"loginid":  aUserLoginId,
-			"password": userPasswordNew,
-		}
-
-		err = base.EmailSend(data, emailTemplateContentType, emailTemplateTitle, emailTemplateBody, smtpConfiguration, aUserEmail)
-		if err != nil {
-			aepr.Log.Warnf("USER_RESET_PASSWORD:SEND_MAIL_ERROR:%s", err.Error())
-			return
-		}
-	}()
-
-	go func() {
-		smsConfiguration, ok := configExternalSystem["SMS1"].(utils.JSON)
-		if !ok {
-			aepr.Log.Errorf(err, "USER_ON_RESET_PASSWORD:SMS_CONFIG_NOT_FOUND")
-		}
-		if !smsConfiguration["enabled"].(bool) {
-			return
-		}
-		if !smsConfiguration["is_send_when_user_reset_password"].(bool) {
-			return
-		}
-		_, smsTemplate, err := configuration_settings.ModuleConfigurationSettings.SMSTemplate.ShouldGetByNameId(&aepr.Log, "USER_RESET_PASSWORD")
-		if err != nil {
-			aepr.Log.Warnf("USER_ON_RESET_PASSWORD:USER_RESET_PASSWORD_SMS_TEMPLATE_NOT_FOUND:%s", err.Error())
-			return
-		}
-		smsTemplateBody := smsTemplate["body"].(string)
-
-		aUserLoginId := user["loginid"].(string)
-		aUserPhoneNumber := user["phonenumber"].(string)
-		aUserFullname := user["fullname"].(string)
+	smtpConfiguration, ok := configExternalSystem["SMTP1"].(utils.JSON)
+	if !ok {
+		return errors.New("USER_RESET_PASSWORD:SMTP_CONFIG_NOT_FOUND")
+	}
+	aUserEmail, _ := user["email"].(string)
+	if aUserEmail == "" {
+		return errors.New("USER_RESET_PASSWORD:USER_EMAIL_IS_EMPTY")
+	}
 
-		data := utils.JSON{
-			"fullname": aUserFullname,
-			"loginid":  aUserLoginId,
-			"password": userPasswordNew,
-		}
+	passwordResetURL, err := general.ModuleGeneral.Property.GetAsString(l, "PASSWORD_RESET_URL")
+	if err != nil {
+		return err
+	}
+	link, err := url.Parse(passwordResetURL)
+	if err != nil {
+		return errors.Wrap(err, "USER_RESET_PASSWORD:PASSWORD_RESET_URL_INVALID")
+	}
+	q := link.Query()
+	q.Set("token", resetToken)
+	link.RawQuery = q.Encode()
 
-		err = base.SMSSend(aUserPhoneNumber, data, smsTemplateBody, smsConfiguration)
-		if err != nil {
-			aepr.Log.Warnf("USER_ON_RESET_PASSWORD:SEND_MAIL_ERROR:%s", err.Error())
-			return
-		}
-	}()
+	_, emailTemplate, err := configuration_settings.ModuleConfigurationSettings.EMailTemplate.ShouldGetByNameId(l, "USER_RESET_PASSWORD")
+	if err != nil {
+		return errors.Wrap(err, "USER_RESET_PASSWORD:USER_RESET_PASSWORD_EMAIL_TEMPLATE_NOT_FOUND")
+	}
+	emailTemplateContentType := emailTemplate["content_type"].(string)
+	emailTemplateTitle := emailTemplate["subject"].(string)
+	emailTemplateBody := emailTemplate["body"].(string)
+
+	data := utils.JSON{
+		"fullname":   user["fullname"],
+		"loginid":    user["loginid"],
+		"link":       link.String(),
+		"ttl_minute": int(ttl.Minutes()),
+	}
 
+	err = base.EmailSend(data, emailTemplateContentType, emailTemplateTitle, emailTemplateBody, smtpConfiguration, aUserEmail)
+	if err != nil {
+		return errors.Wrap(err, "USER_RESET_PASSWORD:SEND_MAIL_ERROR")
+	}
 	return nil
 }
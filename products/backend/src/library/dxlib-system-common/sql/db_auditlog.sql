@@ -67,5 +67,8 @@ create table audit_log.user_activity_log
     activity_result_status    varchar(255),
     activity_result_message   varchar(255),
     activity_input            jsonb,
-    activity_output           jsonb
+    activity_output           jsonb,
+    request_id                varchar(255)
 );
+
+create index user_activity_log_request_id on audit_log.user_activity_log (request_id);
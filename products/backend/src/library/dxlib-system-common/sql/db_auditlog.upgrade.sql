@@ -0,0 +1,6 @@
+-- Upgrade of a db_auditlog database created before the column below was added to db_auditlog.sql,
+-- it is not part of the create scripts, run it by hand on the existing database, every statement can run again
+
+alter table audit_log.user_activity_log add column if not exists request_id varchar(255);
+
+create index if not exists user_activity_log_request_id on audit_log.user_activity_log (request_id);
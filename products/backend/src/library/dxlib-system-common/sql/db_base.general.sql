@@ -34,6 +34,25 @@ VALUES ('SYSTEM-NAME', 'STRING', '{
        }'::JSONB),
        ('RELYON_INBOUND_SESSION_TTL_SECOND', 'INT', '{
          "value": 86400
+       }'::JSONB),
+       ('PASSWORD_RESET_URL', 'STRING', '{
+         "value": "http://localhost/password/reset"
+       }'::JSONB),
+       ('PASSWORD_POLICY', 'JSON', '{
+         "value": {
+           "min_length": 8,
+           "require_uppercase": true,
+           "require_lowercase": true,
+           "require_number": true,
+           "require_special": false,
+           "forbid_special": true,
+           "denylist_file": "",
+           "history_count": 5,
+           "max_age_day": 90,
+           "lockout_max_attempt": 5,
+           "lockout_window_second": 900,
+           "lockout_duration_second": 1800
+         }
        }'::JSONB);
 
 
@@ -0,0 +1,136 @@
+-- Upgrade of a db_base database created before the columns, tables and rows below were added to
+-- db_base.general.sql, db_base.user_management.sql and db_base.user_management.init-data.sql,
+-- it is not part of the create scripts, run it by hand on the existing database, every statement can run again
+
+alter table user_management.user add column if not exists login_failed_count int not null default 0;
+alter table user_management.user add column if not exists login_failed_first_at timestamp with time zone;
+alter table user_management.user add column if not exists locked_until timestamp with time zone;
+
+alter table user_management.organization add column if not exists password_policy jsonb;
+
+alter table user_management.organization_role add column if not exists is_totp_required boolean not null default false;
+
+create table if not exists user_management.user_totp
+(
+    id                           bigserial primary key,
+    uid                          varchar(1024)            not null unique default CONCAT(
+            to_hex((extract(epoch from now()) * 1000000)::bigint), gen_random_uuid()::text),
+    user_id                      bigint                   not null unique references user_management.user (id),
+    secret                       varchar(255)             not null,
+    is_enabled                   boolean                  not null        default false, -- set when the enrollment is confirmed with a first code
+    confirmed_at                 timestamp with time zone,
+    last_used_step               bigint                   not null        default 0,     -- a code of this or an earlier step is rejected as replayed
+    is_deleted                   boolean                  not null        default false,
+    created_at                   timestamp with time zone not null        default now(),
+    created_by_user_id           varchar(255)             not null        default '',
+    created_by_user_nameid       varchar(255)             not null        default '',
+    last_modified_at             timestamp with time zone not null        default now(),
+    last_modified_by_user_id     varchar(255)             not null        default '',
+    last_modified_by_user_nameid varchar(255)             not null        default ''
+);
+
+create table if not exists user_management.user_totp_recovery_code
+(
+    id                           bigserial primary key,
+    uid                          varchar(1024)            not null unique default CONCAT(
+            to_hex((extract(epoch from now()) * 1000000)::bigint), gen_random_uuid()::text),
+    user_id                      bigint                   not null references user_management.user (id),
+    value                        varchar(4096)            not null, -- SHA-256 of "<user_id>:<code>", older rows hold the legacy password hash
+    used_at                      timestamp with time zone,
+    is_deleted                   boolean                  not null        default false,
+    created_at                   timestamp with time zone not null        default now(),
+    created_by_user_id           varchar(255)             not null        default '',
+    created_by_user_nameid       varchar(255)             not null        default '',
+    last_modified_at             timestamp with time zone not null        default now(),
+    last_modified_by_user_id     varchar(255)             not null        default '',
+    last_modified_by_user_nameid varchar(255)             not null        default ''
+);
+
+create index if not exists user_totp_recovery_code_user_id_value on user_management.user_totp_recovery_code (user_id, value);
+
+create table if not exists user_management.user_password_reset_token
+(
+    id                           bigserial primary key,
+    uid                          varchar(1024)            not null unique default CONCAT(
+            to_hex((extract(epoch from now()) * 1000000)::bigint), gen_random_uuid()::text),
+    user_id                      bigint                   not null references user_management.user (id),
+    value                        varchar(255)             not null unique, -- SHA-256 of the token, the token itself is only sent to the user
+    expired_at                   timestamp with time zone not null,
+    used_at                      timestamp with time zone,
+    is_deleted                   boolean                  not null        default false,
+    created_at                   timestamp with time zone not null        default now(),
+    created_by_user_id           varchar(255)             not null        default '',
+    created_by_user_nameid       varchar(255)             not null        default '',
+    last_modified_at             timestamp with time zone not null        default now(),
+    last_modified_by_user_id     varchar(255)             not null        default '',
+    last_modified_by_user_nameid varchar(255)             not null        default ''
+);
+
+-- a view expands a.* when it is created, recreate the views so they show the columns added above
+drop view if exists user_management.v_user;
+
+create view user_management.v_user as
+select a.*,
+       uom.membership_number,
+       uom.organization_id,
+       uom.organization_uid,
+       uom.organization_name,
+       uom.organization_type,
+       uom.organization_address,
+       uom.organization_state,
+       uom.organization_auth_source1,
+       uom.organization_auth_source2,
+       uom.organization_attribute1,
+       uom.organization_attribute2
+from user_management.user a
+         left join user_management.v_user_organization_membership uom on a.id = uom.user_id;
+
+drop view if exists user_management.v_organization_role;
+
+create view user_management.v_organization_role as
+select a.*,
+       r.uid                as role_uid,
+       r.organization_types as role_organization_types,
+       r.nameid             as role_nameid,
+       r.name               as role_name,
+       r.description        as role_description,
+       r.utag               as role_utag,
+       o.uid                as organization_uid,
+       o.name               as organization_name,
+       o.type               as organization_type,
+       o.address            as organization_address,
+       o.status             as organization_state,
+       o.auth_source1       as organization_auth_source1,
+       o.auth_source2       as organization_auth_source2,
+       o.attribute1         as organization_attribute1,
+       o.attribute2         as organization_attribute2
+from user_management.organization_role a
+         join user_management.role r on a.role_id = r.id
+         join user_management.organization o on a.organization_id = o.id;
+
+INSERT INTO general.property (nameid, type, value)
+VALUES ('PASSWORD_RESET_URL', 'STRING', '{
+  "value": "http://localhost/password/reset"
+}'::JSONB),
+       ('PASSWORD_POLICY', 'JSON', '{
+         "value": {
+           "min_length": 8,
+           "require_uppercase": true,
+           "require_lowercase": true,
+           "require_number": true,
+           "require_special": false,
+           "forbid_special": true,
+           "denylist_file": "",
+           "history_count": 5,
+           "max_age_day": 90,
+           "lockout_max_attempt": 5,
+           "lockout_window_second": 900,
+           "lockout_duration_second": 1800
+         }
+       }'::JSONB)
+ON CONFLICT (nameid) DO NOTHING;
+
+insert into user_management.privilege (nameid, name, description)
+values ('USER.UNLOCK', 'User Unlock', 'Unlock Users locked out after failed logins'),
+       ('USER.PASSWORD_HASH.REPORT', 'User Password Hash Report', 'Count Users still on legacy password hashes')
+on conflict (nameid) do nothing;
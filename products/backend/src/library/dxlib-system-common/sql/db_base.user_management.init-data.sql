@@ -65,6 +65,8 @@ values
        ('USER.ACTIVATE', 'User Activate', 'Activate Users'),
        ('USER.SUSPEND', 'User Suspend', 'Suspend Users'),
        ('USER.RESET_PASSWORD', 'User Reset Password', 'Reset User Password'),
+       ('USER.UNLOCK', 'User Unlock', 'Unlock Users locked out after failed logins'),
+       ('USER.PASSWORD_HASH.REPORT', 'User Password Hash Report', 'Count Users still on legacy password hashes'),
        ('USER.ID_CARD.UPDATE', 'User Identity Card Update', 'Update User Identity Card'),
        ('USER.ID_CARD.DOWNLOAD', 'User Identity Card Download', 'Download User Identity Card'),
        ('USER_MESSAGE.LIST', 'User Message List', 'List User Messages'),
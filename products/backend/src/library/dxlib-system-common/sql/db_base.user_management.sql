@@ -18,6 +18,9 @@ create table user_management.user
     gender                       varchar(1),                                                -- M, F
     address_on_identity_card     varchar(1024),
     must_change_password         boolean                  not null        default false,
+    login_failed_count           int                      not null        default 0,
+    login_failed_first_at        timestamp with time zone,
+    locked_until                 timestamp with time zone,
     is_avatar_exist              bool                     not null        default false,
     utag                         varchar(255) unique,
     is_deleted                   boolean                  not null        default false,
@@ -45,6 +48,62 @@ create table user_management.user_password
     last_modified_by_user_nameid varchar(255)             not null        default ''
 );
 
+create table user_management.user_totp
+(
+    id                           bigserial primary key,
+    uid                          varchar(1024)            not null unique default CONCAT(
+            to_hex((extract(epoch from now()) * 1000000)::bigint), gen_random_uuid()::text),
+    user_id                      bigint                   not null unique references user_management.user (id),
+    secret                       varchar(255)             not null,
+    is_enabled                   boolean                  not null        default false, -- set when the enrollment is confirmed with a first code
+    confirmed_at                 timestamp with time zone,
+    last_used_step               bigint                   not null        default 0,     -- a code of this or an earlier step is rejected as replayed
+    is_deleted                   boolean                  not null        default false,
+    created_at                   timestamp with time zone not null        default now(),
+    created_by_user_id           varchar(255)             not null        default '',
+    created_by_user_nameid       varchar(255)             not null        default '',
+    last_modified_at             timestamp with time zone not null        default now(),
+    last_modified_by_user_id     varchar(255)             not null        default '',
+    last_modified_by_user_nameid varchar(255)             not null        default ''
+);
+
+create table user_management.user_totp_recovery_code
+(
+    id                           bigserial primary key,
+    uid                          varchar(1024)            not null unique default CONCAT(
+            to_hex((extract(epoch from now()) * 1000000)::bigint), gen_random_uuid()::text),
+    user_id                      bigint                   not null references user_management.user (id),
+    value                        varchar(4096)            not null, -- SHA-256 of "<user_id>:<code>", older rows hold the legacy password hash
+    used_at                      timestamp with time zone,
+    is_deleted                   boolean                  not null        default false,
+    created_at                   timestamp with time zone not null        default now(),
+    created_by_user_id           varchar(255)             not null        default '',
+    created_by_user_nameid       varchar(255)             not null        default '',
+    last_modified_at             timestamp with time zone not null        default now(),
+    last_modified_by_user_id     varchar(255)             not null        default '',
+    last_modified_by_user_nameid varchar(255)             not null        default ''
+);
+
+create index user_totp_recovery_code_user_id_value on user_management.user_totp_recovery_code (user_id, value);
+
+create table user_management.user_password_reset_token
+(
+    id                           bigserial primary key,
+    uid                          varchar(1024)            not null unique default CONCAT(
+            to_hex((extract(epoch from now()) * 1000000)::bigint), gen_random_uuid()::text),
+    user_id                      bigint                   not null references user_management.user (id),
+    value                        varchar(255)             not null unique, -- SHA-256 of the token, the token itself is only sent to the user
+    expired_at                   timestamp with time zone not null,
+    used_at                      timestamp with time zone,
+    is_deleted                   boolean                  not null        default false,
+    created_at                   timestamp with time zone not null        default now(),
+    created_by_user_id           varchar(255)             not null        default '',
+    created_by_user_nameid       varchar(255)             not null        default '',
+    last_modified_at             timestamp with time zone not null        default now(),
+    last_modified_by_user_id     varchar(255)             not null        default '',
+    last_modified_by_user_nameid varchar(255)             not null        default ''
+);
+
 create table user_management.user_message
 (
     id                           bigserial primary key,
@@ -89,6 +148,7 @@ create table user_management.organization
     attribute2                   varchar(1024),
     utag                         varchar(255) unique,
     tags                         varchar(1024),
+    password_policy              jsonb,                                                     -- NULL or keys of the PASSWORD_POLICY property to override
     is_deleted                   boolean                  not null        default false,
     created_at                   timestamp with time zone not null        default now(),
     created_by_user_id           varchar(255)             not null        default '',
@@ -174,6 +234,7 @@ create table user_management.organization_role
             to_hex((extract(epoch from now()) * 1000000)::bigint), gen_random_uuid()::text),
     organization_id              bigint                   not null references user_management.organization (id),
     role_id                      bigint                   not null references user_management.role (id),
+    is_totp_required             boolean                  not null        default false, -- members with this role in the organization must login with TOTP
     is_deleted                   boolean                  not null        default false,
     created_at                   timestamp with time zone not null        default now(),
     created_by_user_id           varchar(255)             not null        default '',
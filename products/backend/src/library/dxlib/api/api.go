@@ -10,15 +10,20 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	_ "time/tzdata"
 
-	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 
 	dxlibConfiguration "github.com/donnyhardyanto/dxlib/configuration"
 	"github.com/donnyhardyanto/dxlib/core"
+	"github.com/donnyhardyanto/dxlib/health"
 	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/tracing"
 	"github.com/donnyhardyanto/dxlib/utils"
 
 	utilsHttp "github.com/donnyhardyanto/dxlib/utils/http"
@@ -33,6 +38,7 @@ const (
 var UseResponseDataObject = true
 
 type DXAPIAuditLogEntry struct {
+	RequestId    string    `json:"request_id,omitempty"`
 	StartTime    time.Time `json:"start_time,omitempty"`
 	EndTime      time.Time `json:"end_time,omitempty"`
 	IPAddress    string    `json:"ip_address,omitempty"`
@@ -55,12 +61,27 @@ type DXAPI struct {
 	Address                  string
 	WriteTimeoutSec          int
 	ReadTimeoutSec           int
-	EndPoints                []DXAPIEndPoint
+	EndPointTimeoutSec       int
+	StreamEndPointTimeoutSec int
+	FormBodyMaxSize          int64
+	MultiPartBodyMaxSize     int64
+	EndPoints                []*DXAPIEndPoint
+	Middlewares              []DXAPIMiddlewareFunc
+	CORS                     DXAPICORS
+	CORSEndPointOverrides    map[string]*DXAPICORS
+	TLS                      *DXAPITLS
+	ErrorResponseFormat      DXAPIErrorResponseFormat
+	ErrorTypeBaseURI         string
+	IsErrorProductionMode    bool
+	Idempotency              DXAPIIdempotency
 	RuntimeIsActive          bool
 	HTTPServer               *http.Server
 	Log                      log.DXLog
 	Context                  context.Context
 	Cancel                   context.CancelFunc
+	ShutdownContext          context.Context
+	ShutdownCancel           context.CancelFunc
+	RequestsInFlight         atomic.Int64
 	OnAuditLogStart          DXAuditLogHandler
 	OnAuditLogUserIdentified DXAuditLogHandler
 	OnAuditLogEnd            DXAuditLogHandler
@@ -78,8 +99,13 @@ func (a *DXAPI) APIHandlerPrintSpec(aepr *DXAPIEndPointRequest) (err error) {
 }
 
 func (a *DXAPI) PrintSpec() (s string, err error) {
-	s = "# API: " + a.NameId + "\n\n\n"
-	s += "## Version " + a.Version + "\n\n"
+	switch SpecFormat {
+	case "OpenAPI":
+		return a.PrintOpenAPISpecAsJSON()
+	case "OpenAPIYAML":
+		return a.PrintOpenAPISpecAsYAML()
+	}
+	s = a.printSpecHeaderAsMarkDown()
 	for _, v := range a.EndPoints {
 		spec, err := v.PrintSpec()
 		if err != nil {
@@ -90,26 +116,45 @@ func (a *DXAPI) PrintSpec() (s string, err error) {
 	return s, nil
 }
 
+func (a *DXAPI) printSpecHeaderAsMarkDown() string {
+	return "# API: " + a.NameId + "\n\n\n" + "## Version " + a.Version + "\n\n"
+}
+
+// PrintSpecAsMarkDown prints the API as MarkDown whatever SpecFormat is
+func (a *DXAPI) PrintSpecAsMarkDown() (s string) {
+	s = a.printSpecHeaderAsMarkDown()
+	for _, v := range a.EndPoints {
+		s += v.PrintSpecAsMarkDown() + "\n"
+	}
+	return s
+}
+
 type DXAPIManager struct {
-	Context           context.Context
-	Cancel            context.CancelFunc
-	APIs              map[string]*DXAPI
-	ErrorGroup        *errgroup.Group
-	ErrorGroupContext context.Context
+	Context             context.Context
+	Cancel              context.CancelFunc
+	APIs                map[string]*DXAPI
+	ErrorGroup          *errgroup.Group
+	ErrorGroupContext   context.Context
+	backgroundWaitGroup sync.WaitGroup
 }
 
 func (am *DXAPIManager) NewAPI(nameId string) (*DXAPI, error) {
 	ctx, cancel := context.WithCancel(am.Context)
-	a := DXAPI{
-		Version:   "1.0.0",
-		NameId:    nameId,
-		EndPoints: []DXAPIEndPoint{},
-		Context:   ctx,
-		Cancel:    cancel,
-		Log:       log.NewLog(&log.Log, ctx, nameId),
-	}
-	am.APIs[nameId] = &a
-	return &a, nil
+	shutdownCtx, shutdownCancel := context.WithCancel(ctx)
+	a := &DXAPI{
+		Version:               "1.0.0",
+		NameId:                nameId,
+		EndPoints:             []*DXAPIEndPoint{},
+		CORS:                  NewDefaultCORS(),
+		CORSEndPointOverrides: map[string]*DXAPICORS{},
+		Context:               ctx,
+		Cancel:                cancel,
+		ShutdownContext:       shutdownCtx,
+		ShutdownCancel:        shutdownCancel,
+		Log:                   log.NewLog(&log.Log, ctx, nameId),
+	}
+	am.APIs[nameId] = a
+	return a, nil
 }
 
 func (am *DXAPIManager) LoadFromConfiguration(configurationNameId string) (err error) {
@@ -138,19 +183,6 @@ func (am *DXAPIManager) StartAll(errorGroup *errgroup.Group, errorGroupContext c
 	am.ErrorGroup = errorGroup
 	am.ErrorGroupContext = errorGroupContext
 
-	am.ErrorGroup.Go(func() (err error) {
-		<-am.ErrorGroupContext.Done()
-		log.Log.Info("API Manager shutting down... start")
-		for _, v := range am.APIs {
-			vErr := v.StartShutdown()
-			if (err == nil) && (vErr != nil) {
-				err = vErr
-			}
-		}
-		log.Log.Info("API Manager shutting down... done")
-		return nil
-	})
-
 	for _, v := range am.APIs {
 		err := v.StartAndWait(am.ErrorGroup)
 		if err != nil {
@@ -160,11 +192,35 @@ func (am *DXAPIManager) StartAll(errorGroup *errgroup.Group, errorGroupContext c
 	return nil
 }
 
-func (am *DXAPIManager) StopAll() (err error) {
-	am.ErrorGroupContext.Done()
-	err = am.ErrorGroup.Wait()
+// StopAll stops accepting connections on every API and waits for the in-flight requests and then the work they started with GoBackground
+// until ctx is done, the requests still running at the deadline have their context cancelled
+func (am *DXAPIManager) StopAll(ctx context.Context) (err error) {
+	log.Log.Info("API Manager shutting down... start")
+	var wg sync.WaitGroup
+	errs := make([]error, 0, len(am.APIs))
+	var errsMutex sync.Mutex
+	for _, v := range am.APIs {
+		wg.Add(1)
+		go func(v *DXAPI) {
+			defer wg.Done()
+			vErr := v.StartShutdown(ctx)
+			if vErr != nil {
+				errsMutex.Lock()
+				errs = append(errs, vErr)
+				errsMutex.Unlock()
+			}
+		}(v)
+	}
+	wg.Wait()
+	err = am.WaitBackground(ctx)
 	if err != nil {
-		return errors.Wrap(err, "error occurred in Wait()")
+		log.Log.Warnf("API Manager shutting down... deadline reached, background work is still running")
+		errs = append(errs, err)
+	}
+	am.Cancel()
+	log.Log.Info("API Manager shutting down... done")
+	if len(errs) > 0 {
+		return errs[0]
 	}
 	return nil
 }
@@ -189,13 +245,60 @@ func (a *DXAPI) ApplyConfigurations(configurationNameId string) (err error) {
 	}
 	a.WriteTimeoutSec = utilsJSON.GetNumberWithDefault(c1, "writetimeout-sec", DXAPIDefaultWriteTimeoutSec)
 	a.ReadTimeoutSec = utilsJSON.GetNumberWithDefault(c1, "readtimeout-sec", DXAPIDefaultReadTimeoutSec)
+	a.EndPointTimeoutSec = utilsJSON.GetNumberWithDefault(c1, "endpoint-timeout-sec", DXAPIDefaultEndPointTimeoutSec)
+	a.StreamEndPointTimeoutSec = utilsJSON.GetNumberWithDefault(c1, "stream-endpoint-timeout-sec", DXAPIDefaultStreamEndPointTimeoutSec)
+	a.FormBodyMaxSize = utilsJSON.GetNumberWithDefault[int64](c1, "form-body-max-size-byte", DXAPIDefaultFormBodyMaxSize)
+	a.MultiPartBodyMaxSize = utilsJSON.GetNumberWithDefault[int64](c1, "multipart-body-max-size-byte", DXAPIDefaultMultiPartBodyMaxSize)
+	err = a.applyCORSConfiguration(c1)
+	if err != nil {
+		return log.Log.FatalAndCreateErrorf("CONFIGURATION_INVALID:%s.%s/cors:%s", configurationNameId, a.NameId, err.Error())
+	}
+	err = a.applyErrorConfiguration(c1)
+	if err != nil {
+		return log.Log.FatalAndCreateErrorf("CONFIGURATION_INVALID:%s.%s/error:%s", configurationNameId, a.NameId, err.Error())
+	}
+	idempotencyConfiguration, ok := c1["idempotency"].(utils.JSON)
+	if !ok {
+		idempotencyConfiguration = utils.JSON{}
+	}
+	a.Idempotency.ApplyConfiguration(a.NameId, idempotencyConfiguration)
+	tlsConfiguration, ok := c1["tls"].(utils.JSON)
+	if ok {
+		a.TLS = &DXAPITLS{}
+		err = a.TLS.ApplyConfiguration(tlsConfiguration)
+		if err != nil {
+			return log.Log.FatalAndCreateErrorf("CONFIGURATION_INVALID:%s.%s/tls:%s", configurationNameId, a.NameId, err.Error())
+		}
+	}
 	return nil
 }
 
 func (a *DXAPI) FindEndPointByURI(uri string) *DXAPIEndPoint {
 	for _, endPoint := range a.EndPoints {
 		if endPoint.Uri == uri {
-			return &endPoint
+			return endPoint
+		}
+	}
+	for _, endPoint := range a.EndPoints {
+		if _, ok := MatchURITemplate(endPoint.Uri, uri); ok {
+			return endPoint
+		}
+	}
+	return nil
+}
+
+func (a *DXAPI) FindEndPointByURIAndMethod(uri string, method string) *DXAPIEndPoint {
+	for _, endPoint := range a.EndPoints {
+		if (endPoint.Uri == uri) && (endPoint.Method == method) {
+			return endPoint
+		}
+	}
+	for _, endPoint := range a.EndPoints {
+		if endPoint.Method != method {
+			continue
+		}
+		if _, ok := MatchURITemplate(endPoint.Uri, uri); ok {
+			return endPoint
 		}
 	}
 	return nil
@@ -221,10 +324,16 @@ func (a *DXAPI) NewEndPoint(title, description, uri, method string, endPointType
 	onWSLoop DXAPIEndPointExecuteFunc, responsePossibilities map[string]*DXAPIEndPointResponsePossibility, middlewares []DXAPIEndPointExecuteFunc,
 	privileges []string, requestMaxContentLength int64, rateLimitGroupNameId string) *DXAPIEndPoint {
 
-	t := a.FindEndPointByURI(uri)
-	if t != nil {
-		log.Log.Fatalf("Duplicate endpoint uri %s", uri)
+	for _, endPoint := range a.EndPoints {
+		if (endPoint.Uri == uri) && (endPoint.Method == method) {
+			log.Log.Fatalf("Duplicate endpoint uri %s %s", method, uri)
+		}
+	}
+	err := validateParameterConstraints(parameters, "")
+	if err != nil {
+		log.Log.Fatalf("Invalid parameter constraint of endpoint %s %s: %+v", method, uri, err)
 	}
+	pathParameters, parameters := splitPathParameters(uri, parameters)
 	ae := DXAPIEndPoint{
 		Owner:                   a,
 		Title:                   title,
@@ -234,6 +343,7 @@ func (a *DXAPI) NewEndPoint(title, description, uri, method string, endPointType
 		EndPointType:            endPointType,
 		RequestContentType:      contentType,
 		Parameters:              parameters,
+		PathParameters:          pathParameters,
 		OnExecute:               onExecute,
 		OnWSLoop:                onWSLoop,
 		ResponsePossibilities:   responsePossibilities,
@@ -242,13 +352,19 @@ func (a *DXAPI) NewEndPoint(title, description, uri, method string, endPointType
 		RequestMaxContentLength: requestMaxContentLength,
 		RateLimitGroupNameId:    rateLimitGroupNameId,
 	}
-	a.EndPoints = append(a.EndPoints, ae)
+	a.EndPoints = append(a.EndPoints, &ae)
 	return &ae
 }
 
 func (a *DXAPI) routeHandler(w http.ResponseWriter, r *http.Request, p *DXAPIEndPoint) {
-	requestContext, span := otel.Tracer(a.Log.Prefix).Start(a.Context, "routeHandler|"+p.Uri)
-	defer span.End()
+	a.RequestsInFlight.Add(1)
+	defer a.RequestsInFlight.Add(-1)
+
+	requestContext, span := tracing.StartSpan(tracing.ExtractHTTPHeader(a.Context, r.Header), r.Method+" "+p.Uri, trace.SpanKindServer,
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.HTTPRoute(p.Uri),
+		semconv.URLPath(r.URL.Path),
+	)
 
 	var aepr *DXAPIEndPointRequest
 	var err error
@@ -259,30 +375,27 @@ func (a *DXAPI) routeHandler(w http.ResponseWriter, r *http.Request, p *DXAPIEnd
 		}
 	}()
 
+	aepr = p.NewEndPointRequest(requestContext, w, r)
+	defer aepr.applyTimeout()()
+	defer aepr.traceEnd(span)
+	defer aepr.metricsBegin()()
+
 	auditLogId := int64(0)
 	auditLogStartTime := time.Now()
-
-	if a.OnAuditLogStart != nil {
-		auditLogId, err = a.OnAuditLogStart(auditLogId, &DXAPIAuditLogEntry{
-			StartTime: auditLogStartTime,
-			IPAddress: GetIPAddress(r),
-			APIURL:    r.URL.Path,
-			APITitle:  p.Title,
-			Method:    r.Method,
-		})
-	}
+	auditLogErrorMessage := ""
 
 	defer func() {
 		if a.OnAuditLogEnd != nil {
 			_, err = a.OnAuditLogEnd(auditLogId, &DXAPIAuditLogEntry{
-				StartTime:  auditLogStartTime,
-				EndTime:    time.Now(),
-				StatusCode: aepr.ResponseStatusCode,
+				RequestId:    aepr.Id,
+				StartTime:    auditLogStartTime,
+				EndTime:      time.Now(),
+				StatusCode:   aepr.ResponseStatusCode,
+				ErrorMessage: auditLogErrorMessage,
 			})
 		}
 	}()
 
-	aepr = p.NewEndPointRequest(requestContext, w, r)
 	defer func() {
 		if (err != nil) && (dxlib.IsDebug) && (p.RequestContentType == utilsHttp.ContentTypeApplicationJSON) {
 			if aepr.RequestBodyAsBytes != nil {
@@ -293,6 +406,30 @@ func (a *DXAPI) routeHandler(w http.ResponseWriter, r *http.Request, p *DXAPIEnd
 		}
 	}()
 
+	// deferred last so the audit log, metrics and span above see the 500 of a recovered panic
+	defer func() {
+		if v := recover(); v != nil {
+			panicError := newPanicError(v)
+			aepr.onPanic(panicError)
+			auditLogErrorMessage = panicError.Error()
+			return
+		}
+		if aepr.IsTimedOut() {
+			auditLogErrorMessage = aepr.newTimeoutError(nil).Error()
+		}
+	}()
+
+	if a.OnAuditLogStart != nil {
+		auditLogId, err = a.OnAuditLogStart(auditLogId, &DXAPIAuditLogEntry{
+			RequestId: aepr.Id,
+			StartTime: auditLogStartTime,
+			IPAddress: GetIPAddress(r),
+			APIURL:    r.URL.Path,
+			APITitle:  p.Title,
+			Method:    r.Method,
+		})
+	}
+
 	err = aepr.PreProcessRequest()
 	if err != nil {
 		aepr.WriteResponseAsError(http.StatusBadRequest, err)
@@ -305,99 +442,116 @@ func (a *DXAPI) routeHandler(w http.ResponseWriter, r *http.Request, p *DXAPIEnd
 		return
 	}
 
-	aepr.Log.Debugf("Middleware Start: %s", aepr.EndPoint.Uri)
+	// the API and group middlewares wrap the endpoint middlewares and the endpoint itself
+	err = aepr.executeMiddlewares(func() (err error) {
+		aepr.Log.Debugf("Middleware Start: %s", aepr.EndPoint.Uri)
 
-	for _, middleware := range p.Middlewares {
+		for _, middleware := range p.Middlewares {
 
-		err = middleware(aepr)
-		if err != nil {
-			err3 := errors.Wrap(err, fmt.Sprintf("MIDDLEWARE_ERROR:\n%+v", err))
-			aepr.WriteResponseAsError(http.StatusBadRequest, err3)
-			requestDump, err2 := aepr.RequestDump()
-			if err2 != nil {
-				aepr.Log.Errorf(err2, "REQUEST_DUMP_ERROR:%v", err2.Error())
-				return
+			err = middleware(aepr)
+			if err != nil {
+				aepr.writeResponseAsMiddlewareError(err)
+				return err
 			}
-			aepr.Log.Errorf(err3, "ONMIDDLEWARE_ERROR:%v\nRaw Request :\n%v\n", err3, string(requestDump))
-			return
-		}
 
-	}
+		}
 
-	aepr.Log.Debugf("Middleware Done: %s", aepr.EndPoint.Uri)
+		aepr.Log.Debugf("Middleware Done: %s", aepr.EndPoint.Uri)
+
+		if aepr.CurrentUser.Id != "" {
+			if a.OnAuditLogUserIdentified != nil {
+				_, _ = a.OnAuditLogUserIdentified(auditLogId, &DXAPIAuditLogEntry{
+					RequestId:    aepr.Id,
+					StartTime:    auditLogStartTime,
+					IPAddress:    GetIPAddress(r),
+					APIURL:       r.URL.Path,
+					APITitle:     p.Title,
+					Method:       r.Method,
+					UserId:       aepr.CurrentUser.Id,
+					UserUid:      aepr.CurrentUser.Uid,
+					UserLoginId:  aepr.CurrentUser.LoginId,
+					UserFullName: aepr.CurrentUser.FullName,
+				})
+			}
 
-	if aepr.CurrentUser.Id != "" {
-		if a.OnAuditLogUserIdentified != nil {
-			_, err = a.OnAuditLogUserIdentified(auditLogId, &DXAPIAuditLogEntry{
-				StartTime:    auditLogStartTime,
-				IPAddress:    GetIPAddress(r),
-				APIURL:       r.URL.Path,
-				APITitle:     p.Title,
-				Method:       r.Method,
-				UserId:       aepr.CurrentUser.Id,
-				UserUid:      aepr.CurrentUser.Uid,
-				UserLoginId:  aepr.CurrentUser.LoginId,
-				UserFullName: aepr.CurrentUser.FullName,
-			})
 		}
 
-	}
-
-	if p.OnExecute != nil {
-		err = p.OnExecute(aepr)
-		if err != nil {
-			aepr.Log.Errorf(err, "ONEXECUTE_ERROR:\n%+v\n", err)
+		if p.IsIdempotencyKeyEnabled && (p.EndPointType == EndPointTypeHTTPJSON) {
+			isHandled, finish := aepr.idempotencyBegin()
+			if isHandled {
+				return err
+			}
+			defer finish()
+		}
 
-			requestDump, err2 := aepr.RequestDump()
-			if err2 != nil {
-				aepr.Log.Errorf(err2, "REQUEST_DUMP_ERROR:%+v", err2)
-				return
+		if p.EndPointType == EndPointTypeWS {
+			err = aepr.executeWSLoop()
+			if panicError, ok := asPanicError(err); ok {
+				aepr.onPanic(panicError)
+				auditLogErrorMessage = panicError.Error()
+				return err
 			}
-			aepr.Log.Errorf(err, "ONEXECUTE_ERROR:%v\nRaw Request :\n%+v\n", err, string(requestDump))
+			if err != nil {
+				aepr.Log.Errorf(err, "ONWSLOOP_ERROR:\n%+v\n", err)
+			}
+			return err
+		}
 
-			if !aepr.ResponseHeaderSent {
-				s := fmt.Sprintf("ONEXECUTE_ERROR:%v", err.Error())
-				err = aepr.WriteResponseAndNewErrorf(http.StatusBadRequest, s, s)
-				return
+		if p.EndPointType == EndPointTypeSSE {
+			cleanup := aepr.newSSEStream()
+			defer cleanup()
+		}
+
+		if p.OnExecute != nil {
+			err = p.OnExecute(aepr)
+			if (err != nil) && (aepr.SSEStream != nil) && (aepr.SSEStream.Context.Err() != nil) {
+				aepr.Log.Infof("SSE stream closed: %s", err.Error())
+				err = nil
 			}
-		} else {
-			if !aepr.ResponseHeaderSent {
-				aepr.WriteResponseAsString(http.StatusOK, nil, "")
+			if err != nil {
+				aepr.Log.Errorf(err, "ONEXECUTE_ERROR:\n%+v\n", err)
+
+				requestDump, err2 := aepr.RequestDump()
+				if err2 != nil {
+					aepr.Log.Errorf(err2, "REQUEST_DUMP_ERROR:%+v", err2)
+					return err
+				}
+				aepr.Log.Errorf(err, "ONEXECUTE_ERROR:%v\nRaw Request :\n%+v\n", err, string(requestDump))
+
+				if !aepr.ResponseHeaderSent && aepr.IsTimedOut() {
+					aepr.WriteResponseAsError(http.StatusGatewayTimeout, aepr.newTimeoutError(err))
+					return err
+				}
+				var apiError *DXAPIError
+				if !aepr.ResponseHeaderSent && errors.As(err, &apiError) {
+					aepr.WriteResponseAsError(apiError.Definition.StatusCode, err)
+					return err
+				}
+				if !aepr.ResponseHeaderSent && !aepr.isLegacyErrorResponse() {
+					aepr.WriteResponseAsError(http.StatusBadRequest, ErrorOnExecute.Wrap(err, ""))
+					return err
+				}
+				if !aepr.ResponseHeaderSent {
+					s := fmt.Sprintf("ONEXECUTE_ERROR:%v", err.Error())
+					err = aepr.WriteResponseAndNewErrorf(http.StatusBadRequest, s, s)
+					return err
+				}
+			} else {
+				if !aepr.ResponseHeaderSent {
+					aepr.WriteResponseAsString(http.StatusOK, nil, "")
+				}
 			}
 		}
-	}
+		return err
+	})
 	return
 }
 
-func (a *DXAPI) StartAndWait(errorGroup *errgroup.Group) error {
-	if a.RuntimeIsActive {
-		return errors.New("SERVER_ALREADY_ACTIVE")
-	}
+// NewHTTPHandler routes the endpoints of the API, StartAndWait serves it and tests may serve it with httptest
+func (a *DXAPI) NewHTTPHandler() http.Handler {
+	a.applyCORSEndPointOverrides()
 
 	mux := http.NewServeMux()
-	a.HTTPServer = &http.Server{
-		Addr:         a.Address,
-		Handler:      mux,
-		WriteTimeout: time.Duration(a.WriteTimeoutSec) * time.Second,
-		ReadTimeout:  time.Duration(a.ReadTimeoutSec) * time.Second,
-	}
-
-	// CORS middleware
-	corsMiddleware := func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Consider restricting this to specific origins in production
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET,POST,HEAD,PUT,DELETE,PATCH,OPTION")
-			w.Header().Set("Access-Control-Allow-Headers", "Authorization,X-Var,*")
-			w.Header().Set("Access-Control-Expose-Headers", "X-Var")
-
-			if r.Method == http.MethodOptions {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
-			next.ServeHTTP(w, r)
-		})
-	}
 
 	// Handler wrapper that adds New Relic if enabled
 	wrapHandler := func(handler http.HandlerFunc, name string) http.HandlerFunc {
@@ -416,22 +570,71 @@ func (a *DXAPI) StartAndWait(errorGroup *errgroup.Group) error {
 		}
 	}
 
-	// Set up routes
-	for _, endpoint := range a.EndPoints {
-		p := endpoint
+	// Set up routes, endpoints sharing the same uri are dispatched by method
+	uris := []string{}
+	endPointsByUri := map[string][]*DXAPIEndPoint{}
+	for _, p := range a.EndPoints {
+		if _, ok := endPointsByUri[p.Uri]; !ok {
+			uris = append(uris, p.Uri)
+		}
+		endPointsByUri[p.Uri] = append(endPointsByUri[p.Uri], p)
+	}
+	for _, uri := range uris {
+		endPoints := endPointsByUri[uri]
 		handlerFunc := func(w http.ResponseWriter, r *http.Request) {
-			a.routeHandler(w, r, &p)
+			p := endPoints[0]
+			for _, e := range endPoints {
+				if e.Method == r.Method {
+					p = e
+					break
+				}
+			}
+			a.routeHandler(w, r, p)
 		}
 
 		// Always use the wrapper - it will handle both New Relic enabled and disabled cases
-		wrappedHandler := wrapHandler(handlerFunc, p.Uri)
-		mux.Handle(p.Uri, corsMiddleware(http.HandlerFunc(wrappedHandler)))
+		wrappedHandler := wrapHandler(handlerFunc, uri)
+		mux.Handle(uri, a.corsHandler(endPoints, http.HandlerFunc(wrappedHandler)))
+	}
+
+	return mux
+}
+
+func (a *DXAPI) StartAndWait(errorGroup *errgroup.Group) error {
+	if a.RuntimeIsActive {
+		return errors.New("SERVER_ALREADY_ACTIVE")
 	}
 
+	a.HTTPServer = &http.Server{
+		Addr:         a.Address,
+		Handler:      a.NewHTTPHandler(),
+		WriteTimeout: time.Duration(a.WriteTimeoutSec) * time.Second,
+		ReadTimeout:  time.Duration(a.ReadTimeoutSec) * time.Second,
+	}
+	isTLS := (a.TLS != nil) && a.TLS.IsEnabled
+	if a.TLS != nil {
+		a.HTTPServer.Protocols = a.TLS.NewProtocols()
+	}
+	if isTLS {
+		tlsConfig, err := a.TLS.NewTLSConfig()
+		if err != nil {
+			return errors.Wrap(err, "error occured in NewTLSConfig()")
+		}
+		a.HTTPServer.TLSConfig = tlsConfig
+	}
+
+	a.HTTPServer.RegisterOnShutdown(a.closeWSConnections)
+
 	errorGroup.Go(func() error {
 		a.RuntimeIsActive = true
-		log.Log.Infof("Listening at %s... start", a.Address)
-		err := a.HTTPServer.ListenAndServe()
+		var err error
+		if isTLS {
+			log.Log.Infof("Listening at %s (TLS)... start", a.Address)
+			err = a.HTTPServer.ListenAndServeTLS("", "")
+		} else {
+			log.Log.Infof("Listening at %s... start", a.Address)
+			err = a.HTTPServer.ListenAndServe()
+		}
 		if (err != nil) && (!errors.Is(err, http.ErrServerClosed)) {
 			log.Log.Errorf(err, "HTTP server error: %+v", err)
 		}
@@ -443,13 +646,21 @@ func (a *DXAPI) StartAndWait(errorGroup *errgroup.Group) error {
 	return nil
 }
 
-func (a *DXAPI) StartShutdown() (err error) {
+// StartShutdown stops accepting connections and waits for the in-flight requests until ctx is done, SSE streams are ended right away.
+// At the deadline the remaining connections are closed and the request contexts cancelled
+func (a *DXAPI) StartShutdown(ctx context.Context) (err error) {
+	health.Manager.SetShuttingDown(true)
+	a.ShutdownCancel()
 	if a.RuntimeIsActive {
-		log.Log.Infof("Shutdown api %s start...", a.NameId)
-		err = a.HTTPServer.Shutdown(core.RootContext)
+		log.Log.Infof("Shutdown api %s start... %d requests in flight", a.NameId, a.RequestsInFlight.Load())
+		err = a.HTTPServer.Shutdown(ctx)
 		if err != nil {
+			log.Log.Warnf("Shutdown api %s deadline reached, closing %d requests in flight", a.NameId, a.RequestsInFlight.Load())
+			_ = a.HTTPServer.Close()
+			a.Cancel()
 			return errors.Wrap(err, "error occurred in HTTPServer.Shutdown()")
 		}
+		log.Log.Infof("Shutdown api %s done", a.NameId)
 		return nil
 	}
 	return nil
@@ -458,7 +669,8 @@ func (a *DXAPI) StartShutdown() (err error) {
 var Manager DXAPIManager
 
 func init() {
-	ctx, cancel := context.WithCancel(core.RootContext)
+	// request contexts are not cancelled by the shutdown signal so in-flight requests can finish, StopAll cancels them at the deadline
+	ctx, cancel := context.WithCancel(context.WithoutCancel(core.RootContext))
 	Manager = DXAPIManager{
 		Context: ctx,
 		Cancel:  cancel,
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+
+	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/pkg/errors"
+)
+
+// CallRecovered calls f and returns its panic as a DXAPIPanicError, so work outside a request can report a panic like any other failure
+func CallRecovered(f func() (err error)) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newPanicError(r)
+		}
+	}()
+	return f()
+}
+
+// GoBackground runs f in a goroutine StopAll waits for, it is meant for the work a request hands off to run after its response.
+// A panic of f is recovered and logged with its stack instead of ending the process
+func (am *DXAPIManager) GoBackground(l *log.DXLog, f func()) {
+	am.backgroundWaitGroup.Add(1)
+	go func() {
+		defer am.backgroundWaitGroup.Done()
+		err := CallRecovered(func() (err error) {
+			f()
+			return nil
+		})
+		if panicError, ok := asPanicError(err); ok {
+			l.Errorf(panicError, "PANIC_RECOVERED_IN_BACKGROUND\n%s", string(panicError.Stack))
+		}
+	}()
+}
+
+// WaitBackground waits until the work started by GoBackground finishes or ctx is done
+func (am *DXAPIManager) WaitBackground(ctx context.Context) (err error) {
+	done := make(chan struct{})
+	go func() {
+		am.backgroundWaitGroup.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "BACKGROUND_STILL_RUNNING")
+	}
+}
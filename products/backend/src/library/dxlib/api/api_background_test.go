@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/pkg/errors"
+)
+
+func TestCallRecovered(t *testing.T) {
+	errFailed := errors.New("FAILED")
+	tests := []struct {
+		name        string
+		f           func() (err error)
+		wantErr     error
+		wantPanic   bool
+		wantMessage string
+	}{
+		{name: "no error", f: func() (err error) { return nil }},
+		{name: "error is returned", f: func() (err error) { return errFailed }, wantErr: errFailed},
+		{name: "panic becomes an error", f: func() (err error) { panic("bad row") }, wantPanic: true, wantMessage: "PANIC:bad row"},
+		{
+			name: "runtime panic becomes an error",
+			f: func() (err error) {
+				var m map[string]int
+				m["a"] = 1
+				return nil
+			},
+			wantPanic: true, wantMessage: "PANIC:assignment to entry in nil map",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CallRecovered(tt.f)
+			panicError, isPanic := asPanicError(err)
+			if isPanic != tt.wantPanic {
+				t.Fatalf("CallRecovered() err = %v, want panic %v", err, tt.wantPanic)
+			}
+			if isPanic {
+				if panicError.Error() != tt.wantMessage {
+					t.Errorf("CallRecovered() err = %s, want %s", panicError.Error(), tt.wantMessage)
+				}
+				if len(panicError.Stack) == 0 {
+					t.Errorf("CallRecovered() err has no stack")
+				}
+				return
+			}
+			if err != tt.wantErr {
+				t.Errorf("CallRecovered() err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGoBackground(t *testing.T) {
+	l := log.NewLog(nil, context.Background(), "test")
+
+	t.Run("a panic does not end the process and is waited for", func(t *testing.T) {
+		am := &DXAPIManager{}
+		am.GoBackground(&l, func() {
+			panic("bad row")
+		})
+		err := am.WaitBackground(context.Background())
+		if err != nil {
+			t.Errorf("WaitBackground() err = %v", err)
+		}
+	})
+
+	t.Run("wait returns once the work finished", func(t *testing.T) {
+		am := &DXAPIManager{}
+		release := make(chan struct{})
+		isFinished := false
+		am.GoBackground(&l, func() {
+			<-release
+			isFinished = true
+		})
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+		}()
+		err := am.WaitBackground(context.Background())
+		if err != nil {
+			t.Fatalf("WaitBackground() err = %v", err)
+		}
+		if !isFinished {
+			t.Errorf("WaitBackground() returned before the work finished")
+		}
+	})
+
+	t.Run("deadline reached while the work is running", func(t *testing.T) {
+		am := &DXAPIManager{}
+		release := make(chan struct{})
+		defer close(release)
+		am.GoBackground(&l, func() {
+			<-release
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := am.WaitBackground(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("WaitBackground() err = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("StopAll waits for the background work", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		am := &DXAPIManager{Context: ctx, Cancel: cancel, APIs: map[string]*DXAPI{}}
+		release := make(chan struct{})
+		isFinished := false
+		am.GoBackground(&l, func() {
+			<-release
+			isFinished = true
+		})
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+		}()
+		err := am.StopAll(context.Background())
+		if err != nil {
+			t.Fatalf("StopAll() err = %v", err)
+		}
+		if !isFinished {
+			t.Errorf("StopAll() returned before the background work finished")
+		}
+	})
+}
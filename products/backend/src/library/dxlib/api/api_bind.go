@@ -0,0 +1,329 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+/*
+Bind and ParametersOf share one struct declaration, a field is a parameter when it has a `param` tag:
+
+	type UserCreateParameter struct {
+		OrganizationId int64   `param:"organization_id,required" description:"Organization that user belong to"`
+		Email          string  `param:"email,type=email,required" description:"Email"`
+		Attribute      *string `param:"attribute" description:"Attribute"`
+	}
+
+The first tag item is the parameter nameid, options are "required", "nullable", "type=<parameter type>" and the constraints
+"min=", "max=", "minlength=", "maxlength=", "minitems=", "maxitems=", "enum=<a|b|c>" and "pattern=", pattern must be the last option since it may contain commas.
+Without "type=" the parameter type follows the Go type, nested structs become "json" and slices of structs become "array-json-template".
+A pointer field is nullable and stays nil when the parameter is absent or null.
+*/
+
+const BindTagName = "param"
+
+type bindField struct {
+	Index       []int
+	Parameter   DXAPIEndPointParameter
+	ElementType reflect.Type
+}
+
+var (
+	bindFieldsCache    sync.Map
+	typeTime           = reflect.TypeOf(time.Time{})
+	typeUUID           = reflect.TypeOf(uuid.UUID{})
+	typeDecimal        = reflect.TypeOf(decimal.Decimal{})
+	typeDateTimeRange  = reflect.TypeOf(utils.DateTimeRange{})
+	typeBytes          = reflect.TypeOf([]byte{})
+	typeRequestFilePtr = reflect.TypeOf(&DXAPIEndPointRequestFile{})
+)
+
+func bindParameterTypeOf(t reflect.Type) (aType string, isNullable bool, elementType reflect.Type) {
+	if (t.Kind() == reflect.Pointer) && (t != typeRequestFilePtr) {
+		aType, _, elementType = bindParameterTypeOf(t.Elem())
+		switch aType {
+		case "int64":
+			aType = "nullable-int64"
+		case "string":
+			aType = "nullable-string"
+		case "bool":
+			aType = "nullable-bool"
+		case "float64":
+			aType = "nullable-float64"
+		}
+		return aType, true, elementType
+	}
+	switch {
+	case t == typeRequestFilePtr:
+		return "file", false, nil
+	case t == typeTime:
+		return "iso8601", false, nil
+	case t == typeUUID:
+		return "uuid", false, nil
+	case t == typeDecimal:
+		return "decimal", false, nil
+	case t == typeDateTimeRange:
+		return "datetime-range", false, nil
+	case t == typeBytes:
+		return "base64-bytes", false, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string", false, nil
+	case reflect.Bool:
+		return "bool", false, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int64", false, nil
+	case reflect.Float32:
+		return "float32", false, nil
+	case reflect.Float64:
+		return "float64", false, nil
+	case reflect.Struct:
+		return "json", false, t
+	case reflect.Map:
+		return "json-passthrough", false, nil
+	case reflect.Slice:
+		e := t.Elem()
+		for e.Kind() == reflect.Pointer {
+			e = e.Elem()
+		}
+		switch {
+		case e == typeUUID:
+			return "array-uuid", false, nil
+		case (e.Kind() == reflect.Struct) && (e != typeTime) && (e != typeDecimal) && (e != typeDateTimeRange):
+			return "array-json-template", false, e
+		case e.Kind() == reflect.String:
+			return "array-string", false, nil
+		case (e.Kind() >= reflect.Int) && (e.Kind() <= reflect.Uint64):
+			return "array-int64", false, nil
+		case (e.Kind() == reflect.Float32) || (e.Kind() == reflect.Float64):
+			return "array-float64", false, nil
+		}
+		return "array", false, nil
+	}
+	return "", false, nil
+}
+
+// bindFields parses the `param` tags of a struct type once, nested struct children are filled in the returned parameters
+func bindFields(t reflect.Type) (fields []bindField, err error) {
+	if v, ok := bindFieldsCache.Load(t); ok {
+		return v.([]bindField), nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, errors.Errorf("BIND_TARGET_IS_NOT_STRUCT:%s", t.String())
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup(BindTagName)
+		if !ok || (tag == "-") || !f.IsExported() {
+			continue
+		}
+		items := strings.Split(tag, ",")
+		aType, isNullable, elementType := bindParameterTypeOf(f.Type)
+		p := DXAPIEndPointParameter{
+			NameId:      strings.TrimSpace(items[0]),
+			Type:        aType,
+			Description: f.Tag.Get("description"),
+			IsNullable:  isNullable,
+		}
+		if p.NameId == "" {
+			return nil, errors.Errorf("BIND_FIELD_HAS_NO_NAMEID:%s.%s", t.String(), f.Name)
+		}
+		for j := 1; j < len(items); j++ {
+			item := strings.TrimSpace(items[j])
+			k, v, _ := strings.Cut(item, "=")
+			switch k {
+			case "required":
+				p.IsMustExist = true
+			case "nullable":
+				p.IsNullable = true
+			case "type":
+				p.Type = v
+			case "min", "max":
+				n, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					return nil, errors.Errorf("BIND_TAG_OPTION_INVALID:%s.%s=%s", t.String(), f.Name, item)
+				}
+				if k == "min" {
+					p.Constraint.Min = &n
+				} else {
+					p.Constraint.Max = &n
+				}
+			case "minlength", "maxlength", "minitems", "maxitems":
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					return nil, errors.Errorf("BIND_TAG_OPTION_INVALID:%s.%s=%s", t.String(), f.Name, item)
+				}
+				switch k {
+				case "minlength":
+					p.Constraint.MinLength = &n
+				case "maxlength":
+					p.Constraint.MaxLength = &n
+				case "minitems":
+					p.Constraint.MinItems = &n
+				case "maxitems":
+					p.Constraint.MaxItems = &n
+				}
+			case "enum":
+				p.Constraint.Enum = strings.Split(v, "|")
+			case "pattern":
+				_, p.Constraint.Pattern, _ = strings.Cut(strings.Join(items[j:], ","), "=")
+				j = len(items)
+			case "":
+			default:
+				return nil, errors.Errorf("BIND_TAG_OPTION_UNKNOWN:%s.%s=%s", t.String(), f.Name, item)
+			}
+		}
+		if p.Type == "" {
+			return nil, errors.Errorf("BIND_FIELD_TYPE_UNKNOWN:%s.%s", t.String(), f.Name)
+		}
+		if elementType != nil {
+			children, err := bindFields(elementType)
+			if err != nil {
+				return nil, errors.Wrap(err, "error occured")
+			}
+			for _, c := range children {
+				p.Children = append(p.Children, c.Parameter)
+			}
+		}
+		fields = append(fields, bindField{Index: f.Index, Parameter: p, ElementType: elementType})
+	}
+	bindFieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+// ParametersOf derives the endpoint parameter declaration from the `param` tags of T, it panics on an invalid declaration since it is only used while defining endpoints
+func ParametersOf[T any]() []DXAPIEndPointParameter {
+	fields, err := bindFields(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		panic(err)
+	}
+	parameters := make([]DXAPIEndPointParameter, 0, len(fields))
+	for _, f := range fields {
+		parameters = append(parameters, f.Parameter)
+	}
+	return parameters
+}
+
+func bindIsNumberKind(k reflect.Kind) bool {
+	return (k >= reflect.Int) && (k <= reflect.Float64)
+}
+
+// bindAssign stores a validated parameter value into dst, converting between the JSON decoded shapes and the declared Go type
+func bindAssign(dst reflect.Value, v any, path string) (err error) {
+	if v == nil {
+		return nil
+	}
+	src := reflect.ValueOf(v)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.Pointer:
+		e := reflect.New(dst.Type().Elem())
+		err = bindAssign(e.Elem(), v, path)
+		if err != nil {
+			return err
+		}
+		dst.Set(e)
+		return nil
+	case reflect.Interface:
+		if src.Type().Implements(dst.Type()) {
+			dst.Set(src)
+			return nil
+		}
+	case reflect.Struct:
+		if (dst.Type() != typeTime) && (src.Kind() == reflect.Map) && (src.Type().Key().Kind() == reflect.String) {
+			fields, err := bindFields(dst.Type())
+			if err != nil {
+				return err
+			}
+			for _, f := range fields {
+				fv := src.MapIndex(reflect.ValueOf(f.Parameter.NameId))
+				if !fv.IsValid() {
+					continue
+				}
+				err = bindAssign(dst.FieldByIndex(f.Index), fv.Interface(), path+"."+f.Parameter.NameId)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.Slice:
+		if (src.Kind() == reflect.Slice) || (src.Kind() == reflect.Array) {
+			s := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+			for i := 0; i < src.Len(); i++ {
+				err = bindAssign(s.Index(i), src.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i))
+				if err != nil {
+					return err
+				}
+			}
+			dst.Set(s)
+			return nil
+		}
+	case reflect.Map:
+		if src.Type().ConvertibleTo(dst.Type()) {
+			dst.Set(src.Convert(dst.Type()))
+			return nil
+		}
+	default:
+		if bindIsNumberKind(dst.Kind()) && bindIsNumberKind(src.Kind()) {
+			if (src.Kind() == reflect.Float32) || (src.Kind() == reflect.Float64) {
+				f := src.Float()
+				if (dst.Kind() < reflect.Float32) && (f != math.Trunc(f)) {
+					break
+				}
+			}
+			dst.Set(src.Convert(dst.Type()))
+			return nil
+		}
+		if (dst.Kind() == src.Kind()) && src.Type().ConvertibleTo(dst.Type()) {
+			dst.Set(src.Convert(dst.Type()))
+			return nil
+		}
+	}
+	apiError := ErrorRequestFieldValueIsNotType.Newf("%s!=%s (%v)", path, dst.Type().String(), v)
+	apiError.Parameter = path
+	return apiError
+}
+
+// Bind fills T from the validated parameter values, fields of parameters that are absent or null keep their zero value, so a pointer field
+// tells a sent value, even an empty one, from an absent one without looking at ParameterValues
+func Bind[T any](aepr *DXAPIEndPointRequest) (val T, err error) {
+	rv := reflect.ValueOf(&val).Elem()
+	fields, err := bindFields(rv.Type())
+	if err != nil {
+		return val, aepr.Log.ErrorAndCreateErrorf("BIND_DECLARATION_ERROR:%s", err.Error())
+	}
+	for _, f := range fields {
+		pv, ok := aepr.ParameterValues[f.Parameter.NameId]
+		if !ok || (pv.Value == nil) {
+			if f.Parameter.IsMustExist && !f.Parameter.IsNullable {
+				return val, aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorMandatoryParameterNotExist, "%s", f.Parameter.NameId)
+			}
+			continue
+		}
+		err = bindAssign(rv.FieldByIndex(f.Index), pv.Value, f.Parameter.NameId)
+		var apiError *DXAPIError
+		if errors.As(err, &apiError) {
+			return val, aepr.WriteResponseAndNewAPIError(http.StatusBadRequest, apiError)
+		}
+		if err != nil {
+			return val, aepr.WriteResponseAndNewErrorf(http.StatusBadRequest, "", "%s", err.Error())
+		}
+	}
+	return val, nil
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type bindTestParameter struct {
+	Id        int64    `param:"id,required" description:"Id"`
+	Name      string   `param:"name,minlength=1,maxlength=8" description:"Name"`
+	Email     string   `param:"email,type=email,required" description:"Email"`
+	Attribute *string  `param:"attribute" description:"Attribute"`
+	ParentId  *int64   `param:"parent_id" description:"Parent id"`
+	Gender    string   `param:"gender,enum=m|f" description:"Gender"`
+	Tags      []string `param:"tags" description:"Tags"`
+	Ignored   string
+}
+
+func TestParametersOf(t *testing.T) {
+	parameters := ParametersOf[bindTestParameter]()
+	byNameId := map[string]DXAPIEndPointParameter{}
+	for _, p := range parameters {
+		byNameId[p.NameId] = p
+	}
+	if len(parameters) != 7 {
+		t.Fatalf("len(ParametersOf) = %d, want 7", len(parameters))
+	}
+
+	tests := []struct {
+		nameId         string
+		wantType       string
+		wantMustExist  bool
+		wantIsNullable bool
+	}{
+		{nameId: "id", wantType: "int64", wantMustExist: true},
+		{nameId: "name", wantType: "string"},
+		{nameId: "email", wantType: "email", wantMustExist: true},
+		{nameId: "attribute", wantType: "nullable-string", wantIsNullable: true},
+		{nameId: "parent_id", wantType: "nullable-int64", wantIsNullable: true},
+		{nameId: "gender", wantType: "string"},
+		{nameId: "tags", wantType: "array-string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nameId, func(t *testing.T) {
+			p, ok := byNameId[tt.nameId]
+			if !ok {
+				t.Fatalf("parameter %s not declared", tt.nameId)
+			}
+			if p.Type != tt.wantType {
+				t.Errorf("Type = %s, want %s", p.Type, tt.wantType)
+			}
+			if p.IsMustExist != tt.wantMustExist {
+				t.Errorf("IsMustExist = %v, want %v", p.IsMustExist, tt.wantMustExist)
+			}
+			if p.IsNullable != tt.wantIsNullable {
+				t.Errorf("IsNullable = %v, want %v", p.IsNullable, tt.wantIsNullable)
+			}
+		})
+	}
+
+	name := byNameId["name"]
+	if (name.Constraint.MinLength == nil) || (*name.Constraint.MinLength != 1) || (name.Constraint.MaxLength == nil) || (*name.Constraint.MaxLength != 8) {
+		t.Errorf("name constraint = %+v, want minlength 1 and maxlength 8", name.Constraint)
+	}
+	if !reflect.DeepEqual(byNameId["gender"].Constraint.Enum, []string{"m", "f"}) {
+		t.Errorf("gender enum = %v, want [m f]", byNameId["gender"].Constraint.Enum)
+	}
+}
+
+func TestBindAssign(t *testing.T) {
+	s := "abc"
+	tests := []struct {
+		name    string
+		dst     any
+		v       any
+		want    any
+		wantErr bool
+	}{
+		{name: "int64 from json number", dst: new(int64), v: float64(42), want: int64(42)},
+		{name: "int64 from parsed path segment", dst: new(int64), v: int64(9007199254740993), want: int64(9007199254740993)},
+		{name: "int64 from fraction", dst: new(int64), v: 1.5, wantErr: true},
+		{name: "string", dst: new(string), v: "abc", want: "abc"},
+		{name: "string from number", dst: new(string), v: float64(1), wantErr: true},
+		{name: "pointer string", dst: new(*string), v: "abc", want: &s},
+		{name: "nil keeps zero value", dst: new(string), v: nil, want: ""},
+		{name: "slice of string", dst: new([]string), v: []any{"a", "b"}, want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := reflect.ValueOf(tt.dst).Elem()
+			err := bindAssign(dst, tt.v, "p")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("bindAssign() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(dst.Interface(), tt.want) {
+				t.Errorf("bindAssign() = %#v, want %#v", dst.Interface(), tt.want)
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	empty := ""
+	parentId := int64(7)
+	tests := []struct {
+		name          string
+		values        map[string]any
+		want          bindTestParameter
+		wantStatus    int
+		wantParameter string
+	}{
+		{
+			name:   "absent optional pointers stay nil",
+			values: map[string]any{"id": int64(1), "email": "a@example.com"},
+			want:   bindTestParameter{Id: 1, Email: "a@example.com"},
+		},
+		{
+			name:   "sent empty string is told from an absent one",
+			values: map[string]any{"id": int64(1), "email": "a@example.com", "attribute": "", "parent_id": int64(7)},
+			want:   bindTestParameter{Id: 1, Email: "a@example.com", Attribute: &empty, ParentId: &parentId},
+		},
+		{
+			name:   "null keeps a pointer nil",
+			values: map[string]any{"id": int64(1), "email": "a@example.com", "attribute": nil},
+			want:   bindTestParameter{Id: 1, Email: "a@example.com"},
+		},
+		{
+			name:       "missing mandatory parameter",
+			values:     map[string]any{"email": "a@example.com"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:          "value of another type names the parameter",
+			values:        map[string]any{"id": int64(1), "email": "a@example.com", "tags": []any{"a", float64(1)}},
+			wantStatus:    http.StatusUnprocessableEntity,
+			wantParameter: "tags[1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aepr, recorder := testErrorRequest(ErrorResponseFormatProblemJSON, false)
+			aepr.ParameterValues = map[string]*DXAPIEndPointRequestParameterValue{}
+			for k, v := range tt.values {
+				aepr.ParameterValues[k] = &DXAPIEndPointRequestParameterValue{Owner: aepr, Value: v}
+			}
+			got, err := Bind[bindTestParameter](aepr)
+			if tt.wantStatus != 0 {
+				if err == nil {
+					t.Fatalf("Bind() err = nil, want status %d", tt.wantStatus)
+				}
+				if recorder.Code != tt.wantStatus {
+					t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+				}
+				if (tt.wantParameter != "") && !strings.Contains(recorder.Body.String(), `"parameter":"`+tt.wantParameter+`"`) {
+					t.Errorf("body = %s, want parameter %s", recorder.Body.String(), tt.wantParameter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Bind() err = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Bind() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+	utilsJSON "github.com/donnyhardyanto/dxlib/utils/json"
+	"github.com/pkg/errors"
+)
+
+type DXAPICORS struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAgeSec        int
+}
+
+// NewDefaultCORS keeps the behaviour of APIs without a "cors" configuration block
+func NewDefaultCORS() DXAPICORS {
+	return DXAPICORS{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   nil,
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "X-Var", DXAPIIdempotencyKeyHeader},
+		ExposedHeaders:   []string{"X-Var", DXAPIIdempotencyReplayedHeader},
+		AllowCredentials: false,
+		MaxAgeSec:        0,
+	}
+}
+
+func configurationAsStringList(v any) (r []string, err error) {
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		r = []string{}
+		for _, s := range strings.Split(t, ",") {
+			s = strings.TrimSpace(s)
+			if s != "" {
+				r = append(r, s)
+			}
+		}
+		return r, nil
+	case []string:
+		return t, nil
+	case []any:
+		r = []string{}
+		for _, i := range t {
+			s, ok := i.(string)
+			if !ok {
+				return nil, errors.Errorf("CONFIGURATION_VALUE_IS_NOT_STRING:%v", i)
+			}
+			r = append(r, s)
+		}
+		return r, nil
+	default:
+		return nil, errors.Errorf("CONFIGURATION_VALUE_IS_NOT_STRING_LIST:%v", v)
+	}
+}
+
+// ApplyConfiguration returns a copy of c overlaid with the keys present in the configuration
+func (c DXAPICORS) ApplyConfiguration(j utils.JSON) (r DXAPICORS, err error) {
+	r = c
+	keys := map[string]*[]string{
+		"allowed-origins": &r.AllowedOrigins,
+		"allowed-methods": &r.AllowedMethods,
+		"allowed-headers": &r.AllowedHeaders,
+		"exposed-headers": &r.ExposedHeaders,
+	}
+	for k, target := range keys {
+		v, ok := j[k]
+		if !ok {
+			continue
+		}
+		*target, err = configurationAsStringList(v)
+		if err != nil {
+			return c, errors.Wrap(err, k)
+		}
+		if k == "allowed-methods" {
+			for i, m := range r.AllowedMethods {
+				r.AllowedMethods[i] = strings.ToUpper(strings.TrimSpace(m))
+			}
+		}
+	}
+	if _, ok := j["allow-credentials"]; ok {
+		r.AllowCredentials, err = utilsJSON.GetBool(j, "allow-credentials")
+		if err != nil {
+			return c, errors.Wrap(err, "error occured")
+		}
+	}
+	// browsers refuse "*" with credentials, reflecting every origin instead would let any site make credentialed calls
+	if r.AllowCredentials && r.isAnyOriginAllowed() {
+		return c, errors.New("CORS_ALLOWED_ORIGINS_WILDCARD_WITH_ALLOW_CREDENTIALS")
+	}
+	switch v := j["max-age-sec"].(type) {
+	case float64:
+		r.MaxAgeSec = int(v)
+	case int:
+		r.MaxAgeSec = v
+	case string:
+		r.MaxAgeSec, err = strconv.Atoi(v)
+		if err != nil {
+			return c, errors.Wrap(err, "max-age-sec")
+		}
+	}
+	return r, nil
+}
+
+func corsOriginMatch(pattern string, origin string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if strings.EqualFold(pattern, origin) {
+		return true
+	}
+	if !strings.Contains(pattern, "*.") {
+		return false
+	}
+	p, err := url.Parse(pattern)
+	if err != nil {
+		return false
+	}
+	o, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if !strings.EqualFold(p.Scheme, o.Scheme) || (p.Port() != o.Port()) {
+		return false
+	}
+	patternHost := strings.ToLower(p.Hostname())
+	originHost := strings.ToLower(o.Hostname())
+	if !strings.HasPrefix(patternHost, "*.") {
+		return false
+	}
+	suffix := patternHost[1:]
+	return strings.HasSuffix(originHost, suffix) && (len(originHost) > len(suffix))
+}
+
+// IsOriginAllowed never matches "*" when credentials are allowed, only explicitly listed origins get credentialed access
+func (c *DXAPICORS) IsOriginAllowed(origin string) bool {
+	for _, pattern := range c.AllowedOrigins {
+		pattern = strings.TrimSpace(pattern)
+		if (pattern == "*") && c.AllowCredentials {
+			continue
+		}
+		if corsOriginMatch(pattern, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *DXAPICORS) isAnyOriginAllowed() bool {
+	for _, pattern := range c.AllowedOrigins {
+		if pattern == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *DXAPICORS) isAnyHeaderAllowed() bool {
+	for _, h := range c.AllowedHeaders {
+		if h == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *DXAPICORS) methodsFor(endPoints []*DXAPIEndPoint) (methods []string) {
+	isEndPointMethod := map[string]bool{}
+	for _, e := range endPoints {
+		isEndPointMethod[e.Method] = true
+	}
+	methods = []string{}
+	if c.AllowedMethods == nil {
+		for m := range isEndPointMethod {
+			methods = append(methods, m)
+		}
+	} else {
+		for _, m := range c.AllowedMethods {
+			if isEndPointMethod[m] {
+				methods = append(methods, m)
+			}
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+func (c *DXAPICORS) writeOriginHeaders(w http.ResponseWriter, origin string) {
+	w.Header().Add("Vary", "Origin")
+	if c.isAnyOriginAllowed() && !c.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	if c.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func endPointCORS(a *DXAPI, endPoint *DXAPIEndPoint) *DXAPICORS {
+	if (endPoint != nil) && (endPoint.CORS != nil) {
+		return endPoint.CORS
+	}
+	return &a.CORS
+}
+
+// corsHandler wraps all endpoints registered under the same uri, so preflight answers with the methods actually served there
+func (a *DXAPI) corsHandler(endPoints []*DXAPIEndPoint, next http.Handler) http.Handler {
+	findEndPoint := func(method string) *DXAPIEndPoint {
+		for _, e := range endPoints {
+			if e.Method == method {
+				return e
+			}
+		}
+		return nil
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		requestMethod := r.Header.Get("Access-Control-Request-Method")
+
+		if (r.Method == http.MethodOptions) && (requestMethod != "") {
+			endPoint := findEndPoint(strings.ToUpper(requestMethod))
+			c := endPointCORS(a, endPoint)
+			methods := c.methodsFor(endPoints)
+			isMethodAllowed := false
+			for _, m := range methods {
+				if m == strings.ToUpper(requestMethod) {
+					isMethodAllowed = true
+					break
+				}
+			}
+			if (origin == "") || (endPoint == nil) || !isMethodAllowed || !c.IsOriginAllowed(origin) {
+				w.Header().Add("Vary", "Origin")
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			c.writeOriginHeaders(w, origin)
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(append(methods, http.MethodOptions), ","))
+			requestHeaders := r.Header.Get("Access-Control-Request-Headers")
+			if c.isAnyHeaderAllowed() {
+				if requestHeaders != "" {
+					w.Header().Set("Access-Control-Allow-Headers", requestHeaders)
+				}
+			} else if len(c.AllowedHeaders) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ","))
+			}
+			if c.MaxAgeSec > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(c.MaxAgeSec))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		c := endPointCORS(a, findEndPoint(r.Method))
+		if (origin != "") && c.IsOriginAllowed(origin) {
+			c.writeOriginHeaders(w, origin)
+			if len(c.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ","))
+			}
+		} else if !c.isAnyOriginAllowed() || c.AllowCredentials {
+			// the answer depends on the origin, a cache must not hand it to an allowed origin
+			w.Header().Add("Vary", "Origin")
+		}
+
+		if r.Method == http.MethodOptions {
+			methods := c.methodsFor(endPoints)
+			w.Header().Set("Allow", strings.Join(append(methods, http.MethodOptions), ","))
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *DXAPI) applyCORSConfiguration(c1 utils.JSON) (err error) {
+	a.CORS = NewDefaultCORS()
+	a.CORSEndPointOverrides = map[string]*DXAPICORS{}
+	corsConfiguration, ok := c1["cors"].(utils.JSON)
+	if !ok {
+		return nil
+	}
+	a.CORS, err = a.CORS.ApplyConfiguration(corsConfiguration)
+	if err != nil {
+		return errors.Wrap(err, "error occured")
+	}
+	endPointConfigurations, ok := corsConfiguration["endpoints"].(utils.JSON)
+	if !ok {
+		return nil
+	}
+	for k, v := range endPointConfigurations {
+		endPointConfiguration, ok := v.(utils.JSON)
+		if !ok {
+			return errors.Errorf("CORS_ENDPOINT_CONFIGURATION_IS_NOT_JSON:%s", k)
+		}
+		endPointCORS, err := a.CORS.ApplyConfiguration(endPointConfiguration)
+		if err != nil {
+			return errors.Wrap(err, k)
+		}
+		a.CORSEndPointOverrides[k] = &endPointCORS
+	}
+	return nil
+}
+
+// applyCORSEndPointOverrides resolves configuration overrides keyed by "<METHOD> <uri>" or "<uri>"
+func (a *DXAPI) applyCORSEndPointOverrides() {
+	for _, e := range a.EndPoints {
+		if c, ok := a.CORSEndPointOverrides[e.Method+" "+e.Uri]; ok {
+			e.CORS = c
+			continue
+		}
+		if c, ok := a.CORSEndPointOverrides[e.Uri]; ok {
+			e.CORS = c
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+)
+
+func TestCORSIsOriginAllowed(t *testing.T) {
+	tests := []struct {
+		name   string
+		cors   DXAPICORS
+		origin string
+		want   bool
+	}{
+		{name: "wildcard", cors: DXAPICORS{AllowedOrigins: []string{"*"}}, origin: "https://evil.example", want: true},
+		{name: "wildcard ignored with credentials", cors: DXAPICORS{AllowedOrigins: []string{"*"}, AllowCredentials: true}, origin: "https://evil.example", want: false},
+		{name: "exact", cors: DXAPICORS{AllowedOrigins: []string{"https://app.example.com"}}, origin: "https://app.example.com", want: true},
+		{name: "exact is case insensitive", cors: DXAPICORS{AllowedOrigins: []string{"https://App.Example.com"}}, origin: "https://app.example.com", want: true},
+		{name: "exact with credentials", cors: DXAPICORS{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true}, origin: "https://app.example.com", want: true},
+		{name: "other origin", cors: DXAPICORS{AllowedOrigins: []string{"https://app.example.com"}}, origin: "https://app.example.org", want: false},
+		{name: "subdomain wildcard", cors: DXAPICORS{AllowedOrigins: []string{"https://*.example.com"}}, origin: "https://a.b.example.com", want: true},
+		{name: "subdomain wildcard excludes the bare domain", cors: DXAPICORS{AllowedOrigins: []string{"https://*.example.com"}}, origin: "https://example.com", want: false},
+		{name: "subdomain wildcard excludes a lookalike domain", cors: DXAPICORS{AllowedOrigins: []string{"https://*.example.com"}}, origin: "https://evilexample.com", want: false},
+		{name: "subdomain wildcard checks the scheme", cors: DXAPICORS{AllowedOrigins: []string{"https://*.example.com"}}, origin: "http://a.example.com", want: false},
+		{name: "subdomain wildcard checks the port", cors: DXAPICORS{AllowedOrigins: []string{"https://*.example.com:8443"}}, origin: "https://a.example.com", want: false},
+		{name: "nothing allowed", cors: DXAPICORS{}, origin: "https://app.example.com", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cors.IsOriginAllowed(tt.origin); got != tt.want {
+				t.Errorf("IsOriginAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORSApplyConfiguration(t *testing.T) {
+	tests := []struct {
+		name    string
+		j       utils.JSON
+		want    DXAPICORS
+		wantErr bool
+	}{
+		{name: "empty keeps the default", j: utils.JSON{}, want: NewDefaultCORS()},
+		{
+			name: "lists from strings and arrays",
+			j: utils.JSON{
+				"allowed-origins":   "https://a.example.com, https://b.example.com",
+				"allowed-methods":   []any{"get", " post"},
+				"allow-credentials": true,
+				"max-age-sec":       float64(600),
+			},
+			want: DXAPICORS{
+				AllowedOrigins:   []string{"https://a.example.com", "https://b.example.com"},
+				AllowedMethods:   []string{"GET", "POST"},
+				AllowedHeaders:   NewDefaultCORS().AllowedHeaders,
+				ExposedHeaders:   NewDefaultCORS().ExposedHeaders,
+				AllowCredentials: true,
+				MaxAgeSec:        600,
+			},
+		},
+		{name: "wildcard origin with credentials", j: utils.JSON{"allow-credentials": true}, wantErr: true},
+		{name: "origin is not a string", j: utils.JSON{"allowed-origins": []any{1}}, wantErr: true},
+		{name: "max age is not a number", j: utils.JSON{"max-age-sec": "ten"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewDefaultCORS().ApplyConfiguration(tt.j)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyConfiguration() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ApplyConfiguration() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORSHandler(t *testing.T) {
+	credentialed := DXAPICORS{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"X-Var"},
+		AllowCredentials: true,
+		MaxAgeSec:        600,
+	}
+	a := &DXAPI{CORS: NewDefaultCORS()}
+	endPoints := []*DXAPIEndPoint{
+		{Method: http.MethodGet, Uri: "/v1/item"},
+		{Method: http.MethodPost, Uri: "/v1/item", CORS: &credentialed},
+	}
+	handler := a.corsHandler(endPoints, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		name                 string
+		method               string
+		header               map[string]string
+		wantStatusCode       int
+		wantAllowOrigin      string
+		wantAllowCredentials string
+		wantAllowMethods     string
+		wantAllowHeaders     string
+		wantExposeHeaders    string
+		wantMaxAge           string
+		wantVary             bool
+	}{
+		{
+			name:           "preflight of the default policy",
+			method:         http.MethodOptions,
+			header:         map[string]string{"Origin": "https://any.example", "Access-Control-Request-Method": "GET"},
+			wantStatusCode: http.StatusNoContent, wantAllowOrigin: "*", wantAllowMethods: "GET,POST,OPTIONS",
+			wantAllowHeaders: "Authorization,Content-Type,X-Var,Idempotency-Key", wantVary: true,
+		},
+		{
+			name:           "preflight of the credentialed endpoint",
+			method:         http.MethodOptions,
+			header:         map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "post"},
+			wantStatusCode: http.StatusNoContent, wantAllowOrigin: "https://app.example.com", wantAllowCredentials: "true",
+			wantAllowMethods: "GET,POST,OPTIONS", wantAllowHeaders: "Authorization,Content-Type", wantMaxAge: "600", wantVary: true,
+		},
+		{
+			name:           "preflight of the credentialed endpoint from another origin",
+			method:         http.MethodOptions,
+			header:         map[string]string{"Origin": "https://evil.example", "Access-Control-Request-Method": "POST"},
+			wantStatusCode: http.StatusForbidden, wantVary: true,
+		},
+		{
+			name:           "preflight of a method not served",
+			method:         http.MethodOptions,
+			header:         map[string]string{"Origin": "https://any.example", "Access-Control-Request-Method": "DELETE"},
+			wantStatusCode: http.StatusForbidden, wantVary: true,
+		},
+		{
+			name:           "preflight without origin",
+			method:         http.MethodOptions,
+			header:         map[string]string{"Access-Control-Request-Method": "GET"},
+			wantStatusCode: http.StatusForbidden, wantVary: true,
+		},
+		{
+			name:           "simple request of the default policy",
+			method:         http.MethodGet,
+			header:         map[string]string{"Origin": "https://any.example"},
+			wantStatusCode: http.StatusTeapot, wantAllowOrigin: "*", wantExposeHeaders: "X-Var,Idempotent-Replayed", wantVary: true,
+		},
+		{
+			name:           "simple request of the credentialed endpoint",
+			method:         http.MethodPost,
+			header:         map[string]string{"Origin": "https://app.example.com"},
+			wantStatusCode: http.StatusTeapot, wantAllowOrigin: "https://app.example.com", wantAllowCredentials: "true", wantExposeHeaders: "X-Var", wantVary: true,
+		},
+		{
+			name:           "simple request of the credentialed endpoint from another origin is served without cors headers",
+			method:         http.MethodPost,
+			header:         map[string]string{"Origin": "https://evil.example"},
+			wantStatusCode: http.StatusTeapot, wantVary: true,
+		},
+		{
+			name:           "request of the credentialed endpoint without origin",
+			method:         http.MethodPost,
+			wantStatusCode: http.StatusTeapot, wantVary: true,
+		},
+		{
+			name:           "request of the default policy without origin",
+			method:         http.MethodGet,
+			wantStatusCode: http.StatusTeapot,
+		},
+		{
+			name:           "plain options",
+			method:         http.MethodOptions,
+			wantStatusCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/v1/item", nil)
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("status code = %d, want %d", w.Code, tt.wantStatusCode)
+			}
+			checks := []struct {
+				header string
+				want   string
+			}{
+				{header: "Access-Control-Allow-Origin", want: tt.wantAllowOrigin},
+				{header: "Access-Control-Allow-Credentials", want: tt.wantAllowCredentials},
+				{header: "Access-Control-Allow-Methods", want: tt.wantAllowMethods},
+				{header: "Access-Control-Allow-Headers", want: tt.wantAllowHeaders},
+				{header: "Access-Control-Expose-Headers", want: tt.wantExposeHeaders},
+				{header: "Access-Control-Max-Age", want: tt.wantMaxAge},
+			}
+			for _, c := range checks {
+				if got := w.Header().Get(c.header); got != c.want {
+					t.Errorf("%s = %q, want %q", c.header, got, c.want)
+				}
+			}
+			if isVary := w.Header().Get("Vary") != ""; isVary != tt.wantVary {
+				t.Errorf("Vary set = %v, want %v", isVary, tt.wantVary)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 )
 
 type DXAPIEndPointType int
@@ -18,6 +19,7 @@ const (
 	EndPointTypeHTTPUploadStream
 	EndPointTypeHTTPDownloadStream
 	EndPointTypeWS
+	EndPointTypeSSE
 )
 
 type DXAPIEndPointParameter struct {
@@ -29,29 +31,39 @@ type DXAPIEndPointParameter struct {
 	IsMustExist bool
 	IsNullable  bool
 	Children    []DXAPIEndPointParameter
+	// FileMaxSize and FileContentTypes only apply to "file" parameters of multipart/form-data endpoints
+	FileMaxSize      int64
+	FileContentTypes []string
+	Constraint       DXAPIEndPointParameterConstraint
 }
 
 func (aep *DXAPIEndPointParameter) PrintSpec(leftIndent int64) (s string) {
 	switch SpecFormat {
 	case "MarkDown":
-		r := ""
-		if aep.IsMustExist {
-			r = "mandatory"
-		} else {
-			r = "optional"
-		}
-		s += fmt.Sprintf("%*s - %s (%s) %s %s\n", leftIndent, "", aep.NameId, aep.Type, r, aep.Description)
-		if len(aep.Children) > 0 {
-			for _, c := range aep.Children {
-				s += c.PrintSpec(leftIndent + 2)
-			}
-		}
+		s = aep.PrintSpecAsMarkDown(leftIndent)
 	case "PostmanCollection":
 	}
 
 	return s
 }
 
+// PrintSpecAsMarkDown prints the parameter and its children as MarkDown whatever SpecFormat is
+func (aep *DXAPIEndPointParameter) PrintSpecAsMarkDown(leftIndent int64) (s string) {
+	r := ""
+	if aep.IsMustExist {
+		r = "mandatory"
+	} else {
+		r = "optional"
+	}
+	s += fmt.Sprintf("%*s - %s (%s) %s %s\n", leftIndent, "", aep.NameId, aep.Type, r, aep.Description)
+	if len(aep.Children) > 0 {
+		for _, c := range aep.Children {
+			s += c.PrintSpecAsMarkDown(leftIndent + 2)
+		}
+	}
+	return s
+}
+
 type DXAPIEndPointResponsePossibility struct {
 	Owner        *DXAPIEndPoint
 	StatusCode   int
@@ -71,6 +83,7 @@ type DXAPIEndPoint struct {
 	Description             string
 	RequestContentType      utilsHttp.RequestContentType
 	Parameters              []DXAPIEndPointParameter
+	PathParameters          []DXAPIEndPointParameter
 	OnExecute               DXAPIEndPointExecuteFunc
 	OnWSLoop                DXAPIEndPointExecuteFunc
 	ResponsePossibilities   map[string]*DXAPIEndPointResponsePossibility
@@ -78,50 +91,17 @@ type DXAPIEndPoint struct {
 	Privileges              []string
 	RequestMaxContentLength int64
 	RateLimitGroupNameId    string
+	CORS                    *DXAPICORS
+	IsIdempotencyKeyEnabled bool
+	// Timeout is the deadline of aepr.Context, when 0 the endpoint-timeout-sec or stream-endpoint-timeout-sec of the API applies
+	Timeout time.Duration
+	Group   *DXAPIGroup
 }
 
 func (aep *DXAPIEndPoint) PrintSpec() (s string, err error) {
 	switch SpecFormat {
 	case "MarkDown":
-		s = fmt.Sprintf("## %s\n", aep.Title)
-		s += fmt.Sprintf("####  Description: %s\n", aep.Description)
-		s += fmt.Sprintf("####  URI: %s\n", aep.Uri)
-		s += fmt.Sprintf("####  Method: %s\n", aep.Method)
-		s += fmt.Sprintf("####  Request Content Type: %s\n", aep.RequestContentType)
-		s += fmt.Sprintf("####  Request Content Length: %d\n", aep.RequestMaxContentLength)
-		s += "####  Parameters:\n"
-		for _, p := range aep.Parameters {
-			s += p.PrintSpec(4)
-		}
-		s += "####  Response Possibilities:\n"
-		keys := make([]string, 0, len(aep.ResponsePossibilities))
-
-		// Add the keys to the slice
-		for k := range aep.ResponsePossibilities {
-			keys = append(keys, k)
-		}
-
-		// Sort the keys based on StatusCode
-		sort.Slice(keys, func(i, j int) bool {
-			return aep.ResponsePossibilities[keys[i]].StatusCode < aep.ResponsePossibilities[keys[j]].StatusCode
-		})
-
-		// Now you can range over the keys slice and use it to access the map
-		for _, k := range keys {
-			v := aep.ResponsePossibilities[k]
-			//fmt.Println("Key:", k, "StatusCode:", aep.ResponsePossibilities[k].StatusCode)
-			s += fmt.Sprintf("    %s\n", k)
-			s += fmt.Sprintf("      Status Code: %d\n", v.StatusCode)
-			s += fmt.Sprintf("      Description: %s\n", v.Description)
-			s += "      Headers:\n"
-			for hk, hv := range v.Headers {
-				s += fmt.Sprintf("        %s: %s\n", hk, hv)
-			}
-			s += "      Data Template:\n"
-			for _, p := range v.DataTemplate {
-				s += p.PrintSpec(8)
-			}
-		}
+		s = aep.PrintSpecAsMarkDown()
 	case "PostmanCollection":
 		collection := map[string]any{
 			"info": map[string]any{
@@ -177,6 +157,56 @@ func (aep *DXAPIEndPoint) PrintSpec() (s string, err error) {
 	return s, nil
 }
 
+// PrintSpecAsMarkDown prints the endpoint as MarkDown whatever SpecFormat is
+func (aep *DXAPIEndPoint) PrintSpecAsMarkDown() (s string) {
+	s = fmt.Sprintf("## %s\n", aep.Title)
+	s += fmt.Sprintf("####  Description: %s\n", aep.Description)
+	s += fmt.Sprintf("####  URI: %s\n", aep.Uri)
+	s += fmt.Sprintf("####  Method: %s\n", aep.Method)
+	s += fmt.Sprintf("####  Request Content Type: %s\n", aep.RequestContentType)
+	s += fmt.Sprintf("####  Request Content Length: %d\n", aep.RequestMaxContentLength)
+	if len(aep.PathParameters) > 0 {
+		s += "####  Path Parameters:\n"
+		for _, p := range aep.PathParameters {
+			s += p.PrintSpecAsMarkDown(4)
+		}
+	}
+	s += "####  Parameters:\n"
+	for _, p := range aep.Parameters {
+		s += p.PrintSpecAsMarkDown(4)
+	}
+	s += "####  Response Possibilities:\n"
+	keys := make([]string, 0, len(aep.ResponsePossibilities))
+
+	// Add the keys to the slice
+	for k := range aep.ResponsePossibilities {
+		keys = append(keys, k)
+	}
+
+	// Sort the keys based on StatusCode
+	sort.Slice(keys, func(i, j int) bool {
+		return aep.ResponsePossibilities[keys[i]].StatusCode < aep.ResponsePossibilities[keys[j]].StatusCode
+	})
+
+	// Now you can range over the keys slice and use it to access the map
+	for _, k := range keys {
+		v := aep.ResponsePossibilities[k]
+		//fmt.Println("Key:", k, "StatusCode:", aep.ResponsePossibilities[k].StatusCode)
+		s += fmt.Sprintf("    %s\n", k)
+		s += fmt.Sprintf("      Status Code: %d\n", v.StatusCode)
+		s += fmt.Sprintf("      Description: %s\n", v.Description)
+		s += "      Headers:\n"
+		for hk, hv := range v.Headers {
+			s += fmt.Sprintf("        %s: %s\n", hk, hv)
+		}
+		s += "      Data Template:\n"
+		for _, p := range v.DataTemplate {
+			s += p.PrintSpecAsMarkDown(8)
+		}
+	}
+	return s
+}
+
 func (aep *DXAPIEndPoint) NewParameter(parent *DXAPIEndPointParameter, nameId, aType, description string, isMustExist bool) *DXAPIEndPointParameter {
 	nameId = strings.TrimSpace(nameId)
 	aType = strings.TrimSpace(aType)
@@ -197,7 +227,11 @@ func (aep *DXAPIEndPoint) NewParameter(parent *DXAPIEndPointParameter, nameId, a
 }
 
 func (aep *DXAPIEndPoint) NewEndPointRequest(context context.Context, w http.ResponseWriter, r *http.Request) *DXAPIEndPointRequest {
+	requestId := RequestIdOf(r)
+	context = log.ContextWithRequestId(context, requestId)
+	w.Header().Set(DXAPIRequestIdHeader, requestId)
 	er := &DXAPIEndPointRequest{
+		Id:              requestId,
 		Context:         context,
 		ResponseWriter:  &w,
 		Request:         r,
@@ -206,7 +240,7 @@ func (aep *DXAPIEndPoint) NewEndPointRequest(context context.Context, w http.Res
 		LocalData:       map[string]any{},
 		SuppressLogDump: false,
 	}
-	er.Id = fmt.Sprintf("%p", er)
+	er.ClientCertificateSubjects = ClientCertificateSubjects(r)
 	er.Log = log.NewLog(&aep.Owner.Log, context, aep.Title+" | "+er.Id)
 	return er
 }
@@ -25,22 +25,25 @@ type DXAPIUser struct {
 }
 
 type DXAPIEndPointRequest struct {
-	Id                     string
-	Context                context.Context
-	EndPoint               *DXAPIEndPoint
-	ParameterValues        map[string]*DXAPIEndPointRequestParameterValue
-	Log                    log.DXLog
-	Request                *http.Request
-	RequestBodyAsBytes     []byte
-	ResponseWriter         *http.ResponseWriter
-	_responseErrorAsString string
-	ResponseStatusCode     int
-	ErrorMessage           []string
-	CurrentUser            DXAPIUser
-	LocalData              map[string]any
-	ResponseHeaderSent     bool
-	ResponseBodySent       bool
-	SuppressLogDump        bool
+	Id                        string
+	Context                   context.Context
+	EndPoint                  *DXAPIEndPoint
+	ParameterValues           map[string]*DXAPIEndPointRequestParameterValue
+	Log                       log.DXLog
+	Request                   *http.Request
+	RequestBodyAsBytes        []byte
+	ResponseWriter            *http.ResponseWriter
+	_responseErrorAsString    string
+	ResponseStatusCode        int
+	ErrorMessage              []string
+	CurrentUser               DXAPIUser
+	LocalData                 map[string]any
+	ResponseHeaderSent        bool
+	ResponseBodySent          bool
+	SuppressLogDump           bool
+	ClientCertificateSubjects []string
+	WSConnection              *DXAPIWSConnection
+	SSEStream                 *DXAPISSEStream
 }
 
 func (aepr *DXAPIEndPointRequest) GetParameterValues() (r utils.JSON) {
@@ -114,6 +117,7 @@ func (aepr *DXAPIEndPointRequest) GetResponseWriter() *http.ResponseWriter {
 }
 
 func (aepr *DXAPIEndPointRequest) WriteResponseAndNewErrorf(statusCode int, responseMessage string, msg string, data ...any) (err error) {
+	publicResponseMessage := responseMessage
 	if responseMessage == "" {
 		responseMessage = strings.ToUpper(http.StatusText(statusCode))
 	}
@@ -121,6 +125,10 @@ func (aepr *DXAPIEndPointRequest) WriteResponseAndNewErrorf(statusCode int, resp
 		msg = responseMessage
 	}
 	err = aepr.Log.WarnAndCreateErrorf(msg, data...)
+	if !aepr.isLegacyErrorResponse() {
+		aepr.writeResponseAsProblem(statusCode, nil, fmt.Sprintf(msg, data...), publicResponseMessage)
+		return err
+	}
 	s := responseMessage
 	if data != nil {
 		s = fmt.Sprintf(responseMessage, data)
@@ -172,6 +180,10 @@ func (aepr *DXAPIEndPointRequest) WriteResponseAsError(statusCode int, errToSend
 	if aepr.ResponseHeaderSent {
 		return
 	}
+	if !aepr.isLegacyErrorResponse() {
+		aepr.writeResponseAsProblem(statusCode, errToSend, "", "")
+		return
+	}
 	if (200 <= statusCode) && (statusCode < 300) {
 		statusCode = 500
 	}
@@ -193,6 +205,12 @@ func (aepr *DXAPIEndPointRequest) WriteResponseAsErrorMessage(statusCode int, er
 	if aepr.ResponseHeaderSent {
 		return
 	}
+	if !aepr.isLegacyErrorResponse() {
+		// errorMsg is the text the legacy body shows, so it is public as a whole
+		_, detail := errorCodeAndDetail(errorMsg)
+		aepr.writeResponseAsProblem(statusCode, nil, errorMsg, detail)
+		return
+	}
 	if (200 <= statusCode) && (statusCode < 300) {
 		statusCode = 500
 	}
@@ -296,7 +314,7 @@ func (aepr *DXAPIEndPointRequest) NewAPIEndPointRequestParameter(aepp DXAPIEndPo
 func (aepr *DXAPIEndPointRequest) PreProcessRequest() (err error) {
 	if aepr.EndPoint.RequestMaxContentLength > 0 {
 		if aepr.Request.ContentLength > aepr.EndPoint.RequestMaxContentLength {
-			return aepr.WriteResponseAndNewErrorf(http.StatusRequestEntityTooLarge, "", "REQUEST_MAX_CONTENT_LENGTH_EXCEEDED:%d<%d", aepr.EndPoint.RequestMaxContentLength, aepr.Request.ContentLength)
+			return aepr.WriteResponseAndNewAPIErrorf(http.StatusRequestEntityTooLarge, ErrorRequestMaxContentLengthExceeded, "%d<%d", aepr.EndPoint.RequestMaxContentLength, aepr.Request.ContentLength)
 		}
 	}
 	aepr.ParameterValues = map[string]*DXAPIEndPointRequestParameterValue{}
@@ -311,7 +329,24 @@ func (aepr *DXAPIEndPointRequest) PreProcessRequest() (err error) {
 			aepr.WriteResponseAsBytes(http.StatusOK, nil, []byte(""))
 			return nil
 		}
-		return aepr.WriteResponseAndNewErrorf(http.StatusMethodNotAllowed, "", "METHOD_NOT_ALLOWED:%s!=%s", aepr.Request.Method, aepr.EndPoint.Method)
+		return aepr.WriteResponseAndNewAPIErrorf(http.StatusMethodNotAllowed, ErrorMethodNotAllowed, "%s!=%s", aepr.Request.Method, aepr.EndPoint.Method)
+	}
+	for _, v := range aepr.EndPoint.PathParameters {
+		rpv := aepr.NewAPIEndPointRequestParameter(v)
+		variablePath := v.NameId
+		s := aepr.Request.PathValue(v.NameId)
+		if s == "" {
+			return aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorMandatoryPathParameterNotExist, "%s", variablePath)
+		}
+		err = rpv.SetRawValue(stringAsRawValue(v.Type, s), variablePath)
+		if err != nil {
+			return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", err.Error())
+		}
+		err = rpv.Validate()
+		if err != nil {
+			aepr.WriteResponseAsError(http.StatusUnprocessableEntity, err)
+			return errors.Wrap(err, "error occured")
+		}
 	}
 	xVar := aepr.Request.Header.Get("X-Var")
 	var xVarJSON map[string]interface{}
@@ -343,7 +378,7 @@ func (aepr *DXAPIEndPointRequest) PreProcessRequest() (err error) {
 			if rpv.Metadata.IsMustExist {
 				if rpv.RawValue == nil {
 					if !rpv.Metadata.IsNullable {
-						return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "MANDATORY_PARAMETER_NOT_EXIST:%s", variablePath)
+						return aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorMandatoryParameterNotExist, "%s", variablePath)
 					}
 				}
 			}
@@ -363,7 +398,7 @@ func (aepr *DXAPIEndPointRequest) PreProcessRequest() (err error) {
 				variablePath := v.NameId
 				if v.IsMustExist {
 					if !ok {
-						return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "MANDATORY_PARAMETER_NOT_EXIST:%s", variablePath)
+						return aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorMandatoryParameterNotExist, "%s", variablePath)
 					}
 				}
 				if rpv.RawValue != nil {
@@ -377,6 +412,10 @@ func (aepr *DXAPIEndPointRequest) PreProcessRequest() (err error) {
 			err = aepr.preProcessRequestAsApplicationOctetStream()
 		case utilsHttp.ContentTypeApplicationJSON:
 			err = aepr.preProcessRequestAsApplicationJSON()
+		case utilsHttp.ContentTypeApplicationXWwwFormUrlEncoded:
+			err = aepr.preProcessRequestAsApplicationXWwwFormUrlEncoded()
+		case utilsHttp.ContentTypeMultiPartFormData:
+			err = aepr.preProcessRequestAsMultiPartFormData()
 		default:
 			err = aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "Request content-type is not supported yet (%v)", aepr.EndPoint.RequestContentType)
 		}
@@ -403,19 +442,19 @@ func (aepr *DXAPIEndPointRequest) preProcessRequestAsApplicationJSON() (err erro
 	actualContentType := aepr.Request.Header.Get("Content-Type")
 	if actualContentType != "" {
 		if !strings.Contains(actualContentType, "application/json") {
-			return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "REQUEST_CONTENT_TYPE_IS_NOT_APPLICATION_JSON: %s", actualContentType)
+			return aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorRequestContentTypeIsNotJSON, "%s", actualContentType)
 		}
 	}
 	bodyAsJSON := utils.JSON{}
 	aepr.RequestBodyAsBytes, err = io.ReadAll(aepr.Request.Body)
 	if err != nil {
-		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "REQUEST_BODY_CANT_BE_READ:%v=%v", err.Error(), aepr.RequestBodyAsBytes)
+		return aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorRequestBodyCantBeRead, "%v=%v", err.Error(), aepr.RequestBodyAsBytes)
 	}
 
 	if len(aepr.RequestBodyAsBytes) > 0 {
 		err = json.Unmarshal(aepr.RequestBodyAsBytes, &bodyAsJSON)
 		if err != nil {
-			return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "REQUEST_BODY_CANT_BE_PARSED_AS_JSON:%v", err.Error()+"="+string(aepr.RequestBodyAsBytes))
+			return aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorRequestBodyCantBeParsedAsJSON, "%v", err.Error()+"="+string(aepr.RequestBodyAsBytes))
 		}
 	}
 
@@ -430,7 +469,7 @@ func (aepr *DXAPIEndPointRequest) preProcessRequestAsApplicationJSON() (err erro
 		if rpv.Metadata.IsMustExist {
 			if rpv.RawValue == nil {
 				if !rpv.Metadata.IsNullable {
-					return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "MANDATORY_PARAMETER_IS_NOT_EXIST:%s", variablePath)
+					return aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorMandatoryParameterIsNotExist, "%s", variablePath)
 				}
 			}
 		}
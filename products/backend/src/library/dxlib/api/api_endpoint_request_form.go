@@ -0,0 +1,278 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DXAPIDefaultMultiPartFileMaxSize  = 32 << 20
+	DXAPIDefaultMultiPartFieldMaxSize = 1 << 20
+	// DXAPIDefaultFormBodyMaxSize bounds a whole application/x-www-form-urlencoded body, form-body-max-size-byte of the API overrides it
+	DXAPIDefaultFormBodyMaxSize = 1 << 20
+	// DXAPIDefaultMultiPartBodyMaxSize bounds a whole multipart/form-data body including the skipped parts, multipart-body-max-size-byte of the API overrides it
+	DXAPIDefaultMultiPartBodyMaxSize = 64 << 20
+)
+
+type DXAPIEndPointRequestFile struct {
+	FieldName           string
+	FileName            string
+	DeclaredContentType string
+	ContentType         string
+	Size                int64
+	Content             []byte
+}
+
+// stringAsRawValue converts a textual value (path segment, form field) into the raw value shape the JSON decoder would produce for aType
+func stringAsRawValue(aType string, s string) any {
+	if (s == "") && strings.HasPrefix(aType, "nullable-") {
+		return nil
+	}
+	switch aType {
+	case "int64", "int64p", "int64zp", "nullable-int64":
+		// parsed as an integer, a float64 would round ids above 2^53
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return s
+		}
+		return v
+	case "float32", "float32p", "float32zp", "float64", "float64p", "float64zp", "nullable-float64":
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return s
+		}
+		return v
+	case "bool", "nullable-bool":
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return s
+		}
+		return v
+	case "array-int64":
+		return jsonArrayAsInt64RawValue(s)
+	case "json", "json-passthrough", "array", "array-json-template", "array-string", "array-float64", "array-uuid", "datetime-range":
+		var v any
+		err := json.Unmarshal([]byte(s), &v)
+		if err != nil {
+			return s
+		}
+		return v
+	default:
+		return s
+	}
+}
+
+// jsonArrayAsInt64RawValue decodes a JSON array with its integers as int64, like a repeated field, a float64 would round ids above 2^53
+func jsonArrayAsInt64RawValue(s string) any {
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	var items []any
+	err := decoder.Decode(&items)
+	if (err != nil) || decoder.More() {
+		return s
+	}
+	for i, item := range items {
+		n, ok := item.(json.Number)
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseInt(n.String(), 10, 64)
+		if err == nil {
+			items[i] = v
+			continue
+		}
+		// a fraction or an integer out of range is left to the validation as the float64 a JSON body gives
+		items[i], _ = n.Float64()
+	}
+	return items
+}
+
+func formValuesAsRawValue(aType string, values []string) any {
+	if len(values) == 0 {
+		return nil
+	}
+	if strings.HasPrefix(aType, "array-") && (aType != "array-json-template") {
+		if (len(values) > 1) || !strings.HasPrefix(strings.TrimSpace(values[0]), "[") {
+			itemType := strings.TrimPrefix(aType, "array-")
+			items := make([]any, len(values))
+			for i, v := range values {
+				items[i] = stringAsRawValue(itemType, v)
+			}
+			return items
+		}
+	}
+	return stringAsRawValue(aType, values[0])
+}
+
+func isContentTypeAllowed(contentType string, allowedContentTypes []string) bool {
+	if len(allowedContentTypes) == 0 {
+		return true
+	}
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	for _, allowed := range allowedContentTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == mediaType {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitRequestBody makes reads of the request body past maxSize fail with *http.MaxBytesError, defaultMaxSize applies when maxSize is not set
+func (aepr *DXAPIEndPointRequest) limitRequestBody(maxSize int64, defaultMaxSize int64) {
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	var w http.ResponseWriter
+	if aepr.ResponseWriter != nil {
+		w = *aepr.ResponseWriter
+	}
+	aepr.Request.Body = http.MaxBytesReader(w, aepr.Request.Body, maxSize)
+}
+
+// writeRequestBodyReadError answers 413 when the body was cut by limitRequestBody, otherwise 422 with the registered error d
+func (aepr *DXAPIEndPointRequest) writeRequestBodyReadError(err error, d *DXAPIErrorDefinition) error {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return aepr.WriteResponseAndNewErrorf(http.StatusRequestEntityTooLarge, "", "REQUEST_BODY_MAX_SIZE_EXCEEDED:%d", maxBytesError.Limit)
+	}
+	return aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, d, "%v", err.Error())
+}
+
+func (aepr *DXAPIEndPointRequest) preProcessRequestParametersFromForm(values map[string][]string, files map[string]*DXAPIEndPointRequestFile) (err error) {
+	for _, v := range aepr.EndPoint.Parameters {
+		rpv := aepr.NewAPIEndPointRequestParameter(v)
+		variablePath := v.NameId
+		var rawValue any
+		if v.Type == "file" {
+			f, ok := files[v.NameId]
+			if ok {
+				rawValue = f
+			}
+		} else {
+			vv, ok := values[v.NameId]
+			if ok {
+				rawValue = formValuesAsRawValue(v.Type, vv)
+			}
+		}
+		if rawValue != nil {
+			err = rpv.SetRawValue(rawValue, variablePath)
+			if err != nil {
+				return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", err.Error())
+			}
+		}
+		if rpv.Metadata.IsMustExist {
+			if rpv.RawValue == nil {
+				if !rpv.Metadata.IsNullable {
+					return aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorMandatoryParameterIsNotExist, "%s", variablePath)
+				}
+			}
+		}
+		if rpv.RawValue != nil {
+			err = rpv.Validate()
+			if err != nil {
+				aepr.WriteResponseAsError(http.StatusUnprocessableEntity, err)
+				return errors.Wrap(err, "error occured")
+			}
+		}
+	}
+	return nil
+}
+
+func (aepr *DXAPIEndPointRequest) preProcessRequestAsApplicationXWwwFormUrlEncoded() (err error) {
+	actualContentType := aepr.Request.Header.Get("Content-Type")
+	if actualContentType != "" {
+		if !strings.Contains(actualContentType, "application/x-www-form-urlencoded") {
+			return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "REQUEST_CONTENT_TYPE_IS_NOT_APPLICATION_X_WWW_FORM_URLENCODED: %s", actualContentType)
+		}
+	}
+	aepr.limitRequestBody(aepr.EndPoint.Owner.FormBodyMaxSize, DXAPIDefaultFormBodyMaxSize)
+	aepr.RequestBodyAsBytes, err = io.ReadAll(aepr.Request.Body)
+	if err != nil {
+		return aepr.writeRequestBodyReadError(err, ErrorRequestBodyCantBeRead)
+	}
+	values, err := url.ParseQuery(string(aepr.RequestBodyAsBytes))
+	if err != nil {
+		return aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorRequestBodyCantBeParsedAsForm, "%v", err.Error())
+	}
+	return aepr.preProcessRequestParametersFromForm(values, nil)
+}
+
+func (aepr *DXAPIEndPointRequest) preProcessRequestAsMultiPartFormData() (err error) {
+	// the part limits below do not bound the number of parts, the body as a whole is capped as well
+	aepr.limitRequestBody(aepr.EndPoint.Owner.MultiPartBodyMaxSize, DXAPIDefaultMultiPartBodyMaxSize)
+	reader, err := aepr.Request.MultipartReader()
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "REQUEST_CONTENT_TYPE_IS_NOT_MULTIPART_FORM_DATA:%v", err.Error())
+	}
+
+	parameters := map[string]DXAPIEndPointParameter{}
+	for _, v := range aepr.EndPoint.Parameters {
+		parameters[v.NameId] = v
+	}
+
+	values := map[string][]string{}
+	files := map[string]*DXAPIEndPointRequestFile{}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return aepr.writeRequestBodyReadError(err, ErrorRequestBodyCantBeParsedAsMulti)
+		}
+		nameId := part.FormName()
+		p, ok := parameters[nameId]
+		if !ok {
+			_, err = io.Copy(io.Discard, part)
+			_ = part.Close()
+			if err != nil {
+				return aepr.writeRequestBodyReadError(err, ErrorRequestPartCantBeRead)
+			}
+			continue
+		}
+
+		maxSize := int64(DXAPIDefaultMultiPartFieldMaxSize)
+		if p.Type == "file" {
+			maxSize = p.FileMaxSize
+			if maxSize <= 0 {
+				maxSize = DXAPIDefaultMultiPartFileMaxSize
+			}
+		}
+		content, err := io.ReadAll(io.LimitReader(part, maxSize+1))
+		_ = part.Close()
+		if err != nil {
+			return aepr.writeRequestBodyReadError(errors.Wrap(err, nameId), ErrorRequestPartCantBeRead)
+		}
+		if int64(len(content)) > maxSize {
+			return aepr.WriteResponseAndNewAPIErrorf(http.StatusRequestEntityTooLarge, ErrorRequestPartMaxSizeExceeded, "%s>%d", nameId, maxSize)
+		}
+
+		if p.Type != "file" {
+			values[nameId] = append(values[nameId], string(content))
+			continue
+		}
+		contentType := http.DetectContentType(content)
+		if !isContentTypeAllowed(contentType, p.FileContentTypes) {
+			return aepr.WriteResponseAndNewAPIErrorf(http.StatusUnsupportedMediaType, ErrorRequestPartContentTypeNotAllow, "%s=%s", nameId, contentType)
+		}
+		files[nameId] = &DXAPIEndPointRequestFile{
+			FieldName:           nameId,
+			FileName:            part.FileName(),
+			DeclaredContentType: part.Header.Get("Content-Type"),
+			ContentType:         contentType,
+			Size:                int64(len(content)),
+			Content:             content,
+		}
+	}
+	return aepr.preProcessRequestParametersFromForm(values, files)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStringAsRawValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		aType string
+		s     string
+		want  any
+	}{
+		{name: "int64", aType: "int64", s: "42", want: int64(42)},
+		{name: "int64 above 2^53 keeps every digit", aType: "int64", s: "9007199254740993", want: int64(9007199254740993)},
+		{name: "int64p", aType: "int64p", s: "7", want: int64(7)},
+		{name: "int64zp", aType: "int64zp", s: "0", want: int64(0)},
+		{name: "nullable-int64", aType: "nullable-int64", s: "-3", want: int64(-3)},
+		{name: "nullable-int64 empty", aType: "nullable-int64", s: "", want: nil},
+		{name: "int64 with fraction stays a string", aType: "int64", s: "1.5", want: "1.5"},
+		{name: "int64 not a number stays a string", aType: "int64", s: "abc", want: "abc"},
+		{name: "float64", aType: "float64", s: "1.5", want: 1.5},
+		{name: "bool", aType: "bool", s: "true", want: true},
+		{name: "string", aType: "string", s: "abc", want: "abc"},
+		{name: "array-int64 json", aType: "array-int64", s: "[9007199254740993,-1]", want: []any{int64(9007199254740993), int64(-1)}},
+		{name: "array-float64 json", aType: "array-float64", s: "[1.5]", want: []any{1.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stringAsRawValue(tt.aType, tt.s)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stringAsRawValue(%q, %q) = %#v, want %#v", tt.aType, tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormValuesAsRawValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		aType  string
+		values []string
+		want   any
+	}{
+		{name: "no value", aType: "int64", values: nil, want: nil},
+		{name: "int64", aType: "int64", values: []string{"9007199254740993"}, want: int64(9007199254740993)},
+		{name: "array-int64 repeated field", aType: "array-int64", values: []string{"1", "9007199254740993"}, want: []any{int64(1), int64(9007199254740993)}},
+		{name: "array-int64 json", aType: "array-int64", values: []string{"[1,2]"}, want: []any{int64(1), int64(2)}},
+		{name: "array-int64 json above 2^53 keeps every digit", aType: "array-int64", values: []string{"[9007199254740993]"}, want: []any{int64(9007199254740993)}},
+		{name: "array-int64 json with a fraction", aType: "array-int64", values: []string{"[1.5]"}, want: []any{1.5}},
+		{name: "array-int64 json with a string item", aType: "array-int64", values: []string{`[1,"a"]`}, want: []any{int64(1), "a"}},
+		{name: "array-int64 invalid json stays a string", aType: "array-int64", values: []string{"[1,"}, want: "[1,"},
+		{name: "array-int64 json with trailing data stays a string", aType: "array-int64", values: []string{"[1] [2]"}, want: "[1] [2]"},
+		{name: "array-string repeated field", aType: "array-string", values: []string{"a", "b"}, want: []any{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formValuesAsRawValue(tt.aType, tt.values)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("formValuesAsRawValue(%q, %v) = %#v, want %#v", tt.aType, tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawValueAsInt64(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawValue any
+		want     int64
+		wantOk   bool
+	}{
+		{name: "json number", rawValue: float64(42), want: 42, wantOk: true},
+		{name: "parsed path segment", rawValue: int64(9007199254740993), want: 9007199254740993, wantOk: true},
+		{name: "string", rawValue: "42", wantOk: false},
+		{name: "nil", rawValue: nil, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := rawValueAsInt64(tt.rawValue)
+			if (ok != tt.wantOk) || (got != tt.want) {
+				t.Errorf("rawValueAsInt64(%#v) = %v, %v, want %v, %v", tt.rawValue, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"net/http"
 	"time"
 	_ "time/tzdata"
@@ -159,7 +161,7 @@ func getParameterValue[A any](aepr *DXAPIEndPointRequest, k string, defaultValue
 	}
 	val, ok := valAsAny.(A)
 	if !ok {
-		err = aepr.WriteResponseAndNewErrorf(http.StatusBadRequest, "", "REQUEST_FIELD_VALUE_IS_NOT_TYPE:%s!=%T (%v)", k, val, valAsAny)
+		err = aepr.WriteResponseAndNewAPIErrorf(http.StatusBadRequest, ErrorRequestFieldValueIsNotType, "%s!=%T (%v)", k, val, valAsAny)
 		return true, val, err
 	}
 	return true, val, nil
@@ -197,6 +199,34 @@ func (aepr *DXAPIEndPointRequest) GetParameterValueAsArrayOfInt64(k string) (isE
 	return getParameterValue[[]int64](aepr, k)
 }
 
+func (aepr *DXAPIEndPointRequest) GetParameterValueAsArrayOfFloat64(k string) (isExist bool, val []float64, err error) {
+	return getParameterValue[[]float64](aepr, k)
+}
+
+func (aepr *DXAPIEndPointRequest) GetParameterValueAsUUID(k string) (isExist bool, val uuid.UUID, err error) {
+	return getParameterValue[uuid.UUID](aepr, k)
+}
+
+func (aepr *DXAPIEndPointRequest) GetParameterValueAsArrayOfUUID(k string) (isExist bool, val []uuid.UUID, err error) {
+	return getParameterValue[[]uuid.UUID](aepr, k)
+}
+
+func (aepr *DXAPIEndPointRequest) GetParameterValueAsDecimal(k string) (isExist bool, val decimal.Decimal, err error) {
+	return getParameterValue[decimal.Decimal](aepr, k)
+}
+
+func (aepr *DXAPIEndPointRequest) GetParameterValueAsDateTimeRange(k string) (isExist bool, val utils.DateTimeRange, err error) {
+	return getParameterValue[utils.DateTimeRange](aepr, k)
+}
+
+func (aepr *DXAPIEndPointRequest) GetParameterValueAsBytes(k string) (isExist bool, val []byte, err error) {
+	return getParameterValue[[]byte](aepr, k)
+}
+
 func (aepr *DXAPIEndPointRequest) GetParameterValueAsJSON(k string) (isExist bool, val utils.JSON, err error) {
 	return getParameterValue[utils.JSON](aepr, k)
 }
+
+func (aepr *DXAPIEndPointRequest) GetParameterValueAsFile(k string) (isExist bool, val *DXAPIEndPointRequestFile, err error) {
+	return getParameterValue[*DXAPIEndPointRequestFile](aepr, k)
+}
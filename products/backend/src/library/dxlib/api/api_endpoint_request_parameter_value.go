@@ -1,9 +1,12 @@
 package api
 
 import (
+	"encoding/base64"
 	"fmt"
 	security "github.com/donnyhardyanto/dxlib/utils/security"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 	"strings"
 	"time"
 
@@ -26,7 +29,8 @@ type DXAPIEndPointRequestParameterValue struct {
 }
 
 func (aeprpv *DXAPIEndPointRequestParameterValue) GetNameIdPath() (s string) {
-	if aeprpv.Parent == nil {
+	// array items already carry the full path, e.g. "items[0]"
+	if (aeprpv.Parent == nil) || aeprpv.IsArrayChildren {
 		return aeprpv.Metadata.NameId
 	}
 	return aeprpv.Parent.GetNameIdPath() + "." + aeprpv.Metadata.NameId
@@ -43,6 +47,9 @@ func (aeprpv *DXAPIEndPointRequestParameterValue) NewChild(aepp DXAPIEndPointPar
 
 func (aeprpv *DXAPIEndPointRequestParameterValue) SetRawValue(rv any, variablePath string) (err error) {
 	aeprpv.RawValue = rv
+	if rv == nil {
+		return nil
+	}
 	if aeprpv.Metadata.Type == "json" {
 		jsonValue, ok := rv.(map[string]interface{})
 		if !ok {
@@ -80,13 +87,15 @@ func (aeprpv *DXAPIEndPointRequestParameterValue) SetRawValue(rv any, variablePa
 
 			// Create a new object for each array element that will hold all children
 			containerObj := DXAPIEndPointRequestParameterValue{
-				Owner:    aeprpv.Owner,
-				Parent:   aeprpv,
-				Metadata: aeprpv.Metadata,
-				RawValue: jj,
+				Owner:           aeprpv.Owner,
+				Parent:          aeprpv,
+				Metadata:        aeprpv.Metadata,
+				RawValue:        jj,
+				IsArrayChildren: true,
 			}
 			containerObj.Metadata.Type = "json"
 			containerObj.Metadata.NameId = aVariablePath
+			containerObj.Metadata.Constraint = DXAPIEndPointParameterConstraint{}
 
 			for _, v := range containerObj.Metadata.Children {
 				childValue := containerObj.NewChild(v)
@@ -121,6 +130,30 @@ func (aeprpv *DXAPIEndPointRequestParameterValue) Validate() (err error) {
 	if aeprpv.RawValue == nil {
 		return nil
 	}
+	if t, ok := FindParameterType(aeprpv.Metadata.Type); ok {
+		err = aeprpv.validateRegisteredType(t)
+	} else {
+		err = aeprpv.validateBuiltinType()
+	}
+	if err != nil {
+		return err
+	}
+	return aeprpv.validateConstraint()
+}
+
+// rawValueAsInt64 accepts the float64 of a JSON number and the int64 parsed from a path segment or form field, which keeps the digits above 2^53
+func rawValueAsInt64(rawValue any) (v int64, ok bool) {
+	switch t := rawValue.(type) {
+	case float64:
+		return int64(t), true
+	case int64:
+		return t, true
+	default:
+		return 0, false
+	}
+}
+
+func (aeprpv *DXAPIEndPointRequestParameterValue) validateBuiltinType() (err error) {
 	rawValueType := utils.TypeAsString(aeprpv.RawValue)
 	nameIdPath := aeprpv.GetNameIdPath()
 	if aeprpv.Metadata.Type != rawValueType {
@@ -204,8 +237,39 @@ func (aeprpv *DXAPIEndPointRequestParameterValue) Validate() (err error) {
 			if rawValueType != "[]interface {}" {
 				return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, rawValueType, aeprpv.RawValue)
 			}
+		case "uuid", "base64-bytes":
+			if rawValueType != "string" {
+				return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, rawValueType, aeprpv.RawValue)
+			}
+		case "nullable-bool":
+			if rawValueType != "bool" {
+				return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, rawValueType, aeprpv.RawValue)
+			}
+		case "nullable-float64":
+			if rawValueType != "float64" {
+				return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, rawValueType, aeprpv.RawValue)
+			}
+		case "decimal":
+			if rawValueType != "string" {
+				return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, rawValueType, aeprpv.RawValue)
+			}
+		case "array-float64", "array-uuid":
+			if rawValueType != "[]interface {}" {
+				return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, rawValueType, aeprpv.RawValue)
+			}
+		case "datetime-range":
+			if (rawValueType != "string") && (rawValueType != "map[string]interface {}") {
+				return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, rawValueType, aeprpv.RawValue)
+			}
+		case "file":
+			if _, ok := aeprpv.RawValue.(*DXAPIEndPointRequestFile); !ok {
+				return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, rawValueType, aeprpv.RawValue)
+			}
 		default:
-			return aeprpv.Owner.Log.WarnAndCreateErrorf("INVALID_TYPE_MATCHING:SHOULD_[%s].(%v)_BUT_RECEIVE_(%s)=%v", nameIdPath, aeprpv.Metadata.Type, rawValueType, aeprpv.RawValue)
+			apiError := ErrorInvalidTypeMatching.Newf("SHOULD_[%s].(%v)_BUT_RECEIVE_(%s)=%v", nameIdPath, aeprpv.Metadata.Type, rawValueType, aeprpv.RawValue)
+			apiError.Parameter = nameIdPath
+			aeprpv.Owner.Log.Warn(apiError.Error())
+			return apiError
 		}
 	}
 	switch aeprpv.Metadata.Type {
@@ -214,38 +278,34 @@ func (aeprpv *DXAPIEndPointRequestParameterValue) Validate() (err error) {
 			aeprpv.Value = nil
 			return nil
 		}
-		t, ok := aeprpv.RawValue.(float64)
+		v, ok := rawValueAsInt64(aeprpv.RawValue)
 		if !ok {
 			return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
 		}
-		v := int64(t)
 		aeprpv.Value = v
 		return nil
 	case "int64":
-		t, ok := aeprpv.RawValue.(float64)
+		v, ok := rawValueAsInt64(aeprpv.RawValue)
 		if !ok {
 			return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
 		}
-		v := int64(t)
 		aeprpv.Value = v
 		return nil
 	case "int64p":
-		t, ok := aeprpv.RawValue.(float64)
+		v, ok := rawValueAsInt64(aeprpv.RawValue)
 		if !ok {
 			return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
 		}
-		v := int64(t)
 		if v > 0 {
 			aeprpv.Value = v
 			return nil
 		}
 		return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
 	case "int64zp":
-		t, ok := aeprpv.RawValue.(float64)
+		v, ok := rawValueAsInt64(aeprpv.RawValue)
 		if !ok {
 			return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
 		}
-		v := int64(t)
 		if v >= 0 {
 			aeprpv.Value = v
 			return nil
@@ -451,15 +511,127 @@ func (aeprpv *DXAPIEndPointRequestParameterValue) Validate() (err error) {
 		// Convert []any to []string
 		s := make([]int64, len(rawSlice))
 		for i, v := range rawSlice {
-			aNumber, ok := v.(float64)
+			aInt, ok := rawValueAsInt64(v)
 			if !ok {
 				return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
 			}
-			aInt := int64(aNumber)
 			s[i] = aInt
 		}
 		aeprpv.Value = s
 		return nil
+	case "array-float64":
+		rawSlice, ok := aeprpv.RawValue.([]any)
+		if !ok {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
+		}
+		s := make([]float64, len(rawSlice))
+		for i, v := range rawSlice {
+			aNumber, ok := v.(float64)
+			if !ok {
+				return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
+			}
+			s[i] = aNumber
+		}
+		aeprpv.Value = s
+		return nil
+	case "uuid":
+		s, ok := aeprpv.RawValue.(string)
+		if !ok {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
+		}
+		u, err := uuid.Parse(s)
+		if err != nil {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf("INVALID_UUID_FORMAT:%s=%s", nameIdPath, s)
+		}
+		aeprpv.Value = u
+		return nil
+	case "array-uuid":
+		rawSlice, ok := aeprpv.RawValue.([]any)
+		if !ok {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
+		}
+		s := make([]uuid.UUID, len(rawSlice))
+		for i, v := range rawSlice {
+			str, ok := v.(string)
+			if !ok {
+				return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
+			}
+			u, err := uuid.Parse(str)
+			if err != nil {
+				return aeprpv.Owner.Log.WarnAndCreateErrorf("INVALID_UUID_FORMAT:%s[%d]=%s", nameIdPath, i, str)
+			}
+			s[i] = u
+		}
+		aeprpv.Value = s
+		return nil
+	case "nullable-bool":
+		if aeprpv.RawValue == nil {
+			aeprpv.Value = nil
+			return nil
+		}
+		v, ok := aeprpv.RawValue.(bool)
+		if !ok {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
+		}
+		aeprpv.Value = v
+		return nil
+	case "nullable-float64":
+		if aeprpv.RawValue == nil {
+			aeprpv.Value = nil
+			return nil
+		}
+		v, ok := aeprpv.RawValue.(float64)
+		if !ok {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
+		}
+		aeprpv.Value = v
+		return nil
+	case "decimal":
+		// only a string keeps every digit, a JSON number has already lost them as a float64 when it arrives here so it is rejected
+		s, ok := aeprpv.RawValue.(string)
+		if !ok {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
+		}
+		d, err := decimal.NewFromString(strings.TrimSpace(s))
+		if err != nil {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf("INVALID_DECIMAL_FORMAT:%s=%s", nameIdPath, s)
+		}
+		aeprpv.Value = d
+		return nil
+	case "base64-bytes":
+		s, ok := aeprpv.RawValue.(string)
+		if !ok {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf(ErrorMessageIncompatibleTypeReceived, nameIdPath, aeprpv.Metadata.Type, utils.TypeAsString(aeprpv.RawValue), aeprpv.RawValue)
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf("INVALID_BASE64_FORMAT:%s", nameIdPath)
+		}
+		aeprpv.Value = b
+		return nil
+	case "datetime-range":
+		// either {"start": ..., "end": ...} or the ISO 8601 interval "start/end", both as RFC3339
+		var startAsString, endAsString string
+		switch v := aeprpv.RawValue.(type) {
+		case string:
+			startAsString, endAsString, _ = strings.Cut(v, "/")
+		case map[string]any:
+			startAsString, _ = v["start"].(string)
+			endAsString, _ = v["end"].(string)
+		}
+		start, err := time.Parse(time.RFC3339Nano, strings.Replace(strings.TrimSpace(startAsString), " ", "T", 1))
+		if err != nil {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf("INVALID_DATETIME_RANGE_START:%s=%v", nameIdPath, aeprpv.RawValue)
+		}
+		end, err := time.Parse(time.RFC3339Nano, strings.Replace(strings.TrimSpace(endAsString), " ", "T", 1))
+		if err != nil {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf("INVALID_DATETIME_RANGE_END:%s=%v", nameIdPath, aeprpv.RawValue)
+		}
+		if end.Before(start) {
+			return aeprpv.Owner.Log.WarnAndCreateErrorf("INVALID_DATETIME_RANGE_END_BEFORE_START:%s=%v", nameIdPath, aeprpv.RawValue)
+		}
+		aeprpv.Value = utils.DateTimeRange{Start: start, End: end}
+		return nil
 	case "iso8601":
 		/* RFC3339Nano format conform to RFC3339 RFC, not Go https://pkg.go.dev/time#pkg-constants.
 		   The golang time package documentation (https://pkg.go.dev/time#pkg-constants) has wrong information on the RFC3339/RFC3329Nano format.
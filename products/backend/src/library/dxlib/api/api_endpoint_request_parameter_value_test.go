@@ -0,0 +1,81 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+)
+
+func TestValidateBuiltinType(t *testing.T) {
+	u1 := uuid.MustParse("6f1c1d3e-2b7a-4c55-9a57-0a4a7b0f4e01")
+	u2 := uuid.MustParse("0b8e6a0c-5f3e-4d1b-8c0a-3f5d2e7b9c12")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 31, 23, 59, 59, 0, time.UTC)
+	tests := []struct {
+		name      string
+		aType     string
+		rawValue  any
+		wantValue any
+		wantErr   bool
+	}{
+		{name: "uuid", aType: "uuid", rawValue: u1.String(), wantValue: u1},
+		{name: "uuid not a uuid", aType: "uuid", rawValue: "6f1c1d3e", wantErr: true},
+		{name: "uuid not a string", aType: "uuid", rawValue: float64(1), wantErr: true},
+		{name: "nullable-bool", aType: "nullable-bool", rawValue: true, wantValue: true},
+		{name: "nullable-bool not a bool", aType: "nullable-bool", rawValue: "true", wantErr: true},
+		{name: "nullable-float64", aType: "nullable-float64", rawValue: 1.5, wantValue: 1.5},
+		{name: "nullable-float64 not a number", aType: "nullable-float64", rawValue: "1.5", wantErr: true},
+		{name: "decimal keeps every digit", aType: "decimal", rawValue: "12345678901234567890.123456789", wantValue: decimal.RequireFromString("12345678901234567890.123456789")},
+		{name: "decimal is trimmed", aType: "decimal", rawValue: " -0.10 ", wantValue: decimal.RequireFromString("-0.10")},
+		{name: "decimal not a decimal", aType: "decimal", rawValue: "1,5", wantErr: true},
+		{name: "decimal of a JSON number is rejected", aType: "decimal", rawValue: 0.1, wantErr: true},
+		{name: "array-int64 of a form field keeps every digit", aType: "array-int64", rawValue: formValuesAsRawValue("array-int64", []string{"[9007199254740993]"}), wantValue: []int64{9007199254740993}},
+		{name: "array-float64", aType: "array-float64", rawValue: []any{1.5, float64(2)}, wantValue: []float64{1.5, 2}},
+		{name: "array-float64 with a string item", aType: "array-float64", rawValue: []any{1.5, "2"}, wantErr: true},
+		{name: "array-uuid", aType: "array-uuid", rawValue: []any{u1.String(), u2.String()}, wantValue: []uuid.UUID{u1, u2}},
+		{name: "array-uuid with an invalid item", aType: "array-uuid", rawValue: []any{u1.String(), "x"}, wantErr: true},
+		{name: "array-uuid not an array", aType: "array-uuid", rawValue: u1.String(), wantErr: true},
+		{
+			name: "datetime-range as an object", aType: "datetime-range",
+			rawValue:  map[string]any{"start": "2026-01-01T00:00:00Z", "end": "2026-01-31T23:59:59Z"},
+			wantValue: utils.DateTimeRange{Start: start, End: end},
+		},
+		{
+			name: "datetime-range as an interval", aType: "datetime-range",
+			rawValue:  "2026-01-01T00:00:00Z/2026-01-31T23:59:59Z",
+			wantValue: utils.DateTimeRange{Start: start, End: end},
+		},
+		{name: "datetime-range end before start", aType: "datetime-range", rawValue: "2026-01-31T23:59:59Z/2026-01-01T00:00:00Z", wantErr: true},
+		{name: "datetime-range without end", aType: "datetime-range", rawValue: map[string]any{"start": "2026-01-01T00:00:00Z"}, wantErr: true},
+		{name: "base64-bytes", aType: "base64-bytes", rawValue: "aGVsbG8=", wantValue: []byte("hello")},
+		{name: "base64-bytes not base64", aType: "base64-bytes", rawValue: "!!", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aepr, _ := testErrorRequest(ErrorResponseFormatProblemJSON, false)
+			aeprpv := &DXAPIEndPointRequestParameterValue{Owner: aepr, Metadata: DXAPIEndPointParameter{NameId: "p", Type: tt.aType}, RawValue: tt.rawValue}
+			err := aeprpv.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if d, ok := tt.wantValue.(decimal.Decimal); ok {
+				got, isDecimal := aeprpv.Value.(decimal.Decimal)
+				if !isDecimal || !got.Equal(d) {
+					t.Errorf("Value = %v, want %v", aeprpv.Value, d)
+				}
+				return
+			}
+			if !reflect.DeepEqual(aeprpv.Value, tt.wantValue) {
+				t.Errorf("Value = %#v, want %#v", aeprpv.Value, tt.wantValue)
+			}
+		})
+	}
+}
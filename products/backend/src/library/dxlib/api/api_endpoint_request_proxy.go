@@ -59,6 +59,7 @@ func (aepr *DXAPIEndPointRequest) HTTPClientDo(method, url string, parameters ut
 		request.Header.Set("Content-Type", "application/json")
 	}
 	request.Header.Set("Cache-Control", "no-cache")
+	request.Header.Set(DXAPIRequestIdHeader, aepr.Id)
 	for k, v := range headers {
 		request.Header[k] = []string{v}
 	}
@@ -70,7 +71,9 @@ func (aepr *DXAPIEndPointRequest) HTTPClientDo(method, url string, parameters ut
 	}
 	aepr.Log.Debugf("Send Request to %s:\n%s\n", effectiveUrl, string(requestDump))
 
+	request, traceEnd := aepr.traceHTTPClientBegin(request)
 	response, err = client.Do(request)
+	traceEnd(response, err)
 	if err != nil {
 		err = aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "ERROR_IN_DUMP_REQUEST:%v", err.Error())
 		return nil, err
@@ -98,6 +101,7 @@ func (aepr *DXAPIEndPointRequest) HTTPClientDoBodyAsJSONString(method, url strin
 	}
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Cache-Control", "no-cache")
+	request.Header.Set(DXAPIRequestIdHeader, aepr.Id)
 	for k, v := range headers {
 		request.Header[k] = []string{v}
 	}
@@ -109,7 +113,9 @@ func (aepr *DXAPIEndPointRequest) HTTPClientDoBodyAsJSONString(method, url strin
 	}
 	aepr.Log.Debugf("Request :\n%s\n", string(requestDump))
 
+	request, traceEnd := aepr.traceHTTPClientBegin(request)
 	response, err = client.Do(request)
+	traceEnd(response, err)
 	if err != nil {
 		err = aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "ERROR_IN_MAKE_HTTP_REQUEST:%v", err.Error())
 		return nil, err
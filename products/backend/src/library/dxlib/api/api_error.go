@@ -0,0 +1,265 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+	utilsJSON "github.com/donnyhardyanto/dxlib/utils/json"
+	"github.com/pkg/errors"
+)
+
+type DXAPIErrorResponseFormat string
+
+const (
+	// ErrorResponseFormatLegacy is the {status, status_code, reason, reason_message} body
+	ErrorResponseFormatLegacy DXAPIErrorResponseFormat = "legacy"
+	// ErrorResponseFormatProblemJSON is the RFC 7807 application/problem+json body
+	ErrorResponseFormatProblemJSON DXAPIErrorResponseFormat = "problem-json"
+
+	ContentTypeApplicationProblemJSON = "application/problem+json"
+)
+
+// DXAPIErrorDefinition is a registered error, Code is stable and Title is safe to show to any client
+type DXAPIErrorDefinition struct {
+	Code       string
+	StatusCode int
+	Title      string
+	// IsDetailPublic marks errors whose detail only carries request data, e.g. a parameter name, so it is still shown in production mode
+	IsDetailPublic bool
+}
+
+// DXAPIError is an error carrying a registered code, Err is internal and never sent to clients.
+// Parameter is the path of the request parameter the error is about, e.g. "items[0].qty"
+type DXAPIError struct {
+	Definition *DXAPIErrorDefinition
+	Detail     string
+	Parameter  string
+	Err        error
+}
+
+func (e *DXAPIError) Error() string {
+	s := e.Definition.Code
+	if e.Detail != "" {
+		s = s + ":" + e.Detail
+	}
+	if e.Err != nil {
+		s = s + ":" + e.Err.Error()
+	}
+	return s
+}
+
+func (e *DXAPIError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	errorDefinitionsMutex sync.RWMutex
+	errorDefinitions      = map[string]*DXAPIErrorDefinition{}
+)
+
+func RegisterError(code string, statusCode int, title string, isDetailPublic bool) *DXAPIErrorDefinition {
+	d := &DXAPIErrorDefinition{
+		Code:           code,
+		StatusCode:     statusCode,
+		Title:          title,
+		IsDetailPublic: isDetailPublic,
+	}
+	errorDefinitionsMutex.Lock()
+	defer errorDefinitionsMutex.Unlock()
+	errorDefinitions[code] = d
+	return d
+}
+
+func FindError(code string) (d *DXAPIErrorDefinition, ok bool) {
+	errorDefinitionsMutex.RLock()
+	defer errorDefinitionsMutex.RUnlock()
+	d, ok = errorDefinitions[code]
+	return d, ok
+}
+
+func (d *DXAPIErrorDefinition) New(detail string) *DXAPIError {
+	return &DXAPIError{Definition: d, Detail: detail}
+}
+
+func (d *DXAPIErrorDefinition) Newf(format string, v ...any) *DXAPIError {
+	return &DXAPIError{Definition: d, Detail: fmt.Sprintf(format, v...)}
+}
+
+func (d *DXAPIErrorDefinition) Wrap(err error, detail string) *DXAPIError {
+	return &DXAPIError{Definition: d, Detail: detail, Err: err}
+}
+
+var (
+	ErrorInternal                        = RegisterError("INTERNAL_ERROR", http.StatusInternalServerError, "Internal server error", false)
+	ErrorMethodNotAllowed                = RegisterError("METHOD_NOT_ALLOWED", http.StatusMethodNotAllowed, "Method not allowed", true)
+	ErrorRequestMaxContentLengthExceeded = RegisterError("REQUEST_MAX_CONTENT_LENGTH_EXCEEDED", http.StatusRequestEntityTooLarge, "Request content is too large", true)
+	ErrorMandatoryParameterNotExist      = RegisterError("MANDATORY_PARAMETER_NOT_EXIST", http.StatusUnprocessableEntity, "Mandatory parameter is missing", true)
+	ErrorMandatoryParameterIsNotExist    = RegisterError("MANDATORY_PARAMETER_IS_NOT_EXIST", http.StatusUnprocessableEntity, "Mandatory parameter is missing", true)
+	ErrorMandatoryPathParameterNotExist  = RegisterError("MANDATORY_PATH_PARAMETER_NOT_EXIST", http.StatusUnprocessableEntity, "Mandatory path parameter is missing", true)
+	ErrorRequestBodyCantBeRead           = RegisterError("REQUEST_BODY_CANT_BE_READ", http.StatusUnprocessableEntity, "Request body can not be read", false)
+	ErrorRequestBodyCantBeParsedAsJSON   = RegisterError("REQUEST_BODY_CANT_BE_PARSED_AS_JSON", http.StatusUnprocessableEntity, "Request body is not valid JSON", false)
+	ErrorRequestBodyCantBeParsedAsForm   = RegisterError("REQUEST_BODY_CANT_BE_PARSED_AS_FORM", http.StatusUnprocessableEntity, "Request body is not a valid form", false)
+	ErrorRequestBodyCantBeParsedAsMulti  = RegisterError("REQUEST_BODY_CANT_BE_PARSED_AS_MULTIPART", http.StatusUnprocessableEntity, "Request body is not a valid multipart form", false)
+	ErrorRequestPartCantBeRead           = RegisterError("REQUEST_PART_CANT_BE_READ", http.StatusUnprocessableEntity, "Request part can not be read", false)
+	ErrorRequestPartMaxSizeExceeded      = RegisterError("REQUEST_PART_MAX_SIZE_EXCEEDED", http.StatusRequestEntityTooLarge, "Request part is too large", true)
+	ErrorRequestPartContentTypeNotAllow  = RegisterError("REQUEST_PART_CONTENT_TYPE_NOT_ALLOWED", http.StatusUnsupportedMediaType, "Request part content type is not allowed", true)
+	ErrorRequestContentTypeIsNotJSON     = RegisterError("REQUEST_CONTENT_TYPE_IS_NOT_APPLICATION_JSON", http.StatusUnprocessableEntity, "Request content type must be application/json", true)
+	ErrorRequestFieldValueIsNotType      = RegisterError("REQUEST_FIELD_VALUE_IS_NOT_TYPE", http.StatusUnprocessableEntity, "Request field has an invalid type", true)
+	ErrorInvalidTypeMatching             = RegisterError("INVALID_TYPE_MATCHING", http.StatusUnprocessableEntity, "Request field has an invalid type", true)
+	ErrorMiddleware                      = RegisterError("MIDDLEWARE_ERROR", http.StatusBadRequest, "Request rejected", false)
+	ErrorOnExecute                       = RegisterError("ONEXECUTE_ERROR", http.StatusBadRequest, "Request failed", false)
+)
+
+var errorCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*[A-Z0-9]$`)
+
+// errorCodeAndDetail splits the repo-wide "CODE:detail" message convention, messages without a code prefix get an empty code
+func errorCodeAndDetail(msg string) (code string, detail string) {
+	msg = strings.TrimSpace(msg)
+	code, detail, _ = strings.Cut(msg, ":")
+	code = strings.TrimSpace(code)
+	if !errorCodePattern.MatchString(code) {
+		return "", msg
+	}
+	return code, strings.TrimSpace(detail)
+}
+
+// DXAPIProblem is the RFC 7807 body, Code and RequestId are extension members
+type DXAPIProblem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	RequestId string `json:"request_id,omitempty"`
+}
+
+// newProblem resolves what may be shown for an error. The detail of a DXAPIError is shown when its definition is IsDetailPublic, its Err only
+// goes to the log. msg is written for the log, only the detail of a code registered as public reaches the client, otherwise
+// responseMessage does, as it did in the legacy body
+func (aepr *DXAPIEndPointRequest) newProblem(statusCode int, err error, msg string, responseMessage string) (p DXAPIProblem) {
+	a := aepr.EndPoint.Owner
+	var code, detail string
+	var definition *DXAPIErrorDefinition
+	var apiError *DXAPIError
+	switch {
+	case (err != nil) && errors.As(err, &apiError):
+		definition = apiError.Definition
+		code = definition.Code
+		if definition.IsDetailPublic {
+			detail = apiError.Detail
+		}
+		p.Parameter = apiError.Parameter
+		if definition.StatusCode != 0 {
+			statusCode = definition.StatusCode
+		}
+	case err != nil:
+		code, detail = errorCodeAndDetail(errors.Cause(err).Error())
+		definition, _ = FindError(code)
+		if a.IsErrorProductionMode && ((definition == nil) || !definition.IsDetailPublic) {
+			detail = ""
+		}
+	default:
+		code, detail = errorCodeAndDetail(msg)
+		definition, _ = FindError(code)
+		if (definition == nil) || !definition.IsDetailPublic {
+			detail = responseMessage
+		}
+	}
+
+	p.Status = statusCode
+	p.Code = code
+	p.Title = http.StatusText(statusCode)
+	if definition != nil {
+		p.Title = definition.Title
+	}
+	p.Detail = detail
+	p.Type = "about:blank"
+	if (a.ErrorTypeBaseURI != "") && (p.Code != "") {
+		p.Type = a.ErrorTypeBaseURI + strings.ToLower(p.Code)
+	}
+	p.Instance = aepr.Request.URL.Path
+	p.RequestId = aepr.Id
+	return p
+}
+
+// isLegacyErrorResponse keeps the original error bodies untouched for APIs that did not opt in to problem+json or production mode
+func (aepr *DXAPIEndPointRequest) isLegacyErrorResponse() bool {
+	a := aepr.EndPoint.Owner
+	return (a.ErrorResponseFormat != ErrorResponseFormatProblemJSON) && !a.IsErrorProductionMode
+}
+
+func (aepr *DXAPIEndPointRequest) writeResponseAsProblem(statusCode int, err error, msg string, responseMessage string) {
+	if aepr.ResponseHeaderSent {
+		return
+	}
+	if (200 <= statusCode) && (statusCode < 300) {
+		statusCode = 500
+	}
+	p := aepr.newProblem(statusCode, err, msg, responseMessage)
+	if aepr.EndPoint.Owner.ErrorResponseFormat != ErrorResponseFormatProblemJSON {
+		reason := p.Code
+		if reason == "" {
+			reason = strings.ToUpper(p.Title)
+		}
+		if p.Detail != "" {
+			reason = reason + ":" + p.Detail
+		}
+		aepr.WriteResponseAsJSON(p.Status, nil, utils.JSON{
+			"status":         http.StatusText(p.Status),
+			"status_code":    p.Status,
+			"reason":         reason,
+			"reason_message": p.Title,
+		})
+		return
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		_ = aepr.Log.WarnAndCreateErrorf("SHOULD_NOT_HAPPEN:ERROR_AT_MARSHAL_JSON=%s", err.Error())
+		return
+	}
+	aepr.WriteResponseAsBytes(p.Status, map[string]string{"Content-Type": ContentTypeApplicationProblemJSON}, body)
+}
+
+func (a *DXAPI) applyErrorConfiguration(c1 utils.JSON) (err error) {
+	a.ErrorResponseFormat = ErrorResponseFormatLegacy
+	if v, ok := c1["error-response-format"].(string); ok && (v != "") {
+		switch DXAPIErrorResponseFormat(v) {
+		case ErrorResponseFormatLegacy, ErrorResponseFormatProblemJSON:
+			a.ErrorResponseFormat = DXAPIErrorResponseFormat(v)
+		default:
+			return errors.Errorf("ERROR_RESPONSE_FORMAT_NOT_SUPPORTED:%s", v)
+		}
+	}
+	a.ErrorTypeBaseURI, _ = c1["error-type-base-uri"].(string)
+	if _, ok := c1["error-production-mode"]; ok {
+		a.IsErrorProductionMode, err = utilsJSON.GetBool(c1, "error-production-mode")
+		if err != nil {
+			return errors.Wrap(err, "error-production-mode")
+		}
+	}
+	return nil
+}
+
+// WriteResponseAndNewAPIError answers apiError and returns it logged, its detail reaches the client only when its definition is IsDetailPublic.
+// The legacy body only carries the status text of statusCode
+func (aepr *DXAPIEndPointRequest) WriteResponseAndNewAPIError(statusCode int, apiError *DXAPIError) (err error) {
+	err = aepr.Log.WarnAndCreateErrorf("%s", apiError.Error())
+	if aepr.isLegacyErrorResponse() {
+		aepr.WriteResponseAsErrorMessage(statusCode, strings.ToUpper(http.StatusText(statusCode)))
+		return err
+	}
+	aepr.writeResponseAsProblem(statusCode, apiError, "", "")
+	return err
+}
+
+// WriteResponseAndNewAPIErrorf answers the registered error d with msg and data as its detail, see WriteResponseAndNewAPIError
+func (aepr *DXAPIEndPointRequest) WriteResponseAndNewAPIErrorf(statusCode int, d *DXAPIErrorDefinition, msg string, data ...any) (err error) {
+	return aepr.WriteResponseAndNewAPIError(statusCode, d.Newf(msg, data...))
+}
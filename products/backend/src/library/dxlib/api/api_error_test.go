@@ -0,0 +1,222 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/pkg/errors"
+)
+
+func testErrorRequest(format DXAPIErrorResponseFormat, isProductionMode bool) (aepr *DXAPIEndPointRequest, recorder *httptest.ResponseRecorder) {
+	a := &DXAPI{NameId: "test", ErrorResponseFormat: format, IsErrorProductionMode: isProductionMode}
+	endPoint := &DXAPIEndPoint{Owner: a, Method: http.MethodPost, Uri: "/v1/item/create"}
+	recorder = httptest.NewRecorder()
+	var w http.ResponseWriter = recorder
+	aepr = &DXAPIEndPointRequest{
+		Id:             "r1",
+		Context:        context.Background(),
+		EndPoint:       endPoint,
+		Log:            log.NewLog(nil, context.Background(), "test"),
+		Request:        httptest.NewRequest(endPoint.Method, endPoint.Uri, nil),
+		ResponseWriter: &w,
+	}
+	return aepr, recorder
+}
+
+func TestNewProblem(t *testing.T) {
+	errSecret := errors.New("SECRET_DSN=postgres://u:p@db")
+	tests := []struct {
+		name             string
+		isProductionMode bool
+		err              error
+		msg              string
+		responseMessage  string
+		wantStatus       int
+		wantCode         string
+		wantDetail       string
+		wantParameter    string
+	}{
+		{
+			name: "public detail of a registered error", err: ErrorMandatoryParameterNotExist.Newf("%s", "name"),
+			wantStatus: http.StatusUnprocessableEntity, wantCode: "MANDATORY_PARAMETER_NOT_EXIST", wantDetail: "name",
+		},
+		{
+			name: "private detail of a registered error is hidden", err: ErrorRequestBodyCantBeParsedAsJSON.Newf("%s", `invalid character 's' {"password":"secret"}`),
+			wantStatus: http.StatusUnprocessableEntity, wantCode: "REQUEST_BODY_CANT_BE_PARSED_AS_JSON",
+		},
+		{
+			name: "wrapped error of a registered error is hidden", err: ErrorMiddleware.Wrap(errSecret, ""),
+			wantStatus: http.StatusBadRequest, wantCode: "MIDDLEWARE_ERROR",
+		},
+		{
+			name: "wrapped error of a registered error is hidden in production mode", isProductionMode: true, err: ErrorOnExecute.Wrap(errSecret, ""),
+			wantStatus: http.StatusBadRequest, wantCode: "ONEXECUTE_ERROR",
+		},
+		{
+			name: "parameter of a registered error", err: func() error {
+				apiError := ErrorRequestFieldValueIsNotType.Newf("%s", "items[0].qty!=int64 (a)")
+				apiError.Parameter = "items[0].qty"
+				return apiError
+			}(),
+			wantStatus: http.StatusUnprocessableEntity, wantCode: "REQUEST_FIELD_VALUE_IS_NOT_TYPE", wantDetail: "items[0].qty!=int64 (a)", wantParameter: "items[0].qty",
+		},
+		{
+			name: "plain error keeps its detail", err: errors.New("ITEM_NOT_FOUND:7"),
+			wantStatus: http.StatusNotFound, wantCode: "ITEM_NOT_FOUND", wantDetail: "7",
+		},
+		{
+			name: "plain error hides its detail in production mode", isProductionMode: true, err: errors.New("ITEM_NOT_FOUND:7"),
+			wantStatus: http.StatusNotFound, wantCode: "ITEM_NOT_FOUND",
+		},
+		{
+			name: "public detail of a registered message", msg: "MANDATORY_PARAMETER_NOT_EXIST:name",
+			wantStatus: http.StatusNotFound, wantCode: "MANDATORY_PARAMETER_NOT_EXIST", wantDetail: "name",
+		},
+		{
+			name: "private detail of a registered message is hidden", msg: `REQUEST_BODY_CANT_BE_PARSED_AS_JSON:{"password":"secret"}`,
+			wantStatus: http.StatusNotFound, wantCode: "REQUEST_BODY_CANT_BE_PARSED_AS_JSON",
+		},
+		{
+			name: "detail of an unregistered message is hidden", msg: "USER_NOT_FOUND:" + errSecret.Error(),
+			wantStatus: http.StatusNotFound, wantCode: "USER_NOT_FOUND",
+		},
+		{
+			name: "response message replaces a hidden detail", msg: "USER_NOT_FOUND:" + errSecret.Error(), responseMessage: "User is not found",
+			wantStatus: http.StatusNotFound, wantCode: "USER_NOT_FOUND", wantDetail: "User is not found",
+		},
+		{
+			name: "response message replaces a hidden detail in production mode", isProductionMode: true, msg: "USER_NOT_FOUND:" + errSecret.Error(), responseMessage: "User is not found",
+			wantStatus: http.StatusNotFound, wantCode: "USER_NOT_FOUND", wantDetail: "User is not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aepr, _ := testErrorRequest(ErrorResponseFormatProblemJSON, tt.isProductionMode)
+			p := aepr.newProblem(http.StatusNotFound, tt.err, tt.msg, tt.responseMessage)
+			if p.Status != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", p.Status, tt.wantStatus)
+			}
+			if p.Code != tt.wantCode {
+				t.Errorf("Code = %s, want %s", p.Code, tt.wantCode)
+			}
+			if p.Detail != tt.wantDetail {
+				t.Errorf("Detail = %q, want %q", p.Detail, tt.wantDetail)
+			}
+			if p.Parameter != tt.wantParameter {
+				t.Errorf("Parameter = %s, want %s", p.Parameter, tt.wantParameter)
+			}
+			if (p.Instance != "/v1/item/create") || (p.RequestId != "r1") {
+				t.Errorf("Instance = %s, RequestId = %s, want /v1/item/create and r1", p.Instance, p.RequestId)
+			}
+		})
+	}
+}
+
+func TestWriteResponseError(t *testing.T) {
+	secret := `{"password":"secret"}`
+	tests := []struct {
+		name             string
+		format           DXAPIErrorResponseFormat
+		isProductionMode bool
+		write            func(aepr *DXAPIEndPointRequest)
+		wantStatus       int
+		wantContentType  string
+		wantContains     []string
+		wantNotContains  []string
+	}{
+		{
+			name: "legacy body of a registered error", format: ErrorResponseFormatLegacy,
+			write: func(aepr *DXAPIEndPointRequest) {
+				_ = aepr.WriteResponseAndNewAPIErrorf(http.StatusBadRequest, ErrorMandatoryParameterNotExist, "%s", "name")
+			},
+			wantStatus: http.StatusBadRequest, wantContentType: "application/json", wantContains: []string{`"status_code":400`, `"reason":"BAD REQUEST"`},
+		},
+		{
+			name: "legacy body of a middleware error", format: ErrorResponseFormatLegacy,
+			write: func(aepr *DXAPIEndPointRequest) {
+				aepr.writeResponseAsMiddlewareError(errors.New("TOKEN_EXPIRED"))
+			},
+			wantStatus: http.StatusBadRequest, wantContentType: "application/json", wantContains: []string{"MIDDLEWARE_ERROR", "TOKEN_EXPIRED"},
+		},
+		{
+			name: "problem body of a request body that is not JSON", format: ErrorResponseFormatProblemJSON,
+			write: func(aepr *DXAPIEndPointRequest) {
+				_ = aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorRequestBodyCantBeParsedAsJSON, "%s:%v", "invalid character", secret)
+			},
+			wantStatus: http.StatusUnprocessableEntity, wantContentType: ContentTypeApplicationProblemJSON,
+			wantContains:    []string{`"code":"REQUEST_BODY_CANT_BE_PARSED_AS_JSON"`, `"title":"Request body is not valid JSON"`, `"request_id":"r1"`},
+			wantNotContains: []string{"password", "invalid character"},
+		},
+		{
+			name: "problem body of a formatted message", format: ErrorResponseFormatProblemJSON,
+			write: func(aepr *DXAPIEndPointRequest) {
+				_ = aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "REQUEST_BODY_CANT_BE_PARSED_AS_JSON:%v", secret)
+			},
+			wantStatus: http.StatusUnprocessableEntity, wantContentType: ContentTypeApplicationProblemJSON,
+			wantContains: []string{`"code":"REQUEST_BODY_CANT_BE_PARSED_AS_JSON"`}, wantNotContains: []string{"password"},
+		},
+		{
+			name: "problem body of a middleware error", format: ErrorResponseFormatProblemJSON,
+			write: func(aepr *DXAPIEndPointRequest) {
+				aepr.writeResponseAsMiddlewareError(errors.New("SECRET_DSN=postgres://u:p@db"))
+			},
+			wantStatus: http.StatusBadRequest, wantContentType: ContentTypeApplicationProblemJSON,
+			wantContains: []string{`"code":"MIDDLEWARE_ERROR"`}, wantNotContains: []string{"SECRET_DSN"},
+		},
+		{
+			name: "problem body keeps the code of a middleware error", format: ErrorResponseFormatProblemJSON,
+			write: func(aepr *DXAPIEndPointRequest) {
+				aepr.writeResponseAsMiddlewareError(ErrorMandatoryParameterNotExist.New("name"))
+			},
+			wantStatus: http.StatusUnprocessableEntity, wantContentType: ContentTypeApplicationProblemJSON,
+			wantContains: []string{`"code":"MANDATORY_PARAMETER_NOT_EXIST"`, `"detail":"name"`},
+		},
+		{
+			name: "production body of a formatted message", format: ErrorResponseFormatLegacy, isProductionMode: true,
+			write: func(aepr *DXAPIEndPointRequest) {
+				_ = aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "REQUEST_BODY_CANT_BE_PARSED_AS_JSON:%v", secret)
+			},
+			wantStatus: http.StatusUnprocessableEntity, wantContentType: "application/json",
+			wantContains: []string{`"reason":"REQUEST_BODY_CANT_BE_PARSED_AS_JSON"`}, wantNotContains: []string{"password"},
+		},
+		{
+			name: "production body of a public registered error", format: ErrorResponseFormatLegacy, isProductionMode: true,
+			write: func(aepr *DXAPIEndPointRequest) {
+				_ = aepr.WriteResponseAndNewAPIErrorf(http.StatusUnprocessableEntity, ErrorMandatoryParameterNotExist, "%s", "name")
+			},
+			wantStatus: http.StatusUnprocessableEntity, wantContentType: "application/json",
+			wantContains: []string{`"reason":"MANDATORY_PARAMETER_NOT_EXIST:name"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aepr, recorder := testErrorRequest(tt.format, tt.isProductionMode)
+			tt.write(aepr)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, tt.wantContentType) {
+				t.Errorf("Content-Type = %s, want %s", contentType, tt.wantContentType)
+			}
+			body := recorder.Body.String()
+			if !json.Valid([]byte(body)) {
+				t.Fatalf("body = %s, want JSON", body)
+			}
+			for _, s := range tt.wantContains {
+				if !strings.Contains(body, s) {
+					t.Errorf("body = %s, want it to contain %s", body, s)
+				}
+			}
+			for _, s := range tt.wantNotContains {
+				if strings.Contains(body, s) {
+					t.Errorf("body = %s, must not contain %s", body, s)
+				}
+			}
+		})
+	}
+}
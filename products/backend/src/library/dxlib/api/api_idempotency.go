@@ -0,0 +1,211 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/redis"
+	"github.com/donnyhardyanto/dxlib/utils"
+	utilsJSON "github.com/donnyhardyanto/dxlib/utils/json"
+	"github.com/pkg/errors"
+)
+
+const (
+	DXAPIIdempotencyKeyHeader             = "Idempotency-Key"
+	DXAPIIdempotencyReplayedHeader        = "Idempotent-Replayed"
+	DXAPIIdempotencyKeyMaxLength          = 255
+	DXAPIIdempotencyDefaultTTLSec         = 24 * 60 * 60
+	DXAPIIdempotencyDefaultInFlightTTLSec = 5 * 60
+
+	idempotencyStateInFlight  = "in-flight"
+	idempotencyStateCompleted = "completed"
+)
+
+var (
+	ErrorIdempotencyKeyInvalid    = RegisterError("IDEMPOTENCY_KEY_INVALID", http.StatusBadRequest, "Idempotency-Key header is invalid", true)
+	ErrorIdempotencyKeyInProgress = RegisterError("IDEMPOTENCY_KEY_IN_PROGRESS", http.StatusConflict, "A request with the same Idempotency-Key is still in progress", false)
+	ErrorIdempotencyKeyReused     = RegisterError("IDEMPOTENCY_KEY_REUSED", http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request", false)
+	ErrorIdempotencyUnavailable   = RegisterError("IDEMPOTENCY_UNAVAILABLE", http.StatusServiceUnavailable, "Idempotency store is unavailable", false)
+)
+
+type DXAPIIdempotency struct {
+	RedisNameId    string
+	KeyPrefix      string
+	TTLSec         int
+	InFlightTTLSec int
+}
+
+func (i *DXAPIIdempotency) ApplyConfiguration(apiNameId string, j utils.JSON) {
+	i.RedisNameId, _ = j["redis-nameid"].(string)
+	i.KeyPrefix, _ = j["key-prefix"].(string)
+	if i.KeyPrefix == "" {
+		i.KeyPrefix = "idempotency:" + apiNameId
+	}
+	i.TTLSec = utilsJSON.GetNumberWithDefault(j, "ttl-sec", DXAPIIdempotencyDefaultTTLSec)
+	i.InFlightTTLSec = utilsJSON.GetNumberWithDefault(j, "in-flight-ttl-sec", DXAPIIdempotencyDefaultInFlightTTLSec)
+}
+
+func (i *DXAPIIdempotency) redis() (r *redis.DXRedis, err error) {
+	r, ok := redis.Manager.Redises[i.RedisNameId]
+	if !ok || (r.Connection == nil) {
+		return nil, errors.Errorf("IDEMPOTENCY_REDIS_NOT_FOUND:%s", i.RedisNameId)
+	}
+	return r, nil
+}
+
+// idempotencyResponseRecorder passes the response through while keeping a copy to be replayed
+type idempotencyResponseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	header     http.Header
+	body       bytes.Buffer
+}
+
+func (rr *idempotencyResponseRecorder) WriteHeader(statusCode int) {
+	rr.statusCode = statusCode
+	rr.header = rr.ResponseWriter.Header().Clone()
+	rr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rr *idempotencyResponseRecorder) Write(b []byte) (int, error) {
+	if rr.statusCode == 0 {
+		rr.WriteHeader(http.StatusOK)
+	}
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
+
+func isIdempotencyReplayableHeader(k string) bool {
+	k = http.CanonicalHeaderKey(k)
+	return !strings.HasPrefix(k, "Access-Control-") && (k != "Vary") && (k != "Date") && (k != "Content-Length") && (k != DXAPIRequestIdHeader)
+}
+
+func (aepr *DXAPIEndPointRequest) idempotencyRequestHash() string {
+	h := sha256.New()
+	h.Write([]byte(aepr.Request.Method + " " + aepr.Request.URL.Path + "?" + aepr.Request.URL.RawQuery + "\n"))
+	h.Write(aepr.RequestBodyAsBytes)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (aepr *DXAPIEndPointRequest) idempotencyReplay(record utils.JSON) {
+	w := *aepr.ResponseWriter
+	if headers, ok := record["headers"].(map[string]any); ok {
+		for k, v := range headers {
+			values, _ := v.([]any)
+			w.Header().Del(k)
+			for _, value := range values {
+				s, _ := value.(string)
+				w.Header().Add(k, s)
+			}
+		}
+	}
+	w.Header().Set(DXAPIIdempotencyReplayedHeader, "true")
+	bodyAsBase64, _ := record["body"].(string)
+	body, _ := base64.StdEncoding.DecodeString(bodyAsBase64)
+	statusCode := http.StatusOK
+	if v, ok := record["status_code"].(float64); ok {
+		statusCode = int(v)
+	}
+	aepr.WriteResponseAsBytes(statusCode, nil, body)
+}
+
+// idempotencyBegin replays a completed response or claims the key, isHandled is true when the response is already written.
+// finish must be called after the handler so the captured response is stored, or the key released when no result is worth replaying
+func (aepr *DXAPIEndPointRequest) idempotencyBegin() (isHandled bool, finish func()) {
+	finish = func() {}
+	idempotencyKey := strings.TrimSpace(aepr.Request.Header.Get(DXAPIIdempotencyKeyHeader))
+	if idempotencyKey == "" {
+		return false, finish
+	}
+	if len(idempotencyKey) > DXAPIIdempotencyKeyMaxLength {
+		aepr.WriteResponseAsError(http.StatusBadRequest, ErrorIdempotencyKeyInvalid.Newf("LENGTH>%d", DXAPIIdempotencyKeyMaxLength))
+		return true, finish
+	}
+	i := &aepr.EndPoint.Owner.Idempotency
+	if i.RedisNameId == "" {
+		return false, finish
+	}
+	r, err := i.redis()
+	if err != nil {
+		aepr.Log.Errorf(err, "IDEMPOTENCY_REDIS_ERROR:%s", err.Error())
+		aepr.WriteResponseAsError(http.StatusServiceUnavailable, ErrorIdempotencyUnavailable.Wrap(err, ""))
+		return true, finish
+	}
+	r = r.WithContext(aepr.Context)
+
+	key := strings.Join([]string{i.KeyPrefix, aepr.EndPoint.Method, aepr.EndPoint.Uri, aepr.CurrentUser.Id, idempotencyKey}, ":")
+	requestHash := aepr.idempotencyRequestHash()
+
+	// the key can be released or expire between SetNX and Get, the claim is then tried once more before answering in progress
+	for attempt := 0; ; attempt++ {
+		isSet, err := r.SetNX(key, utils.JSON{
+			"state":        idempotencyStateInFlight,
+			"request_hash": requestHash,
+		}, time.Duration(i.InFlightTTLSec)*time.Second)
+		if err != nil {
+			aepr.Log.Errorf(err, "IDEMPOTENCY_REDIS_ERROR:%s", err.Error())
+			aepr.WriteResponseAsError(http.StatusServiceUnavailable, ErrorIdempotencyUnavailable.Wrap(err, ""))
+			return true, finish
+		}
+		if isSet {
+			break
+		}
+		record, err := r.Get(key)
+		if err != nil {
+			aepr.Log.Errorf(err, "IDEMPOTENCY_REDIS_ERROR:%s", err.Error())
+			aepr.WriteResponseAsError(http.StatusServiceUnavailable, ErrorIdempotencyUnavailable.Wrap(err, ""))
+			return true, finish
+		}
+		switch {
+		case (record == nil) && (attempt == 0):
+			continue
+		case record == nil, record["state"] == idempotencyStateInFlight:
+			aepr.WriteResponseAsError(http.StatusConflict, ErrorIdempotencyKeyInProgress.New(""))
+		case record["request_hash"] != requestHash:
+			aepr.WriteResponseAsError(http.StatusUnprocessableEntity, ErrorIdempotencyKeyReused.New(""))
+		default:
+			aepr.idempotencyReplay(record)
+		}
+		return true, finish
+	}
+
+	recorder := &idempotencyResponseRecorder{ResponseWriter: *aepr.ResponseWriter}
+	var w http.ResponseWriter = recorder
+	aepr.ResponseWriter = &w
+
+	// the request context is already cancelled after a timeout or a client disconnect, the key must still be released or stored
+	rFinish := r.WithContext(context.WithoutCancel(aepr.Context))
+	finish = func() {
+		aepr.ResponseWriter = &recorder.ResponseWriter
+		if (recorder.statusCode == 0) || (recorder.statusCode >= 500) {
+			err := rFinish.Delete(key)
+			if err != nil {
+				aepr.Log.Errorf(err, "IDEMPOTENCY_REDIS_ERROR:%s", err.Error())
+			}
+			return
+		}
+		headers := utils.JSON{}
+		for k, v := range recorder.header {
+			if isIdempotencyReplayableHeader(k) {
+				headers[k] = v
+			}
+		}
+		err := rFinish.Set(key, utils.JSON{
+			"state":        idempotencyStateCompleted,
+			"request_hash": requestHash,
+			"status_code":  recorder.statusCode,
+			"headers":      headers,
+			"body":         base64.StdEncoding.EncodeToString(recorder.body.Bytes()),
+		}, time.Duration(i.TTLSec)*time.Second)
+		if err != nil {
+			aepr.Log.Errorf(err, "IDEMPOTENCY_REDIS_ERROR:%s", err.Error())
+		}
+	}
+	return false, finish
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goRedis "github.com/go-redis/redis/v8"
+
+	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/redis"
+	"github.com/donnyhardyanto/dxlib/utils"
+)
+
+func TestIdempotency(t *testing.T) {
+	server := miniredis.RunT(t)
+	connection := goRedis.NewRing(&goRedis.RingOptions{Addrs: map[string]string{"test": server.Addr()}})
+	defer func() {
+		_ = connection.Close()
+	}()
+	redis.Manager.Redises["idempotency_test"] = &redis.DXRedis{NameId: "idempotency_test", Connection: connection, Connected: true, Context: context.Background()}
+	defer delete(redis.Manager.Redises, "idempotency_test")
+
+	a := &DXAPI{NameId: "test"}
+	a.Idempotency.ApplyConfiguration(a.NameId, utils.JSON{"redis-nameid": "idempotency_test"})
+	endPoint := &DXAPIEndPoint{Owner: a, Method: http.MethodPost, Uri: "/v1/item/create", IsIdempotencyKeyEnabled: true}
+
+	handlerCallCount := 0
+	serve := func(userId string, idempotencyKey string, body string, handlerStatusCode int) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(endPoint.Method, endPoint.Uri, strings.NewReader(body))
+		if idempotencyKey != "" {
+			r.Header.Set(DXAPIIdempotencyKeyHeader, idempotencyKey)
+		}
+		recorder := httptest.NewRecorder()
+		var w http.ResponseWriter = recorder
+		aepr := &DXAPIEndPointRequest{
+			Context:            context.Background(),
+			EndPoint:           endPoint,
+			Log:                log.NewLog(nil, context.Background(), "test"),
+			Request:            r,
+			RequestBodyAsBytes: []byte(body),
+			ResponseWriter:     &w,
+			CurrentUser:        DXAPIUser{Id: userId},
+		}
+		isHandled, finish := aepr.idempotencyBegin()
+		if !isHandled {
+			handlerCallCount++
+			aepr.WriteResponseAsJSON(handlerStatusCode, map[string]string{"X-Var": "v"}, utils.JSON{"call": handlerCallCount})
+			finish()
+		}
+		return recorder
+	}
+
+	// the steps share the store, each one sees what the ones before it left behind
+	tests := []struct {
+		name                 string
+		userId               string
+		idempotencyKey       string
+		body                 string
+		handlerStatusCode    int
+		wantStatusCode       int
+		wantHandlerCallCount int
+		wantReplayed         bool
+		wantBodyContains     string
+	}{
+		{name: "first request runs the handler", userId: "1", idempotencyKey: "k1", body: `{"a":1}`, handlerStatusCode: http.StatusCreated, wantStatusCode: http.StatusCreated, wantHandlerCallCount: 1, wantBodyContains: `"call":1`},
+		{name: "retry is replayed", userId: "1", idempotencyKey: "k1", body: `{"a":1}`, handlerStatusCode: http.StatusCreated, wantStatusCode: http.StatusCreated, wantHandlerCallCount: 1, wantReplayed: true, wantBodyContains: `"call":1`},
+		{name: "retry with another body is rejected", userId: "1", idempotencyKey: "k1", body: `{"a":2}`, handlerStatusCode: http.StatusCreated, wantStatusCode: http.StatusUnprocessableEntity, wantHandlerCallCount: 1},
+		{name: "same key of another user runs the handler", userId: "2", idempotencyKey: "k1", body: `{"a":1}`, handlerStatusCode: http.StatusCreated, wantStatusCode: http.StatusCreated, wantHandlerCallCount: 2, wantBodyContains: `"call":2`},
+		{name: "without key every request runs the handler", userId: "1", body: `{"a":1}`, handlerStatusCode: http.StatusCreated, wantStatusCode: http.StatusCreated, wantHandlerCallCount: 3},
+		{name: "key too long", userId: "1", idempotencyKey: strings.Repeat("k", DXAPIIdempotencyKeyMaxLength+1), body: `{"a":1}`, handlerStatusCode: http.StatusCreated, wantStatusCode: http.StatusBadRequest, wantHandlerCallCount: 3},
+		{name: "server error releases the key", userId: "1", idempotencyKey: "k2", body: `{"a":1}`, handlerStatusCode: http.StatusInternalServerError, wantStatusCode: http.StatusInternalServerError, wantHandlerCallCount: 4},
+		{name: "retry after a server error runs the handler again", userId: "1", idempotencyKey: "k2", body: `{"a":1}`, handlerStatusCode: http.StatusOK, wantStatusCode: http.StatusOK, wantHandlerCallCount: 5},
+		{name: "client error is replayed", userId: "1", idempotencyKey: "k3", body: `{"a":1}`, handlerStatusCode: http.StatusConflict, wantStatusCode: http.StatusConflict, wantHandlerCallCount: 6},
+		{name: "retry of a client error is replayed", userId: "1", idempotencyKey: "k3", body: `{"a":1}`, handlerStatusCode: http.StatusOK, wantStatusCode: http.StatusConflict, wantHandlerCallCount: 6, wantReplayed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(tt.userId, tt.idempotencyKey, tt.body, tt.handlerStatusCode)
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("status code = %d, want %d", w.Code, tt.wantStatusCode)
+			}
+			if handlerCallCount != tt.wantHandlerCallCount {
+				t.Errorf("handler call count = %d, want %d", handlerCallCount, tt.wantHandlerCallCount)
+			}
+			if isReplayed := w.Header().Get(DXAPIIdempotencyReplayedHeader) == "true"; isReplayed != tt.wantReplayed {
+				t.Errorf("replayed = %v, want %v", isReplayed, tt.wantReplayed)
+			}
+			if tt.wantReplayed && (w.Header().Get("X-Var") != "v") {
+				t.Errorf("replayed X-Var = %q, want the header of the first response", w.Header().Get("X-Var"))
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBodyContains) {
+				t.Errorf("body = %s, want it to contain %s", w.Body.String(), tt.wantBodyContains)
+			}
+		})
+	}
+
+	t.Run("key of a request still in flight", func(t *testing.T) {
+		key := strings.Join([]string{a.Idempotency.KeyPrefix, endPoint.Method, endPoint.Uri, "1", "k4"}, ":")
+		err := server.Set(key, `{"state":"in-flight","request_hash":"x"}`)
+		if err != nil {
+			t.Fatalf("Set() err = %v", err)
+		}
+		server.SetTTL(key, time.Minute)
+		callCount := handlerCallCount
+		w := serve("1", "k4", `{"a":1}`, http.StatusOK)
+		if w.Code != http.StatusConflict {
+			t.Errorf("status code = %d, want %d", w.Code, http.StatusConflict)
+		}
+		if handlerCallCount != callCount {
+			t.Errorf("handler ran for a key in flight")
+		}
+	})
+
+	t.Run("store unavailable", func(t *testing.T) {
+		server.SetError("LOADING")
+		defer server.SetError("")
+		callCount := handlerCallCount
+		w := serve("1", "k5", `{"a":1}`, http.StatusOK)
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("status code = %d, want %d", w.Code, http.StatusServiceUnavailable)
+		}
+		if handlerCallCount != callCount {
+			t.Errorf("handler ran without the store")
+		}
+	})
+}
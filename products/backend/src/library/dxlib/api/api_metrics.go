@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/metrics"
+)
+
+var (
+	metricAPIRequests         = metrics.Manager.NewCounter("dxlib_api_requests_total", "Requests handled by an API endpoint, by response status code", "api", "method", "uri", "status_code")
+	metricAPIRequestDuration  = metrics.Manager.NewHistogram("dxlib_api_request_duration_seconds", "Duration of requests handled by an API endpoint", metrics.DefaultDurationBuckets, "api", "method", "uri")
+	metricAPIRequestsInFlight = metrics.Manager.NewGauge("dxlib_api_requests_in_flight", "Requests currently handled by an API endpoint", "api", "method", "uri")
+)
+
+// metricsBegin counts the request as in flight, the returned func records its status code and duration once the response is done
+func (aepr *DXAPIEndPointRequest) metricsBegin() (end func()) {
+	p := aepr.EndPoint
+	metricAPIRequestsInFlight.Inc(p.Owner.NameId, p.Method, p.Uri)
+	startTime := time.Now()
+	return func() {
+		metricAPIRequestsInFlight.Dec(p.Owner.NameId, p.Method, p.Uri)
+		metricAPIRequestDuration.Observe(time.Since(startTime).Seconds(), p.Owner.NameId, p.Method, p.Uri)
+		metricAPIRequests.Inc(p.Owner.NameId, p.Method, p.Uri, strconv.Itoa(aepr.ResponseStatusCode))
+	}
+}
+
+// APIHandlerMetrics writes every registered metric in the Prometheus text format, it is meant to be attached to the OAM API
+func APIHandlerMetrics(aepr *DXAPIEndPointRequest) (err error) {
+	s, err := metrics.Manager.Text()
+	if err != nil {
+		return err
+	}
+	aepr.WriteResponseAsString(http.StatusOK, map[string]string{"Content-Type": metrics.ContentType}, s)
+	return nil
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	utilsHttp "github.com/donnyhardyanto/dxlib/utils/http"
+	"github.com/pkg/errors"
+)
+
+// DXAPIMiddlewareNextFunc runs the inner middlewares and the endpoint
+type DXAPIMiddlewareNextFunc func() (err error)
+
+// DXAPIMiddlewareFunc wraps the handling of a request, code before next runs as a before hook and code after next may post-process the response.
+// A middleware rejects the request by returning an error without calling next, the error is then answered like a failing endpoint middleware
+type DXAPIMiddlewareFunc func(aepr *DXAPIEndPointRequest, next DXAPIMiddlewareNextFunc) (err error)
+
+// MiddlewareBefore runs f before the endpoint, it adapts the middlewares of NewEndPoint such as a login check
+func MiddlewareBefore(f DXAPIEndPointExecuteFunc) DXAPIMiddlewareFunc {
+	return func(aepr *DXAPIEndPointRequest, next DXAPIMiddlewareNextFunc) (err error) {
+		err = f(aepr)
+		if err != nil {
+			return err
+		}
+		if aepr.ResponseHeaderSent {
+			// f already answered the request, e.g. with 429
+			return nil
+		}
+		return next()
+	}
+}
+
+// MiddlewareAfter runs f after the endpoint, also when the endpoint failed, the response is already written by then
+func MiddlewareAfter(f DXAPIEndPointExecuteFunc) DXAPIMiddlewareFunc {
+	return func(aepr *DXAPIEndPointRequest, next DXAPIMiddlewareNextFunc) (err error) {
+		err = next()
+		err2 := f(aepr)
+		if err == nil {
+			err = err2
+		}
+		return err
+	}
+}
+
+// Use adds middlewares wrapping every endpoint of the API, they run before the group and endpoint middlewares in the order added
+func (a *DXAPI) Use(middlewares ...DXAPIMiddlewareFunc) {
+	a.Middlewares = append(a.Middlewares, middlewares...)
+}
+
+// DXAPIGroup registers endpoints under a common uri prefix sharing middlewares, the middlewares of a parent group run first
+type DXAPIGroup struct {
+	Owner       *DXAPI
+	Parent      *DXAPIGroup
+	Prefix      string
+	Middlewares []DXAPIMiddlewareFunc
+}
+
+func (a *DXAPI) Group(prefix string, middlewares ...DXAPIMiddlewareFunc) *DXAPIGroup {
+	return &DXAPIGroup{
+		Owner:       a,
+		Prefix:      prefix,
+		Middlewares: middlewares,
+	}
+}
+
+func (g *DXAPIGroup) Group(prefix string, middlewares ...DXAPIMiddlewareFunc) *DXAPIGroup {
+	return &DXAPIGroup{
+		Owner:       g.Owner,
+		Parent:      g,
+		Prefix:      g.Prefix + prefix,
+		Middlewares: middlewares,
+	}
+}
+
+func (g *DXAPIGroup) Use(middlewares ...DXAPIMiddlewareFunc) {
+	g.Middlewares = append(g.Middlewares, middlewares...)
+}
+
+// NewEndPoint is DXAPI.NewEndPoint with uri relative to the group prefix
+func (g *DXAPIGroup) NewEndPoint(title, description, uri, method string, endPointType DXAPIEndPointType,
+	contentType utilsHttp.RequestContentType, parameters []DXAPIEndPointParameter, onExecute DXAPIEndPointExecuteFunc,
+	onWSLoop DXAPIEndPointExecuteFunc, responsePossibilities map[string]*DXAPIEndPointResponsePossibility, middlewares []DXAPIEndPointExecuteFunc,
+	privileges []string, requestMaxContentLength int64, rateLimitGroupNameId string) *DXAPIEndPoint {
+	ae := g.Owner.NewEndPoint(title, description, g.Prefix+uri, method, endPointType, contentType, parameters, onExecute, onWSLoop,
+		responsePossibilities, middlewares, privileges, requestMaxContentLength, rateLimitGroupNameId)
+	ae.Group = g
+	return ae
+}
+
+// middlewareChain lists the API middlewares, then the group middlewares from the outermost group
+func (aep *DXAPIEndPoint) middlewareChain() (middlewares []DXAPIMiddlewareFunc) {
+	for g := aep.Group; g != nil; g = g.Parent {
+		middlewares = append(append([]DXAPIMiddlewareFunc{}, g.Middlewares...), middlewares...)
+	}
+	return append(append([]DXAPIMiddlewareFunc{}, aep.Owner.Middlewares...), middlewares...)
+}
+
+// executeMiddlewares runs endPointExecute wrapped by the API and group middlewares
+func (aepr *DXAPIEndPointRequest) executeMiddlewares(endPointExecute DXAPIMiddlewareNextFunc) (err error) {
+	middlewares := aepr.EndPoint.middlewareChain()
+	var next func(i int) error
+	next = func(i int) error {
+		if i == len(middlewares) {
+			return endPointExecute()
+		}
+		err := middlewares[i](aepr, func() error {
+			return next(i + 1)
+		})
+		if (err != nil) && !aepr.ResponseHeaderSent {
+			// answered here so the outer middlewares see the response
+			aepr.writeResponseAsMiddlewareError(err)
+		}
+		return err
+	}
+	return next(0)
+}
+
+// writeResponseAsMiddlewareError answers a request rejected by a middleware with 400, or 504 when the endpoint deadline passed
+func (aepr *DXAPIEndPointRequest) writeResponseAsMiddlewareError(err error) {
+	if aepr.IsTimedOut() {
+		aepr.Log.Errorf(err, "ONMIDDLEWARE_TIMEOUT:%v", err)
+		aepr.WriteResponseAsError(http.StatusGatewayTimeout, aepr.newTimeoutError(err))
+		return
+	}
+	err3 := errors.Wrap(err, fmt.Sprintf("MIDDLEWARE_ERROR:\n%+v", err))
+	if aepr.isLegacyErrorResponse() {
+		aepr.WriteResponseAsError(http.StatusBadRequest, err3)
+	} else {
+		// an error of a registered code keeps it, any other error is answered as MIDDLEWARE_ERROR
+		var apiError *DXAPIError
+		if !errors.As(err, &apiError) {
+			apiError = ErrorMiddleware.Wrap(err, "")
+		}
+		aepr.WriteResponseAsError(http.StatusBadRequest, apiError)
+	}
+	requestDump, err2 := aepr.RequestDump()
+	if err2 != nil {
+		aepr.Log.Errorf(err2, "REQUEST_DUMP_ERROR:%v", err2.Error())
+		return
+	}
+	aepr.Log.Errorf(err3, "ONMIDDLEWARE_ERROR:%v\nRaw Request :\n%v\n", err3, string(requestDump))
+}
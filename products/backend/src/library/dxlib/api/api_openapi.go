@@ -0,0 +1,542 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+	utilsHttp "github.com/donnyhardyanto/dxlib/utils/http"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const OpenAPIVersion = "3.1.0"
+
+var openAPIOperationIdInvalidChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+var openAPIVersionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+func openAPIParameterTypeSchema(aType string) (schema utils.JSON) {
+	switch aType {
+	case "int64", "nullable-int64":
+		return utils.JSON{"type": "integer", "format": "int64"}
+	case "int64p":
+		return utils.JSON{"type": "integer", "format": "int64", "exclusiveMinimum": 0}
+	case "int64zp":
+		return utils.JSON{"type": "integer", "format": "int64", "minimum": 0}
+	case "float32":
+		return utils.JSON{"type": "number", "format": "float"}
+	case "float32p":
+		return utils.JSON{"type": "number", "format": "float", "exclusiveMinimum": 0}
+	case "float32zp":
+		return utils.JSON{"type": "number", "format": "float", "minimum": 0}
+	case "float64":
+		return utils.JSON{"type": "number", "format": "double"}
+	case "float64p":
+		return utils.JSON{"type": "number", "format": "double", "exclusiveMinimum": 0}
+	case "float64zp":
+		return utils.JSON{"type": "number", "format": "double", "minimum": 0}
+	case "bool":
+		return utils.JSON{"type": "boolean"}
+	case "string", "nullable-string", "protected-string", "protected-sql-string":
+		return utils.JSON{"type": "string"}
+	case "non-empty-string":
+		return utils.JSON{"type": "string", "minLength": 1}
+	case "email":
+		return utils.JSON{"type": "string", "format": "email"}
+	case "phonenumber", "npwp":
+		return utils.JSON{"type": "string", "format": aType}
+	case "iso8601":
+		return utils.JSON{"type": "string", "format": "date-time"}
+	case "date":
+		return utils.JSON{"type": "string", "format": "date"}
+	case "time":
+		return utils.JSON{"type": "string", "format": "time"}
+	case "json", "json-passthrough":
+		return utils.JSON{"type": "object"}
+	case "array", "array-json-template":
+		return utils.JSON{"type": "array", "items": utils.JSON{}}
+	case "array-string":
+		return utils.JSON{"type": "array", "items": utils.JSON{"type": "string"}}
+	case "array-int64":
+		return utils.JSON{"type": "array", "items": utils.JSON{"type": "integer", "format": "int64"}}
+	case "file":
+		return utils.JSON{"type": "string", "format": "binary"}
+	case "uuid":
+		return utils.JSON{"type": "string", "format": "uuid"}
+	case "nullable-bool":
+		return utils.JSON{"type": "boolean"}
+	case "nullable-float64":
+		return utils.JSON{"type": "number", "format": "double"}
+	case "decimal":
+		return utils.JSON{"type": "string", "format": "decimal", "pattern": `^-?[0-9]+(\.[0-9]+)?$`}
+	case "array-float64":
+		return utils.JSON{"type": "array", "items": utils.JSON{"type": "number", "format": "double"}}
+	case "array-uuid":
+		return utils.JSON{"type": "array", "items": utils.JSON{"type": "string", "format": "uuid"}}
+	case "datetime-range":
+		return utils.JSON{"type": "object", "required": []string{"start", "end"}, "properties": utils.JSON{
+			"start": utils.JSON{"type": "string", "format": "date-time"},
+			"end":   utils.JSON{"type": "string", "format": "date-time"},
+		}}
+	case "base64-bytes":
+		return utils.JSON{"type": "string", "contentEncoding": "base64"}
+	default:
+		schema = utils.JSON{}
+		if t, ok := FindParameterType(aType); ok {
+			for k, v := range t.OpenAPISchema {
+				schema[k] = v
+			}
+			if t.Description != "" {
+				schema["description"] = t.Description
+			}
+		}
+		return schema
+	}
+}
+
+func openAPIObjectSchema(children []DXAPIEndPointParameter) (schema utils.JSON) {
+	properties := utils.JSON{}
+	required := []string{}
+	for _, c := range children {
+		properties[c.NameId] = c.OpenAPISchema()
+		if c.IsMustExist {
+			required = append(required, c.NameId)
+		}
+	}
+	schema = utils.JSON{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (aep *DXAPIEndPointParameter) OpenAPISchema() (schema utils.JSON) {
+	schema = openAPIParameterTypeSchema(aep.Type)
+	switch aep.Type {
+	case "json":
+		if len(aep.Children) > 0 {
+			schema = openAPIObjectSchema(aep.Children)
+		}
+	case "array-json-template":
+		schema["items"] = openAPIObjectSchema(aep.Children)
+	}
+	aep.Constraint.OpenAPISchema(schema)
+	if aep.IsNullable {
+		if t, ok := schema["type"].(string); ok {
+			schema["type"] = []string{t, "null"}
+		}
+	}
+	if aep.Type == "file" {
+		if len(aep.FileContentTypes) == 1 {
+			schema["contentMediaType"] = aep.FileContentTypes[0]
+		}
+		if len(aep.FileContentTypes) > 0 {
+			schema["x-dxlib-file-content-types"] = aep.FileContentTypes
+		}
+		if aep.FileMaxSize > 0 {
+			schema["x-dxlib-file-max-size"] = aep.FileMaxSize
+		}
+	}
+	if aep.Description != "" {
+		schema["description"] = aep.Description
+	}
+	schema["x-dxlib-type"] = aep.Type
+	return schema
+}
+
+func openAPIDataTemplateSchema(dataTemplate []*DXAPIEndPointParameter) (schema utils.JSON) {
+	children := make([]DXAPIEndPointParameter, 0, len(dataTemplate))
+	for _, p := range dataTemplate {
+		if p != nil {
+			children = append(children, *p)
+		}
+	}
+	return openAPIObjectSchema(children)
+}
+
+func openAPIResponseEnvelopeSchema(dataSchema utils.JSON) (schema utils.JSON) {
+	properties := utils.JSON{
+		"status":         utils.JSON{"type": "string"},
+		"status_code":    utils.JSON{"type": "integer"},
+		"reason":         utils.JSON{"type": "string"},
+		"reason_message": utils.JSON{"type": "string"},
+	}
+	if dataSchema != nil {
+		properties["data"] = dataSchema
+	}
+	return utils.JSON{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{"status", "status_code"},
+	}
+}
+
+func openAPIOperationTag(uri string) string {
+	for _, segment := range strings.Split(strings.Trim(uri, "/"), "/") {
+		if (segment != "") && !openAPIVersionSegment.MatchString(segment) {
+			return segment
+		}
+	}
+	return "default"
+}
+
+func openAPIPathFromURI(uri string) string {
+	segments := strings.Split(uri, "/")
+	for i, segment := range segments {
+		if segment == "{$}" {
+			segments[i] = ""
+			continue
+		}
+		nameId, _, ok := uriTemplateSegmentParameterName(segment)
+		if ok {
+			segments[i] = "{" + nameId + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func (aep *DXAPIEndPoint) openAPIRequestParameters() (parameters []utils.JSON) {
+	parameters = []utils.JSON{}
+	for _, p := range aep.PathParameters {
+		parameter := utils.JSON{
+			"name":     p.NameId,
+			"in":       "path",
+			"required": true,
+			"schema":   p.OpenAPISchema(),
+		}
+		if p.Description != "" {
+			parameter["description"] = p.Description
+		}
+		parameters = append(parameters, parameter)
+	}
+	switch aep.Method {
+	case "GET", "DELETE":
+		for _, p := range aep.Parameters {
+			parameter := utils.JSON{
+				"name":     p.NameId,
+				"in":       "query",
+				"required": p.IsMustExist,
+				"schema":   p.OpenAPISchema(),
+			}
+			if p.Description != "" {
+				parameter["description"] = p.Description
+			}
+			parameters = append(parameters, parameter)
+		}
+	default:
+		if (aep.RequestContentType == utilsHttp.ContentTypeApplicationOctetStream) && (len(aep.Parameters) > 0) {
+			parameters = append(parameters, utils.JSON{
+				"name":        "X-Var",
+				"in":          "header",
+				"description": "Request parameters encoded as a JSON object",
+				"required":    true,
+				"content": utils.JSON{
+					utilsHttp.ContentTypeApplicationJSON.String(): utils.JSON{
+						"schema": openAPIObjectSchema(aep.Parameters),
+					},
+				},
+			})
+		}
+	}
+	return parameters
+}
+
+func (aep *DXAPIEndPoint) openAPIRequestBody() (requestBody utils.JSON) {
+	switch aep.Method {
+	case "GET", "DELETE":
+		return nil
+	}
+	switch aep.RequestContentType {
+	case utilsHttp.ContentTypeApplicationJSON:
+		return utils.JSON{
+			"required": true,
+			"content": utils.JSON{
+				aep.RequestContentType.String(): utils.JSON{
+					"schema": openAPIObjectSchema(aep.Parameters),
+				},
+			},
+		}
+	case utilsHttp.ContentTypeApplicationOctetStream:
+		return utils.JSON{
+			"required": true,
+			"content": utils.JSON{
+				aep.RequestContentType.String(): utils.JSON{
+					"schema": utils.JSON{"type": "string", "contentMediaType": "application/octet-stream"},
+				},
+			},
+		}
+	case utilsHttp.ContentTypeNone:
+		return nil
+	default:
+		return utils.JSON{
+			"required": true,
+			"content": utils.JSON{
+				aep.RequestContentType.String(): utils.JSON{
+					"schema": openAPIObjectSchema(aep.Parameters),
+				},
+			},
+		}
+	}
+}
+
+func (aep *DXAPIEndPoint) openAPIResponses() (responses utils.JSON) {
+	responses = utils.JSON{}
+	responseContentType := utilsHttp.ContentTypeApplicationJSON.String()
+	switch aep.EndPointType {
+	case EndPointTypeHTTPDownloadStream:
+		responseContentType = utilsHttp.ContentTypeApplicationOctetStream.String()
+	case EndPointTypeSSE:
+		responseContentType = DXAPISSEContentType
+	}
+
+	keys := make([]string, 0, len(aep.ResponsePossibilities))
+	for k, v := range aep.ResponsePossibilities {
+		if v != nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := aep.ResponsePossibilities[k]
+		statusCode := fmt.Sprintf("%d", v.StatusCode)
+		description := v.Description
+		if description == "" {
+			description = http.StatusText(v.StatusCode)
+		}
+		if existing, ok := responses[statusCode].(utils.JSON); ok {
+			existing["description"] = existing["description"].(string) + "; " + description
+			continue
+		}
+		response := utils.JSON{"description": description}
+		if len(v.Headers) > 0 {
+			headers := utils.JSON{}
+			for hk, hv := range v.Headers {
+				headers[hk] = utils.JSON{"description": hv, "schema": utils.JSON{"type": "string"}}
+			}
+			response["headers"] = headers
+		}
+		var dataSchema utils.JSON
+		if v.DataTemplate != nil {
+			dataSchema = openAPIDataTemplateSchema(v.DataTemplate)
+		}
+		if (responseContentType != utilsHttp.ContentTypeApplicationJSON.String()) && (v.StatusCode == http.StatusOK) {
+			response["content"] = utils.JSON{
+				responseContentType: utils.JSON{"schema": utils.JSON{"type": "string", "contentMediaType": responseContentType}},
+			}
+		} else if (v.StatusCode >= 400) && aep.isProblemJSONErrorResponse() {
+			response["content"] = openAPIProblemContent()
+		} else {
+			response["content"] = utils.JSON{
+				utilsHttp.ContentTypeApplicationJSON.String(): utils.JSON{"schema": openAPIResponseEnvelopeSchema(dataSchema)},
+			}
+		}
+		responses[statusCode] = response
+	}
+
+	if len(responses) == 0 {
+		responses["200"] = utils.JSON{
+			"description": http.StatusText(http.StatusOK),
+			"content": utils.JSON{
+				responseContentType: utils.JSON{"schema": openAPIResponseEnvelopeSchema(nil)},
+			},
+		}
+		responses["default"] = utils.JSON{
+			"description": "Error",
+			"content": utils.JSON{
+				utilsHttp.ContentTypeApplicationJSON.String(): utils.JSON{"schema": openAPIResponseEnvelopeSchema(nil)},
+			},
+		}
+		if aep.isProblemJSONErrorResponse() {
+			responses["default"].(utils.JSON)["content"] = openAPIProblemContent()
+		}
+	}
+	return responses
+}
+
+func (aep *DXAPIEndPoint) isProblemJSONErrorResponse() bool {
+	return (aep.Owner != nil) && (aep.Owner.ErrorResponseFormat == ErrorResponseFormatProblemJSON)
+}
+
+func openAPIProblemContent() utils.JSON {
+	return utils.JSON{
+		ContentTypeApplicationProblemJSON: utils.JSON{
+			"schema": utils.JSON{
+				"type": "object",
+				"properties": utils.JSON{
+					"type":     utils.JSON{"type": "string", "format": "uri-reference"},
+					"title":    utils.JSON{"type": "string"},
+					"status":   utils.JSON{"type": "integer"},
+					"detail":   utils.JSON{"type": "string"},
+					"instance": utils.JSON{"type": "string", "format": "uri-reference"},
+					"code":     utils.JSON{"type": "string"},
+				},
+				"required": []string{"type", "title", "status"},
+			},
+		},
+	}
+}
+
+func (aep *DXAPIEndPoint) OpenAPIOperation(operationId string) (operation utils.JSON) {
+	operation = utils.JSON{
+		"operationId": operationId,
+		"summary":     aep.Title,
+		"tags":        []string{openAPIOperationTag(aep.Uri)},
+		"responses":   aep.openAPIResponses(),
+	}
+	if aep.Description != "" {
+		operation["description"] = aep.Description
+	}
+	parameters := aep.openAPIRequestParameters()
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+	requestBody := aep.openAPIRequestBody()
+	if requestBody != nil {
+		operation["requestBody"] = requestBody
+	}
+	if len(aep.Privileges) > 0 {
+		operation["x-dxlib-privileges"] = aep.Privileges
+	}
+	if aep.RequestMaxContentLength > 0 {
+		operation["x-dxlib-request-max-content-length"] = aep.RequestMaxContentLength
+	}
+	if aep.RateLimitGroupNameId != "" {
+		operation["x-dxlib-rate-limit-group"] = aep.RateLimitGroupNameId
+	}
+	return operation
+}
+
+func (a *DXAPI) OpenAPIDocument() (document utils.JSON) {
+	paths := utils.JSON{}
+	operationIds := map[string]bool{}
+	tagNames := map[string]bool{}
+
+	for _, endPoint := range a.EndPoints {
+		method := strings.ToLower(strings.TrimSpace(endPoint.Method))
+		if method == "" {
+			continue
+		}
+		operationId := strings.Trim(openAPIOperationIdInvalidChars.ReplaceAllString(endPoint.Title, "_"), "_")
+		if operationId == "" || operationIds[operationId] {
+			operationId = strings.Trim(openAPIOperationIdInvalidChars.ReplaceAllString(method+"_"+endPoint.Uri, "_"), "_")
+		}
+		operationIds[operationId] = true
+
+		path := openAPIPathFromURI(endPoint.Uri)
+		pathItem, ok := paths[path].(utils.JSON)
+		if !ok {
+			pathItem = utils.JSON{}
+			paths[path] = pathItem
+		}
+		pathItem[method] = endPoint.OpenAPIOperation(operationId)
+		tagNames[openAPIOperationTag(endPoint.Uri)] = true
+	}
+
+	tagNameList := make([]string, 0, len(tagNames))
+	for k := range tagNames {
+		tagNameList = append(tagNameList, k)
+	}
+	sort.Strings(tagNameList)
+	tags := make([]utils.JSON, 0, len(tagNameList))
+	for _, k := range tagNameList {
+		tags = append(tags, utils.JSON{"name": k})
+	}
+
+	document = utils.JSON{
+		"openapi": OpenAPIVersion,
+		"info": utils.JSON{
+			"title":   a.NameId,
+			"version": a.Version,
+		},
+		"paths": paths,
+		"tags":  tags,
+	}
+	return document
+}
+
+func (a *DXAPI) PrintOpenAPISpecAsJSON() (s string, err error) {
+	b, err := json.MarshalIndent(a.OpenAPIDocument(), "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "error occured")
+	}
+	return string(b), nil
+}
+
+func (a *DXAPI) PrintOpenAPISpecAsYAML() (s string, err error) {
+	// Round-trip through JSON so the YAML encoder only sees plain maps, slices and scalars.
+	b, err := json.Marshal(a.OpenAPIDocument())
+	if err != nil {
+		return "", errors.Wrap(err, "error occured")
+	}
+	var v any
+	err = json.Unmarshal(b, &v)
+	if err != nil {
+		return "", errors.Wrap(err, "error occured")
+	}
+	b, err = yaml.Marshal(v)
+	if err != nil {
+		return "", errors.Wrap(err, "error occured")
+	}
+	return string(b), nil
+}
+
+// APIHandlerPrintSpec answers the spec of the API named by the "api" parameter in the "format" parameter, json when it is omitted.
+// Without "api" it answers the first API in nameid order other than the one serving this endpoint, which is the application API of a
+// service that serves it from its own operations API, the only one when there is no other API
+func (am *DXAPIManager) APIHandlerPrintSpec(aepr *DXAPIEndPointRequest) (err error) {
+	_, apiNameId, err := aepr.GetParameterValueAsString("api")
+	if err != nil {
+		return err
+	}
+	_, format, err := aepr.GetParameterValueAsString("format")
+	if err != nil {
+		return err
+	}
+
+	var a *DXAPI
+	if apiNameId != "" {
+		a = am.APIs[apiNameId]
+		if a == nil {
+			return aepr.WriteResponseAndNewErrorf(http.StatusNotFound, "", "API_NOT_FOUND:%s", apiNameId)
+		}
+	} else {
+		a = aepr.EndPoint.Owner
+		nameIds := make([]string, 0, len(am.APIs))
+		for k := range am.APIs {
+			nameIds = append(nameIds, k)
+		}
+		sort.Strings(nameIds)
+		for _, k := range nameIds {
+			if am.APIs[k] != aepr.EndPoint.Owner {
+				a = am.APIs[k]
+				break
+			}
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case "", "json", "openapi", "openapi-json":
+		s, err := a.PrintOpenAPISpecAsJSON()
+		if err != nil {
+			return err
+		}
+		aepr.WriteResponseAsString(http.StatusOK, map[string]string{"Content-Type": "application/json"}, s)
+	case "yaml", "openapi-yaml":
+		s, err := a.PrintOpenAPISpecAsYAML()
+		if err != nil {
+			return err
+		}
+		aepr.WriteResponseAsString(http.StatusOK, map[string]string{"Content-Type": "application/yaml"}, s)
+	case "markdown":
+		s := a.PrintSpecAsMarkDown()
+		aepr.WriteResponseAsString(http.StatusOK, map[string]string{"Content-Type": "text/markdown"}, s)
+	default:
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "SPEC_FORMAT_NOT_SUPPORTED:%s", format)
+	}
+	return nil
+}
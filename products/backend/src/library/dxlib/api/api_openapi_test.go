@@ -0,0 +1,199 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	utilsHttp "github.com/donnyhardyanto/dxlib/utils/http"
+)
+
+var isUpdateGolden = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+// openAPI31SchemaId is the $id of testdata/openapi-3.1-schema.json, the schema of OpenAPI 3.1 documents published at that URL
+const openAPI31SchemaId = "https://spec.openapis.org/oas/3.1/schema/2022-10-07"
+
+func testOpenAPIFixture() *DXAPI {
+	a := &DXAPI{NameId: "fixture", Version: "1.2.3", ErrorResponseFormat: ErrorResponseFormatProblemJSON, ErrorTypeBaseURI: "https://errors.example.com/"}
+	one, ten := 1, 10
+	zero, hundred := float64(0), float64(100)
+	a.NewEndPoint("Item Read", "Read an item", "/v1/item/{id}", "GET", EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON,
+		[]DXAPIEndPointParameter{
+			{NameId: "id", Type: "int64", Description: "Item id", IsMustExist: true},
+			{NameId: "fields", Type: "array-string", Description: "Fields to answer"},
+		}, nil, nil,
+		map[string]*DXAPIEndPointResponsePossibility{
+			"success": {StatusCode: http.StatusOK, Description: "The item", DataTemplate: []*DXAPIEndPointParameter{
+				{NameId: "id", Type: "int64", IsMustExist: true},
+				{NameId: "name", Type: "string", IsMustExist: true},
+			}},
+			"not_found": {StatusCode: http.StatusNotFound, Description: "No such item"},
+		}, nil, []string{"ITEM.READ"}, 0, "")
+	a.NewEndPoint("Item Create", "Create an item", "/v1/item/create", "POST", EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON,
+		[]DXAPIEndPointParameter{
+			{NameId: "name", Type: "non-empty-string", Description: "Item name", IsMustExist: true, Constraint: DXAPIEndPointParameterConstraint{MaxLength: &ten, Pattern: "^[a-z ]+$"}},
+			{NameId: "price", Type: "decimal", Description: "Price", IsMustExist: true},
+			{NameId: "qty", Type: "int64", Description: "Quantity", Constraint: DXAPIEndPointParameterConstraint{Min: &zero, Max: &hundred}},
+			{NameId: "kind", Type: "string", Constraint: DXAPIEndPointParameterConstraint{Enum: []string{"a", "b"}}},
+			{NameId: "tags", Type: "array-uuid", Constraint: DXAPIEndPointParameterConstraint{MinItems: &one, MaxItems: &ten}},
+			{NameId: "period", Type: "datetime-range"},
+			{NameId: "attribute", Type: "json", Children: []DXAPIEndPointParameter{
+				{NameId: "color", Type: "string", IsMustExist: true},
+				{NameId: "weight", Type: "nullable-float64", IsNullable: true},
+			}},
+		}, nil, nil, nil, nil, nil, 1024, "write")
+	a.NewEndPoint("Item Image Upload", "Upload the image of an item", "/v1/item/image/upload", "POST", EndPointTypeHTTPJSON, utilsHttp.ContentTypeMultiPartFormData,
+		[]DXAPIEndPointParameter{
+			{NameId: "id", Type: "int64", IsMustExist: true},
+			{NameId: "image", Type: "file", IsMustExist: true, FileContentTypes: []string{"image/png"}},
+		}, nil, nil, nil, nil, nil, 0, "")
+	return a
+}
+
+func TestOpenAPIDocumentGolden(t *testing.T) {
+	s, err := testOpenAPIFixture().PrintOpenAPISpecAsJSON()
+	if err != nil {
+		t.Fatalf("PrintOpenAPISpecAsJSON() err = %v", err)
+	}
+
+	goldenPath := filepath.Join("testdata", "openapi.golden.json")
+	if *isUpdateGolden {
+		err = os.WriteFile(goldenPath, []byte(s+"\n"), 0644)
+		if err != nil {
+			t.Fatalf("WriteFile() err = %v", err)
+		}
+	}
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("ReadFile() err = %v, run go test -run TestOpenAPIDocumentGolden -update to create it", err)
+	}
+	if string(golden) != s+"\n" {
+		t.Errorf("document differs from %s, run go test -run TestOpenAPIDocumentGolden -update and review the diff", goldenPath)
+	}
+
+	schemaFile, err := os.ReadFile(filepath.Join("testdata", "openapi-3.1-schema.json"))
+	if err != nil {
+		t.Fatalf("ReadFile() err = %v", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	err = compiler.AddResource(openAPI31SchemaId, bytes.NewReader(schemaFile))
+	if err != nil {
+		t.Fatalf("AddResource() err = %v", err)
+	}
+	schema, err := compiler.Compile(openAPI31SchemaId)
+	if err != nil {
+		t.Fatalf("Compile() err = %v", err)
+	}
+	var document any
+	err = json.Unmarshal([]byte(s), &document)
+	if err != nil {
+		t.Fatalf("Unmarshal() err = %v", err)
+	}
+	err = schema.Validate(document)
+	if err != nil {
+		t.Errorf("document is not a valid OpenAPI 3.1 document: %#v", err)
+	}
+	// the OpenAPI schema leaves Schema Objects to the JSON Schema dialect, check each one against the 2020-12 meta schema
+	metaSchema, err := jsonschema.Compile("https://json-schema.org/draft/2020-12/schema")
+	if err != nil {
+		t.Fatalf("Compile() err = %v", err)
+	}
+	for path, v := range testOpenAPISchemaObjects(document, "") {
+		err = metaSchema.Validate(v)
+		if err != nil {
+			t.Errorf("%s is not a valid JSON Schema: %#v", path, err)
+		}
+	}
+
+	invalidDocument := map[string]any{"openapi": "3.0.3", "info": map[string]any{"title": "fixture"}, "paths": map[string]any{}}
+	if schema.Validate(invalidDocument) == nil {
+		t.Errorf("an OpenAPI 3.0 document without info.version passed the OpenAPI 3.1 schema")
+	}
+}
+
+// testOpenAPISchemaObjects returns the Schema Objects of a document by their path, they are the values of the "schema" members
+func testOpenAPISchemaObjects(v any, path string) (schemas map[string]any) {
+	schemas = map[string]any{}
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if k == "schema" {
+				schemas[path+"/schema"] = child
+				continue
+			}
+			for p, s := range testOpenAPISchemaObjects(child, path+"/"+k) {
+				schemas[p] = s
+			}
+		}
+	case []any:
+		for i, child := range t {
+			for p, s := range testOpenAPISchemaObjects(child, path+"/"+strconv.Itoa(i)) {
+				schemas[p] = s
+			}
+		}
+	}
+	return schemas
+}
+
+func TestAPIHandlerPrintSpec(t *testing.T) {
+	fixture := testOpenAPIFixture()
+	operation := &DXAPI{NameId: "operation"}
+	endPoint := operation.NewEndPoint("PrintSpec", "", "/spec", "GET", EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON,
+		[]DXAPIEndPointParameter{{NameId: "api", Type: "string"}, {NameId: "format", Type: "string"}}, nil, nil, nil, nil, nil, 0, "")
+	am := &DXAPIManager{APIs: map[string]*DXAPI{"fixture": fixture, "operation": operation}}
+
+	specFormat := SpecFormat
+	defer func() {
+		SpecFormat = specFormat
+	}()
+	// markdown must not depend on the process wide SpecFormat
+	SpecFormat = "OpenAPI"
+
+	tests := []struct {
+		name            string
+		api             any
+		format          any
+		wantStatus      int
+		wantContentType string
+		wantPrefix      string
+	}{
+		{name: "json by default", wantStatus: http.StatusOK, wantContentType: "application/json", wantPrefix: "{"},
+		{name: "yaml", format: "yaml", wantStatus: http.StatusOK, wantContentType: "application/yaml", wantPrefix: "info:"},
+		{name: "markdown", format: "markdown", wantStatus: http.StatusOK, wantContentType: "text/markdown", wantPrefix: "# API: fixture"},
+		{name: "markdown of the named api", api: "operation", format: "markdown", wantStatus: http.StatusOK, wantContentType: "text/markdown", wantPrefix: "# API: operation"},
+		{name: "unknown api", api: "other", wantStatus: http.StatusNotFound},
+		{name: "unknown format", format: "html", wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aepr, recorder := testErrorRequest(ErrorResponseFormatProblemJSON, false)
+			aepr.EndPoint = endPoint
+			aepr.ParameterValues = map[string]*DXAPIEndPointRequestParameterValue{
+				"api":    {Owner: aepr, Metadata: endPoint.Parameters[0], Value: tt.api},
+				"format": {Owner: aepr, Metadata: endPoint.Parameters[1], Value: tt.format},
+			}
+			_ = am.APIHandlerPrintSpec(aepr)
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != tt.wantContentType {
+				t.Errorf("Content-Type = %s, want %s", contentType, tt.wantContentType)
+			}
+			if !strings.HasPrefix(recorder.Body.String(), tt.wantPrefix) {
+				t.Errorf("body = %.80s, want it to start with %s", recorder.Body.String(), tt.wantPrefix)
+			}
+		})
+	}
+}
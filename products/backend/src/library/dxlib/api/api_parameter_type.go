@@ -0,0 +1,278 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// DXAPIParameterType is an application defined parameter type.
+// Parse receives the JSON decoded raw value, or the string as received for path, query and form parameters, and returns the value handlers read from ParameterValues.
+// Validate is optional and checks the parsed value, OpenAPISchema is the schema published for parameters of this type
+type DXAPIParameterType struct {
+	NameId        string
+	Description   string
+	Parse         func(rawValue any) (value any, err error)
+	Validate      func(value any) (err error)
+	OpenAPISchema utils.JSON
+}
+
+// DXAPIEndPointParameterConstraint is checked after the parameter type is validated, nil and empty fields are not checked.
+// Min and Max apply to numbers, MinLength and MaxLength count the runes of a string, Pattern and Enum match the value as text, all of them are checked on each item of an array.
+// MinItems and MaxItems of a "base64-bytes" parameter bound the decoded size
+type DXAPIEndPointParameterConstraint struct {
+	Min       *float64
+	Max       *float64
+	MinLength *int
+	MaxLength *int
+	Pattern   string
+	Enum      []string
+	MinItems  *int
+	MaxItems  *int
+}
+
+// Validate checks the constraint can be applied before an endpoint serves with it, e.g. that Pattern compiles and Min is not above Max
+func (c *DXAPIEndPointParameterConstraint) Validate() (err error) {
+	if (c.Min != nil) && (c.Max != nil) && (*c.Min > *c.Max) {
+		return errors.Errorf("PARAMETER_CONSTRAINT_MIN_ABOVE_MAX:%v>%v", *c.Min, *c.Max)
+	}
+	for _, v := range []*int{c.MinLength, c.MaxLength, c.MinItems, c.MaxItems} {
+		if (v != nil) && (*v < 0) {
+			return errors.Errorf("PARAMETER_CONSTRAINT_IS_NEGATIVE:%d", *v)
+		}
+	}
+	if (c.MinLength != nil) && (c.MaxLength != nil) && (*c.MinLength > *c.MaxLength) {
+		return errors.Errorf("PARAMETER_CONSTRAINT_MIN_LENGTH_ABOVE_MAX_LENGTH:%d>%d", *c.MinLength, *c.MaxLength)
+	}
+	if (c.MinItems != nil) && (c.MaxItems != nil) && (*c.MinItems > *c.MaxItems) {
+		return errors.Errorf("PARAMETER_CONSTRAINT_MIN_ITEMS_ABOVE_MAX_ITEMS:%d>%d", *c.MinItems, *c.MaxItems)
+	}
+	if c.Pattern != "" {
+		_, err = constraintPattern(c.Pattern)
+		if err != nil {
+			return errors.Wrapf(err, "PARAMETER_CONSTRAINT_PATTERN_INVALID:%s", c.Pattern)
+		}
+	}
+	return nil
+}
+
+// validateParameterConstraints validates the constraint of every parameter and its children, the error names the parameter path
+func validateParameterConstraints(parameters []DXAPIEndPointParameter, parentPath string) (err error) {
+	for _, p := range parameters {
+		nameIdPath := p.NameId
+		if parentPath != "" {
+			nameIdPath = parentPath + "." + p.NameId
+		}
+		err = p.Constraint.Validate()
+		if err != nil {
+			return errors.Wrap(err, nameIdPath)
+		}
+		err = validateParameterConstraints(p.Children, nameIdPath)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	ErrorParameterValueInvalid       = RegisterError("PARAMETER_VALUE_INVALID", http.StatusUnprocessableEntity, "Request parameter value is invalid", true)
+	ErrorParameterConstraintViolated = RegisterError("PARAMETER_CONSTRAINT_VIOLATED", http.StatusUnprocessableEntity, "Request parameter violates a constraint", true)
+)
+
+var builtinParameterTypes = map[string]bool{
+	"nullable-int64": true, "int64": true, "int64p": true, "int64zp": true,
+	"float32": true, "float32p": true, "float32zp": true, "float64": true, "float64p": true, "float64zp": true,
+	"bool": true, "string": true, "nullable-string": true, "non-empty-string": true, "protected-string": true, "protected-sql-string": true,
+	"email": true, "phonenumber": true, "npwp": true, "iso8601": true, "date": true, "time": true,
+	"json": true, "json-passthrough": true, "array": true, "array-json-template": true, "array-string": true, "array-int64": true, "file": true,
+	"uuid": true, "nullable-bool": true, "nullable-float64": true, "decimal": true, "array-float64": true, "array-uuid": true,
+	"datetime-range": true, "base64-bytes": true,
+}
+
+var (
+	parameterTypesMutex sync.RWMutex
+	parameterTypes      = map[string]*DXAPIParameterType{}
+	patternsCache       sync.Map
+)
+
+func RegisterParameterType(t DXAPIParameterType) (err error) {
+	if t.NameId == "" {
+		return errors.New("PARAMETER_TYPE_NAMEID_IS_EMPTY")
+	}
+	if t.Parse == nil {
+		return errors.Errorf("PARAMETER_TYPE_PARSE_IS_NIL:%s", t.NameId)
+	}
+	if builtinParameterTypes[t.NameId] {
+		return errors.Errorf("PARAMETER_TYPE_IS_BUILTIN:%s", t.NameId)
+	}
+	parameterTypesMutex.Lock()
+	defer parameterTypesMutex.Unlock()
+	parameterTypes[t.NameId] = &t
+	return nil
+}
+
+func FindParameterType(nameId string) (t *DXAPIParameterType, ok bool) {
+	parameterTypesMutex.RLock()
+	defer parameterTypesMutex.RUnlock()
+	t, ok = parameterTypes[nameId]
+	return t, ok
+}
+
+func (aeprpv *DXAPIEndPointRequestParameterValue) validateRegisteredType(t *DXAPIParameterType) (err error) {
+	nameIdPath := aeprpv.GetNameIdPath()
+	v, err := t.Parse(aeprpv.RawValue)
+	if err != nil {
+		return &DXAPIError{Definition: ErrorParameterValueInvalid, Parameter: nameIdPath, Detail: nameIdPath + ":" + t.NameId, Err: err}
+	}
+	if t.Validate != nil {
+		err = t.Validate(v)
+		if err != nil {
+			return &DXAPIError{Definition: ErrorParameterValueInvalid, Parameter: nameIdPath, Detail: nameIdPath + ":" + t.NameId, Err: err}
+		}
+	}
+	aeprpv.Value = v
+	return nil
+}
+
+func constraintPattern(pattern string) (r *regexp.Regexp, err error) {
+	if v, ok := patternsCache.Load(pattern); ok {
+		return v.(*regexp.Regexp), nil
+	}
+	r, err = regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrap(err, "error occured")
+	}
+	patternsCache.Store(pattern, r)
+	return r, nil
+}
+
+// constraintNumber returns v as a decimal, so min and max compare every digit of an int64 or a decimal instead of its float64 approximation
+func constraintNumber(v any) (d decimal.Decimal, ok bool) {
+	if d, isDecimal := v.(decimal.Decimal); isDecimal {
+		return d, true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return decimal.NewFromInt(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return decimal.NewFromUint64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return decimal.NewFromFloat(rv.Float()), true
+	}
+	return d, false
+}
+
+func constraintError(nameIdPath string, constraint string, limit any) error {
+	return &DXAPIError{Definition: ErrorParameterConstraintViolated, Parameter: nameIdPath, Detail: fmt.Sprintf("%s:%s=%v", nameIdPath, constraint, limit)}
+}
+
+// validateScalarConstraint checks v, which is the value at nameIdPath, e.g. "tags[1]" for an item of an array
+func (aeprpv *DXAPIEndPointRequestParameterValue) validateScalarConstraint(v any, nameIdPath string) (err error) {
+	c := &aeprpv.Metadata.Constraint
+	if d, ok := constraintNumber(v); ok {
+		if (c.Min != nil) && (d.Cmp(decimal.NewFromFloat(*c.Min)) < 0) {
+			return constraintError(nameIdPath, "min", *c.Min)
+		}
+		if (c.Max != nil) && (d.Cmp(decimal.NewFromFloat(*c.Max)) > 0) {
+			return constraintError(nameIdPath, "max", *c.Max)
+		}
+	}
+	if s, ok := v.(string); ok {
+		if (c.MinLength != nil) && (utf8.RuneCountInString(s) < *c.MinLength) {
+			return constraintError(nameIdPath, "min-length", *c.MinLength)
+		}
+		if (c.MaxLength != nil) && (utf8.RuneCountInString(s) > *c.MaxLength) {
+			return constraintError(nameIdPath, "max-length", *c.MaxLength)
+		}
+	}
+	if c.Pattern != "" {
+		r, err := constraintPattern(c.Pattern)
+		if err != nil {
+			return aeprpv.Owner.Log.ErrorAndCreateErrorf("PARAMETER_CONSTRAINT_PATTERN_INVALID:%s=%s", nameIdPath, c.Pattern)
+		}
+		if !r.MatchString(fmt.Sprint(v)) {
+			return constraintError(nameIdPath, "pattern", c.Pattern)
+		}
+	}
+	if (len(c.Enum) > 0) && !utils.ArrayOfStringIsContains(c.Enum, fmt.Sprint(v)) {
+		return constraintError(nameIdPath, "enum", strings.Join(c.Enum, "|"))
+	}
+	return nil
+}
+
+// validateConstraint checks Metadata.Constraint against the validated value, arrays are checked for their length and then item by item
+func (aeprpv *DXAPIEndPointRequestParameterValue) validateConstraint() (err error) {
+	if aeprpv.Value == nil {
+		return nil
+	}
+	c := &aeprpv.Metadata.Constraint
+	rv := reflect.ValueOf(aeprpv.Value)
+	if rv.Kind() == reflect.Map {
+		return nil
+	}
+	if rv.Kind() != reflect.Slice {
+		return aeprpv.validateScalarConstraint(aeprpv.Value, aeprpv.GetNameIdPath())
+	}
+	if (c.MinItems != nil) && (rv.Len() < *c.MinItems) {
+		return constraintError(aeprpv.GetNameIdPath(), "min-items", *c.MinItems)
+	}
+	if (c.MaxItems != nil) && (rv.Len() > *c.MaxItems) {
+		return constraintError(aeprpv.GetNameIdPath(), "max-items", *c.MaxItems)
+	}
+	if _, isBytes := aeprpv.Value.([]byte); isBytes {
+		return nil
+	}
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i).Interface()
+		if _, isJSON := item.(utils.JSON); isJSON {
+			continue
+		}
+		err = aeprpv.validateScalarConstraint(item, fmt.Sprintf("%s[%d]", aeprpv.GetNameIdPath(), i))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OpenAPISchema adds the constraint keywords to a parameter schema
+func (c *DXAPIEndPointParameterConstraint) OpenAPISchema(schema utils.JSON) {
+	if c.MinItems != nil {
+		schema["minItems"] = *c.MinItems
+	}
+	if c.MaxItems != nil {
+		schema["maxItems"] = *c.MaxItems
+	}
+	target := schema
+	if items, ok := schema["items"].(utils.JSON); ok {
+		target = items
+	}
+	if c.Min != nil {
+		target["minimum"] = *c.Min
+	}
+	if c.Max != nil {
+		target["maximum"] = *c.Max
+	}
+	if c.MinLength != nil {
+		target["minLength"] = *c.MinLength
+	}
+	if c.MaxLength != nil {
+		target["maxLength"] = *c.MaxLength
+	}
+	if c.Pattern != "" {
+		target["pattern"] = c.Pattern
+	}
+	if len(c.Enum) > 0 {
+		target["enum"] = c.Enum
+	}
+}
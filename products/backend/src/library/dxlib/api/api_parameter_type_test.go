@@ -0,0 +1,239 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+func TestRegisterParameterType(t *testing.T) {
+	parse := func(rawValue any) (value any, err error) {
+		s, ok := rawValue.(string)
+		if !ok {
+			return nil, errors.New("NOT_A_STRING")
+		}
+		return strings.ToUpper(s), nil
+	}
+	tests := []struct {
+		name     string
+		t        DXAPIParameterType
+		wantErr  bool
+		wantFind bool
+	}{
+		{name: "registered", t: DXAPIParameterType{NameId: "test-upper", Parse: parse}, wantFind: true},
+		{name: "empty nameid", t: DXAPIParameterType{Parse: parse}, wantErr: true},
+		{name: "without parse", t: DXAPIParameterType{NameId: "test-without-parse"}, wantErr: true},
+		{name: "builtin name", t: DXAPIParameterType{NameId: "uuid", Parse: parse}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterParameterType(tt.t)
+			defer func() {
+				parameterTypesMutex.Lock()
+				delete(parameterTypes, tt.t.NameId)
+				parameterTypesMutex.Unlock()
+			}()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RegisterParameterType() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, ok := FindParameterType(tt.t.NameId); ok != tt.wantFind {
+				t.Errorf("FindParameterType(%s) ok = %v, want %v", tt.t.NameId, ok, tt.wantFind)
+			}
+		})
+	}
+}
+
+func TestValidateRegisteredType(t *testing.T) {
+	err := RegisterParameterType(DXAPIParameterType{
+		NameId: "test-even",
+		Parse: func(rawValue any) (value any, err error) {
+			f, ok := rawValue.(float64)
+			if !ok {
+				return nil, errors.New("NOT_A_NUMBER")
+			}
+			return int64(f), nil
+		},
+		Validate: func(value any) (err error) {
+			if value.(int64)%2 != 0 {
+				return errors.New("NOT_EVEN")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterParameterType() err = %v", err)
+	}
+	defer func() {
+		parameterTypesMutex.Lock()
+		delete(parameterTypes, "test-even")
+		parameterTypesMutex.Unlock()
+	}()
+	tests := []struct {
+		name       string
+		rawValue   any
+		wantValue  any
+		wantErrMsg string
+	}{
+		{name: "parsed and valid", rawValue: float64(4), wantValue: int64(4)},
+		{name: "parse fails", rawValue: "4", wantErrMsg: "PARAMETER_VALUE_INVALID:p:test-even:NOT_A_NUMBER"},
+		{name: "validate fails", rawValue: float64(3), wantErrMsg: "PARAMETER_VALUE_INVALID:p:test-even:NOT_EVEN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aepr, _ := testErrorRequest(ErrorResponseFormatProblemJSON, false)
+			aeprpv := &DXAPIEndPointRequestParameterValue{Owner: aepr, Metadata: DXAPIEndPointParameter{NameId: "p", Type: "test-even"}, RawValue: tt.rawValue}
+			err := aeprpv.Validate()
+			if tt.wantErrMsg != "" {
+				var apiError *DXAPIError
+				if !errors.As(err, &apiError) || (apiError.Error() != tt.wantErrMsg) || (apiError.Parameter != "p") {
+					t.Errorf("Validate() err = %v, want %s on p", err, tt.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() err = %v", err)
+			}
+			if aeprpv.Value != tt.wantValue {
+				t.Errorf("Value = %v, want %v", aeprpv.Value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestParameterConstraintValidate(t *testing.T) {
+	one, two, minus := 1, 2, -1
+	f1, f2 := float64(1), float64(2)
+	tests := []struct {
+		name       string
+		parameters []DXAPIEndPointParameter
+		wantErrMsg string
+	}{
+		{name: "no constraint", parameters: []DXAPIEndPointParameter{{NameId: "a", Type: "string"}}},
+		{
+			name: "valid constraint",
+			parameters: []DXAPIEndPointParameter{{NameId: "a", Type: "string", Constraint: DXAPIEndPointParameterConstraint{
+				Min: &f1, Max: &f2, MinLength: &one, MaxLength: &two, MinItems: &one, MaxItems: &two, Pattern: "^[a-z]+$",
+			}}},
+		},
+		{name: "invalid pattern", parameters: []DXAPIEndPointParameter{{NameId: "a", Constraint: DXAPIEndPointParameterConstraint{Pattern: "[a-z"}}}, wantErrMsg: "a: PARAMETER_CONSTRAINT_PATTERN_INVALID:[a-z"},
+		{name: "min above max", parameters: []DXAPIEndPointParameter{{NameId: "a", Constraint: DXAPIEndPointParameterConstraint{Min: &f2, Max: &f1}}}, wantErrMsg: "a: PARAMETER_CONSTRAINT_MIN_ABOVE_MAX:2>1"},
+		{name: "min length above max length", parameters: []DXAPIEndPointParameter{{NameId: "a", Constraint: DXAPIEndPointParameterConstraint{MinLength: &two, MaxLength: &one}}}, wantErrMsg: "a: PARAMETER_CONSTRAINT_MIN_LENGTH_ABOVE_MAX_LENGTH:2>1"},
+		{name: "min items above max items", parameters: []DXAPIEndPointParameter{{NameId: "a", Constraint: DXAPIEndPointParameterConstraint{MinItems: &two, MaxItems: &one}}}, wantErrMsg: "a: PARAMETER_CONSTRAINT_MIN_ITEMS_ABOVE_MAX_ITEMS:2>1"},
+		{name: "negative length", parameters: []DXAPIEndPointParameter{{NameId: "a", Constraint: DXAPIEndPointParameterConstraint{MaxLength: &minus}}}, wantErrMsg: "a: PARAMETER_CONSTRAINT_IS_NEGATIVE:-1"},
+		{
+			name: "invalid constraint of a child names its path",
+			parameters: []DXAPIEndPointParameter{{NameId: "a", Type: "json", Children: []DXAPIEndPointParameter{
+				{NameId: "b", Type: "json", Children: []DXAPIEndPointParameter{{NameId: "c", Constraint: DXAPIEndPointParameterConstraint{Pattern: "("}}}},
+			}}},
+			wantErrMsg: "a.b.c: PARAMETER_CONSTRAINT_PATTERN_INVALID:(",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateParameterConstraints(tt.parameters, "")
+			if tt.wantErrMsg == "" {
+				if err != nil {
+					t.Errorf("validateParameterConstraints() err = %v", err)
+				}
+				return
+			}
+			if (err == nil) || !strings.HasPrefix(err.Error(), tt.wantErrMsg) {
+				t.Errorf("validateParameterConstraints() err = %v, want %s", err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestValidateConstraint(t *testing.T) {
+	one, two, three := 1, 2, 3
+	f0, f10 := float64(0), float64(10)
+	tests := []struct {
+		name           string
+		constraint     DXAPIEndPointParameterConstraint
+		value          any
+		wantConstraint string
+		wantParameter  string
+	}{
+		{name: "number inside", constraint: DXAPIEndPointParameterConstraint{Min: &f0, Max: &f10}, value: int64(10)},
+		{name: "number below min", constraint: DXAPIEndPointParameterConstraint{Min: &f0}, value: int64(-1), wantConstraint: "min", wantParameter: "p"},
+		{name: "number above max", constraint: DXAPIEndPointParameterConstraint{Max: &f10}, value: 10.5, wantConstraint: "max", wantParameter: "p"},
+		{name: "length counts runes", constraint: DXAPIEndPointParameterConstraint{MaxLength: &two}, value: "éé"},
+		{name: "too short", constraint: DXAPIEndPointParameterConstraint{MinLength: &two}, value: "a", wantConstraint: "min-length", wantParameter: "p"},
+		{name: "too long", constraint: DXAPIEndPointParameterConstraint{MaxLength: &two}, value: "abc", wantConstraint: "max-length", wantParameter: "p"},
+		{name: "pattern matches", constraint: DXAPIEndPointParameterConstraint{Pattern: "^[A-Z]{2}$"}, value: "ID"},
+		{name: "pattern does not match", constraint: DXAPIEndPointParameterConstraint{Pattern: "^[A-Z]{2}$"}, value: "IDN", wantConstraint: "pattern", wantParameter: "p"},
+		{name: "enum contains", constraint: DXAPIEndPointParameterConstraint{Enum: []string{"a", "b"}}, value: "b"},
+		{name: "enum does not contain", constraint: DXAPIEndPointParameterConstraint{Enum: []string{"a", "b"}}, value: "c", wantConstraint: "enum", wantParameter: "p"},
+		{name: "items inside", constraint: DXAPIEndPointParameterConstraint{MinItems: &one, MaxItems: &two}, value: []string{"a", "b"}},
+		{name: "too few items", constraint: DXAPIEndPointParameterConstraint{MinItems: &one}, value: []string{}, wantConstraint: "min-items", wantParameter: "p"},
+		{name: "too many items", constraint: DXAPIEndPointParameterConstraint{MaxItems: &two}, value: []int64{1, 2, 3}, wantConstraint: "max-items", wantParameter: "p"},
+		{name: "bytes are bounded by their size", constraint: DXAPIEndPointParameterConstraint{MaxItems: &two, MaxLength: &one}, value: []byte("abc"), wantConstraint: "max-items", wantParameter: "p"},
+		{name: "item of an array names its index", constraint: DXAPIEndPointParameterConstraint{Max: &f10}, value: []int64{1, 11, 3}, wantConstraint: "max", wantParameter: "p[1]"},
+		{name: "item length of an array", constraint: DXAPIEndPointParameterConstraint{MaxLength: &three}, value: []string{"abc", "abcd"}, wantConstraint: "max-length", wantParameter: "p[1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aepr, _ := testErrorRequest(ErrorResponseFormatProblemJSON, false)
+			aeprpv := &DXAPIEndPointRequestParameterValue{Owner: aepr, Metadata: DXAPIEndPointParameter{NameId: "p", Constraint: tt.constraint}, Value: tt.value}
+			err := aeprpv.validateConstraint()
+			if tt.wantConstraint == "" {
+				if err != nil {
+					t.Errorf("validateConstraint() err = %v", err)
+				}
+				return
+			}
+			var apiError *DXAPIError
+			if !errors.As(err, &apiError) {
+				t.Fatalf("validateConstraint() err = %v, want %s", err, ErrorParameterConstraintViolated.Code)
+			}
+			if apiError.Definition != ErrorParameterConstraintViolated {
+				t.Errorf("code = %s, want %s", apiError.Definition.Code, ErrorParameterConstraintViolated.Code)
+			}
+			if apiError.Parameter != tt.wantParameter {
+				t.Errorf("Parameter = %s, want %s", apiError.Parameter, tt.wantParameter)
+			}
+			if !strings.HasPrefix(apiError.Detail, tt.wantParameter+":"+tt.wantConstraint+"=") {
+				t.Errorf("Detail = %s, want %s:%s=...", apiError.Detail, tt.wantParameter, tt.wantConstraint)
+			}
+		})
+	}
+}
+
+func TestConstraintNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       any
+		wantD   decimal.Decimal
+		wantOk  bool
+		min     float64
+		max     float64
+		wantErr bool
+	}{
+		{name: "decimal inside", v: decimal.RequireFromString("0.1"), wantD: decimal.RequireFromString("0.1"), wantOk: true, min: 0, max: 0.1},
+		{name: "decimal just above max", v: decimal.RequireFromString("0.1000000000000000001"), wantD: decimal.RequireFromString("0.1000000000000000001"), wantOk: true, min: 0, max: 0.1, wantErr: true},
+		{name: "int64 just above max", v: int64(9007199254740993), wantD: decimal.NewFromInt(9007199254740993), wantOk: true, min: 0, max: 9007199254740992, wantErr: true},
+		{name: "int64 at max", v: int64(9007199254740992), wantD: decimal.NewFromInt(9007199254740992), wantOk: true, min: 0, max: 9007199254740992},
+		{name: "uint64 above max", v: uint64(18446744073709551615), wantD: decimal.NewFromUint64(18446744073709551615), wantOk: true, min: 0, max: 1e19, wantErr: true},
+		{name: "float64 below min", v: -0.5, wantD: decimal.NewFromFloat(-0.5), wantOk: true, min: 0, max: 1, wantErr: true},
+		{name: "string is not a number", v: "1", min: 0, max: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := constraintNumber(tt.v)
+			if ok != tt.wantOk {
+				t.Fatalf("constraintNumber(%v) ok = %v, want %v", tt.v, ok, tt.wantOk)
+			}
+			if ok && !d.Equal(tt.wantD) {
+				t.Errorf("constraintNumber(%v) = %s, want %s", tt.v, d, tt.wantD)
+			}
+			aepr, _ := testErrorRequest(ErrorResponseFormatProblemJSON, false)
+			aeprpv := &DXAPIEndPointRequestParameterValue{Owner: aepr, Metadata: DXAPIEndPointParameter{NameId: "p", Constraint: DXAPIEndPointParameterConstraint{Min: &tt.min, Max: &tt.max}}}
+			err := aeprpv.validateScalarConstraint(tt.v, "p")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateScalarConstraint(%v) err = %v, wantErr %v", tt.v, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/pkg/errors"
+)
+
+// DXAPIPanicError is a recovered handler panic, %+v prints the stack of the panicking goroutine
+type DXAPIPanicError struct {
+	Value any
+	Stack []byte
+}
+
+func newPanicError(value any) *DXAPIPanicError {
+	return &DXAPIPanicError{Value: value, Stack: debug.Stack()}
+}
+
+func (e *DXAPIPanicError) Error() string {
+	return fmt.Sprintf("PANIC:%v", e.Value)
+}
+
+func (e *DXAPIPanicError) Format(s fmt.State, verb rune) {
+	_, _ = io.WriteString(s, e.Error())
+	if (verb == 'v') && s.Flag('+') {
+		_, _ = io.WriteString(s, "\n"+string(e.Stack))
+	}
+}
+
+// onPanic logs a recovered panic with its stack, which also reaches log.OnError, and answers 500 when nothing was sent yet
+func (aepr *DXAPIEndPointRequest) onPanic(panicError *DXAPIPanicError) {
+	aepr.Log.Errorf(panicError, "PANIC_RECOVERED:%s %s\n%s", aepr.Request.Method, aepr.Request.URL.Path, string(panicError.Stack))
+	if aepr.ResponseHeaderSent {
+		aepr.ResponseStatusCode = http.StatusInternalServerError
+		return
+	}
+	aepr.writeResponseAsPanic(panicError)
+}
+
+// writeResponseAsPanic answers INTERNAL_ERROR with the request id so the client can quote it, the panic value is only shown outside production mode
+func (aepr *DXAPIEndPointRequest) writeResponseAsPanic(panicError *DXAPIPanicError) {
+	a := aepr.EndPoint.Owner
+	if a.ErrorResponseFormat == ErrorResponseFormatProblemJSON {
+		aepr.writeResponseAsProblem(http.StatusInternalServerError, ErrorInternal.Wrap(panicError, ""), "", "")
+		return
+	}
+	reason := ErrorInternal.Code
+	if !a.IsErrorProductionMode {
+		reason = reason + ":" + panicError.Error()
+	}
+	aepr.WriteResponseAsJSON(http.StatusInternalServerError, nil, utils.JSON{
+		"status":         http.StatusText(http.StatusInternalServerError),
+		"status_code":    http.StatusInternalServerError,
+		"reason":         reason,
+		"reason_message": ErrorInternal.Title,
+		"request_id":     aepr.Id,
+	})
+}
+
+// callOnWSLoop turns a panic of OnWSLoop into an error, the connection is already hijacked so it can not reach the HTTP recovery
+func (aepr *DXAPIEndPointRequest) callOnWSLoop() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newPanicError(r)
+		}
+	}()
+	return aepr.EndPoint.OnWSLoop(aepr)
+}
+
+func asPanicError(err error) (panicError *DXAPIPanicError, ok bool) {
+	ok = errors.As(err, &panicError)
+	return panicError, ok
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DXAPIRequestIdHeader        = "X-Request-Id"
+	DXAPITraceParentHeader      = "traceparent"
+	DXAPIRequestIdMaxLength     = 128
+	dxAPITraceParentTraceIdSize = 32
+)
+
+func isValidRequestId(requestId string) bool {
+	if (requestId == "") || (len(requestId) > DXAPIRequestIdMaxLength) {
+		return false
+	}
+	for _, c := range requestId {
+		if (c <= ' ') || (c > '~') {
+			return false
+		}
+	}
+	return true
+}
+
+// traceIdOfTraceParent returns the trace id of a W3C traceparent header "version-traceid-parentid-flags"
+func traceIdOfTraceParent(traceParent string) string {
+	items := strings.Split(strings.TrimSpace(traceParent), "-")
+	if (len(items) < 4) || (len(items[1]) != dxAPITraceParentTraceIdSize) || (strings.Trim(items[1], "0") == "") {
+		return ""
+	}
+	for _, c := range items[1] {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return ""
+		}
+	}
+	return items[1]
+}
+
+// RequestIdOf takes the request id from X-Request-Id, then from the trace id of traceparent, otherwise a new UUIDv7 is generated
+func RequestIdOf(r *http.Request) string {
+	requestId := strings.TrimSpace(r.Header.Get(DXAPIRequestIdHeader))
+	if isValidRequestId(requestId) {
+		return requestId
+	}
+	requestId = traceIdOfTraceParent(r.Header.Get(DXAPITraceParentHeader))
+	if requestId != "" {
+		return requestId
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
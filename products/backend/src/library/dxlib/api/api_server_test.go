@@ -0,0 +1,377 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+
+	utilsHttp "github.com/donnyhardyanto/dxlib/utils/http"
+)
+
+// testAPI returns an API whose audit log end hook passes each entry to the returned channel
+func testAPI(t *testing.T, format DXAPIErrorResponseFormat) (a *DXAPI, auditLogEntries chan *DXAPIAuditLogEntry) {
+	t.Helper()
+	am := &DXAPIManager{Context: context.Background(), APIs: map[string]*DXAPI{}}
+	a, err := am.NewAPI("test")
+	if err != nil {
+		t.Fatalf("NewAPI() err = %v", err)
+	}
+	t.Cleanup(a.Cancel)
+	a.ErrorResponseFormat = format
+	auditLogEntries = make(chan *DXAPIAuditLogEntry, 16)
+	a.OnAuditLogEnd = func(oldAuditLogId int64, parameters *DXAPIAuditLogEntry) (newAuditLogId int64, err error) {
+		auditLogEntries <- parameters
+		return oldAuditLogId, nil
+	}
+	return a, auditLogEntries
+}
+
+// testServe serves the routes of a, call it after the endpoints are added
+func testServe(t *testing.T, a *DXAPI) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(a.NewHTTPHandler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testNewGetEndPoint(a *DXAPI, uri string, onExecute DXAPIEndPointExecuteFunc) *DXAPIEndPoint {
+	return a.NewEndPoint("Test", "", uri, http.MethodGet, EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, nil, onExecute, nil, nil, nil, nil, 0, "")
+}
+
+func testGet(t *testing.T, url string, header map[string]string) (response *http.Response, body map[string]any) {
+	t.Helper()
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() err = %v", err)
+	}
+	for k, v := range header {
+		request.Header.Set(k, v)
+	}
+	response, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Do() err = %v", err)
+	}
+	defer response.Body.Close()
+	b, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() err = %v", err)
+	}
+	body = map[string]any{}
+	if len(b) > 0 {
+		err = json.Unmarshal(b, &body)
+		if err != nil {
+			t.Fatalf("Unmarshal(%s) err = %v", b, err)
+		}
+	}
+	return response, body
+}
+
+func testAuditLogEntry(t *testing.T, auditLogEntries chan *DXAPIAuditLogEntry) *DXAPIAuditLogEntry {
+	t.Helper()
+	select {
+	case entry := <-auditLogEntries:
+		return entry
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for the audit log entry")
+		return nil
+	}
+}
+
+func TestRequestIdPrecedence(t *testing.T) {
+	a, auditLogEntries := testAPI(t, ErrorResponseFormatProblemJSON)
+	var handlerRequestId string
+	testNewGetEndPoint(a, "/v1/id", func(aepr *DXAPIEndPointRequest) error {
+		handlerRequestId = aepr.Id
+		aepr.WriteResponseAsString(http.StatusOK, nil, "")
+		return nil
+	})
+	server := testServe(t, a)
+
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tests := []struct {
+		name          string
+		header        map[string]string
+		wantRequestId string
+		wantGenerated bool
+	}{
+		{name: "X-Request-Id wins over traceparent", header: map[string]string{"X-Request-Id": "client-1", "traceparent": traceParent}, wantRequestId: "client-1"},
+		{name: "X-Request-Id is trimmed", header: map[string]string{"X-Request-Id": "  client-2 "}, wantRequestId: "client-2"},
+		{name: "trace id of traceparent", header: map[string]string{"traceparent": traceParent}, wantRequestId: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{name: "invalid X-Request-Id falls back to traceparent", header: map[string]string{"X-Request-Id": strings.Repeat("x", DXAPIRequestIdMaxLength+1), "traceparent": traceParent}, wantRequestId: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{name: "X-Request-Id with a control character is generated", header: map[string]string{"X-Request-Id": "a\tb"}, wantGenerated: true},
+		{name: "all zero trace id is generated", header: map[string]string{"traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01"}, wantGenerated: true},
+		{name: "upper case trace id is generated", header: map[string]string{"traceparent": "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"}, wantGenerated: true},
+		{name: "nothing is generated", wantGenerated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, _ := testGet(t, server.URL+"/v1/id", tt.header)
+			entry := testAuditLogEntry(t, auditLogEntries)
+			requestId := response.Header.Get(DXAPIRequestIdHeader)
+			if tt.wantGenerated {
+				if (requestId == "") || (requestId == tt.header["X-Request-Id"]) || strings.Contains(tt.header["traceparent"], requestId) {
+					t.Errorf("X-Request-Id = %q, want a generated id", requestId)
+				}
+			} else if requestId != tt.wantRequestId {
+				t.Errorf("X-Request-Id = %q, want %q", requestId, tt.wantRequestId)
+			}
+			if (handlerRequestId != requestId) || (entry.RequestId != requestId) {
+				t.Errorf("request id of the handler = %q and of the audit log = %q, want %q", handlerRequestId, entry.RequestId, requestId)
+			}
+		})
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	tests := []struct {
+		name             string
+		format           DXAPIErrorResponseFormat
+		isProductionMode bool
+		wantCode         string
+		wantValueShown   bool
+	}{
+		{name: "problem", format: ErrorResponseFormatProblemJSON, wantCode: "INTERNAL_ERROR"},
+		{name: "legacy", format: ErrorResponseFormatLegacy, wantValueShown: true},
+		{name: "legacy in production mode", format: ErrorResponseFormatLegacy, isProductionMode: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, auditLogEntries := testAPI(t, tt.format)
+			a.IsErrorProductionMode = tt.isProductionMode
+			testNewGetEndPoint(a, "/v1/panic", func(aepr *DXAPIEndPointRequest) error {
+				panic("boom secret")
+			})
+			server := testServe(t, a)
+
+			response, body := testGet(t, server.URL+"/v1/panic", map[string]string{DXAPIRequestIdHeader: "panic-1"})
+			if response.StatusCode != http.StatusInternalServerError {
+				t.Errorf("status = %d, want 500", response.StatusCode)
+			}
+			if body["request_id"] != "panic-1" {
+				t.Errorf("request_id = %v, want panic-1, body = %v", body["request_id"], body)
+			}
+			if (tt.wantCode != "") && (body["code"] != tt.wantCode) {
+				t.Errorf("code = %v, want %s", body["code"], tt.wantCode)
+			}
+			b, _ := json.Marshal(body)
+			if isValueShown := strings.Contains(string(b), "boom secret"); isValueShown != tt.wantValueShown {
+				t.Errorf("body = %s, panic value shown = %v, want %v", b, isValueShown, tt.wantValueShown)
+			}
+			entry := testAuditLogEntry(t, auditLogEntries)
+			if (entry.StatusCode != http.StatusInternalServerError) || (entry.RequestId != "panic-1") || !strings.Contains(entry.ErrorMessage, "PANIC") {
+				t.Errorf("audit log entry = %+v, want 500, panic-1 and the panic", entry)
+			}
+		})
+	}
+}
+
+func TestWSPanicRecovery(t *testing.T) {
+	a, auditLogEntries := testAPI(t, ErrorResponseFormatProblemJSON)
+	a.NewEndPoint("WS", "", "/v1/ws", http.MethodGet, EndPointTypeWS, utilsHttp.ContentTypeApplicationJSON, nil, nil,
+		func(aepr *DXAPIEndPointRequest) error {
+			_, err := WSReceiveJSON[map[string]any](aepr.WSConnection)
+			if err != nil {
+				return err
+			}
+			panic("boom")
+		}, nil, nil, nil, 0, "")
+	server := testServe(t, a)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/ws"
+	conn, response, err := websocket.DefaultDialer.Dial(url, http.Header{DXAPIRequestIdHeader: []string{"ws-1"}})
+	if err != nil {
+		t.Fatalf("Dial() err = %v", err)
+	}
+	defer conn.Close()
+	if requestId := response.Header.Get(DXAPIRequestIdHeader); requestId != "ws-1" {
+		t.Errorf("X-Request-Id of the handshake = %q, want ws-1", requestId)
+	}
+	err = conn.WriteJSON(map[string]any{"k": "v"})
+	if err != nil {
+		t.Fatalf("WriteJSON() err = %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseInternalServerErr) {
+		t.Fatalf("ReadMessage() err = %v, want close %d", err, websocket.CloseInternalServerErr)
+	}
+	if closeError := err.(*websocket.CloseError); closeError.Text != ErrorInternal.Code {
+		t.Errorf("close text = %q, want %s", closeError.Text, ErrorInternal.Code)
+	}
+	entry := testAuditLogEntry(t, auditLogEntries)
+	if (entry.StatusCode != http.StatusInternalServerError) || (entry.RequestId != "ws-1") || !strings.Contains(entry.ErrorMessage, "PANIC") {
+		t.Errorf("audit log entry = %+v, want 500, ws-1 and the panic", entry)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var mutex sync.Mutex
+	var calls []string
+	record := func(s string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls = append(calls, s)
+	}
+	// trace records its name before next and the status the response was written with after next
+	trace := func(name string) DXAPIMiddlewareFunc {
+		return func(aepr *DXAPIEndPointRequest, next DXAPIMiddlewareNextFunc) (err error) {
+			record(name + ">")
+			err = next()
+			record(name + "<" + http.StatusText(aepr.ResponseStatusCode))
+			return err
+		}
+	}
+	reject := func(aepr *DXAPIEndPointRequest, next DXAPIMiddlewareNextFunc) (err error) {
+		record("reject")
+		return errors.New("FORBIDDEN_BY_GROUP")
+	}
+	tooManyRequests := func(aepr *DXAPIEndPointRequest) (err error) {
+		record("limit")
+		aepr.WriteResponseAsString(http.StatusTooManyRequests, nil, "")
+		return nil
+	}
+
+	a, _ := testAPI(t, ErrorResponseFormatProblemJSON)
+	a.Use(trace("api"))
+	g := a.Group("/v1/g", trace("group"))
+	sub := g.Group("/sub", trace("subgroup"))
+	endPointMiddleware := func(aepr *DXAPIEndPointRequest) (err error) {
+		record("endpoint-middleware")
+		return nil
+	}
+	execute := func(aepr *DXAPIEndPointRequest) error {
+		record("execute")
+		aepr.WriteResponseAsString(http.StatusCreated, nil, "")
+		return nil
+	}
+	sub.NewEndPoint("Ok", "", "/ok", http.MethodGet, EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, nil, execute, nil, nil,
+		[]DXAPIEndPointExecuteFunc{endPointMiddleware}, nil, 0, "")
+	sub.NewEndPoint("Fail", "", "/fail", http.MethodGet, EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, nil,
+		func(aepr *DXAPIEndPointRequest) error {
+			record("execute")
+			return ErrorMandatoryParameterNotExist.New("name")
+		}, nil, nil, nil, nil, 0, "")
+	rejected := g.Group("/rejected", reject)
+	rejected.NewEndPoint("Rejected", "", "/x", http.MethodGet, EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, nil, execute, nil, nil, nil, nil, 0, "")
+	// the after hook is added first so it wraps the before hook and also sees the request it answered
+	limited := g.Group("/limited", MiddlewareAfter(func(aepr *DXAPIEndPointRequest) (err error) {
+		record("after " + http.StatusText(aepr.ResponseStatusCode))
+		return nil
+	}), MiddlewareBefore(tooManyRequests))
+	limited.NewEndPoint("Limited", "", "/x", http.MethodGet, EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, nil, execute, nil, nil, nil, nil, 0, "")
+	server := testServe(t, a)
+
+	tests := []struct {
+		name       string
+		uri        string
+		wantStatus int
+		wantCode   string
+		wantCalls  []string
+	}{
+		{
+			name: "api then group then subgroup then endpoint", uri: "/v1/g/sub/ok", wantStatus: http.StatusCreated,
+			wantCalls: []string{"api>", "group>", "subgroup>", "endpoint-middleware", "execute", "subgroup<Created", "group<Created", "api<Created"},
+		},
+		{
+			name: "after hooks see the error response of the endpoint", uri: "/v1/g/sub/fail", wantStatus: http.StatusUnprocessableEntity, wantCode: "MANDATORY_PARAMETER_NOT_EXIST",
+			wantCalls: []string{"api>", "group>", "subgroup>", "execute", "subgroup<Unprocessable Entity", "group<Unprocessable Entity", "api<Unprocessable Entity"},
+		},
+		{
+			name: "rejecting group middleware answers before the outer after hooks", uri: "/v1/g/rejected/x", wantStatus: http.StatusBadRequest, wantCode: "MIDDLEWARE_ERROR",
+			wantCalls: []string{"api>", "group>", "reject", "group<Bad Request", "api<Bad Request"},
+		},
+		{
+			name: "before hook answering the request skips the endpoint", uri: "/v1/g/limited/x", wantStatus: http.StatusTooManyRequests,
+			wantCalls: []string{"api>", "group>", "limit", "after Too Many Requests", "group<Too Many Requests", "api<Too Many Requests"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutex.Lock()
+			calls = nil
+			mutex.Unlock()
+			response, body := testGet(t, server.URL+tt.uri, nil)
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if (tt.wantCode != "") && (body["code"] != tt.wantCode) {
+				t.Errorf("code = %v, want %s", body["code"], tt.wantCode)
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			if strings.Join(calls, ",") != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestEndPointTimeout(t *testing.T) {
+	a, auditLogEntries := testAPI(t, ErrorResponseFormatProblemJSON)
+	// waitForDeadline stands for a database or Redis call made with aepr.Context
+	waitForDeadline := func(aepr *DXAPIEndPointRequest) error {
+		select {
+		case <-aepr.Context.Done():
+			return errors.Wrap(aepr.Context.Err(), "QUERY_CANCELLED")
+		case <-time.After(2 * time.Second):
+			return nil
+		}
+	}
+	slow := testNewGetEndPoint(a, "/v1/slow", waitForDeadline)
+	slow.Timeout = 50 * time.Millisecond
+	fast := testNewGetEndPoint(a, "/v1/fast", func(aepr *DXAPIEndPointRequest) error {
+		aepr.WriteResponseAsString(http.StatusOK, nil, "")
+		return nil
+	})
+	fast.Timeout = time.Second
+	slowMiddleware := a.NewEndPoint("Slow middleware", "", "/v1/slow_middleware", http.MethodGet, EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, nil,
+		func(aepr *DXAPIEndPointRequest) error {
+			t.Errorf("endpoint executed after its middleware timed out")
+			return nil
+		}, nil, nil, []DXAPIEndPointExecuteFunc{waitForDeadline}, nil, 0, "")
+	slowMiddleware.Timeout = 50 * time.Millisecond
+	testNewGetEndPoint(a, "/v1/api_default", waitForDeadline)
+	a.EndPointTimeoutSec = 1
+	server := testServe(t, a)
+
+	tests := []struct {
+		name        string
+		uri         string
+		wantStatus  int
+		wantCode    string
+		maxDuration time.Duration
+	}{
+		{name: "endpoint deadline", uri: "/v1/slow", wantStatus: http.StatusGatewayTimeout, wantCode: "REQUEST_TIMEOUT", maxDuration: time.Second},
+		{name: "middleware deadline", uri: "/v1/slow_middleware", wantStatus: http.StatusGatewayTimeout, wantCode: "REQUEST_TIMEOUT", maxDuration: time.Second},
+		{name: "api default deadline", uri: "/v1/api_default", wantStatus: http.StatusGatewayTimeout, wantCode: "REQUEST_TIMEOUT", maxDuration: 1900 * time.Millisecond},
+		{name: "within the deadline", uri: "/v1/fast", wantStatus: http.StatusOK, maxDuration: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			response, body := testGet(t, server.URL+tt.uri, map[string]string{DXAPIRequestIdHeader: "timeout-1"})
+			if duration := time.Since(start); duration > tt.maxDuration {
+				t.Errorf("answered after %s, want within %s", duration, tt.maxDuration)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if (tt.wantCode != "") && ((body["code"] != tt.wantCode) || (body["request_id"] != "timeout-1")) {
+				t.Errorf("body = %v, want code %s and request_id timeout-1", body, tt.wantCode)
+			}
+			entry := testAuditLogEntry(t, auditLogEntries)
+			if entry.StatusCode != tt.wantStatus {
+				t.Errorf("status of the audit log = %d, want %d", entry.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DXAPISSEContentType          = "text/event-stream"
+	DXAPISSEHeartbeatIntervalSec = 15
+)
+
+// DXAPISSEStream writes Server-Sent Events, the response header is only sent on the first write so a handler can still fail with a normal error response before streaming
+type DXAPISSEStream struct {
+	Owner              *DXAPIEndPointRequest
+	Context            context.Context
+	LastEventId        string
+	cancel             context.CancelFunc
+	writeMutex         sync.Mutex
+	responseController *http.ResponseController
+	isStarted          bool
+}
+
+func sseLineSanitize(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+func (s *DXAPISSEStream) start() (err error) {
+	w := *s.Owner.ResponseWriter
+	_ = s.responseController.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", DXAPISSEContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	s.Owner.ResponseStatusCode = http.StatusOK
+	s.Owner.ResponseHeaderSent = true
+	s.isStarted = true
+	err = s.responseController.Flush()
+	if err != nil {
+		return errors.Wrap(err, "SSE_FLUSH_NOT_SUPPORTED")
+	}
+	return nil
+}
+
+func (s *DXAPISSEStream) write(text string) (err error) {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	if s.Context.Err() != nil {
+		return errors.New("SSE_STREAM_CLOSED")
+	}
+	if !s.isStarted {
+		err = s.start()
+		if err != nil {
+			return err
+		}
+	}
+	_, err = (*s.Owner.ResponseWriter).Write([]byte(text))
+	if err != nil {
+		s.cancel()
+		return errors.Wrap(err, "SSE_STREAM_WRITE_ERROR")
+	}
+	err = s.responseController.Flush()
+	if err != nil {
+		s.cancel()
+		return errors.Wrap(err, "SSE_STREAM_FLUSH_ERROR")
+	}
+	return nil
+}
+
+// SendEvent writes one event, data is sent as is when it is a string or []byte and as JSON otherwise
+func (s *DXAPISSEStream) SendEvent(id string, event string, data any) (err error) {
+	var dataAsString string
+	switch v := data.(type) {
+	case nil:
+		dataAsString = ""
+	case string:
+		dataAsString = v
+	case []byte:
+		dataAsString = string(v)
+	default:
+		dataAsBytes, err := json.Marshal(v)
+		if err != nil {
+			return errors.Wrap(err, "error occured")
+		}
+		dataAsString = string(dataAsBytes)
+	}
+	var sb strings.Builder
+	if id != "" {
+		sb.WriteString("id: " + sseLineSanitize(id) + "\n")
+	}
+	if event != "" {
+		sb.WriteString("event: " + sseLineSanitize(event) + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(dataAsString, "\r\n", "\n"), "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+	return s.write(sb.String())
+}
+
+func (s *DXAPISSEStream) SendComment(comment string) error {
+	return s.write(": " + sseLineSanitize(comment) + "\n\n")
+}
+
+func (s *DXAPISSEStream) SetRetry(retryMs int) error {
+	return s.write(fmt.Sprintf("retry: %d\n\n", retryMs))
+}
+
+func (s *DXAPISSEStream) Done() <-chan struct{} {
+	return s.Context.Done()
+}
+
+func (s *DXAPISSEStream) heartbeatLoop() {
+	ticker := time.NewTicker(DXAPISSEHeartbeatIntervalSec * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.Context.Done():
+			return
+		case <-ticker.C:
+			s.writeMutex.Lock()
+			isStarted := s.isStarted
+			s.writeMutex.Unlock()
+			if !isStarted {
+				continue
+			}
+			if s.SendComment("heartbeat") != nil {
+				return
+			}
+		}
+	}
+}
+
+// newSSEStream prepares aepr.SSEStream, the stream ends when the client disconnects or the API starts shutting down.
+// The last_event_id query parameter stands in for the Last-Event-ID header, a client that opens a new EventSource, e.g. with a new stream ticket, can not set that header.
+func (aepr *DXAPIEndPointRequest) newSSEStream() (cleanup func()) {
+	ctx, cancel := context.WithCancel(aepr.Request.Context())
+	stop := context.AfterFunc(aepr.EndPoint.Owner.ShutdownContext, cancel)
+	lastEventId := aepr.Request.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = aepr.Request.URL.Query().Get("last_event_id")
+	}
+	s := &DXAPISSEStream{
+		Owner:              aepr,
+		Context:            ctx,
+		LastEventId:        lastEventId,
+		cancel:             cancel,
+		responseController: http.NewResponseController(*aepr.ResponseWriter),
+	}
+	aepr.SSEStream = s
+	go s.heartbeatLoop()
+	return func() {
+		stop()
+		cancel()
+		s.writeMutex.Lock()
+		defer s.writeMutex.Unlock()
+	}
+}
+
+func (aepr *DXAPIEndPointRequest) SSESendEvent(id string, event string, data any) error {
+	if aepr.SSEStream == nil {
+		return errors.New("SSE_STREAM_NOT_AVAILABLE_ON_NON_SSE_ENDPOINT")
+	}
+	return aepr.SSEStream.SendEvent(id, event, data)
+}
+
+func (aepr *DXAPIEndPointRequest) SSELastEventId() string {
+	if aepr.SSEStream == nil {
+		return ""
+	}
+	return aepr.SSEStream.LastEventId
+}
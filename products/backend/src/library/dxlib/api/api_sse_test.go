@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// testSSERequest builds an aepr of an SSE endpoint, the stream is cleaned up when the test ends
+func testSSERequest(t *testing.T, target string, lastEventId string) (aepr *DXAPIEndPointRequest, recorder *httptest.ResponseRecorder, shutdown context.CancelFunc) {
+	aepr, recorder = testErrorRequest(ErrorResponseFormatProblemJSON, false)
+	shutdownContext, shutdown := context.WithCancel(context.Background())
+	aepr.EndPoint.Owner.ShutdownContext = shutdownContext
+	aepr.EndPoint.EndPointType = EndPointTypeSSE
+	aepr.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	if lastEventId != "" {
+		aepr.Request.Header.Set("Last-Event-ID", lastEventId)
+	}
+	cleanup := aepr.newSSEStream()
+	t.Cleanup(func() {
+		cleanup()
+		shutdown()
+	})
+	return aepr, recorder, shutdown
+}
+
+func TestSSELastEventId(t *testing.T) {
+	tests := []struct {
+		name            string
+		target          string
+		lastEventId     string
+		wantLastEventId string
+	}{
+		{name: "none", target: "/v1/stream", wantLastEventId: ""},
+		{name: "header", target: "/v1/stream", lastEventId: "7", wantLastEventId: "7"},
+		{name: "query parameter", target: "/v1/stream?last_event_id=5", wantLastEventId: "5"},
+		{name: "header wins over query parameter", target: "/v1/stream?last_event_id=5", lastEventId: "7", wantLastEventId: "7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aepr, _, _ := testSSERequest(t, tt.target, tt.lastEventId)
+			if got := aepr.SSELastEventId(); got != tt.wantLastEventId {
+				t.Errorf("SSELastEventId() = %q, want %q", got, tt.wantLastEventId)
+			}
+		})
+	}
+}
+
+func TestSSEResume(t *testing.T) {
+	events := []string{"a", "b", "c", "d"}
+	// replay sends the events after the last event id the client has seen, the way an SSE handler resumes a stream
+	replay := func(aepr *DXAPIEndPointRequest) error {
+		next := 0
+		if lastEventId := aepr.SSELastEventId(); lastEventId != "" {
+			i, err := strconv.Atoi(lastEventId)
+			if err != nil {
+				return err
+			}
+			next = i + 1
+		}
+		for i := next; i < len(events); i++ {
+			err := aepr.SSESendEvent(strconv.Itoa(i), "item", events[i])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	tests := []struct {
+		name        string
+		target      string
+		lastEventId string
+		wantBody    string
+	}{
+		{name: "from the start", target: "/v1/stream", wantBody: "id: 0\nevent: item\ndata: a\n\nid: 1\nevent: item\ndata: b\n\nid: 2\nevent: item\ndata: c\n\nid: 3\nevent: item\ndata: d\n\n"},
+		{name: "after the header", target: "/v1/stream", lastEventId: "1", wantBody: "id: 2\nevent: item\ndata: c\n\nid: 3\nevent: item\ndata: d\n\n"},
+		{name: "after the query parameter", target: "/v1/stream?last_event_id=2", wantBody: "id: 3\nevent: item\ndata: d\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aepr, recorder, _ := testSSERequest(t, tt.target, tt.lastEventId)
+			err := replay(aepr)
+			if err != nil {
+				t.Fatalf("replay() err = %v", err)
+			}
+			if recorder.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", recorder.Code)
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != DXAPISSEContentType {
+				t.Errorf("Content-Type = %s, want %s", contentType, DXAPISSEContentType)
+			}
+			if body := recorder.Body.String(); body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSSESendEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		event    string
+		data     any
+		wantBody string
+	}{
+		{name: "multi line data", data: "a\r\nb", wantBody: "data: a\ndata: b\n\n"},
+		{name: "JSON data", id: "1", data: map[string]any{"k": 1}, wantBody: "id: 1\ndata: {\"k\":1}\n\n"},
+		{name: "new line of id and event is removed", id: "1\n2", event: "x\ny", data: nil, wantBody: "id: 12\nevent: xy\ndata: \n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aepr, recorder, _ := testSSERequest(t, "/v1/stream", "")
+			err := aepr.SSESendEvent(tt.id, tt.event, tt.data)
+			if err != nil {
+				t.Fatalf("SSESendEvent() err = %v", err)
+			}
+			if body := recorder.Body.String(); body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSSEShutdown(t *testing.T) {
+	aepr, recorder, shutdown := testSSERequest(t, "/v1/stream", "")
+	shutdown()
+	<-aepr.SSEStream.Done()
+	if aepr.SSESendEvent("1", "", "a") == nil {
+		t.Errorf("SSESendEvent() after shutdown err = nil, want SSE_STREAM_CLOSED")
+	}
+	if recorder.Body.Len() != 0 {
+		t.Errorf("body = %q, want empty", recorder.Body.String())
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DXAPIDefaultEndPointTimeoutSec of 0 leaves the requests only bound by the server read and write timeouts
+	DXAPIDefaultEndPointTimeoutSec       = 0
+	DXAPIDefaultStreamEndPointTimeoutSec = 0
+)
+
+var ErrorRequestTimeout = RegisterError("REQUEST_TIMEOUT", http.StatusGatewayTimeout, "Request timed out", true)
+
+// timeout is the endpoint Timeout, otherwise the API default of its type, WS and SSE endpoints are long lived and only get an explicit Timeout
+func (aep *DXAPIEndPoint) timeout() time.Duration {
+	if aep.Timeout > 0 {
+		return aep.Timeout
+	}
+	switch aep.EndPointType {
+	case EndPointTypeHTTPJSON:
+		return time.Duration(aep.Owner.EndPointTimeoutSec) * time.Second
+	case EndPointTypeHTTPUploadStream, EndPointTypeHTTPDownloadStream:
+		return time.Duration(aep.Owner.StreamEndPointTimeoutSec) * time.Second
+	}
+	return 0
+}
+
+// applyTimeout puts the endpoint deadline on aepr.Context so database2 and Redis calls made with it are cancelled.
+// Stream endpoints also move the connection deadlines, so their limit may be longer than the server read and write timeouts.
+// The v1 database and table packages take no context, so queries made through them, which are all queries of the webadmin
+// handlers, keep running after the deadline. Bound those with a statement_timeout in the connection_options of the database.
+func (aepr *DXAPIEndPointRequest) applyTimeout() (cancel context.CancelFunc) {
+	timeout := aepr.EndPoint.timeout()
+	if timeout <= 0 {
+		return func() {}
+	}
+	aepr.Context, cancel = context.WithTimeout(aepr.Context, timeout)
+
+	switch aepr.EndPoint.EndPointType {
+	case EndPointTypeHTTPUploadStream, EndPointTypeHTTPDownloadStream:
+		responseController := http.NewResponseController(*aepr.ResponseWriter)
+		deadline := time.Now().Add(timeout)
+		_ = responseController.SetWriteDeadline(deadline)
+		if aepr.EndPoint.EndPointType == EndPointTypeHTTPUploadStream {
+			_ = responseController.SetReadDeadline(deadline)
+		}
+	}
+	return cancel
+}
+
+// IsTimedOut reports whether the endpoint deadline of aepr.Context has passed
+func (aepr *DXAPIEndPointRequest) IsTimedOut() bool {
+	return errors.Is(aepr.Context.Err(), context.DeadlineExceeded)
+}
+
+func (aepr *DXAPIEndPointRequest) newTimeoutError(err error) *DXAPIError {
+	return ErrorRequestTimeout.Wrap(err, aepr.EndPoint.timeout().String())
+}
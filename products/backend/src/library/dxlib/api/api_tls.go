@@ -0,0 +1,290 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/utils"
+	utilsJSON "github.com/donnyhardyanto/dxlib/utils/json"
+	"github.com/pkg/errors"
+)
+
+const DXAPIDefaultTLSReloadIntervalSec = 60
+
+// DXAPITLS is the TLS configuration of a listener, MinVersion below TLS 1.2 is rejected unless IsLegacyMinVersionAllowed is set and a zero MinVersion means TLS 1.2
+type DXAPITLS struct {
+	IsEnabled                 bool
+	CertFile                  string
+	KeyFile                   string
+	CertPEM                   string
+	KeyPEM                    string
+	MinVersion                uint16
+	IsLegacyMinVersionAllowed bool
+	ClientCAFile              string
+	ClientCAPEM               string
+	ClientAuth                tls.ClientAuthType
+	ReloadIntervalSec         int
+	IsHTTP2Enabled            bool
+	IsH2CEnabled              bool
+
+	mutex           sync.Mutex
+	certificate     *tls.Certificate
+	clientCAs       *x509.CertPool
+	lastCheckTime   time.Time
+	certFileModTime time.Time
+	keyFileModTime  time.Time
+	caFileModTime   time.Time
+}
+
+func parseTLSMinVersion(s string) (v uint16, err error) {
+	switch strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "TLS") {
+	case "", "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	default:
+		return 0, errors.Errorf("TLS_MIN_VERSION_NOT_SUPPORTED:%s", s)
+	}
+}
+
+func parseTLSClientAuth(s string) (v tls.ClientAuthType, err error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "require":
+		return tls.RequireAnyClientCert, nil
+	case "verify-if-given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require-and-verify":
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return 0, errors.Errorf("TLS_CLIENT_AUTH_NOT_SUPPORTED:%s", s)
+	}
+}
+
+func (t *DXAPITLS) ApplyConfiguration(j utils.JSON) (err error) {
+	getString := func(k string) string {
+		s, _ := j[k].(string)
+		return strings.TrimSpace(s)
+	}
+	t.CertFile = getString("cert-file")
+	t.KeyFile = getString("key-file")
+	t.CertPEM = getString("cert-pem")
+	t.KeyPEM = getString("key-pem")
+	t.ClientCAFile = getString("client-ca-file")
+	t.ClientCAPEM = getString("client-ca-pem")
+	t.IsEnabled = ((t.CertFile != "") && (t.KeyFile != "")) || ((t.CertPEM != "") && (t.KeyPEM != ""))
+	if _, ok := j["enabled"]; ok {
+		isEnabled, err := utilsJSON.GetBool(j, "enabled")
+		if err != nil {
+			return errors.Wrap(err, "enabled")
+		}
+		t.IsEnabled = t.IsEnabled && isEnabled
+	}
+	t.MinVersion, err = parseTLSMinVersion(getString("min-version"))
+	if err != nil {
+		return err
+	}
+	if _, ok := j["allow-legacy-min-version"]; ok {
+		t.IsLegacyMinVersionAllowed, err = utilsJSON.GetBool(j, "allow-legacy-min-version")
+		if err != nil {
+			return errors.Wrap(err, "allow-legacy-min-version")
+		}
+	}
+	err = t.checkMinVersion()
+	if err != nil {
+		return err
+	}
+	clientAuth := getString("client-auth")
+	if (clientAuth == "") && ((t.ClientCAFile != "") || (t.ClientCAPEM != "")) {
+		clientAuth = "require-and-verify"
+	}
+	t.ClientAuth, err = parseTLSClientAuth(clientAuth)
+	if err != nil {
+		return err
+	}
+	t.ReloadIntervalSec = DXAPIDefaultTLSReloadIntervalSec
+	switch v := j["reload-interval-sec"].(type) {
+	case float64:
+		t.ReloadIntervalSec = int(v)
+	case int:
+		t.ReloadIntervalSec = v
+	}
+	t.IsHTTP2Enabled = true
+	if _, ok := j["http2"]; ok {
+		t.IsHTTP2Enabled, err = utilsJSON.GetBool(j, "http2")
+		if err != nil {
+			return errors.Wrap(err, "http2")
+		}
+	}
+	if _, ok := j["h2c"]; ok {
+		t.IsH2CEnabled, err = utilsJSON.GetBool(j, "h2c")
+		if err != nil {
+			return errors.Wrap(err, "h2c")
+		}
+	}
+	return nil
+}
+
+func (t *DXAPITLS) checkMinVersion() error {
+	if (t.MinVersion != 0) && (t.MinVersion < tls.VersionTLS12) && !t.IsLegacyMinVersionAllowed {
+		return errors.Errorf("TLS_MIN_VERSION_BELOW_1_2_NOT_ALLOWED:%s", tls.VersionName(t.MinVersion))
+	}
+	return nil
+}
+
+func fileModTime(filename string) (time.Time, error) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "error occured")
+	}
+	return fi.ModTime(), nil
+}
+
+func (t *DXAPITLS) load() (err error) {
+	var certificate tls.Certificate
+	if (t.CertFile != "") && (t.KeyFile != "") {
+		t.certFileModTime, err = fileModTime(t.CertFile)
+		if err != nil {
+			return err
+		}
+		t.keyFileModTime, err = fileModTime(t.KeyFile)
+		if err != nil {
+			return err
+		}
+		certificate, err = tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	} else {
+		certificate, err = tls.X509KeyPair([]byte(t.CertPEM), []byte(t.KeyPEM))
+	}
+	if err != nil {
+		return errors.Wrap(err, "TLS_CERTIFICATE_LOAD_ERROR")
+	}
+
+	var clientCAs *x509.CertPool
+	caPEM := []byte(t.ClientCAPEM)
+	if t.ClientCAFile != "" {
+		t.caFileModTime, err = fileModTime(t.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		caPEM, err = os.ReadFile(t.ClientCAFile)
+		if err != nil {
+			return errors.Wrap(err, "TLS_CLIENT_CA_LOAD_ERROR")
+		}
+	}
+	if len(caPEM) > 0 {
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caPEM) {
+			return errors.New("TLS_CLIENT_CA_HAS_NO_VALID_CERTIFICATE")
+		}
+	}
+
+	t.certificate = &certificate
+	t.clientCAs = clientCAs
+	t.lastCheckTime = time.Now()
+	return nil
+}
+
+func (t *DXAPITLS) isChangedOnDisk() bool {
+	isChanged := func(filename string, lastModTime time.Time) bool {
+		if filename == "" {
+			return false
+		}
+		modTime, err := fileModTime(filename)
+		if err != nil {
+			return false
+		}
+		return !modTime.Equal(lastModTime)
+	}
+	return isChanged(t.CertFile, t.certFileModTime) || isChanged(t.KeyFile, t.keyFileModTime) || isChanged(t.ClientCAFile, t.caFileModTime)
+}
+
+// current returns the loaded certificate and client CA pool, reloading them when the files on disk have changed
+func (t *DXAPITLS) current() (certificate *tls.Certificate, clientCAs *x509.CertPool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if (t.ReloadIntervalSec > 0) && (time.Since(t.lastCheckTime) >= time.Duration(t.ReloadIntervalSec)*time.Second) {
+		t.lastCheckTime = time.Now()
+		if t.isChangedOnDisk() {
+			err := t.load()
+			if err != nil {
+				log.Log.Errorf(err, "TLS_RELOAD_ERROR:%s", err.Error())
+			} else {
+				log.Log.Infof("TLS certificate reloaded from %s", t.CertFile)
+			}
+		}
+	}
+	return t.certificate, t.clientCAs
+}
+
+func (t *DXAPITLS) NewTLSConfig() (c *tls.Config, err error) {
+	err = t.checkMinVersion()
+	if err != nil {
+		return nil, err
+	}
+	minVersion := t.MinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+	t.mutex.Lock()
+	err = t.load()
+	t.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if ((t.ClientAuth == tls.VerifyClientCertIfGiven) || (t.ClientAuth == tls.RequireAndVerifyClientCert)) && (t.clientCAs == nil) {
+		return nil, errors.New("TLS_CLIENT_CA_IS_MANDATORY_FOR_CLIENT_CERTIFICATE_VERIFICATION")
+	}
+	nextProtos := []string{"http/1.1"}
+	if t.IsHTTP2Enabled {
+		nextProtos = []string{"h2", "http/1.1"}
+	}
+	c = &tls.Config{
+		MinVersion: minVersion,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			certificate, clientCAs := t.current()
+			return &tls.Config{
+				MinVersion:   minVersion,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{*certificate},
+				ClientAuth:   t.ClientAuth,
+				ClientCAs:    clientCAs,
+			}, nil
+		},
+	}
+	return c, nil
+}
+
+func (t *DXAPITLS) NewProtocols() *http.Protocols {
+	p := &http.Protocols{}
+	p.SetHTTP1(true)
+	p.SetHTTP2(t.IsEnabled && t.IsHTTP2Enabled)
+	p.SetUnencryptedHTTP2(!t.IsEnabled && t.IsH2CEnabled)
+	return p
+}
+
+func ClientCertificateSubjects(r *http.Request) (subjects []string) {
+	subjects = []string{}
+	if r.TLS == nil {
+		return subjects
+	}
+	for _, chain := range r.TLS.VerifiedChains {
+		if len(chain) > 0 {
+			subjects = append(subjects, chain[0].Subject.String())
+		}
+	}
+	return subjects
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+)
+
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	certPEM     []byte
+	keyPEM      []byte
+}
+
+// testNewCertificate issues a certificate of commonName signed by parent, or a self-signed CA when parent is nil
+func testNewCertificate(t *testing.T, commonName string, serial int64, parent *testCertificate) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() err = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"dxlib test"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	signerCertificate, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signerCertificate, signerKey = parent.certificate, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCertificate, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() err = %v", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() err = %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() err = %v", err)
+	}
+	return &testCertificate{
+		certificate: certificate,
+		key:         key,
+		certPEM:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:      pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	certificate, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	if err != nil {
+		t.Fatalf("X509KeyPair() err = %v", err)
+	}
+	return certificate
+}
+
+func testWriteFile(t *testing.T, filename string, b []byte, modTime time.Time) {
+	t.Helper()
+	err := os.WriteFile(filename, b, 0600)
+	if err != nil {
+		t.Fatalf("WriteFile() err = %v", err)
+	}
+	err = os.Chtimes(filename, modTime, modTime)
+	if err != nil {
+		t.Fatalf("Chtimes() err = %v", err)
+	}
+}
+
+// testTLSServer starts a TLS server of config whose handler answers the subjects of the verified client certificates
+func testTLSServer(t *testing.T, config *tls.Config) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, strings.Join(ClientCertificateSubjects(r), ";"))
+	}))
+	server.TLS = config
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testTLSClient(ca *testCertificate, clientCertificate *tls.Certificate) *http.Client {
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.certificate)
+	config := &tls.Config{RootCAs: rootCAs, ServerName: "localhost"}
+	if clientCertificate != nil {
+		// present the certificate even when it is not issued by a CA the server asks for, so the server is the one to refuse it
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return clientCertificate, nil
+		}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}}
+}
+
+func TestTLSApplyConfigurationMinVersion(t *testing.T) {
+	tests := []struct {
+		name           string
+		configuration  utils.JSON
+		wantMinVersion uint16
+		wantErr        bool
+	}{
+		{name: "default", configuration: utils.JSON{}, wantMinVersion: tls.VersionTLS12},
+		{name: "1.2", configuration: utils.JSON{"min-version": "1.2"}, wantMinVersion: tls.VersionTLS12},
+		{name: "TLS1.3", configuration: utils.JSON{"min-version": "TLS1.3"}, wantMinVersion: tls.VersionTLS13},
+		{name: "1.1 is rejected", configuration: utils.JSON{"min-version": "1.1"}, wantErr: true},
+		{name: "1.0 is rejected", configuration: utils.JSON{"min-version": "1.0"}, wantErr: true},
+		{name: "1.0 when legacy is allowed", configuration: utils.JSON{"min-version": "1.0", "allow-legacy-min-version": true}, wantMinVersion: tls.VersionTLS10},
+		{name: "1.1 when legacy is not allowed", configuration: utils.JSON{"min-version": "1.1", "allow-legacy-min-version": false}, wantErr: true},
+		{name: "unknown version", configuration: utils.JSON{"min-version": "2.0"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfiguration := &DXAPITLS{}
+			err := tlsConfiguration.ApplyConfiguration(tt.configuration)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyConfiguration() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (tlsConfiguration.MinVersion != tt.wantMinVersion) {
+				t.Errorf("MinVersion = %s, want %s", tls.VersionName(tlsConfiguration.MinVersion), tls.VersionName(tt.wantMinVersion))
+			}
+		})
+	}
+}
+
+func TestTLSNewTLSConfigMinVersion(t *testing.T) {
+	ca := testNewCertificate(t, "test ca", 1, nil)
+	server := testNewCertificate(t, "localhost", 2, ca)
+	tests := []struct {
+		name           string
+		minVersion     uint16
+		isLegacy       bool
+		wantMinVersion uint16
+		wantErr        bool
+	}{
+		{name: "zero is TLS 1.2", wantMinVersion: tls.VersionTLS12},
+		{name: "TLS 1.3", minVersion: tls.VersionTLS13, wantMinVersion: tls.VersionTLS13},
+		{name: "TLS 1.1 is rejected", minVersion: tls.VersionTLS11, wantErr: true},
+		{name: "TLS 1.1 when legacy is allowed", minVersion: tls.VersionTLS11, isLegacy: true, wantMinVersion: tls.VersionTLS11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfiguration := &DXAPITLS{CertPEM: string(server.certPEM), KeyPEM: string(server.keyPEM), MinVersion: tt.minVersion, IsLegacyMinVersionAllowed: tt.isLegacy}
+			config, err := tlsConfiguration.NewTLSConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTLSConfig() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			clientConfig, err := config.GetConfigForClient(&tls.ClientHelloInfo{})
+			if err != nil {
+				t.Fatalf("GetConfigForClient() err = %v", err)
+			}
+			if (config.MinVersion != tt.wantMinVersion) || (clientConfig.MinVersion != tt.wantMinVersion) {
+				t.Errorf("MinVersion = %s and %s, want %s", tls.VersionName(config.MinVersion), tls.VersionName(clientConfig.MinVersion), tls.VersionName(tt.wantMinVersion))
+			}
+		})
+	}
+}
+
+func TestTLSHandshakeBelowMinVersion(t *testing.T) {
+	ca := testNewCertificate(t, "test ca", 1, nil)
+	serverCertificate := testNewCertificate(t, "localhost", 2, ca)
+	tlsConfiguration := &DXAPITLS{CertPEM: string(serverCertificate.certPEM), KeyPEM: string(serverCertificate.keyPEM)}
+	config, err := tlsConfiguration.NewTLSConfig()
+	if err != nil {
+		t.Fatalf("NewTLSConfig() err = %v", err)
+	}
+	server := testTLSServer(t, config)
+	client := testTLSClient(ca, nil)
+	client.Transport.(*http.Transport).TLSClientConfig.MaxVersion = tls.VersionTLS11
+	client.Transport.(*http.Transport).TLSClientConfig.MinVersion = tls.VersionTLS10
+	response, err := client.Get(server.URL)
+	if err == nil {
+		_ = response.Body.Close()
+		t.Fatalf("Get() with TLS 1.1 err = nil, want a handshake error")
+	}
+}
+
+func TestTLSCertificateReload(t *testing.T) {
+	ca := testNewCertificate(t, "test ca", 1, nil)
+	first := testNewCertificate(t, "localhost", 10, ca)
+	second := testNewCertificate(t, "localhost", 20, ca)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	modTime := time.Now().Add(-time.Minute)
+	testWriteFile(t, certFile, first.certPEM, modTime)
+	testWriteFile(t, keyFile, first.keyPEM, modTime)
+
+	tlsConfiguration := &DXAPITLS{CertFile: certFile, KeyFile: keyFile, ReloadIntervalSec: 1}
+	config, err := tlsConfiguration.NewTLSConfig()
+	if err != nil {
+		t.Fatalf("NewTLSConfig() err = %v", err)
+	}
+	server := testTLSServer(t, config)
+	client := testTLSClient(ca, nil)
+
+	serial := func() int64 {
+		t.Helper()
+		response, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get() err = %v", err)
+		}
+		defer response.Body.Close()
+		return response.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+	// the reload interval has not passed yet, the first certificate is used
+	tlsConfiguration.mutex.Lock()
+	tlsConfiguration.lastCheckTime = time.Now().Add(time.Hour)
+	tlsConfiguration.mutex.Unlock()
+
+	tests := []struct {
+		name       string
+		prepare    func()
+		wantSerial int64
+	}{
+		{name: "loaded certificate", prepare: func() {}, wantSerial: 10},
+		{
+			name: "changed files before the reload interval",
+			prepare: func() {
+				testWriteFile(t, certFile, second.certPEM, modTime.Add(time.Second))
+				testWriteFile(t, keyFile, second.keyPEM, modTime.Add(time.Second))
+			},
+			wantSerial: 10,
+		},
+		{
+			name: "changed files after the reload interval",
+			prepare: func() {
+				tlsConfiguration.mutex.Lock()
+				tlsConfiguration.lastCheckTime = time.Time{}
+				tlsConfiguration.mutex.Unlock()
+			},
+			wantSerial: 20,
+		},
+		{
+			name: "broken files keep the loaded certificate",
+			prepare: func() {
+				testWriteFile(t, keyFile, []byte("broken"), modTime.Add(2*time.Second))
+				tlsConfiguration.mutex.Lock()
+				tlsConfiguration.lastCheckTime = time.Time{}
+				tlsConfiguration.mutex.Unlock()
+			},
+			wantSerial: 20,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			if got := serial(); got != tt.wantSerial {
+				t.Errorf("serial = %d, want %d", got, tt.wantSerial)
+			}
+		})
+	}
+}
+
+func TestTLSClientCertificateSubjects(t *testing.T) {
+	ca := testNewCertificate(t, "test ca", 1, nil)
+	otherCA := testNewCertificate(t, "other ca", 2, nil)
+	serverCertificate := testNewCertificate(t, "localhost", 3, ca)
+	clientCertificate := testNewCertificate(t, "device-7", 4, ca).tlsCertificate(t)
+	otherClientCertificate := testNewCertificate(t, "intruder", 5, otherCA).tlsCertificate(t)
+
+	tests := []struct {
+		name              string
+		configuration     utils.JSON
+		clientCertificate *tls.Certificate
+		wantSubjects      string
+		wantErr           bool
+	}{
+		{name: "client CA requires a verified certificate", configuration: utils.JSON{}, clientCertificate: &clientCertificate, wantSubjects: "CN=device-7,O=dxlib test"},
+		{name: "no client certificate is refused", configuration: utils.JSON{}, wantErr: true},
+		{name: "certificate of another CA is refused", configuration: utils.JSON{}, clientCertificate: &otherClientCertificate, wantErr: true},
+		{name: "verify if given without a certificate", configuration: utils.JSON{"client-auth": "verify-if-given"}, wantSubjects: ""},
+		{name: "verify if given with a certificate", configuration: utils.JSON{"client-auth": "verify-if-given"}, clientCertificate: &clientCertificate, wantSubjects: "CN=device-7,O=dxlib test"},
+		{name: "unverified certificate has no subject", configuration: utils.JSON{"client-auth": "require"}, clientCertificate: &otherClientCertificate, wantSubjects: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configuration := utils.JSON{"cert-pem": string(serverCertificate.certPEM), "key-pem": string(serverCertificate.keyPEM), "client-ca-pem": string(ca.certPEM)}
+			for k, v := range tt.configuration {
+				configuration[k] = v
+			}
+			tlsConfiguration := &DXAPITLS{}
+			err := tlsConfiguration.ApplyConfiguration(configuration)
+			if err != nil {
+				t.Fatalf("ApplyConfiguration() err = %v", err)
+			}
+			config, err := tlsConfiguration.NewTLSConfig()
+			if err != nil {
+				t.Fatalf("NewTLSConfig() err = %v", err)
+			}
+			server := testTLSServer(t, config)
+			response, err := testTLSClient(ca, tt.clientCertificate).Get(server.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			defer response.Body.Close()
+			body, err := io.ReadAll(response.Body)
+			if err != nil {
+				t.Fatalf("ReadAll() err = %v", err)
+			}
+			if string(body) != tt.wantSubjects {
+				t.Errorf("subjects = %q, want %q", body, tt.wantSubjects)
+			}
+		})
+	}
+
+	if subjects := ClientCertificateSubjects(httptest.NewRequest(http.MethodGet, "/", nil)); len(subjects) != 0 {
+		t.Errorf("ClientCertificateSubjects() of a plain request = %v, want none", subjects)
+	}
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/donnyhardyanto/dxlib/tracing"
+)
+
+// traceEnd records the response status code on the server span of the request, 5xx responses mark the span as failed
+func (aepr *DXAPIEndPointRequest) traceEnd(span trace.Span) {
+	span.SetAttributes(semconv.HTTPResponseStatusCode(aepr.ResponseStatusCode))
+	if aepr.ResponseStatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(aepr.ResponseStatusCode))
+	}
+	span.End()
+}
+
+// traceHTTPClientBegin starts a client span for the outbound request and propagates its W3C trace context, the returned func ends the span
+func (aepr *DXAPIEndPointRequest) traceHTTPClientBegin(request *http.Request) (outRequest *http.Request, end func(response *http.Response, err error)) {
+	ctx, span := tracing.StartSpan(aepr.Context, request.Method, trace.SpanKindClient,
+		semconv.HTTPRequestMethodKey.String(request.Method),
+		semconv.URLPath(request.URL.Path),
+		semconv.ServerAddress(request.URL.Hostname()),
+	)
+	outRequest = request.WithContext(ctx)
+	tracing.InjectHTTPHeader(ctx, outRequest.Header)
+	return outRequest, func(response *http.Response, err error) {
+		if response != nil {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+			if (err == nil) && (response.StatusCode >= http.StatusInternalServerError) {
+				span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+			}
+		}
+		tracing.EndSpan(span, err)
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"strings"
+)
+
+func uriTemplateSegmentParameterName(segment string) (nameId string, isRemainder bool, ok bool) {
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+		return "", false, false
+	}
+	nameId = segment[1 : len(segment)-1]
+	if strings.HasSuffix(nameId, "...") {
+		nameId = strings.TrimSuffix(nameId, "...")
+		isRemainder = true
+	}
+	if (nameId == "") || (nameId == "$") {
+		return "", false, false
+	}
+	return nameId, isRemainder, true
+}
+
+// URITemplateParameterNames follows the net/http.ServeMux pattern syntax, e.g. /v1/user/{uid} or /v1/file/{path...}
+func URITemplateParameterNames(uri string) (nameIds []string) {
+	nameIds = []string{}
+	for _, segment := range strings.Split(uri, "/") {
+		nameId, _, ok := uriTemplateSegmentParameterName(segment)
+		if ok {
+			nameIds = append(nameIds, nameId)
+		}
+	}
+	return nameIds
+}
+
+func URITemplateIsPattern(uri string) bool {
+	return len(URITemplateParameterNames(uri)) > 0
+}
+
+func MatchURITemplate(uriTemplate string, path string) (values map[string]string, ok bool) {
+	templateSegments := strings.Split(uriTemplate, "/")
+	pathSegments := strings.Split(path, "/")
+	values = map[string]string{}
+	for i, templateSegment := range templateSegments {
+		if templateSegment == "{$}" {
+			templateSegment = ""
+		}
+		nameId, isRemainder, isParameter := uriTemplateSegmentParameterName(templateSegment)
+		if isParameter && isRemainder {
+			if i > len(pathSegments) {
+				return nil, false
+			}
+			values[nameId] = strings.Join(pathSegments[i:], "/")
+			return values, true
+		}
+		if i >= len(pathSegments) {
+			return nil, false
+		}
+		if isParameter {
+			if pathSegments[i] == "" {
+				return nil, false
+			}
+			values[nameId] = pathSegments[i]
+			continue
+		}
+		if templateSegment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	if len(templateSegments) != len(pathSegments) {
+		return nil, false
+	}
+	return values, true
+}
+
+func splitPathParameters(uri string, parameters []DXAPIEndPointParameter) (pathParameters []DXAPIEndPointParameter, otherParameters []DXAPIEndPointParameter) {
+	pathParameterNameIds := URITemplateParameterNames(uri)
+	if len(pathParameterNameIds) == 0 {
+		return nil, parameters
+	}
+	isPathParameter := map[string]bool{}
+	for _, nameId := range pathParameterNameIds {
+		isPathParameter[nameId] = true
+	}
+	for _, p := range parameters {
+		if !isPathParameter[p.NameId] {
+			otherParameters = append(otherParameters, p)
+		}
+	}
+	for _, nameId := range pathParameterNameIds {
+		pathParameter := DXAPIEndPointParameter{NameId: nameId, Type: "string"}
+		for _, p := range parameters {
+			if p.NameId == nameId {
+				pathParameter = p
+				break
+			}
+		}
+		pathParameter.IsMustExist = true
+		pathParameter.IsNullable = false
+		pathParameters = append(pathParameters, pathParameter)
+	}
+	return pathParameters, otherParameters
+}
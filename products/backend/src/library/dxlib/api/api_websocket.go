@@ -0,0 +1,174 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/websocket/client"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+const (
+	DXAPIWSDefaultMaxMessageSize = 1 << 20
+	DXAPIWSPingIntervalSec       = 30
+	DXAPIWSPongWaitSec           = 60
+	DXAPIWSWriteWaitSec          = 10
+)
+
+// DXAPIWSConnection is registered to client.Manager by Id, the request id is not used since clients may reuse it across connections
+type DXAPIWSConnection struct {
+	Id         string
+	Owner      *DXAPIEndPointRequest
+	Conn       *websocket.Conn
+	writeMutex sync.Mutex
+	closeOnce  sync.Once
+	done       chan struct{}
+}
+
+func (c *DXAPIWSConnection) WriteJSON(v any) (err error) {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	_ = c.Conn.SetWriteDeadline(time.Now().Add(DXAPIWSWriteWaitSec * time.Second))
+	err = c.Conn.WriteJSON(v)
+	if err != nil {
+		return errors.Wrap(err, "error occured")
+	}
+	return nil
+}
+
+func (c *DXAPIWSConnection) ReadJSON(v any) (err error) {
+	err = c.Conn.ReadJSON(v)
+	if err != nil {
+		return errors.Wrap(err, "error occured")
+	}
+	return nil
+}
+
+func (c *DXAPIWSConnection) Subscribe(channel string) bool {
+	return client.Manager.Subscribe(c.Id, channel)
+}
+
+func (c *DXAPIWSConnection) Unsubscribe(channel string) bool {
+	return client.Manager.Unsubscribe(c.Id, channel)
+}
+
+func (c *DXAPIWSConnection) closeWithCode(code int, text string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.writeMutex.Lock()
+		_ = c.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(DXAPIWSWriteWaitSec*time.Second))
+		c.writeMutex.Unlock()
+		err = c.Conn.Close()
+	})
+	return err
+}
+
+func (c *DXAPIWSConnection) Close() error {
+	return c.closeWithCode(websocket.CloseNormalClosure, "")
+}
+
+func (c *DXAPIWSConnection) pingLoop() {
+	ticker := time.NewTicker(DXAPIWSPingIntervalSec * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.writeMutex.Lock()
+			err := c.Conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(DXAPIWSWriteWaitSec*time.Second))
+			c.writeMutex.Unlock()
+			if err != nil {
+				_ = c.Close()
+				return
+			}
+		}
+	}
+}
+
+// WSReceiveJSON reads the next message of the connection and decodes it as T
+func WSReceiveJSON[T any](c *DXAPIWSConnection) (v T, err error) {
+	err = c.ReadJSON(&v)
+	return v, err
+}
+
+func isWSClosedNormally(err error) bool {
+	return websocket.IsCloseError(errors.Cause(err), websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived)
+}
+
+// executeWSLoop upgrades the request, registers the connection to client.Manager and runs OnWSLoop until the connection ends
+func (aepr *DXAPIEndPointRequest) executeWSLoop() (err error) {
+	cors := endPointCORS(aepr.EndPoint.Owner, aepr.EndPoint)
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return (origin == "") || cors.IsOriginAllowed(origin)
+		},
+	}
+	// the upgrader writes the handshake response itself, the headers already set on the response writer are not sent
+	conn, err := upgrader.Upgrade(*aepr.ResponseWriter, aepr.Request, http.Header{DXAPIRequestIdHeader: []string{aepr.Id}})
+	aepr.ResponseHeaderSent = true
+	if err != nil {
+		aepr.ResponseStatusCode = http.StatusBadRequest
+		return errors.Wrap(err, "WS_UPGRADE_ERROR")
+	}
+	aepr.ResponseStatusCode = http.StatusSwitchingProtocols
+
+	maxMessageSize := int64(DXAPIWSDefaultMaxMessageSize)
+	if aepr.EndPoint.RequestMaxContentLength > 0 {
+		maxMessageSize = aepr.EndPoint.RequestMaxContentLength
+	}
+	conn.SetReadLimit(maxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(DXAPIWSPongWaitSec * time.Second))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(DXAPIWSPongWaitSec * time.Second))
+	})
+
+	c := &DXAPIWSConnection{
+		Id:    uuid.NewString(),
+		Owner: aepr,
+		Conn:  conn,
+		done:  make(chan struct{}),
+	}
+	aepr.WSConnection = c
+	client.Manager.Register(c.Id, aepr.CurrentUser.Id, aepr.CurrentUser.OrganizationId, c)
+	defer func() {
+		client.Manager.Unregister(c.Id)
+		if _, ok := asPanicError(err); ok {
+			_ = c.closeWithCode(websocket.CloseInternalServerErr, ErrorInternal.Code)
+			return
+		}
+		_ = c.Close()
+	}()
+	go c.pingLoop()
+
+	if aepr.EndPoint.OnWSLoop != nil {
+		err = aepr.callOnWSLoop()
+	} else {
+		for {
+			_, _, err = conn.ReadMessage()
+			if err != nil {
+				break
+			}
+		}
+	}
+	if (err != nil) && isWSClosedNormally(err) {
+		return nil
+	}
+	return err
+}
+
+// closeWSConnections is registered as HTTP server shutdown hook, hijacked connections are not closed by http.Server.Shutdown
+func (a *DXAPI) closeWSConnections() {
+	for _, wc := range client.Manager.Clients() {
+		c, ok := wc.Connection.(*DXAPIWSConnection)
+		if !ok || (c.Owner.EndPoint.Owner != a) {
+			continue
+		}
+		_ = c.closeWithCode(websocket.CloseGoingAway, "SERVER_SHUTDOWN")
+	}
+}
@@ -2,6 +2,7 @@ package oam
 
 import (
 	"github.com/donnyhardyanto/dxlib/api"
+	"github.com/donnyhardyanto/dxlib/health"
 	"github.com/pkg/errors"
 	"io"
 	"net/http"
@@ -41,3 +42,20 @@ func Ping(aepr *api.DXAPIEndPointRequest) (err error) {
 	aepr.WriteResponseAsJSON(http.StatusOK, nil, data)
 	return errors.Wrap(err, "error occured")
 }
+
+// Healthz is the liveness probe, it does not check any dependency
+func Healthz(aepr *api.DXAPIEndPointRequest) (err error) {
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, health.Manager.Liveness())
+	return nil
+}
+
+// Readyz is the readiness probe, it answers 503 when a critical dependency fails or the service is shutting down
+func Readyz(aepr *api.DXAPIEndPointRequest) (err error) {
+	isReady, result := health.Manager.Readiness(aepr.Context)
+	statusCode := http.StatusOK
+	if !isReady {
+		statusCode = http.StatusServiceUnavailable
+	}
+	aepr.WriteResponseAsJSON(statusCode, nil, result)
+	return nil
+}
@@ -0,0 +1,163 @@
+package oam
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	"github.com/donnyhardyanto/dxlib/health"
+	utilsHttp "github.com/donnyhardyanto/dxlib/utils/http"
+)
+
+// testShutdownAPI serves an API with the readiness probe and an endpoint that answers once release is closed or the request context is done
+func testShutdownAPI(t *testing.T) (a *api.DXAPI, server *httptest.Server, release chan struct{}) {
+	t.Helper()
+	am := &api.DXAPIManager{Context: context.Background(), APIs: map[string]*api.DXAPI{}}
+	a, err := am.NewAPI("test")
+	if err != nil {
+		t.Fatalf("NewAPI() err = %v", err)
+	}
+	release = make(chan struct{})
+	a.NewEndPoint("Readyz", "", "/readyz", http.MethodGet, api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, nil, Readyz, nil, nil, nil, nil, 0, "")
+	a.NewEndPoint("Slow", "", "/slow", http.MethodGet, api.EndPointTypeHTTPJSON, utilsHttp.ContentTypeApplicationJSON, nil,
+		func(aepr *api.DXAPIEndPointRequest) error {
+			select {
+			case <-release:
+				aepr.WriteResponseAsString(http.StatusOK, nil, "")
+				return nil
+			case <-aepr.Context.Done():
+				return aepr.Context.Err()
+			}
+		}, nil, nil, nil, nil, 0, "")
+	server = httptest.NewServer(a.NewHTTPHandler())
+	a.HTTPServer = server.Config
+	a.RuntimeIsActive = true
+	health.Manager.SetShuttingDown(false)
+	t.Cleanup(func() {
+		health.Manager.SetShuttingDown(false)
+		server.Close()
+		a.Cancel()
+	})
+	return a, server, release
+}
+
+func testReadyz(t *testing.T, url string) (statusCode int, isShuttingDown bool) {
+	t.Helper()
+	response, err := http.Get(url + "/readyz")
+	if err != nil {
+		t.Fatalf("Get() err = %v", err)
+	}
+	defer response.Body.Close()
+	b, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() err = %v", err)
+	}
+	var result map[string]any
+	err = json.Unmarshal(b, &result)
+	if err != nil {
+		t.Fatalf("Unmarshal(%s) err = %v", b, err)
+	}
+	isShuttingDown, _ = result["shutting_down"].(bool)
+	return response.StatusCode, isShuttingDown
+}
+
+// testSlowRequest starts a request to /slow and returns its status code on the channel, 0 when the request failed
+func testSlowRequest(t *testing.T, a *api.DXAPI, url string) (statusCode chan int) {
+	t.Helper()
+	statusCode = make(chan int, 1)
+	inFlight := a.RequestsInFlight.Load()
+	go func() {
+		response, err := http.Get(url + "/slow")
+		if err != nil {
+			statusCode <- 0
+			return
+		}
+		_ = response.Body.Close()
+		statusCode <- response.StatusCode
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for a.RequestsInFlight.Load() == inFlight {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for the slow request")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return statusCode
+}
+
+func TestReadyzDuringShutdown(t *testing.T) {
+	a, server, release := testShutdownAPI(t)
+
+	statusCode, isShuttingDown := testReadyz(t, server.URL)
+	if (statusCode != http.StatusOK) || isShuttingDown {
+		t.Fatalf("readyz before shutdown = %d, shutting_down %v, want 200 and false", statusCode, isShuttingDown)
+	}
+
+	slowStatusCode := testSlowRequest(t, a, server.URL)
+
+	// phase 1 of the shutdown: the probe fails while the server still answers
+	health.Manager.SetShuttingDown(true)
+	statusCode, isShuttingDown = testReadyz(t, server.URL)
+	if (statusCode != http.StatusServiceUnavailable) || !isShuttingDown {
+		t.Errorf("readyz while shutting down = %d, shutting_down %v, want 503 and true", statusCode, isShuttingDown)
+	}
+
+	// phase 2: the server drains, the in-flight request finishes within the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- a.StartShutdown(ctx)
+	}()
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("StartShutdown() returned %v with a request in flight", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, err := http.Get(server.URL + "/readyz"); err == nil {
+		t.Errorf("Get() of a new request while draining err = nil, want the connection refused")
+	}
+	close(release)
+	if got := <-slowStatusCode; got != http.StatusOK {
+		t.Errorf("status of the in-flight request = %d, want 200", got)
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("StartShutdown() err = %v", err)
+	}
+	if !health.Manager.IsShuttingDown() {
+		t.Errorf("IsShuttingDown() = false after StartShutdown")
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	a, server, _ := testShutdownAPI(t)
+	slowStatusCode := testSlowRequest(t, a, server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := a.StartShutdown(ctx)
+	if err == nil {
+		t.Errorf("StartShutdown() err = nil, want the deadline error")
+	}
+	if duration := time.Since(start); duration > time.Second {
+		t.Errorf("StartShutdown() took %s, want it to end at the deadline", duration)
+	}
+	// the request context is cancelled at the deadline so the handler ends, its connection is already closed
+	select {
+	case got := <-slowStatusCode:
+		if got == http.StatusOK {
+			t.Errorf("status of the request cut at the deadline = %d, want no response", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for the request cut at the deadline")
+	}
+	if !health.Manager.IsShuttingDown() {
+		t.Errorf("IsShuttingDown() = false after StartShutdown")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/donnyhardyanto/dxlib/vault"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/pkg/errors"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -15,10 +16,12 @@ import (
 	"github.com/donnyhardyanto/dxlib/configuration"
 	"github.com/donnyhardyanto/dxlib/core"
 	"github.com/donnyhardyanto/dxlib/database"
+	"github.com/donnyhardyanto/dxlib/health"
 	"github.com/donnyhardyanto/dxlib/log"
 	"github.com/donnyhardyanto/dxlib/redis"
 	"github.com/donnyhardyanto/dxlib/table"
 	"github.com/donnyhardyanto/dxlib/task"
+	"github.com/donnyhardyanto/dxlib/tracing"
 	"github.com/donnyhardyanto/dxlib/utils/os"
 )
 
@@ -43,6 +46,8 @@ type DXAppArgs struct {
 	Options  map[string]*DXAppArgOption
 }
 
+const DXAppDefaultShutdownTimeoutSec = 30
+
 type DXAppCallbackFunc func() (err error)
 type DXAppEvent func() (err error)
 
@@ -57,12 +62,16 @@ type DXApp struct {
 	RuntimeErrorGroupContext context.Context
 	LocalData                map[string]any
 
+	IsTracingExist       bool
 	IsRedisExist         bool
 	IsStorageExist       bool
 	IsObjectStorageExist bool
 	IsAPIExist           bool
 	IsTaskExist          bool
 
+	ShutdownTimeoutSec        int
+	ShutdownReadinessDelaySec int
+
 	DebugKey                     string
 	DebugValue                   string
 	OnDefine                     DXAppEvent
@@ -114,6 +123,13 @@ func (a *DXApp) loadConfiguration() (err error) {
 	if err != nil {
 		return errors.Wrap(err, "error occured")
 	}
+	_, a.IsTracingExist = configuration.Manager.Configurations["tracing"]
+	if a.IsTracingExist {
+		err = tracing.Manager.LoadFromConfiguration("tracing")
+		if err != nil {
+			return errors.Wrap(err, "error occured")
+		}
+	}
 	_, a.IsRedisExist = configuration.Manager.Configurations["redis"]
 	if a.IsRedisExist {
 		err = redis.Manager.LoadFromConfiguration("redis")
@@ -151,17 +167,29 @@ func (a *DXApp) start() (err error) {
 		return errors.Wrap(err, "error occured")
 	}
 
+	if a.IsTracingExist {
+		if tracing.Manager.ServiceName == "" {
+			tracing.Manager.ServiceName = a.NameId
+		}
+		err = tracing.Manager.Start(core.RootContext)
+		if err != nil {
+			return errors.Wrap(err, "error occured")
+		}
+	}
+
 	if a.IsRedisExist {
 		err = redis.Manager.ConnectAllAtStart()
 		if err != nil {
 			return errors.Wrap(err, "error occured")
 		}
+		redis.Manager.RegisterHealthChecks()
 	}
 	if a.IsStorageExist {
 		err = database.Manager.ConnectAllAtStart()
 		if err != nil {
 			return errors.Wrap(err, "error occured")
 		}
+		database.Manager.RegisterHealthChecks()
 		err := table.Manager.ConnectAll()
 		if err != nil {
 			return errors.Wrap(err, "error occured")
@@ -178,6 +206,7 @@ func (a *DXApp) start() (err error) {
 		if err != nil {
 			return errors.Wrap(err, "error occured")
 		}
+		object_storage.Manager.RegisterHealthChecks()
 	}
 
 	if a.OnDefineSetVariables != nil {
@@ -210,6 +239,7 @@ func (a *DXApp) start() (err error) {
 		if err != nil {
 			return errors.Wrap(err, "error occured")
 		}
+		task.Manager.RegisterHealthChecks()
 	}
 
 	if a.OnAfterConfigurationStartAll != nil {
@@ -222,6 +252,9 @@ func (a *DXApp) start() (err error) {
 	return nil
 }
 
+// Stop runs the shutdown sequence: the readiness probe fails first so load balancers drain the traffic, then the APIs stop accepting
+// connections and the in-flight requests and running tasks get until the shutdown deadline to finish. The connections are closed last,
+// in the reverse order they were opened
 func (a *DXApp) Stop() (err error) {
 	log.Log.Info("Stopping")
 	if a.OnStopping != nil {
@@ -230,14 +263,50 @@ func (a *DXApp) Stop() (err error) {
 			return errors.Wrap(err, "error occured")
 		}
 	}
+
+	log.Log.Info("Stopping phase 1/5: readiness set to not ready... start")
+	health.Manager.SetShuttingDown(true)
+	if a.IsAPIExist && (a.ShutdownReadinessDelaySec > 0) {
+		log.Log.Infof("Stopping phase 1/5: waiting %d sec for the load balancers", a.ShutdownReadinessDelaySec)
+		time.Sleep(time.Duration(a.ShutdownReadinessDelaySec) * time.Second)
+	}
+	log.Log.Info("Stopping phase 1/5: readiness set to not ready... done")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.ShutdownTimeoutSec)*time.Second)
+	defer cancel()
+
+	log.Log.Infof("Stopping phase 2/5: draining in-flight requests, deadline %d sec... start", a.ShutdownTimeoutSec)
+	if a.IsAPIExist {
+		err = api.Manager.StopAll(ctx)
+		if err != nil {
+			log.Log.Warnf("Stopping phase 2/5: %s", err.Error())
+		}
+	}
+	log.Log.Info("Stopping phase 2/5: draining in-flight requests... done")
+
+	log.Log.Info("Stopping phase 3/5: waiting for running tasks... start")
 	if a.IsTaskExist {
-		err = task.Manager.StopAll()
+		err = task.Manager.StopAll(ctx)
+		if err != nil {
+			log.Log.Warnf("Stopping phase 3/5: %s", err.Error())
+		}
+	}
+	log.Log.Info("Stopping phase 3/5: waiting for running tasks... done")
+
+	log.Log.Info("Stopping phase 4/5: stopping the runtime goroutines... start")
+	core.RootContextCancel()
+	runtimeErr := a.waitRuntime(ctx)
+	log.Log.Info("Stopping phase 4/5: stopping the runtime goroutines... done")
+
+	log.Log.Info("Stopping phase 5/5: closing connections... start")
+	if a.IsObjectStorageExist {
+		err = object_storage.Manager.DisconnectAll()
 		if err != nil {
 			return errors.Wrap(err, "error occured")
 		}
 	}
-	if a.IsAPIExist {
-		err = api.Manager.StopAll()
+	if a.IsStorageExist {
+		err = database.Manager.DisconnectAll()
 		if err != nil {
 			return errors.Wrap(err, "error occured")
 		}
@@ -248,20 +317,36 @@ func (a *DXApp) Stop() (err error) {
 			return errors.Wrap(err, "error occured")
 		}
 	}
-	if a.IsStorageExist {
-		err = database.Manager.DisconnectAll()
+	if a.IsTracingExist {
+		err = tracing.Manager.Shutdown()
 		if err != nil {
 			return errors.Wrap(err, "error occured")
 		}
 	}
-	if a.IsObjectStorageExist {
-		err = object_storage.Manager.DisconnectAll()
+	log.Log.Info("Stopping phase 5/5: closing connections... done")
+	log.Log.Info("Stopped")
+	return runtimeErr
+}
+
+// waitRuntime waits for the goroutines of RuntimeErrorGroup until ctx is done, the error is the first one returned by them
+func (a *DXApp) waitRuntime(ctx context.Context) (err error) {
+	if a.RuntimeErrorGroup == nil {
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- a.RuntimeErrorGroup.Wait()
+	}()
+	select {
+	case err = <-done:
 		if err != nil {
 			return errors.Wrap(err, "error occured")
 		}
+		return nil
+	case <-ctx.Done():
+		log.Log.Warn("Stopping: deadline reached, some runtime goroutines are still running")
+		return nil
 	}
-	log.Log.Info("Stopped")
-	return nil
 }
 
 func (a *DXApp) execute() (err error) {
@@ -276,6 +361,9 @@ func (a *DXApp) execute() (err error) {
 			err2 := a.Stop()
 			if err2 != nil {
 				log.Log.Infof("Error in Stopping.Stop(): (%v)", err2.Error())
+				if err == nil {
+					err = err2
+				}
 			}
 
 			//log.Log.Info("Stopped")
@@ -293,11 +381,8 @@ func (a *DXApp) execute() (err error) {
 
 	if a.IsLoop {
 		log.Log.Info("Waiting...")
-		err = a.RuntimeErrorGroup.Wait()
-		if err != nil {
-			log.Log.Infof("Exit reason: %v", err.Error())
-			return errors.Wrap(err, "error occured")
-		}
+		<-a.RuntimeErrorGroupContext.Done()
+		log.Log.Infof("Exit reason: %v", context.Cause(a.RuntimeErrorGroupContext))
 	}
 	return nil
 }
@@ -343,6 +428,7 @@ func init() {
 			Commands: map[string]*DXAppArgCommand{},
 			Options:  map[string]*DXAppArgOption{},
 		},
-		LocalData: map[string]any{},
+		LocalData:          map[string]any{},
+		ShutdownTimeoutSec: DXAppDefaultShutdownTimeoutSec,
 	}
 }
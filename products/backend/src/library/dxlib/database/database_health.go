@@ -0,0 +1,26 @@
+package database
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/donnyhardyanto/dxlib/health"
+)
+
+// RegisterHealthChecks adds a ping readiness check for every database, databases that must be connected are critical
+func (dm *DXDatabaseManager) RegisterHealthChecks() {
+	for _, d := range dm.Databases {
+		d := d
+		health.Manager.RegisterCheck("database."+d.NameId, d.MustConnected, 0, func(ctx context.Context) (err error) {
+			if (d.Connection == nil) || !d.Connected {
+				return errors.Errorf("DATABASE_NOT_CONNECTED:%s", d.NameId)
+			}
+			err = d.Connection.PingContext(ctx)
+			if err != nil {
+				return errors.Wrap(err, "error occured")
+			}
+			return nil
+		})
+	}
+}
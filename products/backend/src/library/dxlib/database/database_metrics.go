@@ -0,0 +1,25 @@
+package database
+
+import (
+	"github.com/donnyhardyanto/dxlib/metrics"
+)
+
+// databasePools reports the connected databases of the manager to the shared dxlib_database_* metrics
+func databasePools() (pools []metrics.DXDatabasePool) {
+	for _, d := range Manager.Databases {
+		if d.Connection == nil {
+			continue
+		}
+		pools = append(pools, metrics.DXDatabasePool{
+			NameId:                       d.NameId,
+			Stats:                        d.Connection.Stats(),
+			ConcurrencySemaphoreInUse:    len(d.ConcurrencySemaphore),
+			ConcurrencySemaphoreCapacity: cap(d.ConcurrencySemaphore),
+		})
+	}
+	return pools
+}
+
+func init() {
+	metrics.Manager.RegisterDatabasePoolMetrics(databasePools)
+}
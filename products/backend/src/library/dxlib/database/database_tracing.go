@@ -0,0 +1,24 @@
+package database
+
+import (
+	"context"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/tracing"
+)
+
+// StartSpan starts the client span of an operation on a table as a child of the span carried by the log, a nil log starts a root span
+func StartSpan(l *log.DXLog, driverName string, operationName string, tableName string) (context.Context, trace.Span) {
+	var ctx context.Context
+	if l != nil {
+		ctx = l.Context
+	}
+	return tracing.StartSpan(ctx, operationName+" "+tableName, trace.SpanKindClient,
+		tracing.DBSystem(driverName),
+		semconv.DBOperationName(operationName),
+		semconv.DBCollectionName(tableName),
+	)
+}
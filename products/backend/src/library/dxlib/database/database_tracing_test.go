@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/tracing"
+)
+
+func TestStartSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracing.Manager.StartWithExporter(exporter)
+	defer func() {
+		_ = tracing.Manager.Shutdown()
+	}()
+
+	requestCtx, requestSpan := tracing.StartSpan(context.Background(), "GET /v1/user/read", trace.SpanKindServer)
+	defer requestSpan.End()
+
+	tests := []struct {
+		name          string
+		l             *log.DXLog
+		operationName string
+		tableName     string
+		wantName      string
+		wantParent    trace.SpanContext
+	}{
+		{
+			name:          "child of the request span carried by the log",
+			l:             &log.DXLog{Context: requestCtx},
+			operationName: "SELECT",
+			tableName:     "user_management.v_user",
+			wantName:      "SELECT user_management.v_user",
+			wantParent:    requestSpan.SpanContext(),
+		},
+		{
+			name:          "nil log starts a root span",
+			l:             nil,
+			operationName: "INSERT",
+			tableName:     "user_management.user",
+			wantName:      "INSERT user_management.user",
+		},
+		{
+			name:          "log without context starts a root span",
+			l:             &log.DXLog{},
+			operationName: "DELETE",
+			tableName:     "user_management.user_session",
+			wantName:      "DELETE user_management.user_session",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			_, span := StartSpan(tt.l, "postgres", tt.operationName, tt.tableName)
+			span.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			s := spans[0]
+			if s.Name != tt.wantName {
+				t.Errorf("name = %q, want %q", s.Name, tt.wantName)
+			}
+			if s.SpanKind != trace.SpanKindClient {
+				t.Errorf("kind = %v, want %v", s.SpanKind, trace.SpanKindClient)
+			}
+			if s.Parent.SpanID() != tt.wantParent.SpanID() {
+				t.Errorf("parent span id = %s, want %s", s.Parent.SpanID(), tt.wantParent.SpanID())
+			}
+			if tt.wantParent.IsValid() && (s.SpanContext.TraceID() != tt.wantParent.TraceID()) {
+				t.Errorf("trace id = %s, want %s", s.SpanContext.TraceID(), tt.wantParent.TraceID())
+			}
+			wantAttributes := []attribute.KeyValue{
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperationName(tt.operationName),
+				semconv.DBCollectionName(tt.tableName),
+			}
+			for _, want := range wantAttributes {
+				found := false
+				for _, got := range s.Attributes {
+					if got == want {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("attribute %s=%s not found", want.Key, want.Value.Emit())
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/donnyhardyanto/dxlib/database/protected/db"
 	"github.com/donnyhardyanto/dxlib/database/protected/dbtx"
 	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/tracing"
 	"github.com/donnyhardyanto/dxlib/utils"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
@@ -65,21 +66,41 @@ func (dtx *DXDatabaseTx) Finish(log *log.DXLog, err error) {
 func (dtx *DXDatabaseTx) Select(tableName string, fieldTypeMapping db.FieldTypeMapping, fieldNames []string, whereAndFieldNameValues utils.JSON, joinSQLPart any,
 	orderbyFieldNameDirections db.FieldsOrderBy, limit any, forUpdatePart any) (rowsInfo *db.RowsInfo, r []utils.JSON, err error) {
 
-	return dbtx.TxSelect(dtx.Log, false, dtx.Tx, tableName, fieldTypeMapping, fieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections, limit, forUpdatePart)
+	_, span := StartSpan(dtx.Log, dtx.DriverName(), "SELECT", tableName)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+	rowsInfo, r, err = dbtx.TxSelect(dtx.Log, false, dtx.Tx, tableName, fieldTypeMapping, fieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections, limit, forUpdatePart)
+	return rowsInfo, r, err
 }
 
 func (dtx *DXDatabaseTx) SelectOne(tableName string, fieldTypeMapping db.FieldTypeMapping, fieldNames []string, whereAndFieldNameValues utils.JSON, joinSQLPart any,
 	orderbyFieldNameDirections db.FieldsOrderBy, forUpdatePart any) (rowsInfo *db.RowsInfo, r utils.JSON, err error) {
 
-	return dbtx.TxSelectOne(dtx.Log, false, dtx.Tx, tableName, fieldTypeMapping, fieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections, forUpdatePart)
+	_, span := StartSpan(dtx.Log, dtx.DriverName(), "SELECT", tableName)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+	rowsInfo, r, err = dbtx.TxSelectOne(dtx.Log, false, dtx.Tx, tableName, fieldTypeMapping, fieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections, forUpdatePart)
+	return rowsInfo, r, err
 }
 
 func (dtx *DXDatabaseTx) ShouldSelectOne(tableName string, fieldTypeMapping db.FieldTypeMapping, fieldNames []string, whereAndFieldNameValues utils.JSON, joinSQLPart any,
 	orderbyFieldNameDirections db.FieldsOrderBy, forUpdatePart any) (rowsInfo *db.RowsInfo, r utils.JSON, err error) {
-	return dbtx.TxShouldSelectOne(dtx.Log, nil, false, dtx.Tx, tableName, fieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections, forUpdatePart)
+	_, span := StartSpan(dtx.Log, dtx.DriverName(), "SELECT", tableName)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+	rowsInfo, r, err = dbtx.TxShouldSelectOne(dtx.Log, nil, false, dtx.Tx, tableName, fieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections, forUpdatePart)
+	return rowsInfo, r, err
 }
 func (dtx *DXDatabaseTx) Insert(tableName string, keyValues utils.JSON) (id int64, err error) {
-	return dbtx.TxInsert(dtx.Log, false, dtx.Tx, tableName, keyValues)
+	_, span := StartSpan(dtx.Log, dtx.DriverName(), "INSERT", tableName)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+	id, err = dbtx.TxInsert(dtx.Log, false, dtx.Tx, tableName, keyValues)
+	return id, err
 }
 
 /*func (dtx *DXDatabaseTx) UpdateOne(tableName string, setKeyValues utils.JSON, whereKeyValues utils.JSON) (result sql.Result, err error) {
@@ -87,7 +108,12 @@ func (dtx *DXDatabaseTx) Insert(tableName string, keyValues utils.JSON) (id int6
 }*/
 
 func (dtx *DXDatabaseTx) Update(tableName string, setKeyValues utils.JSON, whereKeyValues utils.JSON) (result sql.Result, err error) {
-	return dbtx.TxUpdate(dtx.Log, false, dtx.Tx, tableName, setKeyValues, whereKeyValues)
+	_, span := StartSpan(dtx.Log, dtx.DriverName(), "UPDATE", tableName)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+	result, err = dbtx.TxUpdate(dtx.Log, false, dtx.Tx, tableName, setKeyValues, whereKeyValues)
+	return result, err
 }
 
 /*
@@ -98,5 +124,10 @@ func (dtx *DXDatabaseTx) Update(tableName string, setKeyValues utils.JSON, where
 	}
 */
 func (dtx *DXDatabaseTx) Delete(tableName string, whereKeyValues utils.JSON) (result sql.Result, err error) {
-	return dbtx.TxDelete(dtx.Log, false, dtx.Tx, tableName, whereKeyValues)
+	_, span := StartSpan(dtx.Log, dtx.DriverName(), "DELETE", tableName)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+	result, err = dbtx.TxDelete(dtx.Log, false, dtx.Tx, tableName, whereKeyValues)
+	return result, err
 }
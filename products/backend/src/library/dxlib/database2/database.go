@@ -30,7 +30,8 @@ type DXDatabaseEventFunc func(dm *DXDatabase, err error)
 
 type DXDatabaseTx struct {
 	*sqlx.Tx
-	Log *log.DXLog
+	Log     *log.DXLog
+	Context context.Context
 }
 type DXDatabaseTxCallback func(dtx *DXDatabaseTx) (err error)
 
@@ -65,6 +66,14 @@ type DXDatabase struct {
 	OnCannotConnect              DXDatabaseEventFunc
 	CreateScriptFiles            []string
 	ConcurrencySemaphore         chan struct{} // Adjust number based on your DB max_connections
+	Context                      context.Context
+}
+
+// WithContext returns a copy sharing the connection whose statements run with ctx, usually aepr.Context so the statements join the request trace
+func (d *DXDatabase) WithContext(ctx context.Context) *DXDatabase {
+	c := *d
+	c.Context = ctx
+	return &c
 }
 
 func (d *DXDatabase) EnsureConnection() (err error) {
@@ -92,20 +101,21 @@ func (d *DXDatabase) TransactionBegin(isolationLevel DXDatabaseTxIsolationLevel)
 	driverName := d.Connection.DriverName()
 	switch driverName {
 	case "oracle":
-		tx, err := d.Connection.BeginTxx(context.Background(), &sql.TxOptions{
+		tx, err := d.Connection.BeginTxx(d.Context, &sql.TxOptions{
 			ReadOnly: false,
 		})
 		if err != nil {
 			return nil, err
 		}
 		dtx = &DXDatabaseTx{
-			Tx:  tx,
-			Log: &log.Log,
+			Tx:      tx,
+			Log:     &log.Log,
+			Context: d.Context,
 		}
 		return dtx, nil
 	}
 
-	tx, err := d.Connection.BeginTxx(context.Background(), &sql.TxOptions{
+	tx, err := d.Connection.BeginTxx(d.Context, &sql.TxOptions{
 		Isolation: isolationLevel,
 		ReadOnly:  false,
 	})
@@ -113,8 +123,9 @@ func (d *DXDatabase) TransactionBegin(isolationLevel DXDatabaseTxIsolationLevel)
 		return nil, err
 	}
 	dtx = &DXDatabaseTx{
-		Tx:  tx,
-		Log: &log.Log,
+		Tx:      tx,
+		Log:     &log.Log,
+		Context: d.Context,
 	}
 	return dtx, nil
 }
@@ -439,7 +450,7 @@ func (d *DXDatabase) Tx(log *log.DXLog, isolationLevel sql.IsolationLevel, callb
 	driverName := d.Connection.DriverName()
 	switch driverName {
 	case "oracle":
-		tx, err := d.TransactionBegin(isolationLevel)
+		tx, err := d.WithContext(log.Context).TransactionBegin(isolationLevel)
 		if err != nil {
 			return errors.Wrap(err, "error occured")
 		}
@@ -473,8 +484,9 @@ func (d *DXDatabase) Tx(log *log.DXLog, isolationLevel sql.IsolationLevel, callb
 		return errors.Wrap(err, "error occured")
 	}
 	dtx := &DXDatabaseTx{
-		Tx:  tx,
-		Log: log,
+		Tx:      tx,
+		Log:     log,
+		Context: log.Context,
 	}
 	err = callback(dtx)
 	if err != nil {
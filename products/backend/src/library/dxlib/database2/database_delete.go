@@ -15,7 +15,7 @@ func (d *DXDatabase) Delete(tableName string, whereAndFieldNameValues utils.JSON
 	}
 
 	for tryCount := 0; tryCount < 4; tryCount++ {
-		result, returningFieldValues, err = db.Delete(d.Connection, tableName, whereAndFieldNameValues, returningFieldNames)
+		result, returningFieldValues, err = db.Delete(d.Context, d.Connection, tableName, whereAndFieldNameValues, returningFieldNames)
 		if err == nil {
 			return result, returningFieldValues, nil
 		}
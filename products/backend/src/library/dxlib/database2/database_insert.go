@@ -14,7 +14,7 @@ func (d *DXDatabase) Insert(tableName string, setFieldValues utils.JSON, returni
 	}
 
 	for tryCount := 0; tryCount < 4; tryCount++ {
-		result, returningFieldValues, err = db.Insert(d.Connection, tableName, setFieldValues, returningFieldNames)
+		result, returningFieldValues, err = db.Insert(d.Context, d.Connection, tableName, setFieldValues, returningFieldNames)
 		if err == nil {
 			return result, returningFieldValues, nil
 		}
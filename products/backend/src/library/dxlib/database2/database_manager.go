@@ -1,7 +1,9 @@
 package database2
 
 import (
+	"context"
 	dxlibv3Configuration "github.com/donnyhardyanto/dxlib/configuration"
+	"github.com/donnyhardyanto/dxlib/core"
 	"github.com/donnyhardyanto/dxlib/log"
 	"github.com/donnyhardyanto/dxlib/utils"
 	"github.com/pkg/errors"
@@ -25,6 +27,7 @@ func (dm *DXDatabaseManager) NewDatabase(nameId string, isConnectAtStart, mustBe
 		MustConnected:        mustBeConnected,
 		Connected:            false,
 		ConcurrencySemaphore: dbSemaphore,
+		Context:              context.WithoutCancel(core.RootContext),
 	}
 	dm.Databases[nameId] = &d
 	return &d
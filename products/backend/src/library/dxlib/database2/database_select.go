@@ -17,7 +17,7 @@ func (d *DXDatabase) Select(tableName string, fieldTypeMapping utils2.FieldTypeM
 	}
 
 	for tryCount := 0; tryCount < 4; tryCount++ {
-		rowsInfo, resultData, err = db.Select(d.Connection, fieldTypeMapping, tableName, showFieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections, limit, offset, forUpdatePart)
+		rowsInfo, resultData, err = db.Select(d.Context, d.Connection, fieldTypeMapping, tableName, showFieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections, limit, offset, forUpdatePart)
 		if err == nil {
 			return rowsInfo, resultData, nil
 		}
@@ -66,7 +66,7 @@ func (d *DXDatabase) Count(tableName string, whereAndFieldNameValues utils.JSON,
 	}
 
 	for tryCount := 0; tryCount < 4; tryCount++ {
-		count, err = db.Count(d.Connection, tableName, whereAndFieldNameValues, joinSQLPart, nil, "", "")
+		count, err = db.Count(d.Context, d.Connection, tableName, whereAndFieldNameValues, joinSQLPart, nil, "", "")
 		if err == nil {
 			return count, nil
 		}
@@ -7,7 +7,7 @@ import (
 )
 
 func (dtx *DXDatabaseTx) TxDelete(tableName string, whereAndFieldNameValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues []utils.JSON, err error) {
-	result, returningFieldValues, err = db.TxDelete(dtx.Tx, tableName, whereAndFieldNameValues, returningFieldNames)
+	result, returningFieldValues, err = db.TxDelete(dtx.Context, dtx.Tx, tableName, whereAndFieldNameValues, returningFieldNames)
 	if err == nil {
 		return nil, nil, err
 	}
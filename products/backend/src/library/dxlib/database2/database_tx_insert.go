@@ -8,7 +8,7 @@ import (
 
 func (dtx *DXDatabaseTx) Insert(tableName string, setFieldValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues utils.JSON, err error) {
 
-	result, returningFieldValues, err = db.TxInsert(dtx.Tx, tableName, setFieldValues, returningFieldNames)
+	result, returningFieldValues, err = db.TxInsert(dtx.Context, dtx.Tx, tableName, setFieldValues, returningFieldNames)
 	if err != nil {
 		return nil, nil, err
 	}
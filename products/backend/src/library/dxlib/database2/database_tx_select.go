@@ -10,7 +10,7 @@ import (
 func (dtx *DXDatabaseTx) Select(tableName string, fieldTypeMapping utils2.FieldTypeMapping, showFieldNames []string, whereAndFieldNameValues utils.JSON, joinSQLPart any, orderByFieldNameDirections utils2.FieldsOrderBy,
 	limit any, offset any, forUpdatePart any) (rowsInfo *db.RowsInfo, resultData []utils.JSON, err error) {
 
-	rowsInfo, resultData, err = db.TxSelect(dtx.Tx, fieldTypeMapping, tableName, showFieldNames, whereAndFieldNameValues, joinSQLPart, orderByFieldNameDirections, limit, offset, forUpdatePart)
+	rowsInfo, resultData, err = db.TxSelect(dtx.Context, dtx.Tx, fieldTypeMapping, tableName, showFieldNames, whereAndFieldNameValues, joinSQLPart, orderByFieldNameDirections, limit, offset, forUpdatePart)
 	if err != nil {
 		return rowsInfo, resultData, err
 	}
@@ -20,7 +20,7 @@ func (dtx *DXDatabaseTx) Select(tableName string, fieldTypeMapping utils2.FieldT
 
 func (dtx *DXDatabaseTx) Count(tableName string, whereAndFieldNameValues utils.JSON, joinSQLPart any) (count int64, err error) {
 
-	count, err = db.TxCount(dtx.Tx, tableName, whereAndFieldNameValues, joinSQLPart, nil, "", "")
+	count, err = db.TxCount(dtx.Context, dtx.Tx, tableName, whereAndFieldNameValues, joinSQLPart, nil, "", "")
 	if err != nil {
 		return 0, err
 	}
@@ -7,7 +7,7 @@ import (
 )
 
 func (dtx *DXDatabaseTx) Update(tableName string, setFieldValues utils.JSON, whereAndFieldNameValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues []utils.JSON, err error) {
-	result, returningFieldValues, err = db.TxUpdate(dtx.Tx, tableName, setFieldValues, whereAndFieldNameValues, returningFieldNames)
+	result, returningFieldValues, err = db.TxUpdate(dtx.Context, dtx.Tx, tableName, setFieldValues, whereAndFieldNameValues, returningFieldNames)
 	if err == nil {
 		return nil, nil, err
 	}
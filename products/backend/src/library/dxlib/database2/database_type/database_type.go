@@ -64,3 +64,9 @@ func StringToDXDatabaseType(v string) DXDatabaseType {
 		return UnknownDatabaseType
 	}
 }
+
+// RowsInfo describes the columns of a query result
+type RowsInfo struct {
+	Columns []string
+	//	ColumnTypes []*sql.ColumnType
+}
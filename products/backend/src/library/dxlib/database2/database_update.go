@@ -15,7 +15,7 @@ func (d *DXDatabase) Update(tableName string, setFieldValues utils.JSON, whereAn
 	}
 
 	for tryCount := 0; tryCount < 4; tryCount++ {
-		result, returningFieldValues, err = db.Update(d.Connection, tableName, setFieldValues, whereAndFieldNameValues, returningFieldNames)
+		result, returningFieldValues, err = db.Update(d.Context, d.Connection, tableName, setFieldValues, whereAndFieldNameValues, returningFieldNames)
 		if err == nil {
 			return nil, nil, err
 		}
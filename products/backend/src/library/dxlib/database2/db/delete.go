@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	utils2 "github.com/donnyhardyanto/dxlib/database2/db/utils"
 	"strings"
@@ -32,7 +33,7 @@ import (
 //   - SQL Server: OUTPUT clause
 //   - Oracle: RETURNING INTO clause
 //   - MySQL: Not supported for DELETE (will return errors.Wrap(err, "error occured")or if requested)
-func Delete(db *sqlx.DB, tableName string, whereAndFieldNameValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues []utils.JSON, err error) {
+func Delete(ctx context.Context, db *sqlx.DB, tableName string, whereAndFieldNameValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues []utils.JSON, err error) {
 	// Basic input validation
 	if db == nil {
 		return nil, nil, errors.New("database connection is nil")
@@ -95,7 +96,7 @@ func Delete(db *sqlx.DB, tableName string, whereAndFieldNameValues utils.JSON, r
 
 		if len(returningFieldNames) == 0 {
 			// Simple delete without returning
-			result, err := raw.Exec(db, baseSQL, whereAndFieldNameValues)
+			result, err := raw.Exec(ctx, db, baseSQL, whereAndFieldNameValues)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing delete")
 			}
@@ -110,7 +111,7 @@ func Delete(db *sqlx.DB, tableName string, whereAndFieldNameValues utils.JSON, r
 			returningClause,
 		}, " ")
 
-		_, rows, err := raw.QueryRows(db, nil, sqlStatement, whereAndFieldNameValues)
+		_, rows, err := raw.QueryRows(ctx, db, nil, sqlStatement, whereAndFieldNameValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing delete with RETURNING clause")
 		}
@@ -126,7 +127,7 @@ func Delete(db *sqlx.DB, tableName string, whereAndFieldNameValues utils.JSON, r
 				tableName,
 				effectiveWhere,
 			}, " ")
-			result, err := raw.Exec(db, baseSQL, whereAndFieldNameValues)
+			result, err := raw.Exec(ctx, db, baseSQL, whereAndFieldNameValues)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing delete")
 			}
@@ -148,7 +149,7 @@ func Delete(db *sqlx.DB, tableName string, whereAndFieldNameValues utils.JSON, r
 			effectiveWhere,
 		}, " ")
 
-		_, rows, err := raw.QueryRows(db, nil, sqlStatement, whereAndFieldNameValues)
+		_, rows, err := raw.QueryRows(ctx, db, nil, sqlStatement, whereAndFieldNameValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing delete with OUTPUT clause")
 		}
@@ -165,7 +166,7 @@ func Delete(db *sqlx.DB, tableName string, whereAndFieldNameValues utils.JSON, r
 
 		if len(returningFieldNames) == 0 {
 			// Simple delete without returning
-			result, err := raw.Exec(db, baseSQL, whereAndFieldNameValues)
+			result, err := raw.Exec(ctx, db, baseSQL, whereAndFieldNameValues)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing oracle delete")
 			}
@@ -185,7 +186,7 @@ func Delete(db *sqlx.DB, tableName string, whereAndFieldNameValues utils.JSON, r
 		}, " ")
 
 		// Execute the delete
-		result, err := raw.Exec(db, baseSQL, whereAndFieldNameValues)
+		result, err := raw.Exec(ctx, db, baseSQL, whereAndFieldNameValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing mysql delete")
 		}
@@ -205,7 +206,7 @@ func Delete(db *sqlx.DB, tableName string, whereAndFieldNameValues utils.JSON, r
 }
 
 // TxDelete executes an SQL DELETE statement within a transaction with support for returning values
-func TxDelete(tx *sqlx.Tx, tableName string, whereAndFieldNameValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues []utils.JSON, err error) {
+func TxDelete(ctx context.Context, tx *sqlx.Tx, tableName string, whereAndFieldNameValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues []utils.JSON, err error) {
 	// Basic input validation
 	if tx == nil {
 		return nil, nil, errors.New("database transaction connection is nil")
@@ -268,7 +269,7 @@ func TxDelete(tx *sqlx.Tx, tableName string, whereAndFieldNameValues utils.JSON,
 
 		if len(returningFieldNames) == 0 {
 			// Simple delete without returning
-			result, err = raw.TxExec(tx, baseSQL, whereAndFieldNameValues)
+			result, err = raw.TxExec(ctx, tx, baseSQL, whereAndFieldNameValues)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing delete")
 			}
@@ -283,7 +284,7 @@ func TxDelete(tx *sqlx.Tx, tableName string, whereAndFieldNameValues utils.JSON,
 			returningClause,
 		}, " ")
 
-		_, rows, err := raw.TxQueryRows(tx, nil, sqlStatement, whereAndFieldNameValues)
+		_, rows, err := raw.TxQueryRows(ctx, tx, nil, sqlStatement, whereAndFieldNameValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing delete with RETURNING clause")
 		}
@@ -299,7 +300,7 @@ func TxDelete(tx *sqlx.Tx, tableName string, whereAndFieldNameValues utils.JSON,
 				tableName,
 				effectiveWhere,
 			}, " ")
-			result, err := raw.TxExec(tx, baseSQL, whereAndFieldNameValues)
+			result, err := raw.TxExec(ctx, tx, baseSQL, whereAndFieldNameValues)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing delete")
 			}
@@ -321,7 +322,7 @@ func TxDelete(tx *sqlx.Tx, tableName string, whereAndFieldNameValues utils.JSON,
 			effectiveWhere,
 		}, " ")
 
-		_, rows, err := raw.TxQueryRows(tx, nil, sqlStatement, whereAndFieldNameValues)
+		_, rows, err := raw.TxQueryRows(ctx, tx, nil, sqlStatement, whereAndFieldNameValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing delete with OUTPUT clause")
 		}
@@ -338,7 +339,7 @@ func TxDelete(tx *sqlx.Tx, tableName string, whereAndFieldNameValues utils.JSON,
 
 		if len(returningFieldNames) == 0 {
 			// Simple delete without returning
-			result, err = raw.TxExec(tx, baseSQL, whereAndFieldNameValues)
+			result, err = raw.TxExec(ctx, tx, baseSQL, whereAndFieldNameValues)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing oracle delete")
 			}
@@ -358,7 +359,7 @@ func TxDelete(tx *sqlx.Tx, tableName string, whereAndFieldNameValues utils.JSON,
 		}, " ")
 
 		// Execute the delete
-		result, err := raw.TxExec(tx, baseSQL, whereAndFieldNameValues)
+		result, err := raw.TxExec(ctx, tx, baseSQL, whereAndFieldNameValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing mysql delete")
 		}
@@ -1,12 +1,14 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/donnyhardyanto/dxlib/database2/database_type"
 	"github.com/donnyhardyanto/dxlib/database2/db/raw"
 	"github.com/donnyhardyanto/dxlib/database2/sqlchecker"
 	"github.com/donnyhardyanto/dxlib/database2/utils/sql_expression"
+	"github.com/donnyhardyanto/dxlib/tracing"
 	"github.com/donnyhardyanto/dxlib/utils"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
@@ -47,7 +49,7 @@ func SQLPartInsertFieldNamesFieldValues(insertKeyValues utils.JSON, driverName s
 // Returns:
 //   - returningFieldValues: Map of field names to their values after insert
 //   - err: Error if any occurred
-func Insert(db *sqlx.DB, tableName string, setFieldValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues utils.JSON, err error) {
+func Insert(ctx context.Context, db *sqlx.DB, tableName string, setFieldValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues utils.JSON, err error) {
 	// Basic input validation
 	if db == nil {
 		return nil, nil, errors.New("database connection is nil")
@@ -96,7 +98,7 @@ func Insert(db *sqlx.DB, tableName string, setFieldValues utils.JSON, returningF
 
 	// If no returning keys requested, simply execute the insert
 	if returningFieldNames == nil || len(returningFieldNames) == 0 {
-		result, err := raw.Exec(db, baseSQL, setFieldValues)
+		result, err := raw.Exec(ctx, db, baseSQL, setFieldValues)
 		return result, returningFieldValues, err
 	}
 
@@ -105,7 +107,7 @@ func Insert(db *sqlx.DB, tableName string, setFieldValues utils.JSON, returningF
 	case "postgres", "mariadb":
 		// Both PostgreSQL and MariaDB 10.5.0+ support RETURNING clause with the same syntax
 		sqlStatement := fmt.Sprintf("%s RETURNING %s", baseSQL, strings.Join(returningFieldNames, ", "))
-		_, rows, err := raw.QueryRows(db, nil, sqlStatement, setFieldValues)
+		_, rows, err := raw.QueryRows(ctx, db, nil, sqlStatement, setFieldValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing insert with RETURNING clause")
 		}
@@ -133,7 +135,7 @@ func Insert(db *sqlx.DB, tableName string, setFieldValues utils.JSON, returningF
 			fmt.Sprintf("(%s)", fieldValues),
 		}, " ")
 
-		_, rows, err := raw.QueryRows(db, nil, sqlStatement, setFieldValues)
+		_, rows, err := raw.QueryRows(ctx, db, nil, sqlStatement, setFieldValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing insert with OUTPUT clause")
 		}
@@ -172,7 +174,9 @@ func Insert(db *sqlx.DB, tableName string, setFieldValues utils.JSON, returningF
 			strings.Join(returningIntoFields, ", "))
 
 		// Execute directly for Oracle with output parameters
-		result, err = db.Exec(sqlStatement, namedArgs...)
+		spanCtx, span := raw.StartSpan(ctx, db.DriverName(), sqlStatement)
+		result, err = db.ExecContext(spanCtx, sqlStatement, namedArgs...)
+		tracing.EndSpan(span, err)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing oracle insert with RETURNING INTO")
 		}
@@ -200,7 +204,7 @@ func Insert(db *sqlx.DB, tableName string, setFieldValues utils.JSON, returningF
 
 	case "mysql":
 		// MySQL doesn't support RETURNING, so we need to do a separate query
-		result, err := raw.Exec(db, baseSQL, setFieldValues)
+		result, err := raw.Exec(ctx, db, baseSQL, setFieldValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing mysql insert")
 		}
@@ -263,7 +267,7 @@ func Insert(db *sqlx.DB, tableName string, setFieldValues utils.JSON, returningF
 				idField: result.LastInsertId,
 			}
 
-			_, rows, err := raw.QueryRows(db, nil, selectSQL, selectArgs)
+			_, rows, err := raw.QueryRows(ctx, db, nil, selectSQL, selectArgs)
 			if err == nil && len(rows) > 0 {
 				// Merge additional values
 				for k, v := range rows[0] {
@@ -280,7 +284,7 @@ func Insert(db *sqlx.DB, tableName string, setFieldValues utils.JSON, returningF
 	return result, returningFieldValues, nil
 }
 
-func TxInsert(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues utils.JSON, err error) {
+func TxInsert(ctx context.Context, tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues utils.JSON, err error) {
 	// Basic input validation
 	if tx == nil {
 		return nil, nil, errors.New("database transaction connection is nil")
@@ -329,7 +333,7 @@ func TxInsert(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, returnin
 
 	// If no returning keys requested, simply execute the insert
 	if returningFieldNames == nil || len(returningFieldNames) == 0 {
-		result, err := raw.TxExec(tx, baseSQL, setFieldValues)
+		result, err := raw.TxExec(ctx, tx, baseSQL, setFieldValues)
 		return result, returningFieldValues, err
 	}
 
@@ -338,7 +342,7 @@ func TxInsert(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, returnin
 	case "postgres", "mariadb":
 		// Both PostgreSQL and MariaDB 10.5.0+ support RETURNING clause with the same syntax
 		sqlStatement := fmt.Sprintf("%s RETURNING %s", baseSQL, strings.Join(returningFieldNames, ", "))
-		_, rows, err := raw.TxQueryRows(tx, nil, sqlStatement, setFieldValues)
+		_, rows, err := raw.TxQueryRows(ctx, tx, nil, sqlStatement, setFieldValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing insert with RETURNING clause")
 		}
@@ -366,7 +370,7 @@ func TxInsert(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, returnin
 			fmt.Sprintf("(%s)", fieldValues),
 		}, " ")
 
-		_, rows, err := raw.TxQueryRows(tx, nil, sqlStatement, setFieldValues)
+		_, rows, err := raw.TxQueryRows(ctx, tx, nil, sqlStatement, setFieldValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing insert with OUTPUT clause")
 		}
@@ -405,7 +409,9 @@ func TxInsert(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, returnin
 			strings.Join(returningIntoFields, ", "))
 
 		// Execute directly for Oracle with output parameters
-		_, err = tx.Exec(sqlStatement, namedArgs...)
+		spanCtx, span := raw.StartSpan(ctx, tx.DriverName(), sqlStatement)
+		_, err = tx.ExecContext(spanCtx, sqlStatement, namedArgs...)
+		tracing.EndSpan(span, err)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing oracle insert with RETURNING INTO")
 		}
@@ -433,7 +439,7 @@ func TxInsert(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, returnin
 
 	case "mysql":
 		// MySQL doesn't support RETURNING, so we need to do a separate query
-		result, err := raw.TxExec(tx, baseSQL, setFieldValues)
+		result, err := raw.TxExec(ctx, tx, baseSQL, setFieldValues)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing mysql insert")
 		}
@@ -496,7 +502,7 @@ func TxInsert(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, returnin
 				idField: result.LastInsertId,
 			}
 
-			_, rows, err := raw.TxQueryRows(tx, nil, selectSQL, selectArgs)
+			_, rows, err := raw.TxQueryRows(ctx, tx, nil, selectSQL, selectArgs)
 			if err == nil && len(rows) > 0 {
 				// Merge additional values
 				for k, v := range rows[0] {
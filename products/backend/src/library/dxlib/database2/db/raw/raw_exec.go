@@ -1,22 +1,26 @@
 package raw
 
 import (
+	"context"
 	"database/sql"
 	"github.com/donnyhardyanto/dxlib/database2/database_type"
 	"github.com/donnyhardyanto/dxlib/database2/sqlchecker"
+	"github.com/donnyhardyanto/dxlib/tracing"
 	"github.com/donnyhardyanto/dxlib/utils"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 )
 
-func RawExec(db *sqlx.DB, query string, arg []any) (result sql.Result, err error) {
+func RawExec(ctx context.Context, db *sqlx.DB, query string, arg []any) (result sql.Result, err error) {
 	dbt := database_type.StringToDXDatabaseType(db.DriverName())
 	err = sqlchecker.CheckAll(dbt, query, arg)
 	if err != nil {
 		return nil, errors.Errorf("SQL_INJECTION_DETECTED:QUERY_VALIDATION_FAILED: %w", err)
 	}
 
-	result, err = db.Exec(query, arg...)
+	ctx, span := StartSpan(ctx, db.DriverName(), query)
+	result, err = db.ExecContext(ctx, query, arg...)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -24,14 +28,16 @@ func RawExec(db *sqlx.DB, query string, arg []any) (result sql.Result, err error
 	return result, nil
 }
 
-func RawTxExec(tx *sqlx.Tx, query string, arg []any) (result sql.Result, err error) {
+func RawTxExec(ctx context.Context, tx *sqlx.Tx, query string, arg []any) (result sql.Result, err error) {
 	dbt := database_type.StringToDXDatabaseType(tx.DriverName())
 	err = sqlchecker.CheckAll(dbt, query, arg)
 	if err != nil {
 		return nil, errors.Errorf("SQL_INJECTION_DETECTED:QUERY_VALIDATION_FAILED: %w", err)
 	}
 
-	result, err = tx.Exec(query, arg...)
+	ctx, span := StartSpan(ctx, tx.DriverName(), query)
+	result, err = tx.ExecContext(ctx, query, arg...)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -39,7 +45,7 @@ func RawTxExec(tx *sqlx.Tx, query string, arg []any) (result sql.Result, err err
 	return result, nil
 }
 
-func Exec(db *sqlx.DB, sqlStatement string, sqlArguments utils.JSON) (result sql.Result, err error) {
+func Exec(ctx context.Context, db *sqlx.DB, sqlStatement string, sqlArguments utils.JSON) (result sql.Result, err error) {
 	var (
 		modifiedSQL string
 		args        []interface{}
@@ -87,10 +93,11 @@ func Exec(db *sqlx.DB, sqlStatement string, sqlArguments utils.JSON) (result sql
 	}
 
 	// Call the RawExec function with the modified SQL and arguments
-	return RawExec(db, modifiedSQL, args)
+	return RawExec(ctx, db, modifiedSQL, args)
 }
 
 func TxExec(
+	ctx context.Context,
 	tx *sqlx.Tx,
 	sqlStatement string,
 	sqlArguments utils.JSON,
@@ -142,5 +149,5 @@ func TxExec(
 	}
 
 	// Call the RawTxExec function with the modified SQL and arguments
-	return RawTxExec(tx, modifiedSQL, args)
+	return RawTxExec(ctx, tx, modifiedSQL, args)
 }
@@ -1,17 +1,19 @@
 package raw
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/donnyhardyanto/dxlib/database2/database_type"
 	utils2 "github.com/donnyhardyanto/dxlib/database2/db/utils"
 	"github.com/donnyhardyanto/dxlib/database2/sqlchecker"
+	"github.com/donnyhardyanto/dxlib/tracing"
 	"github.com/donnyhardyanto/dxlib/utils"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 )
 
-func RawQueryRows(db *sqlx.DB, fieldTypeMapping utils2.FieldTypeMapping, query string, arg []any) (rowsInfo *database_type.RowsInfo, r []utils.JSON, err error) {
+func RawQueryRows(ctx context.Context, db *sqlx.DB, fieldTypeMapping utils2.FieldTypeMapping, query string, arg []any) (rowsInfo *database_type.RowsInfo, r []utils.JSON, err error) {
 	r = []utils.JSON{}
 	dbt := database_type.StringToDXDatabaseType(db.DriverName())
 	err = sqlchecker.CheckAll(dbt, query, arg)
@@ -19,7 +21,11 @@ func RawQueryRows(db *sqlx.DB, fieldTypeMapping utils2.FieldTypeMapping, query s
 		return nil, r, errors.Errorf("SQL_INJECTION_DETECTED:QUERY_VALIDATION_FAILED: %w", err)
 	}
 
-	rows, err := db.Queryx(query, arg...)
+	ctx, span := StartSpan(ctx, db.DriverName(), query)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+	rows, err := db.QueryxContext(ctx, query, arg...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -50,7 +56,7 @@ func RawQueryRows(db *sqlx.DB, fieldTypeMapping utils2.FieldTypeMapping, query s
 	return rowsInfo, r, nil
 }
 
-func RawTxQueryRows(tx *sqlx.Tx, fieldTypeMapping utils2.FieldTypeMapping, query string, arg []any) (rowsInfo *database_type.RowsInfo, r []utils.JSON, err error) {
+func RawTxQueryRows(ctx context.Context, tx *sqlx.Tx, fieldTypeMapping utils2.FieldTypeMapping, query string, arg []any) (rowsInfo *database_type.RowsInfo, r []utils.JSON, err error) {
 	r = []utils.JSON{}
 
 	dbt := database_type.StringToDXDatabaseType(tx.DriverName())
@@ -59,7 +65,11 @@ func RawTxQueryRows(tx *sqlx.Tx, fieldTypeMapping utils2.FieldTypeMapping, query
 		return nil, nil, errors.Errorf("SQL_INJECTION_DETECTED:QUERY_VALIDATION_FAILED: %w=%s +%v", err, query, arg)
 	}
 
-	rows, err := tx.Queryx(query, arg...)
+	ctx, span := StartSpan(ctx, tx.DriverName(), query)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+	rows, err := tx.QueryxContext(ctx, query, arg...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -91,6 +101,7 @@ func RawTxQueryRows(tx *sqlx.Tx, fieldTypeMapping utils2.FieldTypeMapping, query
 }
 
 func QueryRows(
+	ctx context.Context,
 	db *sqlx.DB,
 	fieldTypeMapping utils2.FieldTypeMapping,
 	sqlStatement string,
@@ -142,10 +153,11 @@ func QueryRows(
 	}
 
 	// Call the original RawQueryRows function with the modified SQL and arguments
-	return RawQueryRows(db, fieldTypeMapping, modifiedSQL, args)
+	return RawQueryRows(ctx, db, fieldTypeMapping, modifiedSQL, args)
 }
 
 func Count(
+	ctx context.Context,
 	db *sqlx.DB,
 	fromWhereJoinPartSqlStatement string,
 	sqlArguments utils.JSON,
@@ -199,7 +211,7 @@ func Count(
 	}
 
 	// Call the original RawQueryRows function with the modified SQL and arguments
-	_, r, err := RawQueryRows(db, nil, modifiedSQL, args)
+	_, r, err := RawQueryRows(ctx, db, nil, modifiedSQL, args)
 	if err != nil {
 		return 0, errors.Wrapf(err, "error executing count query %s with args %+v", modifiedSQL, args)
 	}
@@ -223,6 +235,7 @@ func Count(
 }
 
 func TxQueryRows(
+	ctx context.Context,
 	tx *sqlx.Tx,
 	fieldTypeMapping utils2.FieldTypeMapping,
 	sqlStatement string,
@@ -275,5 +288,5 @@ func TxQueryRows(
 	}
 
 	// Call the original RawQueryRows function with the modified SQL and arguments
-	return RawTxQueryRows(tx, fieldTypeMapping, modifiedSQL, args)
+	return RawTxQueryRows(ctx, tx, fieldTypeMapping, modifiedSQL, args)
 }
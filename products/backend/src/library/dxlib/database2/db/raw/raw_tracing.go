@@ -0,0 +1,25 @@
+package raw
+
+import (
+	"context"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/donnyhardyanto/dxlib/tracing"
+)
+
+// StartSpan starts the client span of a statement as a child of the span in ctx, the span is named after the SQL operation
+func StartSpan(ctx context.Context, driverName string, query string) (context.Context, trace.Span) {
+	operationName := "SQL"
+	fields := strings.Fields(query)
+	if len(fields) > 0 {
+		operationName = strings.ToUpper(fields[0])
+	}
+	return tracing.StartSpan(ctx, operationName, trace.SpanKindClient,
+		tracing.DBSystem(driverName),
+		semconv.DBOperationName(operationName),
+		tracing.SQLStatement(query),
+	)
+}
@@ -0,0 +1,99 @@
+package raw
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/donnyhardyanto/dxlib/tracing"
+)
+
+func TestStartSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracing.Manager.StartWithExporter(exporter)
+	defer func() {
+		_ = tracing.Manager.Shutdown()
+	}()
+
+	requestCtx, requestSpan := tracing.StartSpan(context.Background(), "GET /v1/user/read", trace.SpanKindServer)
+	defer requestSpan.End()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		driverName string
+		query      string
+		wantName   string
+		wantSystem attribute.KeyValue
+		wantParent trace.SpanContext
+	}{
+		{
+			name:       "child of the request span",
+			ctx:        requestCtx,
+			driverName: "postgres",
+			query:      "select * from user_management.v_user where id = $1",
+			wantName:   "SELECT",
+			wantSystem: semconv.DBSystemPostgreSQL,
+			wantParent: requestSpan.SpanContext(),
+		},
+		{
+			name:       "root span without a request span",
+			ctx:        context.Background(),
+			driverName: "mysql",
+			query:      "  INSERT INTO user (name) VALUES (?)",
+			wantName:   "INSERT",
+			wantSystem: semconv.DBSystemMySQL,
+		},
+		{
+			name:       "empty statement",
+			ctx:        context.Background(),
+			driverName: "postgres",
+			query:      "",
+			wantName:   "SQL",
+			wantSystem: semconv.DBSystemPostgreSQL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			_, span := StartSpan(tt.ctx, tt.driverName, tt.query)
+			span.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			s := spans[0]
+			if s.Name != tt.wantName {
+				t.Errorf("name = %q, want %q", s.Name, tt.wantName)
+			}
+			if s.SpanKind != trace.SpanKindClient {
+				t.Errorf("kind = %v, want %v", s.SpanKind, trace.SpanKindClient)
+			}
+			if s.Parent.SpanID() != tt.wantParent.SpanID() {
+				t.Errorf("parent span id = %s, want %s", s.Parent.SpanID(), tt.wantParent.SpanID())
+			}
+			wantAttributes := []attribute.KeyValue{
+				tt.wantSystem,
+				semconv.DBOperationName(tt.wantName),
+				semconv.DBQueryText(tt.query),
+			}
+			for _, want := range wantAttributes {
+				found := false
+				for _, got := range s.Attributes {
+					if got == want {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("attribute %s=%s not found", want.Key, want.Value.Emit())
+				}
+			}
+		})
+	}
+}
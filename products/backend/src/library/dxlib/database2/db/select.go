@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"github.com/donnyhardyanto/dxlib/database2/database_type"
 	"github.com/donnyhardyanto/dxlib/database2/db/raw"
 	utils2 "github.com/donnyhardyanto/dxlib/database2/db/utils"
@@ -14,10 +15,8 @@ import (
 	"time"
 )
 
-type RowsInfo struct {
-	Columns []string
-	//	ColumnTypes []*sql.ColumnType
-}
+// RowsInfo is kept for the callers of db, the type lives in database_type so db/raw can return it without importing db
+type RowsInfo = database_type.RowsInfo
 
 // FieldsOrderBy is a map that defines ordering directions for fields
 // The key is the field name and the value is the direction ("ASC" or "DESC")
@@ -237,19 +236,19 @@ func SQLPartConstructSelect(driverName string, tableName string, fieldNames []st
 // Examples:
 //
 //	// Simple select
-//	rows, err := BaseSelect(db, mapping, "users", []string{"id", "name"}, nil, nil, nil, nil, nil, nil, nil, "", "")
+//	rows, err := BaseSelect(ctx, db, mapping, "users", []string{"id", "name"}, nil, nil, nil, nil, nil, nil, nil, "", "")
 //	// Generates: SELECT id, name FROM users
 //
 //	// Select with GROUP BY and HAVING
-//	rows, err := BaseSelect(db, mapping, "orders", []string{"customer_id", "COUNT(*) as order_count"},
+//	rows, err := BaseSelect(ctx, db, mapping, "orders", []string{"customer_id", "COUNT(*) as order_count"},
 //	  nil, nil, nil, nil, nil, nil, []string{"customer_id"}, "COUNT(*) > 5", "")
 //	// Generates: SELECT customer_id, COUNT(*) as order_count FROM orders GROUP BY customer_id HAVING COUNT(*) > 5
 //
 //	// Select with CTE
 //	cte := "recent_orders AS (SELECT * FROM orders WHERE order_date > '2023-01-01')"
-//	rows, err := BaseSelect(db, mapping, "recent_orders", []string{"*"}, nil, nil, nil, nil, nil, nil, nil, "", cte)
+//	rows, err := BaseSelect(ctx, db, mapping, "recent_orders", []string{"*"}, nil, nil, nil, nil, nil, nil, nil, "", cte)
 //	// Generates: WITH recent_orders AS (SELECT * FROM orders WHERE order_date > '2023-01-01') SELECT * FROM recent_orders
-func BaseSelect(db *sqlx.DB, fieldTypeMapping utils2.FieldTypeMapping,
+func BaseSelect(ctx context.Context, db *sqlx.DB, fieldTypeMapping utils2.FieldTypeMapping,
 	tableName string, fieldNames []string, whereAndFieldNameValues utils.JSON, joinSQLPart any,
 	orderByFieldNameDirections utils2.FieldsOrderBy, limit any, offset any, forUpdatePart any,
 	groupByFields []string, havingClause string, withCTE string) (rowsInfo *database_type.RowsInfo, r []utils.JSON, err error) {
@@ -275,7 +274,7 @@ func BaseSelect(db *sqlx.DB, fieldTypeMapping utils2.FieldTypeMapping,
 		return nil, nil, err
 	}
 
-	rowsInfo, r, err = raw.QueryRows(db, fieldTypeMapping, s, wKV)
+	rowsInfo, r, err = raw.QueryRows(ctx, db, fieldTypeMapping, s, wKV)
 	return rowsInfo, r, err
 }
 
@@ -304,7 +303,7 @@ func BaseSelect(db *sqlx.DB, fieldTypeMapping utils2.FieldTypeMapping,
 //
 // This function is similar to BaseSelect but operates within a transaction context,
 // allowing for consistent reads and potential row locking when used with forUpdatePart=true
-func BaseTxSelect(tx *sqlx.Tx, fieldTypeMapping utils2.FieldTypeMapping,
+func BaseTxSelect(ctx context.Context, tx *sqlx.Tx, fieldTypeMapping utils2.FieldTypeMapping,
 	tableName string, fieldNames []string, whereAndFieldNameValues utils.JSON, joinSQLPart any,
 	orderByFieldNameDirections utils2.FieldsOrderBy, limit any, offset any, forUpdatePart any,
 	groupByFields []string, havingClause string, withCTE string) (rowsInfo *database_type.RowsInfo, r []utils.JSON, err error) {
@@ -378,7 +377,7 @@ func BaseTxSelect(tx *sqlx.Tx, fieldTypeMapping utils2.FieldTypeMapping,
 		return nil, nil, err
 	}
 
-	rowsInfo, r, err = raw.TxQueryRows(tx, fieldTypeMapping, s, wKV)
+	rowsInfo, r, err = raw.TxQueryRows(ctx, tx, fieldTypeMapping, s, wKV)
 	return rowsInfo, r, err
 }
 
@@ -404,11 +403,11 @@ func BaseTxSelect(tx *sqlx.Tx, fieldTypeMapping utils2.FieldTypeMapping,
 //
 // This function is a backward-compatible wrapper around BaseSelect.
 // It passes nil or empty values for the GROUP BY, HAVING, and CTE parameters.
-func Select(db *sqlx.DB, fieldTypeMapping utils2.FieldTypeMapping, tableName string, fieldNames []string,
+func Select(ctx context.Context, db *sqlx.DB, fieldTypeMapping utils2.FieldTypeMapping, tableName string, fieldNames []string,
 	whereAndFieldNameValues utils.JSON, joinSQLPart any, orderByFieldNameDirections utils2.FieldsOrderBy,
 	limit any, offset any, forUpdatePart any) (rowsInfo *database_type.RowsInfo, r []utils.JSON, err error) {
 
-	return BaseSelect(db, fieldTypeMapping, tableName, fieldNames, whereAndFieldNameValues,
+	return BaseSelect(ctx, db, fieldTypeMapping, tableName, fieldNames, whereAndFieldNameValues,
 		joinSQLPart, orderByFieldNameDirections, limit, offset, forUpdatePart, nil, "", "")
 }
 
@@ -434,11 +433,11 @@ func Select(db *sqlx.DB, fieldTypeMapping utils2.FieldTypeMapping, tableName str
 //
 // This function is a transaction-based wrapper around BaseTxSelect.
 // It passes nil or empty values for the GROUP BY, HAVING, and CTE parameters.
-func TxSelect(tx *sqlx.Tx, fieldTypeMapping utils2.FieldTypeMapping, tableName string, fieldNames []string,
+func TxSelect(ctx context.Context, tx *sqlx.Tx, fieldTypeMapping utils2.FieldTypeMapping, tableName string, fieldNames []string,
 	whereAndFieldNameValues utils.JSON, joinSQLPart any, orderByFieldNameDirections utils2.FieldsOrderBy,
 	limit any, offset any, forUpdatePart any) (rowsInfo *database_type.RowsInfo, r []utils.JSON, err error) {
 
-	return BaseTxSelect(tx, fieldTypeMapping, tableName, fieldNames, whereAndFieldNameValues,
+	return BaseTxSelect(ctx, tx, fieldTypeMapping, tableName, fieldNames, whereAndFieldNameValues,
 		joinSQLPart, orderByFieldNameDirections, limit, offset, forUpdatePart, nil, "", "")
 }
 
@@ -521,7 +520,7 @@ func isSubquery(str string) bool {
 //	// Count with subquery
 //	count, err := Count(db, "(SELECT * FROM orders WHERE date > '2023-01-01')", "", nil, nil, nil, "", "")
 //	// Generates: SELECT COUNT(*) FROM (SELECT * FROM orders WHERE date > '2023-01-01') AS subquery__sq_[unique_id]
-func Count(db *sqlx.DB, tableOrSubquery string /*countExpr string,*/, whereAndFieldNameValues utils.JSON,
+func Count(ctx context.Context, db *sqlx.DB, tableOrSubquery string /*countExpr string,*/, whereAndFieldNameValues utils.JSON,
 	joinSQLPart any, groupByFields []string, havingClause string, withCTE string) (count int64, err error) {
 
 	// Determine if this is a subquery
@@ -556,7 +555,7 @@ func Count(db *sqlx.DB, tableOrSubquery string /*countExpr string,*/, whereAndFi
 	}
 
 	// Execute the SELECT query with COUNT expression
-	rowsInfo, rows, err := BaseSelect(db, nil, effectiveTable, []string{effectiveCountExpr},
+	rowsInfo, rows, err := BaseSelect(ctx, db, nil, effectiveTable, []string{effectiveCountExpr},
 		whereAndFieldNameValues, joinSQLPart, nil, nil, nil, nil,
 		groupByFields, havingClause, withCTE)
 
@@ -613,10 +612,10 @@ func Count(db *sqlx.DB, tableOrSubquery string /*countExpr string,*/, whereAndFi
 
 	s := "select " + effectiveCountExpr + " from " + effectiveTable + " where " + whereStatements
 
-	rowsInfo, rows, err = raw.QueryRows(db, nil, s, wKV)
+	rowsInfo, rows, err = raw.QueryRows(ctx, db, nil, s, wKV)
 
 	// Execute the SELECT query with COUNT expression
-	rowsInfo, rows, err := BaseSelect(db, nil, effectiveTable, []string{effectiveCountExpr},
+	rowsInfo, rows, err := BaseSelect(ctx, db, nil, effectiveTable, []string{effectiveCountExpr},
 		whereAndFieldNameValues, joinSQLPart, nil, nil, nil, nil,
 		groupByFields, havingClause, withCTE)
 
@@ -661,7 +660,7 @@ func Count(db *sqlx.DB, tableOrSubquery string /*countExpr string,*/, whereAndFi
 // This function is a transaction-based version of the Count function.
 // It provides the same functionality but within a transaction context,
 // which ensures consistency across multiple database operations.
-func TxCount(tx *sqlx.Tx, tableOrSubquery string, whereAndFieldNameValues utils.JSON,
+func TxCount(ctx context.Context, tx *sqlx.Tx, tableOrSubquery string, whereAndFieldNameValues utils.JSON,
 	joinSQLPart any, groupByFields []string, havingClause string, withCTE string) (count int64, err error) {
 
 	// Determine if this is a subquery
@@ -696,7 +695,7 @@ func TxCount(tx *sqlx.Tx, tableOrSubquery string, whereAndFieldNameValues utils.
 	}
 
 	// Execute the SELECT query with COUNT expression
-	rowsInfo, rows, err := BaseTxSelect(tx, nil, effectiveTable, []string{effectiveCountExpr},
+	rowsInfo, rows, err := BaseTxSelect(ctx, tx, nil, effectiveTable, []string{effectiveCountExpr},
 		whereAndFieldNameValues, joinSQLPart, nil, nil, nil, nil,
 		groupByFields, havingClause, withCTE)
 
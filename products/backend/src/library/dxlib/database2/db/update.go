@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	utils2 "github.com/donnyhardyanto/dxlib/database2/db/utils"
 	"strings"
@@ -64,7 +65,7 @@ func SQLPartUpdateSetFieldValues(setFieldValues utils.JSON, driverName string) (
 //   - SQL Server: OUTPUT clause
 //   - Oracle: RETURNING INTO clause
 //   - MySQL: Separate SELECT query after UPDATE (with limitations)
-func Update(db *sqlx.DB, tableName string, setFieldNameValues utils.JSON, whereAndFieldNameValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues []utils.JSON, err error) {
+func Update(ctx context.Context, db *sqlx.DB, tableName string, setFieldNameValues utils.JSON, whereAndFieldNameValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues []utils.JSON, err error) {
 	// Basic input validation
 	if db == nil {
 		return nil, nil, errors.New("database connection is nil")
@@ -155,7 +156,7 @@ func Update(db *sqlx.DB, tableName string, setFieldNameValues utils.JSON, whereA
 
 		if len(returningFieldNames) == 0 {
 			// Simple update without returning
-			result, err := raw.Exec(db, baseSQL, combinedParams)
+			result, err := raw.Exec(ctx, db, baseSQL, combinedParams)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing update")
 			}
@@ -170,7 +171,7 @@ func Update(db *sqlx.DB, tableName string, setFieldNameValues utils.JSON, whereA
 			returningClause,
 		}, " ")
 
-		_, rows, err := raw.QueryRows(db, nil, sqlStatement, combinedParams)
+		_, rows, err := raw.QueryRows(ctx, db, nil, sqlStatement, combinedParams)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing update with RETURNING clause")
 		}
@@ -188,7 +189,7 @@ func Update(db *sqlx.DB, tableName string, setFieldNameValues utils.JSON, whereA
 				setClause,
 				effectiveWhere,
 			}, " ")
-			result, err := raw.Exec(db, baseSQL, combinedParams)
+			result, err := raw.Exec(ctx, db, baseSQL, combinedParams)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing update")
 			}
@@ -212,7 +213,7 @@ func Update(db *sqlx.DB, tableName string, setFieldNameValues utils.JSON, whereA
 			effectiveWhere,
 		}, " ")
 
-		_, rows, err := raw.QueryRows(db, nil, sqlStatement, combinedParams)
+		_, rows, err := raw.QueryRows(ctx, db, nil, sqlStatement, combinedParams)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing update with OUTPUT clause")
 		}
@@ -231,7 +232,7 @@ func Update(db *sqlx.DB, tableName string, setFieldNameValues utils.JSON, whereA
 
 		if len(returningFieldNames) == 0 {
 			// Simple update without returning
-			result, err := raw.Exec(db, baseSQL, combinedParams)
+			result, err := raw.Exec(ctx, db, baseSQL, combinedParams)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing oracle update")
 			}
@@ -254,7 +255,7 @@ func Update(db *sqlx.DB, tableName string, setFieldNameValues utils.JSON, whereA
 		}, " ")
 
 		// Execute the update
-		result, err := raw.Exec(db, baseSQL, combinedParams)
+		result, err := raw.Exec(ctx, db, baseSQL, combinedParams)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing mysql update")
 		}
@@ -279,7 +280,7 @@ func Update(db *sqlx.DB, tableName string, setFieldNameValues utils.JSON, whereA
 			effectiveWhere,
 		}, " ")
 
-		_, rows, err := raw.QueryRows(db, nil, selectSQL, whereAndFieldNameValues)
+		_, rows, err := raw.QueryRows(ctx, db, nil, selectSQL, whereAndFieldNameValues)
 		if err != nil {
 			// Log the error but don't fail - we've already done the update
 			return result, nil, errors.Wrap(err, "error fetching updated rows")
@@ -293,7 +294,7 @@ func Update(db *sqlx.DB, tableName string, setFieldNameValues utils.JSON, whereA
 	}
 }
 
-func TxUpdate(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, whereAndFieldNameValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues []utils.JSON, err error) {
+func TxUpdate(ctx context.Context, tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, whereAndFieldNameValues utils.JSON, returningFieldNames []string) (result sql.Result, returningFieldValues []utils.JSON, err error) {
 	// Basic input validation
 	if tx == nil {
 		return nil, nil, errors.New("database transaction connection is nil")
@@ -384,7 +385,7 @@ func TxUpdate(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, whereAnd
 
 		if len(returningFieldNames) == 0 {
 			// Simple update without returning
-			result, err := raw.TxExec(tx, baseSQL, combinedParams)
+			result, err := raw.TxExec(ctx, tx, baseSQL, combinedParams)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing update")
 			}
@@ -399,7 +400,7 @@ func TxUpdate(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, whereAnd
 			returningClause,
 		}, " ")
 
-		_, rows, err := raw.TxQueryRows(tx, nil, sqlStatement, combinedParams)
+		_, rows, err := raw.TxQueryRows(ctx, tx, nil, sqlStatement, combinedParams)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing update with RETURNING clause")
 		}
@@ -417,7 +418,7 @@ func TxUpdate(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, whereAnd
 				setClause,
 				effectiveWhere,
 			}, " ")
-			result, err := raw.TxExec(tx, baseSQL, combinedParams)
+			result, err := raw.TxExec(ctx, tx, baseSQL, combinedParams)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing update")
 			}
@@ -441,7 +442,7 @@ func TxUpdate(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, whereAnd
 			effectiveWhere,
 		}, " ")
 
-		_, rows, err := raw.TxQueryRows(tx, nil, sqlStatement, combinedParams)
+		_, rows, err := raw.TxQueryRows(ctx, tx, nil, sqlStatement, combinedParams)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing update with OUTPUT clause")
 		}
@@ -460,7 +461,7 @@ func TxUpdate(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, whereAnd
 
 		if len(returningFieldNames) == 0 {
 			// Simple update without returning
-			result, err := raw.TxExec(tx, baseSQL, combinedParams)
+			result, err := raw.TxExec(ctx, tx, baseSQL, combinedParams)
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "error executing oracle update")
 			}
@@ -483,7 +484,7 @@ func TxUpdate(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, whereAnd
 		}, " ")
 
 		// Execute the update
-		result, err := raw.TxExec(tx, baseSQL, combinedParams)
+		result, err := raw.TxExec(ctx, tx, baseSQL, combinedParams)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "error executing mysql update")
 		}
@@ -508,7 +509,7 @@ func TxUpdate(tx *sqlx.Tx, tableName string, setFieldValues utils.JSON, whereAnd
 			effectiveWhere,
 		}, " ")
 
-		_, rows, err := raw.TxQueryRows(tx, nil, selectSQL, whereAndFieldNameValues)
+		_, rows, err := raw.TxQueryRows(ctx, tx, nil, selectSQL, whereAndFieldNameValues)
 		if err != nil {
 			// Log the error but don't fail - we've already done the update
 			return result, nil, errors.Wrap(err, "error fetching updated rows")
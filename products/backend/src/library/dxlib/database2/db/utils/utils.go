@@ -297,6 +297,45 @@ func DbDriverConvertValueTypeToDBCompatible(driverName string, v any) (any, erro
 			return v.(uuid.UUID).String(), nil
 		}
 
+	case []uuid.UUID:
+		// UUID arrays are sent as their string form
+		uuids := v.([]uuid.UUID)
+		uuidsAsStrings := make([]string, len(uuids))
+		for i, u := range uuids {
+			uuidsAsStrings[i] = u.String()
+		}
+		switch driverName {
+		case "postgres", "postgresql":
+			// PostgreSQL casts text[] parameters to uuid[]
+			return pq.Array(uuidsAsStrings), nil
+		default:
+			vx, err := utils.ArrayToJSON[string](uuidsAsStrings)
+			if err != nil {
+				return nil, err
+			}
+			return vx, nil
+		}
+
+	case utils.DateTimeRange:
+		// Datetime range handling
+		dateTimeRange := v.(utils.DateTimeRange)
+		switch driverName {
+		case "postgres", "postgresql":
+			// PostgreSQL has native tstzrange support, use the [start,end) range literal
+			return dateTimeRange.Value()
+		default:
+			// The other databases have no range type, store the range as a start/end JSON document
+			vx, err := json.Marshal(dateTimeRange)
+			if err != nil {
+				return nil, errors.Wrap(err, "error occured")
+			}
+			return string(vx), nil
+		}
+
+	case []byte:
+		// Binary data is passed as is, all drivers bind []byte to their binary column types
+		return v, nil
+
 	case json.RawMessage:
 		// JSON handling
 		switch driverName {
@@ -0,0 +1,164 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+)
+
+const (
+	DXHealthDefaultCheckTimeoutSec = 3
+
+	DXHealthStatusOk       = "ok"
+	DXHealthStatusDegraded = "degraded"
+	DXHealthStatusFail     = "fail"
+)
+
+// DXHealthCheckFunc returns nil when the dependency is usable, it should give up once ctx is done
+type DXHealthCheckFunc func(ctx context.Context) (err error)
+
+type DXHealthCheck struct {
+	NameId     string
+	IsCritical bool
+	Timeout    time.Duration
+	Check      DXHealthCheckFunc
+}
+
+// DXHealthManager aggregates the dependency checks for the readiness probe, a failing critical check or a started shutdown makes the service not ready
+type DXHealthManager struct {
+	mutex          sync.Mutex
+	Checks         map[string]*DXHealthCheck
+	isShuttingDown atomic.Bool
+}
+
+// RegisterCheck adds or replaces the check of nameId, a zero timeout uses DXHealthDefaultCheckTimeoutSec
+func (h *DXHealthManager) RegisterCheck(nameId string, isCritical bool, timeout time.Duration, check DXHealthCheckFunc) *DXHealthCheck {
+	if timeout <= 0 {
+		timeout = DXHealthDefaultCheckTimeoutSec * time.Second
+	}
+	c := &DXHealthCheck{
+		NameId:     nameId,
+		IsCritical: isCritical,
+		Timeout:    timeout,
+		Check:      check,
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.Checks[nameId] = c
+	return c
+}
+
+func (h *DXHealthManager) UnregisterCheck(nameId string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.Checks, nameId)
+}
+
+// SetShuttingDown makes the readiness fail so load balancers stop sending new requests
+func (h *DXHealthManager) SetShuttingDown(isShuttingDown bool) {
+	h.isShuttingDown.Store(isShuttingDown)
+}
+
+func (h *DXHealthManager) IsShuttingDown() bool {
+	return h.isShuttingDown.Load()
+}
+
+// run calls the check with its timeout, a check ignoring ctx is abandoned once the timeout is reached
+func (c *DXHealthCheck) run(ctx context.Context) (duration time.Duration, err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	startTime := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- errors.Errorf("HEALTH_CHECK_PANIC:%v", r)
+			}
+		}()
+		result <- c.Check(ctx)
+	}()
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = errors.Errorf("HEALTH_CHECK_TIMEOUT:%s", c.Timeout)
+	}
+	return time.Since(startTime), err
+}
+
+// Liveness only tells the process is able to serve a request, dependencies are not checked so a database outage does not restart the service
+func (h *DXHealthManager) Liveness() utils.JSON {
+	return utils.JSON{
+		"status": DXHealthStatusOk,
+	}
+}
+
+// Readiness runs every check concurrently, the service is ready when not shutting down and all critical checks pass.
+// A failing non critical check only degrades the status
+func (h *DXHealthManager) Readiness(ctx context.Context) (isReady bool, result utils.JSON) {
+	h.mutex.Lock()
+	checks := make([]*DXHealthCheck, 0, len(h.Checks))
+	for _, c := range h.Checks {
+		checks = append(checks, c)
+	}
+	h.mutex.Unlock()
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].NameId < checks[j].NameId
+	})
+
+	checkResults := make([]utils.JSON, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *DXHealthCheck) {
+			defer wg.Done()
+			duration, err := c.run(ctx)
+			checkResult := utils.JSON{
+				"status":      DXHealthStatusOk,
+				"critical":    c.IsCritical,
+				"duration_ms": duration.Milliseconds(),
+			}
+			if err != nil {
+				checkResult["status"] = DXHealthStatusFail
+				checkResult["error"] = err.Error()
+			}
+			checkResults[i] = checkResult
+		}(i, c)
+	}
+	wg.Wait()
+
+	isShuttingDown := h.IsShuttingDown()
+	isReady = !isShuttingDown
+	status := DXHealthStatusOk
+	resultChecks := utils.JSON{}
+	for i, c := range checks {
+		resultChecks[c.NameId] = checkResults[i]
+		if checkResults[i]["status"] == DXHealthStatusOk {
+			continue
+		}
+		if c.IsCritical {
+			isReady = false
+		} else if status == DXHealthStatusOk {
+			status = DXHealthStatusDegraded
+		}
+	}
+	if !isReady {
+		status = DXHealthStatusFail
+	}
+	return isReady, utils.JSON{
+		"status":        status,
+		"shutting_down": isShuttingDown,
+		"checks":        resultChecks,
+	}
+}
+
+var Manager DXHealthManager
+
+func init() {
+	Manager = DXHealthManager{Checks: map[string]*DXHealthCheck{}}
+}
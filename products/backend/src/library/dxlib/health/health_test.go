@@ -0,0 +1,161 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+)
+
+func TestReadiness(t *testing.T) {
+	ok := func(ctx context.Context) (err error) {
+		return nil
+	}
+	fail := func(ctx context.Context) (err error) {
+		return errors.New("DEPENDENCY_DOWN")
+	}
+	hang := func(ctx context.Context) (err error) {
+		time.Sleep(time.Second)
+		return nil
+	}
+	panics := func(ctx context.Context) (err error) {
+		panic("boom")
+	}
+
+	type check struct {
+		nameId     string
+		isCritical bool
+		check      DXHealthCheckFunc
+	}
+	tests := []struct {
+		name           string
+		checks         []check
+		isShuttingDown bool
+		wantIsReady    bool
+		wantStatus     string
+		wantFailed     []string
+	}{
+		{
+			name:        "no check is ready",
+			wantIsReady: true,
+			wantStatus:  DXHealthStatusOk,
+		},
+		{
+			name: "all checks pass",
+			checks: []check{
+				{nameId: "database.db_base", isCritical: true, check: ok},
+				{nameId: "redis.session", isCritical: false, check: ok},
+			},
+			wantIsReady: true,
+			wantStatus:  DXHealthStatusOk,
+		},
+		{
+			name: "failing non critical check degrades",
+			checks: []check{
+				{nameId: "database.db_base", isCritical: true, check: ok},
+				{nameId: "object_storage.avatar", isCritical: false, check: fail},
+			},
+			wantIsReady: true,
+			wantStatus:  DXHealthStatusDegraded,
+			wantFailed:  []string{"object_storage.avatar"},
+		},
+		{
+			name: "failing critical check is not ready",
+			checks: []check{
+				{nameId: "database.db_base", isCritical: true, check: fail},
+				{nameId: "object_storage.avatar", isCritical: false, check: fail},
+			},
+			wantIsReady: false,
+			wantStatus:  DXHealthStatusFail,
+			wantFailed:  []string{"database.db_base", "object_storage.avatar"},
+		},
+		{
+			name: "check ignoring ctx times out",
+			checks: []check{
+				{nameId: "module.user_management.session_redis", isCritical: true, check: hang},
+			},
+			wantIsReady: false,
+			wantStatus:  DXHealthStatusFail,
+			wantFailed:  []string{"module.user_management.session_redis"},
+		},
+		{
+			name: "panicking check fails",
+			checks: []check{
+				{nameId: "task.fcm", isCritical: true, check: panics},
+			},
+			wantIsReady: false,
+			wantStatus:  DXHealthStatusFail,
+			wantFailed:  []string{"task.fcm"},
+		},
+		{
+			name: "shutting down is not ready",
+			checks: []check{
+				{nameId: "database.db_base", isCritical: true, check: ok},
+			},
+			isShuttingDown: true,
+			wantIsReady:    false,
+			wantStatus:     DXHealthStatusFail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := DXHealthManager{Checks: map[string]*DXHealthCheck{}}
+			for _, c := range tt.checks {
+				h.RegisterCheck(c.nameId, c.isCritical, 50*time.Millisecond, c.check)
+			}
+			h.SetShuttingDown(tt.isShuttingDown)
+
+			isReady, result := h.Readiness(context.Background())
+			if isReady != tt.wantIsReady {
+				t.Errorf("isReady = %v, want %v", isReady, tt.wantIsReady)
+			}
+			if result["status"] != tt.wantStatus {
+				t.Errorf("status = %v, want %v", result["status"], tt.wantStatus)
+			}
+			checkResults := result["checks"].(utils.JSON)
+			if len(checkResults) != len(tt.checks) {
+				t.Fatalf("got %d check results, want %d", len(checkResults), len(tt.checks))
+			}
+			failed := map[string]bool{}
+			for _, nameId := range tt.wantFailed {
+				failed[nameId] = true
+			}
+			for _, c := range tt.checks {
+				checkResult := checkResults[c.nameId].(utils.JSON)
+				wantCheckStatus := DXHealthStatusOk
+				if failed[c.nameId] {
+					wantCheckStatus = DXHealthStatusFail
+				}
+				if checkResult["status"] != wantCheckStatus {
+					t.Errorf("%s status = %v, want %v", c.nameId, checkResult["status"], wantCheckStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterCheckDefaultTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeout     time.Duration
+		wantTimeout time.Duration
+	}{
+		{name: "zero uses the default", timeout: 0, wantTimeout: DXHealthDefaultCheckTimeoutSec * time.Second},
+		{name: "negative uses the default", timeout: -time.Second, wantTimeout: DXHealthDefaultCheckTimeoutSec * time.Second},
+		{name: "positive is kept", timeout: 500 * time.Millisecond, wantTimeout: 500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := DXHealthManager{Checks: map[string]*DXHealthCheck{}}
+			c := h.RegisterCheck("check", true, tt.timeout, func(ctx context.Context) (err error) {
+				return nil
+			})
+			if c.Timeout != tt.wantTimeout {
+				t.Errorf("timeout = %s, want %s", c.Timeout, tt.wantTimeout)
+			}
+		})
+	}
+}
@@ -46,6 +46,29 @@ type DXLog struct {
 var Format DXLogFormat
 var OnError func(errPrev error, severity DXLogLevel, location string, text string, stack string) (err error)
 
+type requestIdContextKey struct{}
+
+// ContextWithRequestId returns a context carrying the request id, every line logged through a DXLog of that context has a request_id field
+func ContextWithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, requestId)
+}
+
+func RequestIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestId, _ := ctx.Value(requestIdContextKey{}).(string)
+	return requestId
+}
+
+func (l *DXLog) fields(location string) logrus.Fields {
+	fields := logrus.Fields{"prefix": l.Prefix, "location": location}
+	if requestId := RequestIdFromContext(l.Context); requestId != "" {
+		fields["request_id"] = requestId
+	}
+	return fields
+}
+
 func NewLog(parentLog *DXLog, context context.Context, prefix string) DXLog {
 	if parentLog != nil {
 		if parentLog.Prefix != "" {
@@ -65,7 +88,7 @@ func (l *DXLog) LogText(err error, severity DXLogLevel, location string, text st
 		text = text + "\n" + err.Error()
 	}
 
-	a := logrus.WithFields(logrus.Fields{"prefix": l.Prefix, "location": location})
+	a := logrus.WithFields(l.fields(location))
 	switch severity {
 	case DXLogLevelTrace:
 		a.Tracef("%s", text)
@@ -104,7 +127,7 @@ func (l *DXLog) LogText2(err error, severity DXLogLevel, location string, text s
 		//		text = text + "\n" + err.Error()
 	}
 
-	a := logrus.WithFields(logrus.Fields{"prefix": l.Prefix, "location": location})
+	a := logrus.WithFields(l.fields(location))
 	switch severity {
 	case DXLogLevelTrace:
 		a.Tracef("%s", text)
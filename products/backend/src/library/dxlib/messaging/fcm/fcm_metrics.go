@@ -0,0 +1,31 @@
+package fcm
+
+import (
+	"firebase.google.com/go/v4/messaging"
+	"github.com/donnyhardyanto/dxlib/metrics"
+)
+
+var metricFCMSends = metrics.Manager.NewCounter("dxlib_fcm_sends_total", "Messages sent to Firebase Cloud Messaging, by device type and outcome", "device_type", "outcome")
+
+// SendOutcome classifies the error returned by a FCM send
+func SendOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "sent"
+	case messaging.IsUnregistered(err):
+		return "unregistered"
+	case messaging.IsInvalidArgument(err):
+		return "invalid_argument"
+	case messaging.IsQuotaExceeded(err):
+		return "quota_exceeded"
+	case messaging.IsUnavailable(err), messaging.IsInternal(err):
+		return "unavailable"
+	case messaging.IsSenderIDMismatch(err), messaging.IsThirdPartyAuthError(err):
+		return "auth_error"
+	}
+	return "error"
+}
+
+func ObserveSend(deviceType string, err error) {
+	metricFCMSends.Inc(deviceType, SendOutcome(err))
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"database/sql"
+)
+
+// DXDatabasePool is the state of one connection pool at scrape time, Stats come from database/sql
+type DXDatabasePool struct {
+	NameId                       string
+	Stats                        sql.DBStats
+	ConcurrencySemaphoreInUse    int
+	ConcurrencySemaphoreCapacity int
+}
+
+// DXDatabasePoolsFunc returns the connected pools of a database manager
+type DXDatabasePoolsFunc func() []DXDatabasePool
+
+func collectDatabasePoolMetrics(pools DXDatabasePoolsFunc, value func(p DXDatabasePool) float64) DXMetricCollectFunc {
+	return func(emit func(value float64, labelValues ...string)) {
+		for _, p := range pools() {
+			emit(value(p), p.NameId)
+		}
+	}
+}
+
+// RegisterDatabasePoolMetrics registers the dxlib_database_* metrics of a database manager, every database package reports its pools through the same families
+func (m *DXMetricsManager) RegisterDatabasePoolMetrics(pools DXDatabasePoolsFunc) {
+	labelNames := []string{"database"}
+	m.NewGaugeFunc("dxlib_database_connections_open", "Established connections of the database pool, both in use and idle", labelNames, collectDatabasePoolMetrics(pools, func(p DXDatabasePool) float64 {
+		return float64(p.Stats.OpenConnections)
+	}))
+	m.NewGaugeFunc("dxlib_database_connections_in_use", "Connections of the database pool currently in use", labelNames, collectDatabasePoolMetrics(pools, func(p DXDatabasePool) float64 {
+		return float64(p.Stats.InUse)
+	}))
+	m.NewGaugeFunc("dxlib_database_connections_idle", "Idle connections of the database pool", labelNames, collectDatabasePoolMetrics(pools, func(p DXDatabasePool) float64 {
+		return float64(p.Stats.Idle)
+	}))
+	m.NewGaugeFunc("dxlib_database_connections_max_open", "Maximum number of open connections of the database pool, 0 is unlimited", labelNames, collectDatabasePoolMetrics(pools, func(p DXDatabasePool) float64 {
+		return float64(p.Stats.MaxOpenConnections)
+	}))
+	m.NewCounterFunc("dxlib_database_connections_wait_total", "Connections waited for because the database pool was exhausted", labelNames, collectDatabasePoolMetrics(pools, func(p DXDatabasePool) float64 {
+		return float64(p.Stats.WaitCount)
+	}))
+	m.NewCounterFunc("dxlib_database_connections_wait_seconds_total", "Time spent waiting for a connection of the database pool", labelNames, collectDatabasePoolMetrics(pools, func(p DXDatabasePool) float64 {
+		return p.Stats.WaitDuration.Seconds()
+	}))
+	m.NewGaugeFunc("dxlib_database_concurrency_semaphore_in_use", "Slots of the database ConcurrencySemaphore currently held", labelNames, collectDatabasePoolMetrics(pools, func(p DXDatabasePool) float64 {
+		return float64(p.ConcurrencySemaphoreInUse)
+	}))
+	m.NewGaugeFunc("dxlib_database_concurrency_semaphore_capacity", "Slots of the database ConcurrencySemaphore", labelNames, collectDatabasePoolMetrics(pools, func(p DXDatabasePool) float64 {
+		return float64(p.ConcurrencySemaphoreCapacity)
+	}))
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterDatabasePoolMetrics(t *testing.T) {
+	v1Pools := func() []DXDatabasePool {
+		return []DXDatabasePool{
+			{
+				NameId:                       "db_base",
+				Stats:                        sql.DBStats{MaxOpenConnections: 20, OpenConnections: 5, InUse: 3, Idle: 2, WaitCount: 7, WaitDuration: 1500 * time.Millisecond},
+				ConcurrencySemaphoreInUse:    3,
+				ConcurrencySemaphoreCapacity: 20,
+			},
+		}
+	}
+	v2Pools := func() []DXDatabasePool {
+		return []DXDatabasePool{
+			{
+				NameId: "auditlog",
+				Stats:  sql.DBStats{OpenConnections: 1, Idle: 1},
+			},
+		}
+	}
+
+	m := DXMetricsManager{Metrics: map[string]*DXMetric{}}
+	m.RegisterDatabasePoolMetrics(v1Pools)
+	m.RegisterDatabasePoolMetrics(v2Pools)
+	text, err := m.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		wantLine string
+	}{
+		{name: "open", wantLine: `dxlib_database_connections_open{database="db_base"} 5`},
+		{name: "in use", wantLine: `dxlib_database_connections_in_use{database="db_base"} 3`},
+		{name: "idle", wantLine: `dxlib_database_connections_idle{database="db_base"} 2`},
+		{name: "max open", wantLine: `dxlib_database_connections_max_open{database="db_base"} 20`},
+		{name: "wait count", wantLine: `dxlib_database_connections_wait_total{database="db_base"} 7`},
+		{name: "wait duration", wantLine: `dxlib_database_connections_wait_seconds_total{database="db_base"} 1.5`},
+		{name: "semaphore in use", wantLine: `dxlib_database_concurrency_semaphore_in_use{database="db_base"} 3`},
+		{name: "semaphore capacity", wantLine: `dxlib_database_concurrency_semaphore_capacity{database="db_base"} 20`},
+		{name: "second manager shares the family", wantLine: `dxlib_database_connections_open{database="auditlog"} 1`},
+		{name: "counter type", wantLine: `# TYPE dxlib_database_connections_wait_total counter`},
+		{name: "gauge type", wantLine: `# TYPE dxlib_database_connections_open gauge`},
+	}
+	lines := strings.Split(text, "\n")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, line := range lines {
+				if line == tt.wantLine {
+					return
+				}
+			}
+			t.Errorf("line %q not found in\n%s", tt.wantLine, text)
+		})
+	}
+
+	if strings.Count(text, "# TYPE dxlib_database_connections_open ") != 1 {
+		t.Errorf("dxlib_database_connections_open declared more than once")
+	}
+}
@@ -0,0 +1,296 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ContentType is the Prometheus text exposition format version 0.0.4
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type DXMetricType string
+
+const (
+	MetricTypeCounter   DXMetricType = "counter"
+	MetricTypeGauge     DXMetricType = "gauge"
+	MetricTypeHistogram DXMetricType = "histogram"
+)
+
+// DefaultDurationBuckets are the histogram upper bounds in seconds used for request, command and task durations
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// DXMetricCollectFunc is called on every scrape, it reports the current values through emit, label values are in the order of the metric label names
+type DXMetricCollectFunc func(emit func(value float64, labelValues ...string))
+
+type dxMetricSeries struct {
+	LabelValues  []string
+	Value        float64
+	BucketCounts []uint64
+	Sum          float64
+	Count        uint64
+}
+
+type DXMetric struct {
+	Name         string
+	Help         string
+	Type         DXMetricType
+	LabelNames   []string
+	Buckets      []float64
+	mutex        sync.Mutex
+	series       map[string]*dxMetricSeries
+	collectFuncs []DXMetricCollectFunc
+}
+
+type DXMetricCounter struct{ metric *DXMetric }
+type DXMetricGauge struct{ metric *DXMetric }
+type DXMetricHistogram struct{ metric *DXMetric }
+
+type DXMetricsManager struct {
+	mutex   sync.Mutex
+	Metrics map[string]*DXMetric
+}
+
+// register returns the already registered metric of the same name so packages may share a family, a different type or label set is a programming error
+func (m *DXMetricsManager) register(name, help string, aType DXMetricType, buckets []float64, labelNames []string) *DXMetric {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if metric, ok := m.Metrics[name]; ok {
+		if (metric.Type != aType) || (strings.Join(metric.LabelNames, ",") != strings.Join(labelNames, ",")) {
+			panic(errors.Errorf("METRIC_ALREADY_REGISTERED_WITH_DIFFERENT_DECLARATION:%s", name))
+		}
+		return metric
+	}
+	metric := &DXMetric{
+		Name:       name,
+		Help:       help,
+		Type:       aType,
+		LabelNames: labelNames,
+		Buckets:    buckets,
+		series:     map[string]*dxMetricSeries{},
+	}
+	m.Metrics[name] = metric
+	return metric
+}
+
+func (m *DXMetricsManager) NewCounter(name, help string, labelNames ...string) *DXMetricCounter {
+	return &DXMetricCounter{metric: m.register(name, help, MetricTypeCounter, nil, labelNames)}
+}
+
+func (m *DXMetricsManager) NewGauge(name, help string, labelNames ...string) *DXMetricGauge {
+	return &DXMetricGauge{metric: m.register(name, help, MetricTypeGauge, nil, labelNames)}
+}
+
+func (m *DXMetricsManager) NewHistogram(name, help string, buckets []float64, labelNames ...string) *DXMetricHistogram {
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	return &DXMetricHistogram{metric: m.register(name, help, MetricTypeHistogram, buckets, labelNames)}
+}
+
+// NewGaugeFunc registers a gauge whose values are read at scrape time, such as connection pool statistics
+func (m *DXMetricsManager) NewGaugeFunc(name, help string, labelNames []string, collect DXMetricCollectFunc) {
+	metric := m.register(name, help, MetricTypeGauge, nil, labelNames)
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	metric.collectFuncs = append(metric.collectFuncs, collect)
+}
+
+// NewCounterFunc registers a counter whose values are read at scrape time from a monotonic source
+func (m *DXMetricsManager) NewCounterFunc(name, help string, labelNames []string, collect DXMetricCollectFunc) {
+	metric := m.register(name, help, MetricTypeCounter, nil, labelNames)
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	metric.collectFuncs = append(metric.collectFuncs, collect)
+}
+
+// seriesOf must be called with the metric mutex held, missing label values are empty and extra ones are ignored
+func (metric *DXMetric) seriesOf(labelValues []string) *dxMetricSeries {
+	values := make([]string, len(metric.LabelNames))
+	copy(values, labelValues)
+	key := strings.Join(values, "\xff")
+	s, ok := metric.series[key]
+	if !ok {
+		s = &dxMetricSeries{LabelValues: values}
+		if metric.Type == MetricTypeHistogram {
+			s.BucketCounts = make([]uint64, len(metric.Buckets))
+		}
+		metric.series[key] = s
+	}
+	return s
+}
+
+func (metric *DXMetric) add(v float64, labelValues []string) {
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	metric.seriesOf(labelValues).Value += v
+}
+
+func (c *DXMetricCounter) Inc(labelValues ...string) {
+	c.metric.add(1, labelValues)
+}
+
+// Add ignores negative values since a counter only goes up
+func (c *DXMetricCounter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.metric.add(v, labelValues)
+}
+
+func (g *DXMetricGauge) Set(v float64, labelValues ...string) {
+	g.metric.mutex.Lock()
+	defer g.metric.mutex.Unlock()
+	g.metric.seriesOf(labelValues).Value = v
+}
+
+func (g *DXMetricGauge) Add(v float64, labelValues ...string) {
+	g.metric.add(v, labelValues)
+}
+
+func (g *DXMetricGauge) Inc(labelValues ...string) {
+	g.metric.add(1, labelValues)
+}
+
+func (g *DXMetricGauge) Dec(labelValues ...string) {
+	g.metric.add(-1, labelValues)
+}
+
+func (h *DXMetricHistogram) Observe(v float64, labelValues ...string) {
+	h.metric.mutex.Lock()
+	defer h.metric.mutex.Unlock()
+	s := h.metric.seriesOf(labelValues)
+	i := sort.SearchFloat64s(h.metric.Buckets, v)
+	if i < len(s.BucketCounts) {
+		s.BucketCounts[i]++
+	}
+	s.Sum += v
+	s.Count++
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func writeSample(w *bufio.Writer, name string, labelNames []string, labelValues []string, extraLabelName string, extraLabelValue string, value string) {
+	w.WriteString(name)
+	if (len(labelNames) > 0) || (extraLabelName != "") {
+		w.WriteByte('{')
+		for i, labelName := range labelNames {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(labelName + `="` + labelValueEscaper.Replace(labelValues[i]) + `"`)
+		}
+		if extraLabelName != "" {
+			if len(labelNames) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extraLabelName + `="` + extraLabelValue + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteString(" " + value + "\n")
+}
+
+// snapshot copies the recorded series and the values of the collect funcs, sorted by label values
+func (metric *DXMetric) snapshot() (series []dxMetricSeries) {
+	metric.mutex.Lock()
+	for _, s := range metric.series {
+		c := *s
+		c.BucketCounts = append([]uint64{}, s.BucketCounts...)
+		series = append(series, c)
+	}
+	collectFuncs := append([]DXMetricCollectFunc{}, metric.collectFuncs...)
+	metric.mutex.Unlock()
+
+	for _, collect := range collectFuncs {
+		collect(func(value float64, labelValues ...string) {
+			values := make([]string, len(metric.LabelNames))
+			copy(values, labelValues)
+			series = append(series, dxMetricSeries{LabelValues: values, Value: value})
+		})
+	}
+	sort.SliceStable(series, func(i, j int) bool {
+		return strings.Join(series[i].LabelValues, "\xff") < strings.Join(series[j].LabelValues, "\xff")
+	})
+	return series
+}
+
+// WriteText writes all metrics in the Prometheus text exposition format, sorted by name
+func (m *DXMetricsManager) WriteText(out io.Writer) (err error) {
+	m.mutex.Lock()
+	names := make([]string, 0, len(m.Metrics))
+	for name := range m.Metrics {
+		names = append(names, name)
+	}
+	m.mutex.Unlock()
+	sort.Strings(names)
+
+	w := bufio.NewWriter(out)
+	for _, name := range names {
+		m.mutex.Lock()
+		metric := m.Metrics[name]
+		m.mutex.Unlock()
+
+		series := metric.snapshot()
+		if len(series) == 0 {
+			continue
+		}
+		w.WriteString("# HELP " + metric.Name + " " + helpEscaper.Replace(metric.Help) + "\n")
+		w.WriteString("# TYPE " + metric.Name + " " + string(metric.Type) + "\n")
+		for _, s := range series {
+			if metric.Type != MetricTypeHistogram {
+				writeSample(w, metric.Name, metric.LabelNames, s.LabelValues, "", "", formatValue(s.Value))
+				continue
+			}
+			cumulativeCount := uint64(0)
+			for i, bucket := range metric.Buckets {
+				cumulativeCount += s.BucketCounts[i]
+				writeSample(w, metric.Name+"_bucket", metric.LabelNames, s.LabelValues, "le", formatValue(bucket), strconv.FormatUint(cumulativeCount, 10))
+			}
+			writeSample(w, metric.Name+"_bucket", metric.LabelNames, s.LabelValues, "le", "+Inf", strconv.FormatUint(s.Count, 10))
+			writeSample(w, metric.Name+"_sum", metric.LabelNames, s.LabelValues, "", "", formatValue(s.Sum))
+			writeSample(w, metric.Name+"_count", metric.LabelNames, s.LabelValues, "", "", strconv.FormatUint(s.Count, 10))
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		return errors.Wrap(err, "error occured")
+	}
+	return nil
+}
+
+func (m *DXMetricsManager) Text() (s string, err error) {
+	var b bytes.Buffer
+	err = m.WriteText(&b)
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+var Manager DXMetricsManager
+
+func init() {
+	Manager = DXMetricsManager{Metrics: map[string]*DXMetric{}}
+}
@@ -1,5 +1,11 @@
 package module
 
+import (
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/health"
+)
+
 type DXModuleInterface interface {
 }
 
@@ -8,3 +14,8 @@ type DXModule struct {
 	NameId         string
 	DatabaseNameId string
 }
+
+// RegisterHealthCheck adds a readiness check of a dependency of the module, it is reported by /readyz as module.<NameId>.<checkNameId>
+func (m *DXModule) RegisterHealthCheck(checkNameId string, isCritical bool, timeout time.Duration, check health.DXHealthCheckFunc) *health.DXHealthCheck {
+	return health.Manager.RegisterCheck("module."+m.NameId+"."+checkNameId, isCritical, timeout, check)
+}
@@ -0,0 +1,40 @@
+package module
+
+import (
+	"context"
+	"testing"
+
+	"github.com/donnyhardyanto/dxlib/health"
+)
+
+func TestRegisterHealthCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		moduleName  string
+		checkNameId string
+		isCritical  bool
+		wantNameId  string
+	}{
+		{name: "critical", moduleName: "user_management", checkNameId: "session_redis", isCritical: true, wantNameId: "module.user_management.session_redis"},
+		{name: "non critical", moduleName: "push_notification", checkNameId: "fcm", isCritical: false, wantNameId: "module.push_notification.fcm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := DXModule{NameId: tt.moduleName}
+			c := m.RegisterHealthCheck(tt.checkNameId, tt.isCritical, 0, func(ctx context.Context) (err error) {
+				return nil
+			})
+			defer health.Manager.UnregisterCheck(tt.wantNameId)
+
+			if c.NameId != tt.wantNameId {
+				t.Errorf("nameId = %q, want %q", c.NameId, tt.wantNameId)
+			}
+			if health.Manager.Checks[tt.wantNameId] != c {
+				t.Errorf("check %q not registered in health.Manager", tt.wantNameId)
+			}
+			if c.IsCritical != tt.isCritical {
+				t.Errorf("isCritical = %v, want %v", c.IsCritical, tt.isCritical)
+			}
+		})
+	}
+}
@@ -7,10 +7,13 @@ import (
 	dxlibv3Configuration "github.com/donnyhardyanto/dxlib/configuration"
 	"github.com/donnyhardyanto/dxlib/core"
 	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/tracing"
 	"github.com/donnyhardyanto/dxlib/utils"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"io"
 	"net/http"
 	"strings"
@@ -79,7 +82,7 @@ func (osm *DXObjectStorageManager) NewObjectStorage(nameId string, isConnectAtSt
 		HasPassword:      false,
 		BasePath:         "/",
 		UseSSL:           false,
-		Context:          core.RootContext,
+		Context:          context.WithoutCancel(core.RootContext),
 	}
 	osm.ObjectStorages[nameId] = &r
 	return &r
@@ -273,6 +276,13 @@ func (r *DXObjectStorage) Disconnect() (err error) {
 	return nil
 }
 
+// WithContext returns a copy sharing the client whose transfers run with ctx, usually aepr.Context so the transfers join the request trace
+func (r *DXObjectStorage) WithContext(ctx context.Context) *DXObjectStorage {
+	c := *r
+	c.Context = ctx
+	return &c
+}
+
 func (r *DXObjectStorage) UploadStream(reader io.Reader, objectName string, originalFilename string, contentType string, disableMultipart bool, objectSize int64) (uploadInfo *minio.UploadInfo, err error) {
 	if r.Client == nil {
 		return nil, log.Log.ErrorAndCreateErrorf("CLIENT_IS_NIL")
@@ -282,8 +292,17 @@ func (r *DXObjectStorage) UploadStream(reader io.Reader, objectName string, orig
 		fullPathObjectName += "/"
 	}
 	fullPathObjectName = fullPathObjectName + objectName
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	ctx, cancel := context.WithTimeout(r.Context, 10*time.Minute)
 	defer cancel()
+	ctx, span := tracing.StartSpan(ctx, "object_storage upload", trace.SpanKindClient,
+		attribute.String("object_storage.name_id", r.NameId),
+		attribute.String("object_storage.bucket", r.BucketName),
+		attribute.String("object_storage.object", fullPathObjectName),
+		attribute.Int64("object_storage.size", objectSize),
+	)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
 	/*info, err := r.Client.PutObject(
 		ctx,
 		r.BucketName,
@@ -328,7 +347,7 @@ func (r *DXObjectStorage) ReceiveStreamObject(aepr *api.DXAPIEndPointRequest, fi
 
 	s := aepr.Request.Body
 
-	uploadInfo, err := r.UploadStream(s, filename, filename, "application/octet-stream", false, bodyLen)
+	uploadInfo, err := r.WithContext(aepr.Context).UploadStream(s, filename, filename, "application/octet-stream", false, bodyLen)
 	if err != nil {
 		return errors.Wrap(err, "error occured")
 	}
@@ -336,7 +355,7 @@ func (r *DXObjectStorage) ReceiveStreamObject(aepr *api.DXAPIEndPointRequest, fi
 	return nil
 }
 
-func (r *DXObjectStorage) DownloadStream(objectName string) (*minio.Object, error) {
+func (r *DXObjectStorage) DownloadStream(objectName string) (object *minio.Object, err error) {
 	if r.Client == nil {
 		return nil, log.Log.ErrorAndCreateErrorf("CLIENT_IS_NIL")
 	}
@@ -347,8 +366,17 @@ func (r *DXObjectStorage) DownloadStream(objectName string) (*minio.Object, erro
 	}
 	fullPathObjectName = fullPathObjectName + objectName
 
+	ctx, span := tracing.StartSpan(r.Context, "object_storage download", trace.SpanKindClient,
+		attribute.String("object_storage.name_id", r.NameId),
+		attribute.String("object_storage.bucket", r.BucketName),
+		attribute.String("object_storage.object", fullPathObjectName),
+	)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	// Get the object from the bucket
-	object, err := r.Client.GetObject(context.Background(), r.BucketName, fullPathObjectName, minio.GetObjectOptions{})
+	object, err = r.Client.GetObject(ctx, r.BucketName, fullPathObjectName, minio.GetObjectOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -359,7 +387,7 @@ func (r *DXObjectStorage) DownloadStream(objectName string) (*minio.Object, erro
 
 func (r *DXObjectStorage) SendStreamObject(aepr *api.DXAPIEndPointRequest, filename string) (err error) {
 	// Get the object storage bucket using the bucket_name
-	object, err := r.DownloadStream(filename)
+	object, err := r.WithContext(aepr.Context).DownloadStream(filename)
 	if err != nil {
 		return aepr.WriteResponseAndNewErrorf(http.StatusInternalServerError, "", "ERROR_IN_DOWNLOAD_STREAM:%s", err.Error())
 	}
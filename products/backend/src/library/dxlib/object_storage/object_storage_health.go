@@ -0,0 +1,29 @@
+package object_storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/donnyhardyanto/dxlib/health"
+)
+
+// RegisterHealthChecks adds a readiness check of the bucket of every object storage, the ones that must be connected are critical
+func (osm *DXObjectStorageManager) RegisterHealthChecks() {
+	for _, r := range osm.ObjectStorages {
+		r := r
+		health.Manager.RegisterCheck("object_storage."+r.NameId, r.MustConnected, 0, func(ctx context.Context) (err error) {
+			if (r.Client == nil) || !r.Connected {
+				return errors.Errorf("OBJECT_STORAGE_NOT_CONNECTED:%s", r.NameId)
+			}
+			isExist, err := r.Client.BucketExists(ctx, r.BucketName)
+			if err != nil {
+				return errors.Wrap(err, "error occured")
+			}
+			if !isExist {
+				return errors.Errorf("OBJECT_STORAGE_BUCKET_NOT_FOUND:%s", r.BucketName)
+			}
+			return nil
+		})
+	}
+}
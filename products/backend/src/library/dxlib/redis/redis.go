@@ -46,7 +46,7 @@ func (rs *DXRedisManager) NewRedis(nameId string, isConnectAtStart, mustConnecte
 		HasUserName:      false,
 		HasPassword:      false,
 		DatabaseIndex:    0,
-		Context:          core.RootContext,
+		Context:          context.WithoutCancel(core.RootContext),
 	}
 	rs.Redises[nameId] = &r
 	return &r
@@ -186,6 +186,8 @@ func (r *DXRedis) Connect() (err error) {
 			redisRingOptions.Password = r.Password
 		}
 		connection := redis.NewRing(redisRingOptions)
+		connection.AddHook(redisMetricsHook{NameId: r.NameId})
+		connection.AddHook(redisTracingHook{NameId: r.NameId})
 		err = connection.Ping(r.Context).Err()
 		if err != nil {
 			if r.MustConnected {
@@ -224,6 +226,20 @@ func (r *DXRedis) Set(key string, value utils.JSON, expirationDuration time.Dura
 	return nil
 }
 
+// SetNX stores the value only when the key does not exist yet, isSet is false when another writer already holds the key
+func (r *DXRedis) SetNX(key string, value utils.JSON, expirationDuration time.Duration) (isSet bool, err error) {
+	valueAsBytes, err := json.Marshal(value)
+	if err != nil {
+		return false, errors.Wrapf(err, "Cannot save to Redis %s k/v (%v) %s/%v", r.NameId, err, key, value)
+	}
+
+	isSet, err = r.Connection.SetNX(r.Context, key, valueAsBytes, expirationDuration).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "Cannot save to Redis %s k/v (%v) %s/%v", r.NameId, err, key, value)
+	}
+	return isSet, nil
+}
+
 func (r *DXRedis) Get(key string) (value utils.JSON, err error) {
 	valueAsBytes, err := r.Connection.Get(r.Context, key).Bytes()
 	if err != nil {
@@ -277,6 +293,65 @@ func (r *DXRedis) Delete(key string) (err error) {
 	return nil
 }
 
+// DeleteExisting deletes the key and reports whether this call removed it, of concurrent callers only one gets true
+func (r *DXRedis) DeleteExisting(key string) (isDeleted bool, err error) {
+	n, err := r.Connection.Del(r.Context, key).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "Error in deleting key Redis %s k/v (%v) %s", r.NameId, err, key)
+	}
+	return n > 0, nil
+}
+
+var redisIncrScript = redis.NewScript(`local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count`)
+
+// Incr increments the plain integer counter at key in one step, the expiration is set by the first increment only so later increments never extend it
+func (r *DXRedis) Incr(key string, expirationDuration time.Duration) (count int64, err error) {
+	count, err = redisIncrScript.Run(r.Context, r.Connection, []string{key}, expirationDuration.Milliseconds()).Int64()
+	if err != nil {
+		return 0, errors.Wrapf(err, "Cannot increment in Redis %s k/v (%v) %s", r.NameId, err, key)
+	}
+	return count, nil
+}
+
+var redisRPushScript = redis.NewScript(`local length = redis.call('RPUSH', KEYS[1], ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return length`)
+
+// RPush appends value to the list at key and returns the new length, every append moves the expiration of the whole list
+func (r *DXRedis) RPush(key string, value utils.JSON, expirationDuration time.Duration) (length int64, err error) {
+	valueAsBytes, err := json.Marshal(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Cannot save to Redis %s k/v (%v) %s/%v", r.NameId, err, key, value)
+	}
+	length, err = redisRPushScript.Run(r.Context, r.Connection, []string{key}, valueAsBytes, expirationDuration.Milliseconds()).Int64()
+	if err != nil {
+		return 0, errors.Wrapf(err, "Cannot save to Redis %s k/v (%v) %s/%v", r.NameId, err, key, value)
+	}
+	return length, nil
+}
+
+// LRange returns the values of the list at key from index start to the end, a missing key gives an empty list
+func (r *DXRedis) LRange(key string, start int64) (values []utils.JSON, err error) {
+	valuesAsStrings, err := r.Connection.LRange(r.Context, key, start, -1).Result()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot get to Redis %s k/v (%s) %s", r.NameId, err.Error(), key)
+	}
+	values = make([]utils.JSON, 0, len(valuesAsStrings))
+	for _, valueAsString := range valuesAsStrings {
+		var value utils.JSON
+		err = json.Unmarshal([]byte(valueAsString), &value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot unmarshall from bytes in Redis %s k/v (%s) %s/%v", r.NameId, err.Error(), key, valueAsString)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
 func (r *DXRedis) Disconnect() (err error) {
 	if r.Connected {
 		log.Log.Infof("Disconnecting to Redis %s at %s/%d... start", r.NameId, r.Address, r.DatabaseIndex)
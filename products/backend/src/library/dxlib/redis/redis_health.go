@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/donnyhardyanto/dxlib/health"
+)
+
+// RegisterHealthChecks adds a ping readiness check for every Redis, the ones that must be connected are critical
+func (rs *DXRedisManager) RegisterHealthChecks() {
+	for _, r := range rs.Redises {
+		r := r
+		health.Manager.RegisterCheck("redis."+r.NameId, r.MustConnected, 0, func(ctx context.Context) (err error) {
+			if (r.Connection == nil) || !r.Connected {
+				return errors.Errorf("REDIS_NOT_CONNECTED:%s", r.NameId)
+			}
+			err = r.Connection.Ping(ctx).Err()
+			if err != nil {
+				return errors.Wrap(err, "error occured")
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/metrics"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+var metricRedisCommandDuration = metrics.Manager.NewHistogram("dxlib_redis_command_duration_seconds", "Duration of Redis commands, by command name and result", metrics.DefaultDurationBuckets, "redis", "command", "result")
+
+type redisMetricsStartTimeKey struct{}
+
+// redisMetricsHook times every command of a connection, a missing key is not counted as an error
+type redisMetricsHook struct {
+	NameId string
+}
+
+func redisCommandResult(err error) string {
+	if (err == nil) || errors.Is(err, redis.Nil) {
+		return "ok"
+	}
+	return "error"
+}
+
+func (h redisMetricsHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisMetricsStartTimeKey{}, time.Now()), nil
+}
+
+func (h redisMetricsHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	startTime, ok := ctx.Value(redisMetricsStartTimeKey{}).(time.Time)
+	if ok {
+		metricRedisCommandDuration.Observe(time.Since(startTime).Seconds(), h.NameId, cmd.Name(), redisCommandResult(cmd.Err()))
+	}
+	return nil
+}
+
+func (h redisMetricsHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisMetricsStartTimeKey{}, time.Now()), nil
+}
+
+func (h redisMetricsHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	startTime, ok := ctx.Value(redisMetricsStartTimeKey{}).(time.Time)
+	if ok {
+		var err error
+		for _, cmd := range cmds {
+			if redisCommandResult(cmd.Err()) != "ok" {
+				err = cmd.Err()
+				break
+			}
+		}
+		metricRedisCommandDuration.Observe(time.Since(startTime).Seconds(), h.NameId, "pipeline", redisCommandResult(err))
+	}
+	return nil
+}
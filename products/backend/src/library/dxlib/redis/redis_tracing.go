@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/donnyhardyanto/dxlib/tracing"
+)
+
+// redisTracingHook starts a client span for every command, the span is a child of the span in the context given to the command
+type redisTracingHook struct {
+	NameId string
+}
+
+// WithContext returns a copy sharing the connection whose commands run with ctx, usually aepr.Context so the commands join the request trace
+func (r *DXRedis) WithContext(ctx context.Context) *DXRedis {
+	c := *r
+	c.Context = ctx
+	return &c
+}
+
+func (h redisTracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, _ = tracing.StartSpan(ctx, "redis "+cmd.Name(), trace.SpanKindClient,
+		semconv.DBSystemRedis,
+		semconv.DBNamespace(h.NameId),
+		semconv.DBOperationName(cmd.Name()),
+	)
+	return ctx, nil
+}
+
+func (h redisTracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	err := cmd.Err()
+	if redisCommandResult(err) == "ok" {
+		err = nil
+	}
+	tracing.EndSpan(trace.SpanFromContext(ctx), err)
+	return nil
+}
+
+func (h redisTracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	names := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		names = append(names, cmd.Name())
+	}
+	ctx, _ = tracing.StartSpan(ctx, "redis pipeline", trace.SpanKindClient,
+		semconv.DBSystemRedis,
+		semconv.DBNamespace(h.NameId),
+		semconv.DBOperationName(strings.Join(names, " ")),
+	)
+	return ctx, nil
+}
+
+func (h redisTracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if redisCommandResult(cmd.Err()) != "ok" {
+			err = cmd.Err()
+			break
+		}
+	}
+	tracing.EndSpan(trace.SpanFromContext(ctx), err)
+	return nil
+}
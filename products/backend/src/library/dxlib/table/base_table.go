@@ -7,6 +7,7 @@ import (
 	"github.com/donnyhardyanto/dxlib/database"
 	"github.com/donnyhardyanto/dxlib/database/protected/db"
 	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/tracing"
 	"github.com/donnyhardyanto/dxlib/utils"
 	utilsJson "github.com/donnyhardyanto/dxlib/utils/json"
 	"github.com/pkg/errors"
@@ -58,6 +59,11 @@ func (bt *DXBaseTable) Select(log *log.DXLog, fieldNames []string, whereAndField
 		return nil, nil, err
 	}
 
+	_, span := database.StartSpan(log, bt.Database.DatabaseType.Driver(), "SELECT", bt.ListViewNameId)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	rowsInfo, r, err = bt.Database.Select(bt.ListViewNameId, bt.FieldTypeMapping, fieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections, limit, forUpdatePart)
 	if err != nil {
 		return rowsInfo, nil, err
@@ -74,7 +80,13 @@ func (bt *DXBaseTable) ShouldSelectOne(log *log.DXLog, whereAndFieldNameValues u
 		return nil, nil, err
 	}
 
-	return bt.Database.ShouldSelectOne(bt.ListViewNameId, bt.FieldTypeMapping, nil, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections)
+	_, span := database.StartSpan(log, bt.Database.DatabaseType.Driver(), "SELECT", bt.ListViewNameId)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	rowsInfo, r, err = bt.Database.ShouldSelectOne(bt.ListViewNameId, bt.FieldTypeMapping, nil, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections)
+	return rowsInfo, r, err
 }
 
 func (bt *DXBaseTable) SelectOne(log *log.DXLog, fieldNames []string, whereAndFieldNameValues utils.JSON, joinSQLPart any, orderbyFieldNameDirections db.FieldsOrderBy) (
@@ -95,7 +107,13 @@ func (bt *DXBaseTable) SelectOne(log *log.DXLog, fieldNames []string, whereAndFi
 		}
 	}
 
-	return bt.Database.SelectOne(bt.ListViewNameId, bt.FieldTypeMapping, fieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections)
+	_, span := database.StartSpan(log, bt.Database.DatabaseType.Driver(), "SELECT", bt.ListViewNameId)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	rowsInfo, r, err = bt.Database.SelectOne(bt.ListViewNameId, bt.FieldTypeMapping, fieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections)
+	return rowsInfo, r, err
 }
 
 func (t *DXBaseTable) GetById(log *log.DXLog, id int64) (rowsInfo *db.RowsInfo, r utils.JSON, err error) {
@@ -8,6 +8,7 @@ import (
 	"github.com/donnyhardyanto/dxlib/database/protected/db"
 	"github.com/donnyhardyanto/dxlib/database/protected/export"
 	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/tracing"
 	"github.com/donnyhardyanto/dxlib/utils"
 	utilsJson "github.com/donnyhardyanto/dxlib/utils/json"
 	"github.com/pkg/errors"
@@ -235,6 +236,11 @@ func (t *DXTable) Insert(log *log.DXLog, newKeyValues utils.JSON) (newId int64,
 		t.Database = database.Manager.Databases[t.DatabaseNameId]
 	}
 
+	_, span := database.StartSpan(log, t.Database.DatabaseType.Driver(), "INSERT", t.NameId)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	newId, err = t.Database.Insert(t.NameId, t.FieldNameForRowId, newKeyValues)
 	return newId, err
 }
@@ -286,9 +292,15 @@ func (t *DXTable) UpdateOne(l *log.DXLog, FieldValueForId int64, setKeyValues ut
 		t.Database = database.Manager.Databases[t.DatabaseNameId]
 	}
 
-	return t.Database.Update(t.NameId, setKeyValues, utils.JSON{
+	_, span := database.StartSpan(l, t.Database.DatabaseType.Driver(), "UPDATE", t.NameId)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	result, err = t.Database.Update(t.NameId, setKeyValues, utils.JSON{
 		t.FieldNameForRowId: FieldValueForId,
 	})
+	return result, err
 }
 
 func (t *DXTable) InRequestInsert(aepr *api.DXAPIEndPointRequest, newKeyValues utils.JSON) (newId int64, err error) {
@@ -656,6 +668,11 @@ func (t *DXTable) Count(log *log.DXLog, summaryCalcFieldsPart string, whereAndFi
 		t.Database = database.Manager.Databases[t.DatabaseNameId]
 	}
 
+	_, span := database.StartSpan(log, t.Database.DatabaseType.Driver(), "SELECT", t.ListViewNameId)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	totalRows, summaryCalcRow, err = t.Database.CountOne(t.ListViewNameId, summaryCalcFieldsPart, whereAndFieldNameValues, joinSQLPart)
 	return totalRows, summaryCalcRow, err
 }
@@ -701,6 +718,11 @@ func (t *DXTable) Select(log *log.DXLog, fieldNames []string, whereAndFieldNameV
 		t.Database = database.Manager.Databases[t.DatabaseNameId]
 	}
 
+	_, span := database.StartSpan(log, t.Database.DatabaseType.Driver(), "SELECT", t.ListViewNameId)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	rowsInfo, r, err = t.Database.Select(t.ListViewNameId, t.FieldTypeMapping, fieldNames, whereAndFieldNameValues,
 		joinSQLPart, orderByFieldNameDirections, limit, nil)
 	if err != nil {
@@ -722,7 +744,13 @@ func (t *DXTable) ShouldSelectOne(log *log.DXLog, whereAndFieldNameValues utils.
 		t.Database = database.Manager.Databases[t.DatabaseNameId]
 	}
 
-	return t.Database.ShouldSelectOne(t.ListViewNameId, t.FieldTypeMapping, nil, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections)
+	_, span := database.StartSpan(log, t.Database.DatabaseType.Driver(), "SELECT", t.ListViewNameId)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	rowsInfo, r, err = t.Database.ShouldSelectOne(t.ListViewNameId, t.FieldTypeMapping, nil, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections)
+	return rowsInfo, r, err
 }
 
 func (t *DXTable) TxShouldSelectOne(tx *database.DXDatabaseTx, whereAndFieldNameValues utils.JSON,
@@ -1070,7 +1098,13 @@ func (t *DXTable) SelectOne(log *log.DXLog, fieldNames []string, whereAndFieldNa
 		t.Database = database.Manager.Databases[t.DatabaseNameId]
 	}
 
-	return t.Database.SelectOne(t.ListViewNameId, t.FieldTypeMapping, fieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections)
+	_, span := database.StartSpan(log, t.Database.DatabaseType.Driver(), "SELECT", t.ListViewNameId)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	rowsInfo, r, err = t.Database.SelectOne(t.ListViewNameId, t.FieldTypeMapping, fieldNames, whereAndFieldNameValues, joinSQLPart, orderbyFieldNameDirections)
+	return rowsInfo, r, err
 }
 
 func (t *DXTable) IsFieldValueExistAsString(log *log.DXLog, fieldName string, fieldValue string) (bool, error) {
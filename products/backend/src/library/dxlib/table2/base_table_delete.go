@@ -16,7 +16,7 @@ func (bt *DXBaseTable2) Delete(whereKeyValues utils.JSON) (err error) {
 		return err
 	}
 
-	_, _, err = db.Delete(bt.Database.Connection, bt.NameId, whereKeyValues, nil)
+	_, _, err = db.Delete(bt.Database.Context, bt.Database.Connection, bt.NameId, whereKeyValues, nil)
 	if err != nil {
 		return err
 	}
@@ -211,7 +211,7 @@ func (bt *DXBaseTable2) DoRequestList(aepr *api.DXAPIEndPointRequest, filterWher
 		return err
 	}
 
-	rowsInfo, list, err := raw.QueryRows(bt.Database.Connection, bt.FieldTypeMapping, sqlStatement, filterKeyValues)
+	rowsInfo, list, err := raw.QueryRows(aepr.Context, bt.Database.Connection, bt.FieldTypeMapping, sqlStatement, filterKeyValues)
 
 	if err != nil {
 		return err
@@ -291,12 +291,12 @@ func (bt *DXBaseTable2) DoRequestPagingList(aepr *api.DXAPIEndPointRequest, filt
 		}
 	}()
 
-	count, err := raw.TxQueryRows(dtx, bt.FieldTypeMapping, sqlCountStatement, filterKeyValues)
+	count, err := raw.TxQueryRows(aepr.Context, dtx, bt.FieldTypeMapping, sqlCountStatement, filterKeyValues)
 	if err != nil {
 		return err
 	}
 
-	_, countList, err := raw.QueryRows(bt.Database.Connection, bt.FieldTypeMapping, sqlCountStatement, filterKeyValues)
+	_, countList, err := raw.QueryRows(aepr.Context, bt.Database.Connection, bt.FieldTypeMapping, sqlCountStatement, filterKeyValues)
 	if err != nil {
 		return err
 	}
@@ -304,7 +304,7 @@ func (bt *DXBaseTable2) DoRequestPagingList(aepr *api.DXAPIEndPointRequest, filt
 	totalRows := int(countList[0].(utils.JSON)["count_result"].(float64))
 	totalPage := int((totalRows + rowPerPage - 1) / rowPerPage)
 
-	rowsInfo, list, err := raw.QueryRows(bt.Database.Connection, bt.FieldTypeMapping, sqlStatement, filterKeyValues)
+	rowsInfo, list, err := raw.QueryRows(aepr.Context, bt.Database.Connection, bt.FieldTypeMapping, sqlStatement, filterKeyValues)
 	if err != nil {
 		return errors.Wrap(err, "error occured")
 	}
@@ -610,7 +610,7 @@ func (pt *DXPropertyTable) DoEdit(aepr *api.DXAPIEndPointRequest, id int64, newK
 	if pt.Database == nil {
 		pt.Database = database2.Manager.Databases[pt.DatabaseNameId]
 	}
-	_, err = db.Update(pt.Database.Connection, pt.NameId, newKeyValues, utils.JSON{
+	_, err = db.Update(aepr.Context, pt.Database.Connection, pt.NameId, newKeyValues, utils.JSON{
 		pt.FieldNameForRowId: id,
 		"is_deleted":         false,
 	})
@@ -653,7 +653,7 @@ func (pt *DXPropertyTable) DoEditByUid(aepr *api.DXAPIEndPointRequest, uid strin
 	if pt.Database == nil {
 		pt.Database = database2.Manager.Databases[pt.DatabaseNameId]
 	}
-	_, err = db.Update(pt.Database.Connection, pt.NameId, newKeyValues, utils.JSON{
+	_, err = db.Update(aepr.Context, pt.Database.Connection, pt.NameId, newKeyValues, utils.JSON{
 		pt.FieldNameForRowUid: uid,
 		"is_deleted":          false,
 	})
@@ -707,7 +707,7 @@ func (pt *DXPropertyTable) DoDelete(aepr *api.DXAPIEndPointRequest, id int64) (e
 	if pt.Database == nil {
 		pt.Database = database2.Manager.Databases[pt.DatabaseNameId]
 	}
-	_, err = db.Delete(pt.Database.Connection, pt.NameId, utils.JSON{
+	_, err = db.Delete(aepr.Context, pt.Database.Connection, pt.NameId, utils.JSON{
 		pt.FieldNameForRowId: id,
 	})
 	if err != nil {
@@ -727,7 +727,7 @@ func (pt *DXPropertyTable) DoDeleteByUid(aepr *api.DXAPIEndPointRequest, uid str
 	if pt.Database == nil {
 		pt.Database = database2.Manager.Databases[pt.DatabaseNameId]
 	}
-	_, err = db.Delete(pt.Database.Connection, pt.NameId, utils.JSON{
+	_, err = db.Delete(aepr.Context, pt.Database.Connection, pt.NameId, utils.JSON{
 		pt.FieldNameForRowUid: uid,
 	})
 	if err != nil {
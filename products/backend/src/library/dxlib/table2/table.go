@@ -390,7 +390,7 @@ func (t *DXTable2) DoEdit(aepr *api.DXAPIEndPointRequest, id int64, newKeyValues
 		t.Database = database2.Manager.Databases[t.DatabaseNameId]
 	}
 
-	_, err = db.Update(t.Database.Connection, t.NameId, newKeyValues, utils.JSON{
+	_, err = db.Update(aepr.Context, t.Database.Connection, t.NameId, newKeyValues, utils.JSON{
 		t.FieldNameForRowId: id,
 		"is_deleted":        false,
 	})
@@ -434,7 +434,7 @@ func (t *DXTable2) DoEditByUid(aepr *api.DXAPIEndPointRequest, uid string, newKe
 		t.Database = database2.Manager.Databases[t.DatabaseNameId]
 	}
 
-	_, err = db.Update(t.Database.Connection, t.NameId, newKeyValues, utils.JSON{
+	_, err = db.Update(aepr.Context, t.Database.Connection, t.NameId, newKeyValues, utils.JSON{
 		t.FieldNameForRowUid: uid,
 		"is_deleted":         false,
 	})
@@ -489,7 +489,7 @@ func (t *DXTable2) DoDelete(aepr *api.DXAPIEndPointRequest, id int64) (err error
 		t.Database = database2.Manager.Databases[t.DatabaseNameId]
 	}
 
-	_, err = db.Delete(t.Database.Connection, t.NameId, utils.JSON{
+	_, err = db.Delete(aepr.Context, t.Database.Connection, t.NameId, utils.JSON{
 		t.FieldNameForRowId: id,
 	})
 	if err != nil {
@@ -510,7 +510,7 @@ func (t *DXTable2) DoDeleteByUid(aepr *api.DXAPIEndPointRequest, uid string) (er
 		t.Database = database2.Manager.Databases[t.DatabaseNameId]
 	}
 
-	_, err = db.Delete(t.Database.Connection, t.NameId, utils.JSON{
+	_, err = db.Delete(aepr.Context, t.Database.Connection, t.NameId, utils.JSON{
 		t.FieldNameForRowUid: uid,
 	})
 	if err != nil {
@@ -531,7 +531,7 @@ func (t *DXTable2) SoftDelete(aepr *api.DXAPIEndPointRequest, id int64) (err err
 		t.Database = databas2.Manager.Databases[t.DatabaseNameId]
 	}
 
-	_, err = db.Update(t.Database.Connection, t.NameId, utils.JSON{
+	_, err = db.Update(aepr.Context, t.Database.Connection, t.NameId, utils.JSON{
 		"is_deleted": true,
 	}, utils.JSON{
 		t.FieldNameForRowId: id,
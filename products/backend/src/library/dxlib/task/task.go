@@ -27,6 +27,7 @@ type DXTask struct {
 	RuntimeIsActive bool
 	Context         context.Context
 	Cancel          context.CancelFunc
+	done            chan struct{}
 }
 
 type DXTaskManager struct {
@@ -56,19 +57,6 @@ func (am *DXTaskManager) StartAll(errorGroup *errgroup.Group, errorGroupContext
 	am.ErrorGroup = errorGroup
 	am.ErrorGroupContext = errorGroupContext
 
-	am.ErrorGroup.Go(func() (err error) {
-		<-am.ErrorGroupContext.Done()
-		log.Log.Info("Task Manager shutting down... start")
-		for _, v := range am.Tasks {
-			vErr := v.StartShutdown()
-			if (err == nil) && (vErr != nil) {
-				err = vErr
-			}
-		}
-		log.Log.Info("Task Manager shutting down... done")
-		return nil
-	})
-
 	for _, v := range am.Tasks {
 		err := v.StartAndWait(am.ErrorGroup)
 		if err != nil {
@@ -78,10 +66,21 @@ func (am *DXTaskManager) StartAll(errorGroup *errgroup.Group, errorGroupContext
 	return nil
 }
 
-func (am *DXTaskManager) StopAll() (err error) {
-	am.ErrorGroupContext.Done()
-	err = am.ErrorGroup.Wait()
-	return errors.Wrap(err, "error occured")
+// StopAll cancels every task and waits until the running executions finish or ctx is done
+func (am *DXTaskManager) StopAll(ctx context.Context) (err error) {
+	log.Log.Info("Task Manager shutting down... start")
+	for _, v := range am.Tasks {
+		_ = v.StartShutdown()
+	}
+	for _, v := range am.Tasks {
+		err = v.Wait(ctx)
+		if err != nil {
+			log.Log.Warnf("Task Manager shutting down... deadline reached, task %s is still running", v.NameId)
+			return err
+		}
+	}
+	log.Log.Info("Task Manager shutting down... done")
+	return nil
 }
 
 func (a *DXTask) ApplyConfigurations() (err error) {
@@ -113,29 +112,31 @@ func (a *DXTask) StartAndWait(errorGroup *errgroup.Group) error {
 		if err != nil {
 			return errors.Wrap(err, "error occured")
 		}
+		a.done = make(chan struct{})
 		errorGroup.Go(func() (err error) {
+			defer close(a.done)
 			a.RuntimeIsActive = true
 			log.Log.Infof("Starting task [%s] at %s... start", a.NameId, a.StartAt)
 			switch a.StartAt {
 			case "once":
 				log.Log.Infof("Task %s at (%s): Starting task start", a.NameId, a.StartAt)
-				err = a.OnExecute(a)
-				log.Log.Infof("Task %s at (%s): Task done: %v", a.NameId, a.StartAt, err.Error())
+				err = a.execute()
+				log.Log.Infof("Task %s at (%s): Task done: %v", a.NameId, a.StartAt, err)
 				log.Log.Info("Start AfterDelay sleep...")
-				time.Sleep(time.Duration(a.AfterDelaySec) * time.Second)
+				a.sleep(time.Duration(a.AfterDelaySec) * time.Second)
 				log.Log.Info("Finish AfterDelay sleep...")
 			case "always":
 				inLoop := true
 				var iterationIndex uint64 = 0
 				for inLoop {
 					log.Log.Infof("Task %s:%v at (%s): Execute task start", a.NameId, iterationIndex, a.StartAt)
-					err = a.OnExecute(a)
-					log.Log.Infof("Task %s:%v at (%s): Execute task done with result err=%v", a.NameId, iterationIndex, a.StartAt, err.Error())
+					err = a.execute()
+					log.Log.Infof("Task %s:%v at (%s): Execute task done with result err=%v", a.NameId, iterationIndex, a.StartAt, err)
 					if err != nil {
 						inLoop = false
 					} else {
 						log.Log.Infof("Task %s:%v at (%s): Start AfterDelay sleep... %v sec", a.NameId, iterationIndex, a.StartAt, a.AfterDelaySec)
-						time.Sleep(time.Duration(a.AfterDelaySec) * time.Second)
+						a.sleep(time.Duration(a.AfterDelaySec) * time.Second)
 						log.Log.Infof("Task %s:%v at (%s) Finish AfterDelay sleep...", a.NameId, iterationIndex, a.StartAt)
 						select {
 						case <-a.Context.Done():
@@ -159,19 +160,42 @@ func (a *DXTask) StartAndWait(errorGroup *errgroup.Group) error {
 	return nil
 }
 
+// sleep returns early once the task is cancelled
+func (a *DXTask) sleep(d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-a.Context.Done():
+	}
+}
+
 func (a *DXTask) StartShutdown() (err error) {
 	if a.RuntimeIsActive {
-		log.Log.Infof("Shutdown api %s start...", a.NameId)
-		a.Cancel()
-		return errors.Wrap(err, "error occured")
+		log.Log.Infof("Shutdown task %s start...", a.NameId)
 	}
+	a.Cancel()
 	return nil
 }
 
+// Wait blocks until the started task returns or ctx is done
+func (a *DXTask) Wait(ctx context.Context) (err error) {
+	if a.done == nil {
+		return nil
+	}
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "TASK_STILL_RUNNING:%s", a.NameId)
+	}
+}
+
 var Manager DXTaskManager
 
 func init() {
-	ctx, cancel := context.WithCancel(core.RootContext)
+	// tasks are cancelled by StopAll during the shutdown sequence, not directly by the shutdown signal
+	ctx, cancel := context.WithCancel(context.WithoutCancel(core.RootContext))
 	Manager = DXTaskManager{
 		Context: ctx,
 		Cancel:  cancel,
@@ -0,0 +1,22 @@
+package task
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/donnyhardyanto/dxlib/health"
+)
+
+// RegisterHealthChecks adds a non critical readiness check for every task started "always", such a task is expected to keep running
+func (am *DXTaskManager) RegisterHealthChecks() {
+	for _, a := range am.Tasks {
+		a := a
+		health.Manager.RegisterCheck("task."+a.NameId, false, 0, func(ctx context.Context) (err error) {
+			if (a.StartAt == "always") && !a.RuntimeIsActive {
+				return errors.Errorf("TASK_NOT_RUNNING:%s", a.NameId)
+			}
+			return nil
+		})
+	}
+}
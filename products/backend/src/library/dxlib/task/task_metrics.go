@@ -0,0 +1,25 @@
+package task
+
+import (
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/metrics"
+)
+
+var (
+	metricTaskRuns        = metrics.Manager.NewCounter("dxlib_task_runs_total", "Executions of a task, by result", "task", "result")
+	metricTaskRunDuration = metrics.Manager.NewHistogram("dxlib_task_run_duration_seconds", "Duration of task executions", metrics.DefaultDurationBuckets, "task")
+)
+
+// execute runs OnExecute once and records its duration and result
+func (a *DXTask) execute() (err error) {
+	startTime := time.Now()
+	err = a.OnExecute(a)
+	metricTaskRunDuration.Observe(time.Since(startTime).Seconds(), a.NameId)
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	metricTaskRuns.Inc(a.NameId, result)
+	return err
+}
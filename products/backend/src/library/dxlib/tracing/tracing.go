@@ -0,0 +1,230 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	dxlibConfiguration "github.com/donnyhardyanto/dxlib/configuration"
+	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/utils"
+	utilsJSON "github.com/donnyhardyanto/dxlib/utils/json"
+)
+
+const (
+	TracerName = "github.com/donnyhardyanto/dxlib"
+
+	ProtocolGRPC         = "grpc"
+	ProtocolHTTPProtobuf = "http/protobuf"
+
+	DXTracingDefaultShutdownTimeoutSec = 10
+	DXTracingMaxStatementLength        = 4096
+)
+
+// DXTracingManager configures the global OpenTelemetry tracer provider, spans are exported with OTLP when enabled.
+// The endpoint is either host:port or a URL, when empty the exporter falls back to the OTEL_EXPORTER_OTLP_* environment variables.
+// Without a started manager the spans are not recorded, but the W3C trace context of incoming requests is still propagated
+type DXTracingManager struct {
+	IsEnabled      bool
+	ServiceName    string
+	Protocol       string
+	Endpoint       string
+	IsInsecure     bool
+	Headers        map[string]string
+	SampleRatio    float64
+	TracerProvider *sdktrace.TracerProvider
+}
+
+func (t *DXTracingManager) ApplyConfiguration(j utils.JSON) (err error) {
+	t.IsEnabled, _ = j["enabled"].(bool)
+	t.ServiceName, _ = j["service-name"].(string)
+	t.Protocol, _ = j["protocol"].(string)
+	if t.Protocol == "" {
+		t.Protocol = ProtocolHTTPProtobuf
+	}
+	if (t.Protocol != ProtocolGRPC) && (t.Protocol != ProtocolHTTPProtobuf) {
+		return errors.Errorf("TRACING_PROTOCOL_INVALID:%s", t.Protocol)
+	}
+	t.Endpoint, _ = j["endpoint"].(string)
+	t.IsInsecure, _ = j["insecure"].(bool)
+	t.Headers = map[string]string{}
+	switch headers := j["headers"].(type) {
+	case utils.JSON:
+		for k, v := range headers {
+			t.Headers[k], _ = v.(string)
+		}
+	case string:
+		// the "key1=value1,key2=value2" form of OTEL_EXPORTER_OTLP_HEADERS
+		for _, item := range strings.Split(headers, ",") {
+			k, v, ok := strings.Cut(item, "=")
+			if ok {
+				t.Headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+	}
+	t.SampleRatio = utilsJSON.GetNumberWithDefault(j, "sample-ratio", 1.0)
+	return nil
+}
+
+func (t *DXTracingManager) LoadFromConfiguration(configurationNameId string) (err error) {
+	configuration, ok := dxlibConfiguration.Manager.Configurations[configurationNameId]
+	if !ok {
+		return errors.Errorf("CONFIGURATION_NOT_FOUND:%s", configurationNameId)
+	}
+	err = t.ApplyConfiguration(*configuration.Data)
+	if err != nil {
+		return errors.Wrapf(err, "CONFIGURATION_INVALID:%s", configurationNameId)
+	}
+	return nil
+}
+
+func (t *DXTracingManager) newExporter(ctx context.Context) (exporter sdktrace.SpanExporter, err error) {
+	if t.Protocol == ProtocolGRPC {
+		options := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(t.Headers)}
+		if strings.Contains(t.Endpoint, "://") {
+			options = append(options, otlptracegrpc.WithEndpointURL(t.Endpoint))
+		} else if t.Endpoint != "" {
+			options = append(options, otlptracegrpc.WithEndpoint(t.Endpoint))
+		}
+		if t.IsInsecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, options...)
+	} else {
+		options := []otlptracehttp.Option{otlptracehttp.WithHeaders(t.Headers)}
+		if strings.Contains(t.Endpoint, "://") {
+			options = append(options, otlptracehttp.WithEndpointURL(t.Endpoint))
+		} else if t.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(t.Endpoint))
+		}
+		if t.IsInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error occured")
+	}
+	return exporter, nil
+}
+
+// Start creates the OTLP exporter and installs the tracer provider, it does nothing when tracing is not enabled
+func (t *DXTracingManager) Start(ctx context.Context) (err error) {
+	if !t.IsEnabled {
+		return nil
+	}
+	log.Log.Infof("Starting tracing to %s (%s)... start", t.Endpoint, t.Protocol)
+	exporter, err := t.newExporter(ctx)
+	if err != nil {
+		return err
+	}
+	t.StartWithExporter(exporter, sdktrace.WithBatcher(exporter))
+	log.Log.Infof("Starting tracing to %s (%s)... done", t.Endpoint, t.Protocol)
+	return nil
+}
+
+// StartWithExporter installs a tracer provider exporting to exporter, tests pass tracetest.NewInMemoryExporter() and sdktrace.WithSyncer
+func (t *DXTracingManager) StartWithExporter(exporter sdktrace.SpanExporter, options ...sdktrace.TracerProviderOption) {
+	if len(options) == 0 {
+		options = []sdktrace.TracerProviderOption{sdktrace.WithSyncer(exporter)}
+	}
+	serviceName := t.ServiceName
+	if serviceName == "" {
+		serviceName = "dxlib"
+	}
+	options = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(t.SampleRatio))),
+	}, options...)
+	t.TracerProvider = sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(t.TracerProvider)
+}
+
+// Shutdown flushes the pending spans
+func (t *DXTracingManager) Shutdown() (err error) {
+	if t.TracerProvider == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DXTracingDefaultShutdownTimeoutSec*time.Second)
+	defer cancel()
+	err = t.TracerProvider.Shutdown(ctx)
+	t.TracerProvider = nil
+	if err != nil {
+		return errors.Wrap(err, "error occured")
+	}
+	return nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// StartSpan starts a child span of the span in ctx, a nil ctx starts a root span
+func StartSpan(ctx context.Context, name string, kind trace.SpanKind, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return Tracer().Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attributes...))
+}
+
+// EndSpan records err on the span before ending it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SQLStatement is the db.query.text attribute, long statements are truncated
+func SQLStatement(statement string) attribute.KeyValue {
+	if len(statement) > DXTracingMaxStatementLength {
+		statement = statement[:DXTracingMaxStatementLength]
+	}
+	return semconv.DBQueryText(statement)
+}
+
+// DBSystem maps a database/sql driver name to the db.system attribute
+func DBSystem(driverName string) attribute.KeyValue {
+	switch driverName {
+	case "postgres":
+		return semconv.DBSystemPostgreSQL
+	case "mysql":
+		return semconv.DBSystemMySQL
+	case "oracle":
+		return semconv.DBSystemOracle
+	case "sqlserver", "mssql":
+		return semconv.DBSystemMSSQL
+	}
+	return semconv.DBSystemKey.String(driverName)
+}
+
+// InjectHTTPHeader writes the trace context of ctx into the outbound request header
+func InjectHTTPHeader(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// ExtractHTTPHeader returns ctx with the remote span of the incoming request header as parent
+func ExtractHTTPHeader(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+var Manager DXTracingManager
+
+func init() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	Manager = DXTracingManager{Protocol: ProtocolHTTPProtobuf, SampleRatio: 1.0, Headers: map[string]string{}}
+}
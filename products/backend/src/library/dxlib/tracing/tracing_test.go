@@ -0,0 +1,120 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceparentPropagation(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	Manager.StartWithExporter(exporter)
+	defer func() {
+		_ = Manager.Shutdown()
+	}()
+
+	remoteTraceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	remoteSpanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+
+	tests := []struct {
+		name              string
+		traceparent       string
+		isRemoteParent    bool
+		wantTraceId       trace.TraceID
+		wantParentSpanId  trace.SpanID
+		wantParentIsValid bool
+	}{
+		{
+			name:              "incoming traceparent becomes the parent of the server span",
+			traceparent:       "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			isRemoteParent:    true,
+			wantTraceId:       remoteTraceId,
+			wantParentSpanId:  remoteSpanId,
+			wantParentIsValid: true,
+		},
+		{
+			name:              "without traceparent the server span is a root span",
+			traceparent:       "",
+			wantParentIsValid: false,
+		},
+		{
+			name:              "malformed traceparent is ignored",
+			traceparent:       "00-not-a-trace-01",
+			wantParentIsValid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			header := http.Header{}
+			if tt.traceparent != "" {
+				header.Set("traceparent", tt.traceparent)
+			}
+
+			serverCtx, serverSpan := StartSpan(ExtractHTTPHeader(context.Background(), header), "GET /v1/test", trace.SpanKindServer)
+			clientCtx, clientSpan := StartSpan(serverCtx, "GET", trace.SpanKindClient)
+			outHeader := http.Header{}
+			InjectHTTPHeader(clientCtx, outHeader)
+			EndSpan(clientSpan, nil)
+			EndSpan(serverSpan, nil)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 2 {
+				t.Fatalf("got %d spans, want 2", len(spans))
+			}
+			client, server := spans[0], spans[1]
+
+			if server.Parent.IsValid() != tt.wantParentIsValid {
+				t.Fatalf("server parent valid = %v, want %v", server.Parent.IsValid(), tt.wantParentIsValid)
+			}
+			if tt.wantParentIsValid {
+				if server.SpanContext.TraceID() != tt.wantTraceId {
+					t.Errorf("server trace id = %s, want %s", server.SpanContext.TraceID(), tt.wantTraceId)
+				}
+				if server.Parent.SpanID() != tt.wantParentSpanId {
+					t.Errorf("server parent span id = %s, want %s", server.Parent.SpanID(), tt.wantParentSpanId)
+				}
+				if server.Parent.IsRemote() != tt.isRemoteParent {
+					t.Errorf("server parent remote = %v, want %v", server.Parent.IsRemote(), tt.isRemoteParent)
+				}
+			}
+
+			if client.SpanContext.TraceID() != server.SpanContext.TraceID() {
+				t.Errorf("client trace id = %s, want %s", client.SpanContext.TraceID(), server.SpanContext.TraceID())
+			}
+			if client.Parent.SpanID() != server.SpanContext.SpanID() {
+				t.Errorf("client parent span id = %s, want %s", client.Parent.SpanID(), server.SpanContext.SpanID())
+			}
+
+			wantTraceparent := "00-" + client.SpanContext.TraceID().String() + "-" + client.SpanContext.SpanID().String() + "-01"
+			if outHeader.Get("traceparent") != wantTraceparent {
+				t.Errorf("injected traceparent = %q, want %q", outHeader.Get("traceparent"), wantTraceparent)
+			}
+		})
+	}
+}
+
+func TestDBSystem(t *testing.T) {
+	tests := []struct {
+		driverName string
+		want       string
+	}{
+		{driverName: "postgres", want: "postgresql"},
+		{driverName: "mysql", want: "mysql"},
+		{driverName: "oracle", want: "oracle"},
+		{driverName: "sqlserver", want: "mssql"},
+		{driverName: "mssql", want: "mssql"},
+		{driverName: "sqlite3", want: "sqlite3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driverName, func(t *testing.T) {
+			got := DBSystem(tt.driverName).Value.AsString()
+			if got != tt.want {
+				t.Errorf("DBSystem(%q) = %q, want %q", tt.driverName, got, tt.want)
+			}
+		})
+	}
+}
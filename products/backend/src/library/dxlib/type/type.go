@@ -73,4 +73,85 @@ func init() {
 		Db_type_mysql:      "DATETIME",
 		Db_type_oracle:     "TIMESTAMP",
 	}
+	Types = append(Types, a)
+
+	a = TypeCompatibilityMappingStruct{
+		Api_parameter_type: "uuid",
+		Go_type:            "uuid.UUID",
+		Db_type_postgres:   "UUID",
+		Db_type_sqlserver:  "UNIQUEIDENTIFIER",
+		Db_type_mysql:      "CHAR(36)",
+		Db_type_oracle:     "CHAR(36)",
+	}
+	Types = append(Types, a)
+
+	a = TypeCompatibilityMappingStruct{
+		Api_parameter_type: "nullable-bool",
+		Go_type:            "*bool",
+		Db_type_postgres:   "BOOLEAN",
+		Db_type_sqlserver:  "BIT",
+		Db_type_mysql:      "BOOLEAN",
+		Db_type_oracle:     "NUMBER(1)",
+	}
+	Types = append(Types, a)
+
+	a = TypeCompatibilityMappingStruct{
+		Api_parameter_type: "nullable-float64",
+		Go_type:            "*float64",
+		Db_type_postgres:   "FLOAT",
+		Db_type_sqlserver:  "FLOAT",
+		Db_type_mysql:      "FLOAT",
+		Db_type_oracle:     "FLOAT",
+	}
+	Types = append(Types, a)
+
+	a = TypeCompatibilityMappingStruct{
+		Api_parameter_type: "decimal",
+		Go_type:            "decimal.Decimal",
+		Db_type_postgres:   "NUMERIC(38,10)",
+		Db_type_sqlserver:  "DECIMAL(38,10)",
+		Db_type_mysql:      "DECIMAL(38,10)",
+		Db_type_oracle:     "NUMBER(38,10)",
+	}
+	Types = append(Types, a)
+
+	a = TypeCompatibilityMappingStruct{
+		Api_parameter_type: "array-float64",
+		Go_type:            "[]float64",
+		Db_type_postgres:   "FLOAT8[]",
+		Db_type_sqlserver:  "NVARCHAR(MAX)",
+		Db_type_mysql:      "JSON",
+		Db_type_oracle:     "CLOB",
+	}
+	Types = append(Types, a)
+
+	a = TypeCompatibilityMappingStruct{
+		Api_parameter_type: "array-uuid",
+		Go_type:            "[]uuid.UUID",
+		Db_type_postgres:   "UUID[]",
+		Db_type_sqlserver:  "NVARCHAR(MAX)",
+		Db_type_mysql:      "JSON",
+		Db_type_oracle:     "CLOB",
+	}
+	Types = append(Types, a)
+
+	a = TypeCompatibilityMappingStruct{
+		Api_parameter_type: "datetime-range",
+		Go_type:            "utils.DateTimeRange",
+		Db_type_postgres:   "TSTZRANGE",
+		Db_type_sqlserver:  "NVARCHAR(MAX)",
+		Db_type_mysql:      "JSON",
+		Db_type_oracle:     "CLOB",
+	}
+	Types = append(Types, a)
+
+	a = TypeCompatibilityMappingStruct{
+		Api_parameter_type: "base64-bytes",
+		Go_type:            "[]byte",
+		Db_type_postgres:   "BYTEA",
+		Db_type_sqlserver:  "VARBINARY(MAX)",
+		Db_type_mysql:      "LONGBLOB",
+		Db_type_oracle:     "BLOB",
+	}
+	Types = append(Types, a)
 }
@@ -0,0 +1,106 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RFC 6238 parameters understood by the common authenticator apps
+const (
+	SecretSize = 20
+	Digits     = 6
+	Period     = 30
+	// Skew is the number of periods accepted before and after the current one to allow for clock drift
+	Skew = 1
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateSecret() (secret string, err error) {
+	b := make([]byte, SecretSize)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "error occured")
+	}
+	return base32NoPadding.EncodeToString(b), nil
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(strings.TrimRight(secret, "="), " ", ""))
+	key, err := base32NoPadding.DecodeString(secret)
+	if err != nil {
+		return nil, errors.Errorf("TOTP_INVALID_SECRET:%v", err)
+	}
+	return key, nil
+}
+
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// CodeAt is the HOTP code of RFC 4226 for the period counter step
+func CodeAt(secret string, step int64) (code string, err error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000), nil
+}
+
+func Code(secret string, t time.Time) (code string, err error) {
+	return CodeAt(secret, Step(t))
+}
+
+// Verify checks code against the periods around t and returns the matched step, callers reject steps not after the last used one so a code can not be replayed
+func Verify(secret string, code string, t time.Time) (isValid bool, step int64, err error) {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != Digits {
+		return false, 0, nil
+	}
+	current := Step(t)
+	for i := int64(-Skew); i <= Skew; i++ {
+		expected, err := CodeAt(secret, current+i)
+		if err != nil {
+			return false, 0, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return true, current + i, nil
+		}
+	}
+	return false, 0, nil
+}
+
+// URI is the otpauth key URI encoded in the enrollment QR code
+func URI(issuer string, accountName string, secret string) string {
+	label := url.PathEscape(accountName)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+	v := url.Values{}
+	v.Set("secret", secret)
+	if issuer != "" {
+		v.Set("issuer", issuer)
+	}
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(Digits))
+	v.Set("period", fmt.Sprint(Period))
+	// authenticator apps do not all decode + as a space
+	return "otpauth://totp/" + label + "?" + strings.ReplaceAll(v.Encode(), "+", "%20")
+}
@@ -0,0 +1,139 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed "12345678901234567890" of the RFC 6238 appendix B test vectors in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// the RFC lists 8 digit codes, a 6 digit code is its last 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			got, err := Code(rfc6238Secret, time.Unix(tt.unix, 0))
+			if err != nil {
+				t.Fatalf("Code() err = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Code() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+	codeAt := func(step int64) string {
+		code, err := CodeAt(rfc6238Secret, step)
+		if err != nil {
+			t.Fatalf("CodeAt() err = %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name        string
+		secret      string
+		code        string
+		wantIsValid bool
+		wantStep    int64
+		wantErr     bool
+	}{
+		{name: "current period", secret: rfc6238Secret, code: "050471", wantIsValid: true, wantStep: step},
+		{name: "spaces are ignored", secret: rfc6238Secret, code: "050 471", wantIsValid: true, wantStep: step},
+		{name: "lowercase secret with padding", secret: "gezdgnbvgy3tqojqgezdgnbvgy3tqojq====", code: "050471", wantIsValid: true, wantStep: step},
+		{name: "previous period within skew", secret: rfc6238Secret, code: codeAt(step - 1), wantIsValid: true, wantStep: step - 1},
+		{name: "next period within skew", secret: rfc6238Secret, code: codeAt(step + 1), wantIsValid: true, wantStep: step + 1},
+		{name: "outside skew", secret: rfc6238Secret, code: codeAt(step - 2), wantIsValid: false},
+		{name: "wrong code", secret: rfc6238Secret, code: "000000", wantIsValid: false},
+		{name: "wrong length", secret: rfc6238Secret, code: "05047", wantIsValid: false},
+		{name: "invalid secret", secret: "!!!", code: "050471", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isValid, gotStep, err := Verify(tt.secret, tt.code, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if isValid != tt.wantIsValid {
+				t.Errorf("Verify() isValid = %v, want %v", isValid, tt.wantIsValid)
+			}
+			if isValid && (gotStep != tt.wantStep) {
+				t.Errorf("Verify() step = %d, want %d", gotStep, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() err = %v", err)
+	}
+	b, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() err = %v", err)
+	}
+	if a == b {
+		t.Errorf("GenerateSecret() returned the same secret twice")
+	}
+	key, err := decodeSecret(a)
+	if err != nil {
+		t.Fatalf("decodeSecret() err = %v", err)
+	}
+	if len(key) != SecretSize {
+		t.Errorf("len(secret) = %d, want %d", len(key), SecretSize)
+	}
+}
+
+func TestURI(t *testing.T) {
+	tests := []struct {
+		name        string
+		issuer      string
+		accountName string
+		wantPath    string
+		wantIssuer  string
+	}{
+		{name: "with issuer", issuer: "Acme Corp", accountName: "user@example.com", wantPath: "/Acme Corp:user@example.com", wantIssuer: "Acme Corp"},
+		{name: "without issuer", issuer: "", accountName: "user", wantPath: "/user", wantIssuer: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(URI(tt.issuer, tt.accountName, rfc6238Secret))
+			if err != nil {
+				t.Fatalf("url.Parse() err = %v", err)
+			}
+			if (u.Scheme != "otpauth") || (u.Host != "totp") {
+				t.Errorf("URI scheme and host = %s://%s, want otpauth://totp", u.Scheme, u.Host)
+			}
+			if u.Path != tt.wantPath {
+				t.Errorf("URI path = %q, want %q", u.Path, tt.wantPath)
+			}
+			q := u.Query()
+			if q.Get("secret") != rfc6238Secret {
+				t.Errorf("URI secret = %q, want %q", q.Get("secret"), rfc6238Secret)
+			}
+			if q.Get("issuer") != tt.wantIssuer {
+				t.Errorf("URI issuer = %q, want %q", q.Get("issuer"), tt.wantIssuer)
+			}
+			if (q.Get("digits") != "6") || (q.Get("period") != "30") {
+				t.Errorf("URI digits and period = %s and %s, want 6 and 30", q.Get("digits"), q.Get("period"))
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// DateTimeRange is the half open range [Start, End), the value of "datetime-range" API parameters
+type DateTimeRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+func (r DateTimeRange) IsContains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+func (r DateTimeRange) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// String is the ISO 8601 time interval "start/end"
+func (r DateTimeRange) String() string {
+	return r.Start.Format(time.RFC3339Nano) + "/" + r.End.Format(time.RFC3339Nano)
+}
+
+// Value is the PostgreSQL range literal, drivers without a range type store it as text
+func (r DateTimeRange) Value() (driver.Value, error) {
+	return "[" + r.Start.Format(time.RFC3339Nano) + "," + r.End.Format(time.RFC3339Nano) + ")", nil
+}
@@ -189,13 +189,17 @@ func GetNumber[A Number](kv utils.JSON, k string) (v A, err error) {
 	return y, nil
 }
 
+// GetNumberWithDefault accepts float64 as decoded from a configuration file and int or int64 as set in code
 func GetNumberWithDefault[A Number](kv utils.JSON, k string, defaultValue A) (v A) {
-	z, ok := kv[k].(float64)
-	if !ok {
-		return defaultValue
+	switch z := kv[k].(type) {
+	case float64:
+		return A(z)
+	case int:
+		return A(z)
+	case int64:
+		return A(z)
 	}
-	y := A(z)
-	return y
+	return defaultValue
 }
 
 func GetInt64(kv utils.JSON, k string) (v int64, err error) {
@@ -71,6 +71,18 @@ func GetEnvDefaultValueAsInt(key string, defaultValue int) int {
 	return valueInt
 }
 
+func GetEnvDefaultValueAsFloat64(key string, defaultValue float64) float64 {
+	value, isPresent := os.LookupEnv(key)
+	if !isPresent {
+		return defaultValue
+	}
+	valueFloat64, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		panic(err)
+	}
+	return valueFloat64
+}
+
 func GetEnvDefaultValueAsBool(key string, defaultValue bool) bool {
 	value, isPresent := os.LookupEnv(key)
 	if !isPresent {
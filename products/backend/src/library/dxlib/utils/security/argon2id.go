@@ -0,0 +1,129 @@
+package sql
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+const Argon2idPrefix = "$argon2id$"
+
+// Argon2idParameter is kept inside every encoded hash, so changing it only affects new hashes
+type Argon2idParameter struct {
+	MemoryKiB   uint32
+	Time        uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParameter follows the second recommended option of RFC 9106 with a larger memory
+var DefaultArgon2idParameter = Argon2idParameter{
+	MemoryKiB:   64 * 1024,
+	Time:        3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Validate rejects parameters argon2.IDKey panics on or that give a weak hash, Parallelism is limited to 255 by its type
+func (p Argon2idParameter) Validate() (err error) {
+	if p.Time < 1 {
+		return errors.Errorf("ARGON2ID_PARAMETER_TIME_INVALID:%d", p.Time)
+	}
+	if p.Parallelism < 1 {
+		return errors.Errorf("ARGON2ID_PARAMETER_PARALLELISM_INVALID:%d", p.Parallelism)
+	}
+	if p.MemoryKiB < 8*uint32(p.Parallelism) {
+		return errors.Errorf("ARGON2ID_PARAMETER_MEMORY_KIB_LESS_THAN_8_PER_LANE:%d", p.MemoryKiB)
+	}
+	if p.SaltLength < 8 {
+		return errors.Errorf("ARGON2ID_PARAMETER_SALT_LENGTH_INVALID:%d", p.SaltLength)
+	}
+	if p.KeyLength < 16 {
+		return errors.Errorf("ARGON2ID_PARAMETER_KEY_LENGTH_INVALID:%d", p.KeyLength)
+	}
+	return nil
+}
+
+// HashArgon2id returns the PHC string format $argon2id$v=19$m=<memory>,t=<time>,p=<parallelism>$<salt>$<hash>
+func HashArgon2id(data []byte, p Argon2idParameter) (encodedHash string, err error) {
+	err = p.Validate()
+	if err != nil {
+		return "", err
+	}
+	salt := make([]byte, p.SaltLength)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return "", errors.Wrap(err, "error occured")
+	}
+	key := argon2.IDKey(data, salt, p.Time, p.MemoryKiB, p.Parallelism, p.KeyLength)
+	encodedHash = fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", Argon2idPrefix, argon2.Version, p.MemoryKiB, p.Time, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+	return encodedHash, nil
+}
+
+// Argon2idDecode splits an encoded hash into its parameter, salt and key
+func Argon2idDecode(encodedHash string) (p Argon2idParameter, salt []byte, key []byte, err error) {
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errors.New("ARGON2ID_HASH_INVALID_FORMAT")
+	}
+	var version int
+	_, err = fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil {
+		return p, nil, nil, errors.Wrap(err, "ARGON2ID_HASH_INVALID_VERSION")
+	}
+	if version != argon2.Version {
+		return p, nil, nil, errors.Errorf("ARGON2ID_HASH_UNSUPPORTED_VERSION:%d", version)
+	}
+	// parallelism is scanned wide, a stored value above 255 must fail instead of wrapping
+	var parallelism uint32
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.MemoryKiB, &p.Time, &parallelism)
+	if err != nil {
+		return p, nil, nil, errors.Wrap(err, "ARGON2ID_HASH_INVALID_PARAMETER")
+	}
+	if (parallelism < 1) || (parallelism > 255) {
+		return p, nil, nil, errors.Errorf("ARGON2ID_HASH_INVALID_PARALLELISM:%d", parallelism)
+	}
+	p.Parallelism = uint8(parallelism)
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, errors.Wrap(err, "ARGON2ID_HASH_INVALID_SALT")
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, errors.Wrap(err, "ARGON2ID_HASH_INVALID_KEY")
+	}
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	err = p.Validate()
+	if err != nil {
+		return p, nil, nil, err
+	}
+	return p, salt, key, nil
+}
+
+// VerifyArgon2id hashes data with the parameter and salt of the encoded hash and compares in constant time
+func VerifyArgon2id(data []byte, encodedHash string) (verificationResult bool, err error) {
+	p, salt, key, err := Argon2idDecode(encodedHash)
+	if err != nil {
+		return false, err
+	}
+	tryKey := argon2.IDKey(data, salt, p.Time, p.MemoryKiB, p.Parallelism, p.KeyLength)
+	return subtle.ConstantTimeCompare(tryKey, key) == 1, nil
+}
+
+// Argon2idIsOutdated reports whether an encoded hash was made with other parameters than p
+func Argon2idIsOutdated(encodedHash string, p Argon2idParameter) (isOutdated bool, err error) {
+	hp, _, _, err := Argon2idDecode(encodedHash)
+	if err != nil {
+		return false, err
+	}
+	return hp != p, nil
+}
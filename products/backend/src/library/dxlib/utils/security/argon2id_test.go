@@ -0,0 +1,118 @@
+package sql
+
+import (
+	"strings"
+	"testing"
+)
+
+// testArgon2idParameter keeps the tests fast, DefaultArgon2idParameter is only checked for validity
+var testArgon2idParameter = Argon2idParameter{
+	MemoryKiB:   1024,
+	Time:        1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestArgon2idParameterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Argon2idParameter
+		wantErr bool
+	}{
+		{name: "default", p: DefaultArgon2idParameter},
+		{name: "test", p: testArgon2idParameter},
+		{name: "time zero", p: Argon2idParameter{MemoryKiB: 1024, Time: 0, Parallelism: 1, SaltLength: 16, KeyLength: 32}, wantErr: true},
+		{name: "parallelism zero", p: Argon2idParameter{MemoryKiB: 1024, Time: 1, Parallelism: 0, SaltLength: 16, KeyLength: 32}, wantErr: true},
+		{name: "memory below 8 KiB per lane", p: Argon2idParameter{MemoryKiB: 15, Time: 1, Parallelism: 2, SaltLength: 16, KeyLength: 32}, wantErr: true},
+		{name: "short salt", p: Argon2idParameter{MemoryKiB: 1024, Time: 1, Parallelism: 1, SaltLength: 4, KeyLength: 32}, wantErr: true},
+		{name: "short key", p: Argon2idParameter{MemoryKiB: 1024, Time: 1, Parallelism: 1, SaltLength: 16, KeyLength: 8}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHashArgon2id(t *testing.T) {
+	encodedHash, err := HashArgon2id([]byte("correct horse"), testArgon2idParameter)
+	if err != nil {
+		t.Fatalf("HashArgon2id() err = %v", err)
+	}
+	if !strings.HasPrefix(encodedHash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("HashArgon2id() = %s, want the PHC string of the test parameter", encodedHash)
+	}
+	otherHash, err := HashArgon2id([]byte("correct horse"), testArgon2idParameter)
+	if err != nil {
+		t.Fatalf("HashArgon2id() err = %v", err)
+	}
+	if encodedHash == otherHash {
+		t.Errorf("HashArgon2id() returned the same hash twice, the salt must be random")
+	}
+
+	tests := []struct {
+		name                   string
+		data                   string
+		encodedHash            string
+		wantVerificationResult bool
+		wantErr                bool
+	}{
+		{name: "correct", data: "correct horse", encodedHash: encodedHash, wantVerificationResult: true},
+		{name: "correct with the other salt", data: "correct horse", encodedHash: otherHash, wantVerificationResult: true},
+		{name: "wrong", data: "correct horsf", encodedHash: encodedHash},
+		{name: "empty", data: "", encodedHash: encodedHash},
+		{name: "not argon2id", data: "correct horse", encodedHash: "$argon2i$v=19$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5", wantErr: true},
+		{name: "unsupported version", data: "correct horse", encodedHash: strings.Replace(encodedHash, "v=19", "v=16", 1), wantErr: true},
+		{name: "parallelism above 255", data: "correct horse", encodedHash: strings.Replace(encodedHash, "p=1$", "p=257$", 1), wantErr: true},
+		{name: "weak stored parameter", data: "correct horse", encodedHash: strings.Replace(encodedHash, "t=1,", "t=0,", 1), wantErr: true},
+		{name: "truncated", data: "correct horse", encodedHash: encodedHash[:strings.LastIndex(encodedHash, "$")], wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verificationResult, err := VerifyArgon2id([]byte(tt.data), tt.encodedHash)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyArgon2id() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if verificationResult != tt.wantVerificationResult {
+				t.Errorf("VerifyArgon2id() = %v, want %v", verificationResult, tt.wantVerificationResult)
+			}
+		})
+	}
+}
+
+func TestArgon2idIsOutdated(t *testing.T) {
+	encodedHash, err := HashArgon2id([]byte("correct horse"), testArgon2idParameter)
+	if err != nil {
+		t.Fatalf("HashArgon2id() err = %v", err)
+	}
+	moreTime := testArgon2idParameter
+	moreTime.Time = 2
+	longerKey := testArgon2idParameter
+	longerKey.KeyLength = 64
+
+	tests := []struct {
+		name           string
+		p              Argon2idParameter
+		wantIsOutdated bool
+	}{
+		{name: "same parameter", p: testArgon2idParameter},
+		{name: "more time", p: moreTime, wantIsOutdated: true},
+		{name: "longer key", p: longerKey, wantIsOutdated: true},
+		{name: "default", p: DefaultArgon2idParameter, wantIsOutdated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isOutdated, err := Argon2idIsOutdated(encodedHash, tt.p)
+			if err != nil {
+				t.Fatalf("Argon2idIsOutdated() err = %v", err)
+			}
+			if isOutdated != tt.wantIsOutdated {
+				t.Errorf("Argon2idIsOutdated() = %v, want %v", isOutdated, tt.wantIsOutdated)
+			}
+		})
+	}
+}
@@ -91,3 +91,7 @@ func HashBcrypt(data []byte) ([]byte, error) {
 	hashed, err := bcrypt.GenerateFromPassword(data, bcrypt.MaxCost)
 	return hashed, err
 }
+
+func VerifyBcrypt(data []byte, hashed []byte) bool {
+	return bcrypt.CompareHashAndPassword(hashed, data) == nil
+}
@@ -817,6 +817,11 @@ func RemoveDuplicates[T comparable](slice []T) []T {
 	return result
 }
 
+// Ptr returns a pointer to a copy of v, for optional fields declared in struct literals
+func Ptr[T any](v T) *T {
+	return &v
+}
+
 func GetBuildTime() string {
 	// Try to get VCS timestamp from build info
 	if info, ok := debug.ReadBuildInfo(); ok {
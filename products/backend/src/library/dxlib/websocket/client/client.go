@@ -2,12 +2,38 @@ package client
 
 import (
 	"context"
+	"sync"
+
 	"github.com/donnyhardyanto/dxlib/core"
+	"github.com/donnyhardyanto/dxlib/log"
 	"golang.org/x/sync/errgroup"
 )
 
+// DXWSConnection is the transport side of a client, implemented by api.DXAPIWSConnection
+type DXWSConnection interface {
+	WriteJSON(v any) error
+	Close() error
+}
+
+// DXWSClientSendQueueSize is the number of messages a client may lag behind before it is dropped
+const DXWSClientSendQueueSize = 256
+
+// DXWSClient receives the messages of the manager through its send queue, a writer goroutine drains the queue to the connection so a slow client does not block the sender
 type DXWSClient struct {
-	NameId string
+	NameId         string
+	UserId         string
+	OrganizationId string
+	Connection     DXWSConnection
+	Channels       map[string]bool
+	queue          chan any
+	done           chan struct{}
+	doneOnce       sync.Once
+}
+
+func (c *DXWSClient) stop() {
+	c.doneOnce.Do(func() {
+		close(c.done)
+	})
 }
 
 type DXWSClientManager struct {
@@ -16,6 +42,154 @@ type DXWSClientManager struct {
 	WSClient          map[string]*DXWSClient
 	ErrorGroup        *errgroup.Group
 	ErrorGroupContext context.Context
+	mutex             sync.RWMutex
+}
+
+func (wcm *DXWSClientManager) Register(nameId string, userId string, organizationId string, connection DXWSConnection) *DXWSClient {
+	c := &DXWSClient{
+		NameId:         nameId,
+		UserId:         userId,
+		OrganizationId: organizationId,
+		Connection:     connection,
+		Channels:       map[string]bool{},
+		queue:          make(chan any, DXWSClientSendQueueSize),
+		done:           make(chan struct{}),
+	}
+	wcm.mutex.Lock()
+	old, ok := wcm.WSClient[nameId]
+	wcm.WSClient[nameId] = c
+	wcm.mutex.Unlock()
+	if ok {
+		old.stop()
+	}
+	go wcm.writeLoop(c)
+	return c
+}
+
+func (wcm *DXWSClientManager) Unregister(nameId string) {
+	wcm.mutex.Lock()
+	c, ok := wcm.WSClient[nameId]
+	delete(wcm.WSClient, nameId)
+	wcm.mutex.Unlock()
+	if ok {
+		c.stop()
+	}
+}
+
+// drop closes the connection of c and unregisters it, unless its name id is already taken by a newer client
+func (wcm *DXWSClientManager) drop(c *DXWSClient) {
+	c.stop()
+	_ = c.Connection.Close()
+	wcm.mutex.Lock()
+	defer wcm.mutex.Unlock()
+	if wcm.WSClient[c.NameId] == c {
+		delete(wcm.WSClient, c.NameId)
+	}
+}
+
+// writeLoop writes the queued messages of c until c is unregistered or the manager is cancelled, a client that fails to receive is dropped
+func (wcm *DXWSClientManager) writeLoop(c *DXWSClient) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-wcm.Context.Done():
+			return
+		case v := <-c.queue:
+			err := c.Connection.WriteJSON(v)
+			if err != nil {
+				log.Log.Warnf("WS_CLIENT_SEND_ERROR:%s:%s", c.NameId, err.Error())
+				wcm.drop(c)
+				return
+			}
+		}
+	}
+}
+
+func (wcm *DXWSClientManager) Subscribe(nameId string, channel string) bool {
+	wcm.mutex.Lock()
+	defer wcm.mutex.Unlock()
+	c, ok := wcm.WSClient[nameId]
+	if !ok {
+		return false
+	}
+	c.Channels[channel] = true
+	return true
+}
+
+func (wcm *DXWSClientManager) Unsubscribe(nameId string, channel string) bool {
+	wcm.mutex.Lock()
+	defer wcm.mutex.Unlock()
+	c, ok := wcm.WSClient[nameId]
+	if !ok {
+		return false
+	}
+	delete(c.Channels, channel)
+	return true
+}
+
+func (wcm *DXWSClientManager) Clients() (clients []*DXWSClient) {
+	wcm.mutex.RLock()
+	defer wcm.mutex.RUnlock()
+	clients = make([]*DXWSClient, 0, len(wcm.WSClient))
+	for _, c := range wcm.WSClient {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+func (wcm *DXWSClientManager) Count() int {
+	wcm.mutex.RLock()
+	defer wcm.mutex.RUnlock()
+	return len(wcm.WSClient)
+}
+
+// send queues v to every client accepted by filter and returns the number of clients it was queued to, a client whose queue is full is closed and unregistered
+func (wcm *DXWSClientManager) send(filter func(c *DXWSClient) bool, v any) (count int) {
+	wcm.mutex.RLock()
+	targets := []*DXWSClient{}
+	for _, c := range wcm.WSClient {
+		if filter(c) {
+			targets = append(targets, c)
+		}
+	}
+	wcm.mutex.RUnlock()
+
+	for _, c := range targets {
+		select {
+		case <-c.done:
+		case c.queue <- v:
+			count++
+		default:
+			log.Log.Warnf("WS_CLIENT_SEND_QUEUE_FULL:%s", c.NameId)
+			wcm.drop(c)
+		}
+	}
+	return count
+}
+
+func (wcm *DXWSClientManager) SendToUser(userId string, v any) int {
+	return wcm.send(func(c *DXWSClient) bool {
+		return c.UserId == userId
+	}, v)
+}
+
+func (wcm *DXWSClientManager) SendToOrganization(organizationId string, v any) int {
+	return wcm.send(func(c *DXWSClient) bool {
+		return c.OrganizationId == organizationId
+	}, v)
+}
+
+func (wcm *DXWSClientManager) SendToChannel(channel string, v any) int {
+	return wcm.send(func(c *DXWSClient) bool {
+		return c.Channels[channel]
+	}, v)
+}
+
+func (wcm *DXWSClientManager) SendToAll(v any) int {
+	return wcm.send(func(c *DXWSClient) bool {
+		return true
+	}, v)
 }
 
 var Manager DXWSClientManager
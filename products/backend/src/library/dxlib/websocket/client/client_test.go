@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testConnection struct {
+	mutex    sync.Mutex
+	messages []any
+	isClosed bool
+	writeErr error
+	block    chan struct{}
+}
+
+func (c *testConnection) WriteJSON(v any) error {
+	if c.block != nil {
+		<-c.block
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.writeErr != nil {
+		return c.writeErr
+	}
+	c.messages = append(c.messages, v)
+	return nil
+}
+
+func (c *testConnection) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.isClosed = true
+	return nil
+}
+
+func (c *testConnection) state() (messages int, isClosed bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.messages), c.isClosed
+}
+
+func testManager(t *testing.T) *DXWSClientManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return &DXWSClientManager{Context: ctx, Cancel: cancel, WSClient: map[string]*DXWSClient{}}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name      string
+		send      func(wcm *DXWSClientManager) int
+		wantCount int
+		want      map[string]int
+	}{
+		{name: "to user", send: func(wcm *DXWSClientManager) int { return wcm.SendToUser("u1", "m") }, wantCount: 2, want: map[string]int{"a": 1, "b": 1, "c": 0}},
+		{name: "to organization", send: func(wcm *DXWSClientManager) int { return wcm.SendToOrganization("o2", "m") }, wantCount: 1, want: map[string]int{"a": 0, "b": 0, "c": 1}},
+		{name: "to channel", send: func(wcm *DXWSClientManager) int { return wcm.SendToChannel("news", "m") }, wantCount: 2, want: map[string]int{"a": 1, "b": 0, "c": 1}},
+		{name: "to all", send: func(wcm *DXWSClientManager) int { return wcm.SendToAll("m") }, wantCount: 3, want: map[string]int{"a": 1, "b": 1, "c": 1}},
+		{name: "to nobody", send: func(wcm *DXWSClientManager) int { return wcm.SendToUser("u9", "m") }, wantCount: 0, want: map[string]int{"a": 0, "b": 0, "c": 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wcm := testManager(t)
+			connections := map[string]*testConnection{"a": {}, "b": {}, "c": {}}
+			wcm.Register("a", "u1", "o1", connections["a"])
+			wcm.Register("b", "u1", "o1", connections["b"])
+			wcm.Register("c", "u2", "o2", connections["c"])
+			wcm.Subscribe("a", "news")
+			wcm.Subscribe("c", "news")
+			if count := tt.send(wcm); count != tt.wantCount {
+				t.Errorf("count = %d, want %d", count, tt.wantCount)
+			}
+			for nameId, want := range tt.want {
+				waitFor(t, "message of "+nameId, func() bool {
+					got, _ := connections[nameId].state()
+					return got == want
+				})
+			}
+		})
+	}
+}
+
+func TestSendSlowClient(t *testing.T) {
+	wcm := testManager(t)
+	slow := &testConnection{block: make(chan struct{})}
+	defer close(slow.block)
+	fast := &testConnection{}
+	wcm.Register("slow", "u1", "o1", slow)
+	wcm.Register("fast", "u2", "o1", fast)
+
+	// the writer of slow holds one message, the queue holds DXWSClientSendQueueSize more, the next one overflows
+	total := DXWSClientSendQueueSize + 2
+	for i := 0; i < total; i++ {
+		wcm.SendToOrganization("o1", i)
+		if i == 0 {
+			waitFor(t, "writer of slow to take the first message", func() bool {
+				return len(wcm.WSClient["slow"].queue) == 0
+			})
+		}
+	}
+	if _, isClosed := slow.state(); !isClosed {
+		t.Errorf("slow client is not closed")
+	}
+	if wcm.Count() != 1 {
+		t.Errorf("Count() = %d, want 1", wcm.Count())
+	}
+	waitFor(t, "messages of fast", func() bool {
+		got, _ := fast.state()
+		return got == total
+	})
+	if count := wcm.SendToUser("u1", "m"); count != 0 {
+		t.Errorf("count to a dropped client = %d, want 0", count)
+	}
+}
+
+func TestSendWriteError(t *testing.T) {
+	wcm := testManager(t)
+	broken := &testConnection{writeErr: errors.New("broken pipe")}
+	wcm.Register("broken", "u1", "o1", broken)
+	if count := wcm.SendToAll("m"); count != 1 {
+		t.Errorf("count = %d, want 1", count)
+	}
+	waitFor(t, "broken client to be dropped", func() bool {
+		_, isClosed := broken.state()
+		return isClosed && (wcm.Count() == 0)
+	})
+}
+
+func TestRegisterReplacesClient(t *testing.T) {
+	wcm := testManager(t)
+	first := &testConnection{}
+	second := &testConnection{}
+	c := wcm.Register("a", "u1", "o1", first)
+	wcm.Register("a", "u1", "o1", second)
+	// dropping the replaced client must not unregister its successor
+	wcm.drop(c)
+	if wcm.Count() != 1 {
+		t.Fatalf("Count() = %d, want 1", wcm.Count())
+	}
+	wcm.SendToAll("m")
+	waitFor(t, "message of the second client", func() bool {
+		got, _ := second.state()
+		return got == 1
+	})
+	if got, _ := first.state(); got != 0 {
+		t.Errorf("first client got %d messages, want 0", got)
+	}
+}
@@ -166,7 +166,7 @@ func (ios *ImageObjectStorage) Update(aepr *api.DXAPIEndPointRequest, filename s
 		return aepr.WriteResponseAndNewErrorf(http.StatusRequestEntityTooLarge, "", "REQUEST_ENTITY_TOO_LARGE")
 	}
 
-	objectStorage, exists := object_storage.Manager.ObjectStorages[ios.ObjectStorageSourceNameId]
+	_, exists := object_storage.Manager.ObjectStorages[ios.ObjectStorageSourceNameId]
 	if !exists {
 		return aepr.WriteResponseAndNewErrorf(http.StatusNotFound, "", "OBJECT_STORAGE_NAME_NOT_FOUND:%s", ios.ObjectStorageSourceNameId)
 	}
@@ -203,6 +203,22 @@ func (ios *ImageObjectStorage) Update(aepr *api.DXAPIEndPointRequest, filename s
 		buf.Write(decodedBytes)
 	}
 
+	return ios.UpdateFromBytes(aepr, filename, buf.Bytes())
+}
+
+func (ios *ImageObjectStorage) UpdateFromBytes(aepr *api.DXAPIEndPointRequest, filename string, content []byte) (err error) {
+	if int64(len(content)) > ios.MaxRequestSize {
+		return aepr.WriteResponseAndNewErrorf(http.StatusRequestEntityTooLarge, "", "REQUEST_ENTITY_TOO_LARGE")
+	}
+
+	objectStorage, exists := object_storage.Manager.ObjectStorages[ios.ObjectStorageSourceNameId]
+	if !exists {
+		return aepr.WriteResponseAndNewErrorf(http.StatusNotFound, "", "OBJECT_STORAGE_NAME_NOT_FOUND:%s", ios.ObjectStorageSourceNameId)
+	}
+
+	buf := bytes.NewBuffer(content)
+	bodyLen := int64(len(content))
+
 	// Validate image dimensions to prevent pixel flood attacks
 	err = ios.ValidateImageDimensions(buf.Bytes())
 	if err != nil {
@@ -210,7 +226,7 @@ func (ios *ImageObjectStorage) Update(aepr *api.DXAPIEndPointRequest, filename s
 	}
 
 	// Upload the original file
-	uploadInfo, err := objectStorage.UploadStream(bytes.NewReader(buf.Bytes()), filename, filename, "application/octet-stream", false, bodyLen)
+	uploadInfo, err := objectStorage.WithContext(aepr.Context).UploadStream(bytes.NewReader(buf.Bytes()), filename, filename, "application/octet-stream", false, bodyLen)
 	if err != nil {
 		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "FAILED_TO_UPLOAD_SOURCE_IMAGE_TO_OBJECT_STORAGE:%s=%v", ios.ObjectStorageSourceNameId, err.Error())
 	}
@@ -282,7 +298,7 @@ func (ios *ImageObjectStorage) Update(aepr *api.DXAPIEndPointRequest, filename s
 		// Upload the resized image
 		buf := resizedBuf.Bytes()
 		bufLen := int64(len(buf))
-		uploadInfo, err := objectStorage.UploadStream(bytes.NewReader(buf), filename, filename, "image/"+formatName, false, bufLen)
+		uploadInfo, err := objectStorage.WithContext(aepr.Context).UploadStream(bytes.NewReader(buf), filename, filename, "image/"+formatName, false, bufLen)
 		if err != nil {
 			return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "FAILED_TO_UPLOAD_RESIZED_IMAGE_TO_OBJECT_STORAGE:(%s)=%v", processedImage.ObjectStorageNameId, err.Error())
 		}
@@ -3,9 +3,12 @@ package general
 import (
 	"github.com/donnyhardyanto/dxlib/api"
 	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib/websocket/client"
 	"github.com/pkg/errors"
 )
 
+const WSChannelAnnouncement = "announcement"
+
 func (g *DxmGeneral) AnnouncementList(aepr *api.DXAPIEndPointRequest) (err error) {
 	return g.Announcement.RequestPagingList(aepr)
 }
@@ -15,11 +18,25 @@ func (g *DxmGeneral) AnnouncementListAll(aepr *api.DXAPIEndPointRequest) (err er
 }
 
 func (g *DxmGeneral) AnnouncementCreate(aepr *api.DXAPIEndPointRequest) (err error) {
-	_, err = g.Announcement.DoCreate(aepr, map[string]any{
-		"title":   aepr.ParameterValues["title"].Value.(string),
-		"content": aepr.ParameterValues["content"].Value.(string),
+	title := aepr.ParameterValues["title"].Value.(string)
+	content := aepr.ParameterValues["content"].Value.(string)
+	newId, err := g.Announcement.DoCreate(aepr, map[string]any{
+		"title":   title,
+		"content": content,
+	})
+	if err != nil {
+		return err
+	}
+	client.Manager.SendToChannel(WSChannelAnnouncement, utils.JSON{
+		"type":    "announcement.created",
+		"channel": WSChannelAnnouncement,
+		"data": utils.JSON{
+			"id":      newId,
+			"title":   title,
+			"content": content,
+		},
 	})
-	return err
+	return nil
 }
 
 func (g *DxmGeneral) AnnouncementRead(aepr *api.DXAPIEndPointRequest) (err error) {
@@ -13,10 +13,31 @@ func DefineAPIEndPoints(anAPI *api.DXAPI) (err error) {
 		oam.Ping, nil, nil, nil, nil, 0, "",
 	)
 
+	anAPI.NewEndPoint("Healthz",
+		"Liveness probe. Used to indicate the process is able to serve requests, dependencies are not checked.",
+		"/healthz", "GET", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, nil,
+		oam.Healthz, nil, nil, nil, nil, 0, "",
+	)
+
+	anAPI.NewEndPoint("Readyz",
+		"Readiness probe. Checks the databases, Redis, object storages, tasks and module dependencies, answers 503 when a critical one fails or the service is shutting down.",
+		"/readyz", "GET", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, nil,
+		oam.Readyz, nil, nil, nil, nil, 0, "",
+	)
+
 	anAPI.NewEndPoint("PrintSpec",
-		"Print the API Specification",
-		"/spec", "GET", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, nil,
-		anAPI.APIHandlerPrintSpec, nil, nil, nil, nil, 0, "",
+		"Print the API Specification as an OpenAPI 3.1 document (JSON or YAML) or as MarkDown",
+		"/spec", "GET", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, []api.DXAPIEndPointParameter{
+			{NameId: "api", Type: "string", Description: "API nameid, default to the first API in nameid order other than the one serving this endpoint", IsMustExist: false},
+			{NameId: "format", Type: "string", Description: "json (default), yaml or markdown", IsMustExist: false},
+		},
+		api.Manager.APIHandlerPrintSpec, nil, nil, nil, nil, 0, "",
+	)
+
+	anAPI.NewEndPoint("Metrics",
+		"Metrics of the API endpoints, databases, Redis, tasks and FCM in the Prometheus text format",
+		"/metrics", "GET", api.EndPointTypeHTTPJSON, http.ContentTypeApplicationJSON, nil,
+		api.APIHandlerMetrics, nil, nil, nil, nil, 0, "",
 	)
 
 	return nil
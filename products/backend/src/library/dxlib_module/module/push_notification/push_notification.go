@@ -462,7 +462,9 @@ func (f *FirebaseCloudMessaging) processMessages(applicationId int64) error {
 		msgTitle := fcmMessage["title"].(string)
 		msgBody := fcmMessage["body"].(string)
 		msgData := fcmMessage["data"].(map[string]string)
-		err = f.sendNotification(ctx, firebaseServiceAccount.Client, fcmMessage["token"].(string), fcmMessage["device_type"].(string), msgTitle, msgBody, msgData)
+		deviceType := fcmMessage["device_type"].(string)
+		err = f.sendNotification(ctx, firebaseServiceAccount.Client, fcmMessage["token"].(string), deviceType, msgTitle, msgBody, msgData)
+		fcm.ObserveSend(deviceType, err)
 		if err != nil {
 			log.Log.Warnf("Error sending notification %d: %v", fcmMessage["id"], err)
 			retryCount++
@@ -18,7 +18,6 @@ import (
 	"strconv"
 	"strings"
 	"time"
-	"unicode"
 
 	"github.com/donnyhardyanto/dxlib/api"
 	dxlibLog "github.com/donnyhardyanto/dxlib/log"
@@ -42,6 +41,7 @@ type DxmSelf struct {
 	OnInitialize                   func(s *DxmSelf) (err error)
 	OnAuthenticateUser             func(aepr *api.DXAPIEndPointRequest, loginId string, password string, organizationUid string) (isSuccess bool, user utils.JSON, organization utils.JSON /*organizations []utils.JSON*/, err error)
 	OnCreateSessionObject          func(aepr *api.DXAPIEndPointRequest, user utils.JSON, organization utils.JSON, originalSessionObject utils.JSON) (newSessionObject utils.JSON, err error)
+	OnOTPSend                      func(l *dxlibLog.DXLog, channel OTPChannel, user utils.JSON, purpose string, code string, ttl time.Duration) (err error)
 }
 
 func (s *DxmSelf) Init(databaseNameId string) {
@@ -140,7 +140,7 @@ func (s *DxmSelf) SelfPrelogin(aepr *api.DXAPIEndPointRequest) (err error) {
 		return err
 	}
 	preKeyTTLAsDuration := time.Duration(preKeyTTLAsInt) * time.Second
-	err = user_management.ModuleUserManagement.PreKeyRedis.Set(preKeyString, utils.JSON{
+	err = user_management.ModuleUserManagement.PreKeyRedis.WithContext(aepr.Context).Set(preKeyString, utils.JSON{
 		"shared_key_1":   sharedKey1AsHexString,
 		"shared_key_2":   sharedKey2AsHexString,
 		"a0_public_key":  edA0PublicKeyAsHexString,
@@ -245,7 +245,7 @@ func (s *DxmSelf) SelfPreloginCaptcha(aepr *api.DXAPIEndPointRequest) (err error
 		return err
 	}
 	preKeyTTLAsDuration := time.Duration(preKeyTTLAsInt) * time.Second
-	err = user_management.ModuleUserManagement.PreKeyRedis.Set(preKeyString, utils.JSON{
+	err = user_management.ModuleUserManagement.PreKeyRedis.WithContext(aepr.Context).Set(preKeyString, utils.JSON{
 		"captcha_id":     captchaID,
 		"captcha_text":   captchaText,
 		"shared_key_1":   sharedKey1AsHexString,
@@ -484,17 +484,18 @@ func (s *DxmSelf) SelfConfiguration(aepr *api.DXAPIEndPointRequest) (err error)
 	return err
 }
 
+type SelfLoginParameter struct {
+	PreKeyIndex     string `param:"i,required" description:"Pre-key index"`
+	DataAsHexString string `param:"d,required" description:"Login data"`
+}
+
 func (s *DxmSelf) SelfLogin(aepr *api.DXAPIEndPointRequest) (err error) {
-	_, preKeyIndex, err := aepr.GetParameterValueAsString("i")
-	if err != nil {
-		return err
-	}
-	_, dataAsHexString, err := aepr.GetParameterValueAsString("d")
+	parameter, err := api.Bind[SelfLoginParameter](aepr)
 	if err != nil {
 		return err
 	}
 
-	lvPayloadElements, sharedKey2AsBytes, edB0PrivateKeyAsBytes, err := user_management.ModuleUserManagement.PreKeyUnpack(preKeyIndex, dataAsHexString)
+	lvPayloadElements, sharedKey2AsBytes, edB0PrivateKeyAsBytes, err := user_management.ModuleUserManagement.PreKeyUnpack(parameter.PreKeyIndex, parameter.DataAsHexString)
 	if err != nil {
 		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:%v", err.Error())
 	}
@@ -517,13 +518,22 @@ func (s *DxmSelf) SelfLogin(aepr *api.DXAPIEndPointRequest) (err error) {
 	var userLoggedOrganization utils.JSON
 	var verificationResult bool
 	if s.OnAuthenticateUser != nil {
+		err = selfLoginLockCheckByLoginId(aepr, userLoginId)
+		if err != nil {
+			return err
+		}
 		verificationResult, user, userLoggedOrganization, err = s.OnAuthenticateUser(aepr, userLoginId, userPassword, organizationUId)
 		if err != nil {
 			return err
 		}
-		if !verificationResult {
+		if user == nil {
 			return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_CREDENTIAL")
 		}
+		organizationId, _ := userLoggedOrganization["id"].(int64)
+		err = selfLoginPasswordPolicyApply(aepr, user, organizationId, verificationResult)
+		if err != nil {
+			return err
+		}
 
 		userId := user["id"].(int64)
 
@@ -557,6 +567,10 @@ func (s *DxmSelf) SelfLogin(aepr *api.DXAPIEndPointRequest) (err error) {
 		if user == nil {
 			return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_CREDENTIAL")
 		}
+		err = selfLoginLockCheck(aepr, user)
+		if err != nil {
+			return err
+		}
 
 		userId := user["id"].(int64)
 
@@ -591,11 +605,19 @@ func (s *DxmSelf) SelfLogin(aepr *api.DXAPIEndPointRequest) (err error) {
 			return err
 		}
 
-		if !verificationResult {
-			return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_CREDENTIAL")
+		err = selfLoginPasswordPolicyApply(aepr, user, userLoggedOrganizationId, verificationResult)
+		if err != nil {
+			return err
 		}
 	}
 
+	return s.selfLoginPasswordVerified(aepr, parameter.PreKeyIndex, edB0PrivateKeyAsBytes, sharedKey2AsBytes, user, userOrganizationMemberships,
+		userLoggedOrganizationId, userLoggedOrganizationUid, userLoggedOrganization)
+}
+
+// selfLoginSessionCreate stores the session of a fully authenticated login and answers it packed with the pre-key, extra LVs follow the session object
+func (s *DxmSelf) selfLoginSessionCreate(aepr *api.DXAPIEndPointRequest, preKeyIndex string, edB0PrivateKeyAsBytes []byte, sharedKey2AsBytes []byte, user utils.JSON,
+	userOrganizationMemberships any, userLoggedOrganizationId int64, userLoggedOrganizationUid string, userLoggedOrganization utils.JSON, lvExtras ...*lv.LV) (err error) {
 	sessionKey, err := GenerateSessionKey()
 	if err != nil {
 		return err
@@ -627,13 +649,15 @@ func (s *DxmSelf) SelfLogin(aepr *api.DXAPIEndPointRequest) (err error) {
 			return err
 		}
 	}*/
+	sessionObject["created_at"] = time.Now().UnixMilli()
+
 	sessionKeyTTLAsInt, err := general.ModuleGeneral.Property.GetAsInt(&aepr.Log, "SESSION_TTL_SECOND")
 	if err != nil {
 		return err
 	}
 	sessionKeyTTLAsDuration := time.Duration(sessionKeyTTLAsInt) * time.Second
 
-	err = user_management.ModuleUserManagement.SessionRedis.Set(sessionKey, sessionObject, sessionKeyTTLAsDuration)
+	err = user_management.ModuleUserManagement.SessionRedis.WithContext(aepr.Context).Set(sessionKey, sessionObject, sessionKeyTTLAsDuration)
 	if err != nil {
 		return err
 	}
@@ -649,7 +673,7 @@ func (s *DxmSelf) SelfLogin(aepr *api.DXAPIEndPointRequest) (err error) {
 	if err != nil {
 		return err
 	}
-	dataBlockEnvelopeAsHexString, err := datablock.PackLVPayload(preKeyIndex, edB0PrivateKeyAsBytes, sharedKey2AsBytes, lvSessionObject)
+	dataBlockEnvelopeAsHexString, err := datablock.PackLVPayload(preKeyIndex, edB0PrivateKeyAsBytes, sharedKey2AsBytes, append([]*lv.LV{lvSessionObject}, lvExtras...)...)
 	if err != nil {
 		return err
 	}
@@ -776,17 +800,12 @@ func GenerateSessionKey() (string, error) {
 }
 
 func (s *DxmSelf) SelfLoginCaptcha(aepr *api.DXAPIEndPointRequest) (err error) {
-
-	_, preKeyIndex, err := aepr.GetParameterValueAsString("i")
-	if err != nil {
-		return err
-	}
-	_, dataAsHexString, err := aepr.GetParameterValueAsString("d")
+	parameter, err := api.Bind[SelfLoginParameter](aepr)
 	if err != nil {
 		return err
 	}
 
-	lvPayloadElements, sharedKey2AsBytes, edB0PrivateKeyAsBytes, storedCaptchaId, storedCapchaText, err := user_management.ModuleUserManagement.PreKeyUnpackCaptcha(preKeyIndex, dataAsHexString)
+	lvPayloadElements, sharedKey2AsBytes, edB0PrivateKeyAsBytes, storedCaptchaId, storedCapchaText, err := user_management.ModuleUserManagement.PreKeyUnpackCaptcha(parameter.PreKeyIndex, parameter.DataAsHexString)
 	if err != nil {
 		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:%v", err.Error())
 	}
@@ -817,13 +836,22 @@ func (s *DxmSelf) SelfLoginCaptcha(aepr *api.DXAPIEndPointRequest) (err error) {
 	var userLoggedOrganization utils.JSON
 	var verificationResult bool
 	if s.OnAuthenticateUser != nil {
+		err = selfLoginLockCheckByLoginId(aepr, userLoginId)
+		if err != nil {
+			return err
+		}
 		verificationResult, user, userLoggedOrganization, err = s.OnAuthenticateUser(aepr, userLoginId, userPassword, organizationUId)
 		if err != nil {
 			return err
 		}
-		if !verificationResult {
+		if user == nil {
 			return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_CREDENTIAL")
 		}
+		organizationId, _ := userLoggedOrganization["id"].(int64)
+		err = selfLoginPasswordPolicyApply(aepr, user, organizationId, verificationResult)
+		if err != nil {
+			return err
+		}
 	} else {
 		_, user, err := user_management.ModuleUserManagement.User.SelectOne(&aepr.Log, nil, utils.JSON{
 			"loginid": userLoginId,
@@ -834,6 +862,10 @@ func (s *DxmSelf) SelfLoginCaptcha(aepr *api.DXAPIEndPointRequest) (err error) {
 		if user == nil {
 			return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_CREDENTIAL")
 		}
+		err = selfLoginLockCheck(aepr, user)
+		if err != nil {
+			return err
+		}
 
 		userId := user["id"].(int64)
 
@@ -868,78 +900,19 @@ func (s *DxmSelf) SelfLoginCaptcha(aepr *api.DXAPIEndPointRequest) (err error) {
 			return err
 		}
 
-		if !verificationResult {
-			return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_CREDENTIAL")
-		}
-	}
-
-	sessionKey, err := GenerateSessionKey()
-	if err != nil {
-		return err
-	}
-
-	userId, ok := user["id"].(int64)
-	if !ok {
-		return aepr.WriteResponseAndNewErrorf(500, "", "SHOULD_NOT_HAPPEN:USER_ID_NOT_FOUND_IN_USER")
-	}
-	a := []any{userOrganizationMemberships}
-	/*userEffectivePrivilegeIds, */ sessionObject, allowed, err2 := s.RegenerateSessionObject(aepr, userId, sessionKey, user, userLoggedOrganizationId, userLoggedOrganizationUid, userLoggedOrganization, a)
-	if err2 != nil {
-		return err2
-	}
-
-	/*	allowed := false
-		for k := range userEffectivePrivilegeIds {
-			if slices.Contains(aepr.EndPoint.Privileges, k) {
-				allowed = true
-			}
-		}*/
-	if !allowed {
-		return aepr.WriteResponseAndNewErrorf(http.StatusForbidden, "", "USER_ROLE_PRIVILEGE_FORBIDDEN")
-	}
-
-	/*	if s.OnCreateSessionObject != nil {
-		sessionObject, err = s.OnCreateSessionObject(aepr, user, userLoggedOrganization, sessionObject)
+		err = selfLoginPasswordPolicyApply(aepr, user, userLoggedOrganizationId, verificationResult)
 		if err != nil {
 			return err
 		}
-	}*/
-	sessionKeyTTLAsInt, err := general.ModuleGeneral.Property.GetAsInt(&aepr.Log, "SESSION_TTL_SECOND")
-	if err != nil {
-		return err
-	}
-
-	sessionKeyTTLAsDuration := time.Duration(sessionKeyTTLAsInt) * time.Second
-
-	err = user_management.ModuleUserManagement.SessionRedis.Set(sessionKey, sessionObject, sessionKeyTTLAsDuration)
-	if err != nil {
-		return err
 	}
 
-	sessionObjectJSON, err := json.Marshal(sessionObject)
-	if err != nil {
-		return err
-	}
-
-	sessionObjectJSONString := string(sessionObjectJSON)
-
-	lvSessionObject, err := lv.NewLV([]byte(sessionObjectJSONString))
-	if err != nil {
-		return err
-	}
-	dataBlockEnvelopeAsHexString, err := datablock.PackLVPayload(preKeyIndex, edB0PrivateKeyAsBytes, sharedKey2AsBytes, lvSessionObject)
-	if err != nil {
-		return err
-	}
-
-	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
-		"d": dataBlockEnvelopeAsHexString,
-	})
-	return err
+	return s.selfLoginPasswordVerified(aepr, parameter.PreKeyIndex, edB0PrivateKeyAsBytes, sharedKey2AsBytes, user, userOrganizationMemberships,
+		userLoggedOrganizationId, userLoggedOrganizationUid, userLoggedOrganization)
 }
 
 func (s *DxmSelf) SelfLoginToken(aepr *api.DXAPIEndPointRequest) (err error) {
 	sessionObject := aepr.LocalData["session_object"].(utils.JSON)
+	sessionCreatedAt, _ := utilsJSON.GetInt64WithDefault(sessionObject, "created_at", 0)
 	userId := aepr.LocalData["user_id"].(int64)
 	sessionKey := sessionObject["session_key"].(string)
 	userLoggedOrganizationId := aepr.LocalData["organization_id"].(int64)
@@ -959,6 +932,8 @@ func (s *DxmSelf) SelfLoginToken(aepr *api.DXAPIEndPointRequest) (err error) {
 	if !allowed {
 		return aepr.WriteResponseAndNewErrorf(http.StatusForbidden, "", "USER_ROLE_PRIVILEGE_FORBIDDEN")
 	}
+	// a refreshed session keeps its creation time so a later revocation still covers it
+	sessionObject["created_at"] = sessionCreatedAt
 
 	sessionKeyTTLAsInt, err := general.ModuleGeneral.Property.GetAsInt(&aepr.Log, "SESSION_TTL_SECOND")
 	if err != nil {
@@ -966,7 +941,7 @@ func (s *DxmSelf) SelfLoginToken(aepr *api.DXAPIEndPointRequest) (err error) {
 	}
 	sessionKeyTTLAsDuration := time.Duration(sessionKeyTTLAsInt) * time.Second
 
-	err = user_management.ModuleUserManagement.SessionRedis.Set(sessionKey, sessionObject, sessionKeyTTLAsDuration)
+	err = user_management.ModuleUserManagement.SessionRedis.WithContext(aepr.Context).Set(sessionKey, sessionObject, sessionKeyTTLAsDuration)
 	if err != nil {
 		return err
 	}
@@ -984,7 +959,7 @@ func SessionKeyToSessionObject(aepr *api.DXAPIEndPointRequest, sessionKey string
 	}
 	sessionKeyTTLAsDuration := time.Duration(sessionKeyTTLAsInt) * time.Second
 
-	sessionObject, err = user_management.ModuleUserManagement.SessionRedis.GetEx(sessionKey, sessionKeyTTLAsDuration)
+	sessionObject, err = user_management.ModuleUserManagement.SessionRedis.WithContext(aepr.Context).GetEx(sessionKey, sessionKeyTTLAsDuration)
 	if err != nil {
 		return nil, err
 	}
@@ -992,6 +967,18 @@ func SessionKeyToSessionObject(aepr *api.DXAPIEndPointRequest, sessionKey string
 		return nil, aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "NOT_ERROR:SESSION_NOT_FOUND")
 	}
 	userId := utilsJSON.MustGetInt64(sessionObject, "user_id")
+	sessionRevoked, err := user_management.ModuleUserManagement.SessionRedis.WithContext(aepr.Context).Get(sessionRevokedAtKey(userId))
+	if err != nil {
+		return nil, err
+	}
+	if sessionRevoked != nil {
+		revokedAt, _ := utilsJSON.GetInt64WithDefault(sessionRevoked, "revoked_at", 0)
+		createdAt, _ := utilsJSON.GetInt64WithDefault(sessionObject, "created_at", 0)
+		if createdAt <= revokedAt {
+			_ = user_management.ModuleUserManagement.SessionRedis.WithContext(aepr.Context).Delete(sessionKey)
+			return nil, aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "NOT_ERROR:SESSION_REVOKED")
+		}
+	}
 	user := sessionObject["user"].(utils.JSON)
 	userUid, err := utilsJSON.GetString(user, "uid")
 	if err != nil {
@@ -1048,11 +1035,55 @@ func SessionKeyToSessionObject(aepr *api.DXAPIEndPointRequest, sessionKey string
 	return sessionObject, nil
 }
 
+func sessionRevokedAtKey(userId int64) string {
+	return fmt.Sprintf("USER_SESSION_REVOKED_AT_%d", userId)
+}
+
+// SessionRevokeAllOfUser invalidates every session of the user created until now, sessions are only indexed by their key so a revocation time is kept instead.
+// The marker only has to live for SESSION_TTL_SECOND, an older session that was not used within that time has expired anyway.
+func SessionRevokeAllOfUser(aepr *api.DXAPIEndPointRequest, userId int64) (err error) {
+	sessionKeyTTLAsInt, err := general.ModuleGeneral.Property.GetAsInt(&aepr.Log, "SESSION_TTL_SECOND")
+	if err != nil {
+		return err
+	}
+	sessionKeyTTLAsDuration := time.Duration(sessionKeyTTLAsInt) * time.Second
+
+	return user_management.ModuleUserManagement.SessionRedis.WithContext(aepr.Context).Set(sessionRevokedAtKey(userId), utils.JSON{
+		"revoked_at": time.Now().UnixMilli(),
+	}, sessionKeyTTLAsDuration)
+}
+
+// authorizationHeader falls back to a stream ticket for websocket and Server-Sent Events endpoints, browsers can not set headers on those requests
+func authorizationHeader(aepr *api.DXAPIEndPointRequest) (authHeader string, err error) {
+	authHeader = aepr.Request.Header.Get("Authorization")
+	if authHeader != "" {
+		return authHeader, nil
+	}
+	if (aepr.EndPoint.EndPointType != api.EndPointTypeWS) && (aepr.EndPoint.EndPointType != api.EndPointTypeSSE) {
+		return "", nil
+	}
+	ticket := aepr.Request.URL.Query().Get(StreamTicketQueryParameter)
+	if ticket == "" {
+		return "", nil
+	}
+	sessionKey, err := streamTicketSpend(aepr, ticket)
+	if err != nil {
+		return "", err
+	}
+	if sessionKey == "" {
+		return "", aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "STREAM_TICKET_INVALID")
+	}
+	return "Bearer " + sessionKey, nil
+}
+
 func (s *DxmSelf) MiddlewareUserLogged(aepr *api.DXAPIEndPointRequest) (err error) {
 	aepr.Log.Debugf("Middleware Start: %s", aepr.EndPoint.Uri)
 	defer aepr.Log.Debugf("Middleware Done: %s", aepr.EndPoint.Uri)
 
-	authHeader := aepr.Request.Header.Get("Authorization")
+	authHeader, err := authorizationHeader(aepr)
+	if err != nil {
+		return err
+	}
 	if authHeader == "" {
 		return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "AUTHORIZATION_HEADER_NOT_FOUND")
 	}
@@ -1116,7 +1147,10 @@ func (s *DxmSelf) MiddlewareUserLoggedAndPrivilegeCheck(aepr *api.DXAPIEndPointR
 	aepr.Log.Debugf("Middleware Start: %s", aepr.EndPoint.Uri)
 	defer aepr.Log.Debugf("Middleware Done: %s", aepr.EndPoint.Uri)
 
-	authHeader := aepr.Request.Header.Get("Authorization")
+	authHeader, err := authorizationHeader(aepr)
+	if err != nil {
+		return err
+	}
 	if authHeader == "" {
 		return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "AUTHORIZATION_HEADER_NOT_FOUND")
 	}
@@ -1198,51 +1232,20 @@ func (s *DxmSelf) SelfLogout(aepr *api.DXAPIEndPointRequest) (err error) {
 	if sessionKey == "" {
 		return aepr.WriteResponseAndNewErrorf(http.StatusNotFound, "", "SESSION_KEY_IS_EMPTY")
 	}
-	err = user_management.ModuleUserManagement.SessionRedis.Delete(sessionKey)
+	err = user_management.ModuleUserManagement.SessionRedis.WithContext(aepr.Context).Delete(sessionKey)
+	if err != nil {
+		return err
+	}
+	err = user_management.ModuleUserManagement.PreKeyRedis.WithContext(aepr.Context).Delete(otpStepUpKey(sessionKey))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// PasswordFormatValidation checks the built-in rules of user_management.DefaultPasswordPolicy, handlers use the configured policy of the organization instead
 func PasswordFormatValidation(password string) (err error) {
-
-	if len(password) < 8 {
-		return errors.Errorf("password must be at least 8 characters long")
-	}
-
-	hasUpper := false
-	hasLower := false
-	hasNumber := false
-	hasSpecial := false
-
-	for _, char := range password {
-		switch {
-		case unicode.IsUpper(char):
-			hasUpper = true
-		case unicode.IsLower(char):
-			hasLower = true
-		case unicode.IsNumber(char):
-			hasNumber = true
-		case !unicode.IsLetter(char) && !unicode.IsNumber(char):
-			hasSpecial = true
-		}
-	}
-
-	if !hasUpper {
-		return errors.Errorf("password must contain at least one uppercase letter")
-	}
-	if !hasLower {
-		return errors.Errorf("password must contain at least one lowercase letter")
-	}
-	if !hasNumber {
-		return errors.Errorf("password must contain at least one number")
-	}
-	if hasSpecial {
-		return errors.Errorf("password must not contain special characters")
-	}
-
-	return nil
+	return user_management.DefaultPasswordPolicy.Validate(password)
 }
 
 func (s *DxmSelf) SelfPasswordChange(aepr *api.DXAPIEndPointRequest) (err error) {
@@ -1266,12 +1269,12 @@ func (s *DxmSelf) SelfPasswordChange(aepr *api.DXAPIEndPointRequest) (err error)
 	userPasswordNew := string(lvPayloadNewPassword.Value)
 	userPasswordOld := string(lvPayloadOldPassword.Value)
 
-	err = PasswordFormatValidation(userPasswordNew)
+	userId := aepr.LocalData["user_id"].(int64)
+	organizationId := aepr.LocalData["organization_id"].(int64)
+	err = selfPasswordPolicyCheck(aepr, userId, organizationId, userPasswordNew)
 	if err != nil {
-		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "INVALID_PASSWORD_FORMAT:%v", err.Error())
+		return err
 	}
-
-	userId := aepr.LocalData["user_id"].(int64)
 	var verificationResult bool
 
 	d := database.Manager.Databases[s.DatabaseNameId]
@@ -1357,6 +1360,28 @@ func (s *DxmSelf) SelfAvatarUpdateFileContentBase64(aepr *api.DXAPIEndPointReque
 	return nil
 }
 
+func (s *DxmSelf) SelfAvatarUpdateFile(aepr *api.DXAPIEndPointRequest) (err error) {
+	user := aepr.LocalData["user"].(utils.JSON)
+	userId := aepr.LocalData["user_id"].(int64)
+	userUid := user["uid"].(string)
+	filename := userUid + ".png"
+
+	_, file, err := aepr.GetParameterValueAsFile("file")
+	if err != nil {
+		return err
+	}
+
+	err = s.Avatar.UpdateFromBytes(aepr, filename, file.Content)
+	if err != nil {
+		return err
+	}
+
+	_, err = user_management.ModuleUserManagement.User.UpdateOne(&aepr.Log, userId, utils.JSON{
+		"is_avatar_exist": true,
+	})
+	return err
+}
+
 func (s *DxmSelf) SelfAvatarDownloadSource(aepr *api.DXAPIEndPointRequest) (err error) {
 	user := aepr.LocalData["user"].(utils.JSON)
 	userUid := user["uid"].(string)
@@ -0,0 +1,439 @@
+package self
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	"github.com/donnyhardyanto/dxlib/endpoint_rate_limiter"
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/redis"
+	"github.com/donnyhardyanto/dxlib/utils"
+	utilsJSON "github.com/donnyhardyanto/dxlib/utils/json"
+	"github.com/donnyhardyanto/dxlib_module/module/user_management"
+	goRedis "github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+type OTPChannel string
+
+const (
+	OTPChannelEmail OTPChannel = "EMAIL"
+	OTPChannelSMS   OTPChannel = "SMS"
+)
+
+const (
+	OTPPurposeLogin  = "LOGIN"
+	OTPPurposeStepUp = "STEP_UP"
+)
+
+const (
+	OTPLength     = 6
+	OTPTTL        = 5 * time.Minute
+	OTPMaxAttempt = 5
+	// OTPStepUpTTL is how long a verified step-up OTP keeps sensitive operations of the session open
+	OTPStepUpTTL = 5 * time.Minute
+	// OTPSendRateLimitGroupNameId limits the codes sent per user, configured like the other endpoint_rate_limiter groups
+	OTPSendRateLimitGroupNameId = "/otp/send"
+)
+
+/*
+  - One-time codes
+    The code is sent through OnOTPSend, which renders the EMAIL or SMS template of the purpose. Only HMAC-SHA256(SALT, CODE) is kept in
+    PreKeyRedis under OTP_{<PURPOSE>_<USER_ID>} for OTPTTL, a new code replaces the previous one and resets the attempt counter kept next to it
+    under OTP_{<PURPOSE>_<USER_ID>}_ATTEMPT, the code is dropped once more than OTPMaxAttempt codes were tried.
+*/
+
+// otpKey carries a hash tag, so the code and its attempt counter land on the same shard of the ring for otpVerifyScript
+func otpKey(purpose string, userId int64) string {
+	return fmt.Sprintf("OTP_{%s_%d}", purpose, userId)
+}
+
+// otpAttemptKey holds the plain integer attempt counter of the code at otpKey
+func otpAttemptKey(purpose string, userId int64) string {
+	return otpKey(purpose, userId) + "_ATTEMPT"
+}
+
+func otpHash(salt []byte, code string) string {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func otpGenerate() (code string, err error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", errors.Wrap(err, "error occured")
+	}
+	return fmt.Sprintf("%0*d", OTPLength, n.Int64()), nil
+}
+
+// otpIssueCheck answers the errors that do not depend on the user, so they are the same whether the user exists or not
+func (s *DxmSelf) otpIssueCheck(aepr *api.DXAPIEndPointRequest, channel OTPChannel) (err error) {
+	if s.OnOTPSend == nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusNotImplemented, "", "OTP_SEND_NOT_CONFIGURED")
+	}
+	if (channel != OTPChannelEmail) && (channel != OTPChannelSMS) {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "OTP_CHANNEL_INVALID:%s", channel)
+	}
+	return nil
+}
+
+// otpSend stores a new code for purpose and hands it to OnOTPSend, isAllowed is false when the user asked for too many codes.
+// It writes no response, so it can also run after the response was written.
+func (s *DxmSelf) otpSend(l *dxlibLog.DXLog, user utils.JSON, purpose string, channel OTPChannel) (isAllowed bool, err error) {
+	userId, ok := user["id"].(int64)
+	if !ok {
+		return false, errors.New("SHOULD_NOT_HAPPEN:USER_ID_NOT_FOUND_IN_USER")
+	}
+
+	limiter := endpoint_rate_limiter.Manager.EndpointRateLimiter
+	isAllowed, err = limiter.IsAllowed(l.Context, OTPSendRateLimitGroupNameId, fmt.Sprintf("user:%d", userId))
+	if err != nil {
+		return false, err
+	}
+	if !isAllowed {
+		return false, nil
+	}
+
+	code, err := otpGenerate()
+	if err != nil {
+		return false, err
+	}
+	salt := make([]byte, 16)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return false, errors.Wrap(err, "error occured")
+	}
+	preKeyRedis := user_management.ModuleUserManagement.PreKeyRedis.WithContext(l.Context)
+	err = preKeyRedis.Set(otpKey(purpose, userId), utils.JSON{
+		"salt":       hex.EncodeToString(salt),
+		"code_hash":  otpHash(salt, code),
+		"channel":    string(channel),
+		"expired_at": time.Now().Add(OTPTTL).Unix(),
+	}, OTPTTL)
+	if err != nil {
+		return false, err
+	}
+	err = preKeyRedis.Delete(otpAttemptKey(purpose, userId))
+	if err != nil {
+		return false, err
+	}
+
+	err = s.OnOTPSend(l, channel, user, purpose, code, OTPTTL)
+	if err != nil {
+		return false, err
+	}
+	l.Infof("OTP_SENT:user_id=%d,purpose=%s,channel=%s", userId, purpose, channel)
+	return true, nil
+}
+
+// OTPIssue sends a new code for purpose to the user, it answers 429 when the user asked for too many codes
+func (s *DxmSelf) OTPIssue(aepr *api.DXAPIEndPointRequest, user utils.JSON, purpose string, channel OTPChannel) (err error) {
+	err = s.otpIssueCheck(aepr, channel)
+	if err != nil {
+		return err
+	}
+	isAllowed, err := s.otpSend(&aepr.Log, user, purpose, channel)
+	if err != nil {
+		return err
+	}
+	if !isAllowed {
+		return aepr.WriteResponseAndNewErrorf(http.StatusTooManyRequests, "", "OTP_RATE_LIMIT_EXCEEDED")
+	}
+	return nil
+}
+
+// otpVerifyScript counts the attempt, compares the hash and spends the code in one step, so of concurrent requests with the right code exactly
+// one wins and concurrent guesses can not pass the attempt limit. KEYS[1] is the code, KEYS[2] its attempt counter, ARGV[1] the hash of the
+// tried code, ARGV[2] OTPMaxAttempt and ARGV[3] the remaining lifetime of the code in milliseconds
+var otpVerifyScript = goRedis.NewScript(`local otpData = redis.call('GET', KEYS[1])
+if not otpData then
+	return 0
+end
+local attemptCount = redis.call('INCR', KEYS[2])
+if attemptCount == 1 then
+	redis.call('PEXPIRE', KEYS[2], ARGV[3])
+end
+if attemptCount > tonumber(ARGV[2]) then
+	redis.call('DEL', KEYS[1], KEYS[2])
+	return 0
+end
+if cjson.decode(otpData)['code_hash'] ~= ARGV[1] then
+	return 0
+end
+redis.call('DEL', KEYS[1], KEYS[2])
+return 1`)
+
+// OTPVerify checks code against the code issued for purpose, a valid code is spent.
+// The salt is read first to hash the tried code, the rest runs in otpVerifyScript, a code replaced in between simply does not match.
+func (s *DxmSelf) OTPVerify(aepr *api.DXAPIEndPointRequest, userId int64, purpose string, code string) (verificationResult bool, err error) {
+	preKeyRedis := user_management.ModuleUserManagement.PreKeyRedis.WithContext(aepr.Context)
+	key := otpKey(purpose, userId)
+	attemptKey := otpAttemptKey(purpose, userId)
+	otpData, err := preKeyRedis.Get(key)
+	if err != nil {
+		return false, err
+	}
+	if otpData == nil {
+		return false, nil
+	}
+	expiredAt, err := utilsJSON.GetInt64(otpData, "expired_at")
+	if err != nil {
+		return false, err
+	}
+	ttl := time.Until(time.Unix(expiredAt, 0))
+	if ttl <= 0 {
+		return false, otpDelete(preKeyRedis, key, attemptKey)
+	}
+	salt, err := hex.DecodeString(otpData["salt"].(string))
+	if err != nil {
+		return false, errors.Wrap(err, "error occured")
+	}
+	isSpent, err := otpVerifyScript.Run(preKeyRedis.Context, preKeyRedis.Connection, []string{key, attemptKey}, otpHash(salt, code), OTPMaxAttempt, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, errors.Wrap(err, "error occured")
+	}
+	return isSpent == 1, nil
+}
+
+func otpDelete(preKeyRedis *redis.DXRedis, key string, attemptKey string) (err error) {
+	err = preKeyRedis.Delete(key)
+	if err != nil {
+		return err
+	}
+	return preKeyRedis.Delete(attemptKey)
+}
+
+func otpStepUpKey(sessionKey string) string {
+	return "OTP_STEP_UP_" + sessionKey
+}
+
+type SelfOTPSendParameter struct {
+	Channel string `param:"channel,required" description:"EMAIL or SMS"`
+}
+
+// SelfOTPSend sends a step-up code to the logged user
+func (s *DxmSelf) SelfOTPSend(aepr *api.DXAPIEndPointRequest) (err error) {
+	parameter, err := api.Bind[SelfOTPSendParameter](aepr)
+	if err != nil {
+		return err
+	}
+	userId := aepr.LocalData["user_id"].(int64)
+	_, user, err := user_management.ModuleUserManagement.User.ShouldGetById(&aepr.Log, userId)
+	if err != nil {
+		return err
+	}
+	err = s.OTPIssue(aepr, user, OTPPurposeStepUp, OTPChannel(parameter.Channel))
+	if err != nil {
+		return err
+	}
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"expired_in_second": int64(OTPTTL.Seconds()),
+	})
+	return nil
+}
+
+type SelfOTPVerifyParameter struct {
+	Code string `param:"code,required" description:"One-time code"`
+}
+
+// SelfOTPVerify spends a step-up code, MiddlewareOTPStepUpCheck endpoints of the session are then open for OTPStepUpTTL
+func (s *DxmSelf) SelfOTPVerify(aepr *api.DXAPIEndPointRequest) (err error) {
+	parameter, err := api.Bind[SelfOTPVerifyParameter](aepr)
+	if err != nil {
+		return err
+	}
+	userId := aepr.LocalData["user_id"].(int64)
+	sessionKey := aepr.LocalData["session_key"].(string)
+	verificationResult, err := s.OTPVerify(aepr, userId, OTPPurposeStepUp, parameter.Code)
+	if err != nil {
+		return err
+	}
+	if !verificationResult {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_OTP_CODE")
+	}
+	err = user_management.ModuleUserManagement.PreKeyRedis.WithContext(aepr.Context).Set(otpStepUpKey(sessionKey), utils.JSON{
+		"user_id":     userId,
+		"verified_at": time.Now().Unix(),
+	}, OTPStepUpTTL)
+	if err != nil {
+		return err
+	}
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"expired_in_second": int64(OTPStepUpTTL.Seconds()),
+	})
+	return nil
+}
+
+// MiddlewareOTPStepUpCheck guards a sensitive endpoint, it must follow a login middleware and needs a step-up code verified within OTPStepUpTTL
+func (s *DxmSelf) MiddlewareOTPStepUpCheck(aepr *api.DXAPIEndPointRequest) (err error) {
+	sessionKey, ok := aepr.LocalData["session_key"].(string)
+	if !ok {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "SESSION_KEY_NOT_FOUND")
+	}
+	stepUp, err := user_management.ModuleUserManagement.PreKeyRedis.WithContext(aepr.Context).Get(otpStepUpKey(sessionKey))
+	if err != nil {
+		return err
+	}
+	if stepUp == nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusForbidden, "", "OTP_STEP_UP_REQUIRED")
+	}
+	return nil
+}
+
+// selfLoginUserOrganization resolves the logged organization of user like the password login without OnAuthenticateUser
+func selfLoginUserOrganization(aepr *api.DXAPIEndPointRequest, userId int64, organizationUId string) (userOrganizationMemberships []utils.JSON,
+	userLoggedOrganizationId int64, userLoggedOrganizationUid string, userLoggedOrganization utils.JSON, err error) {
+	us := utils.JSON{
+		"user_id": userId,
+	}
+	if organizationUId != "" {
+		us["organization_uid"] = organizationUId
+	}
+	_, userOrganizationMemberships, err = user_management.ModuleUserManagement.UserOrganizationMembership.Select(&aepr.Log, nil, us, nil,
+		map[string]string{"order_index": "asc"}, nil)
+	if err != nil {
+		return nil, 0, "", nil, err
+	}
+	if len(userOrganizationMemberships) == 0 {
+		return nil, 0, "", nil, aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_CREDENTIAL")
+	}
+	userLoggedOrganizationId = userOrganizationMemberships[0]["organization_id"].(int64)
+	userLoggedOrganizationUid = userOrganizationMemberships[0]["organization_uid"].(string)
+	_, userLoggedOrganization, err = user_management.ModuleUserManagement.Organization.ShouldGetById(&aepr.Log, userLoggedOrganizationId)
+	if err != nil {
+		return nil, 0, "", nil, err
+	}
+	return userOrganizationMemberships, userLoggedOrganizationId, userLoggedOrganizationUid, userLoggedOrganization, nil
+}
+
+/*
+  - One-time code login
+    SelfLoginOTPRequest d=PACK(LV(LOGINID),LV(CHANNEL)) sends a LOGIN code, it answers the same whether the login id exists or not.
+    SelfLoginOTP d=PACK(LV(LOGINID),LV(CODE)[,LV(ORGANIZATION_UID)]) then continues like a password success, including the TOTP challenge.
+*/
+
+func (s *DxmSelf) SelfLoginOTPRequest(aepr *api.DXAPIEndPointRequest) (err error) {
+	parameter, err := api.Bind[SelfLoginParameter](aepr)
+	if err != nil {
+		return err
+	}
+	lvPayloadElements, _, _, err := user_management.ModuleUserManagement.PreKeyUnpack(parameter.PreKeyIndex, parameter.DataAsHexString)
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:%v", err.Error())
+	}
+	if len(lvPayloadElements) < 2 {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:PAYLOAD_ELEMENTS_MISSING")
+	}
+	userLoginId := string(lvPayloadElements[0].Value)
+	channel := OTPChannel(lvPayloadElements[1].Value)
+
+	err = s.otpIssueCheck(aepr, channel)
+	if err != nil {
+		return err
+	}
+
+	// the user is looked up and the code is sent after the response, so an unknown user, the rate limit or a failing sender can not be told
+	// apart by the status or the time of the answer, failures are only logged
+	l := dxlibLog.NewLog(&aepr.Log, context.WithoutCancel(aepr.Context), "OTP_LOGIN_REQUEST")
+	// OnOTPSend is application code, a panic in it must not take the server down
+	api.Manager.GoBackground(&l, func() {
+		s.selfLoginOTPSend(&l, userLoginId, channel)
+	})
+
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"expired_in_second": int64(OTPTTL.Seconds()),
+	})
+	return nil
+}
+
+// selfLoginOTPSend runs after the response of SelfLoginOTPRequest was written
+func (s *DxmSelf) selfLoginOTPSend(l *dxlibLog.DXLog, userLoginId string, channel OTPChannel) {
+	_, user, err := user_management.ModuleUserManagement.User.SelectOne(l, nil, utils.JSON{
+		"loginid": userLoginId,
+	}, nil, nil)
+	if err != nil {
+		l.Errorf(err, "OTP_LOGIN_REQUEST_USER_SELECT_ERROR:%s", userLoginId)
+		return
+	}
+	if (user == nil) || (user["status"] != user_management.UserStatusActive) {
+		l.Warnf("OTP_LOGIN_REQUEST_USER_NOT_FOUND:%s", userLoginId)
+		return
+	}
+	isAllowed, err := s.otpSend(l, user, OTPPurposeLogin, channel)
+	if err != nil {
+		l.Errorf(err, "OTP_LOGIN_REQUEST_SEND_ERROR:%s", userLoginId)
+		return
+	}
+	if !isAllowed {
+		l.Warnf("OTP_LOGIN_REQUEST_RATE_LIMIT_EXCEEDED:%s", userLoginId)
+	}
+}
+
+func (s *DxmSelf) SelfLoginOTP(aepr *api.DXAPIEndPointRequest) (err error) {
+	parameter, err := api.Bind[SelfLoginParameter](aepr)
+	if err != nil {
+		return err
+	}
+	lvPayloadElements, sharedKey2AsBytes, edB0PrivateKeyAsBytes, err := user_management.ModuleUserManagement.PreKeyUnpack(parameter.PreKeyIndex, parameter.DataAsHexString)
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:%v", err.Error())
+	}
+	if len(lvPayloadElements) < 2 {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:PAYLOAD_ELEMENTS_MISSING")
+	}
+	userLoginId := string(lvPayloadElements[0].Value)
+	code := string(lvPayloadElements[1].Value)
+	organizationUId := ""
+	if len(lvPayloadElements) > 2 {
+		organizationUId = string(lvPayloadElements[2].Value)
+	}
+
+	_, user, err := user_management.ModuleUserManagement.User.SelectOne(&aepr.Log, nil, utils.JSON{
+		"loginid": userLoginId,
+	}, nil, nil)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_CREDENTIAL")
+	}
+	err = selfLoginLockCheck(aepr, user)
+	if err != nil {
+		return err
+	}
+	userId := user["id"].(int64)
+
+	verificationResult, err := s.OTPVerify(aepr, userId, OTPPurposeLogin, code)
+	if err != nil {
+		return err
+	}
+	organizationId, _ := user["organization_id"].(int64)
+	policy, err := user_management.ModuleUserManagement.PasswordPolicyGet(&aepr.Log, organizationId)
+	if err != nil {
+		return err
+	}
+	if !verificationResult {
+		return selfLoginFailedRecord(aepr, userId, policy)
+	}
+	err = user_management.ModuleUserManagement.UserLoginSucceededRecord(&aepr.Log, user)
+	if err != nil {
+		return err
+	}
+
+	userOrganizationMemberships, userLoggedOrganizationId, userLoggedOrganizationUid, userLoggedOrganization, err := selfLoginUserOrganization(aepr, userId, organizationUId)
+	if err != nil {
+		return err
+	}
+
+	return s.selfLoginPasswordVerified(aepr, parameter.PreKeyIndex, edB0PrivateKeyAsBytes, sharedKey2AsBytes, user, userOrganizationMemberships,
+		userLoggedOrganizationId, userLoggedOrganizationUid, userLoggedOrganization)
+}
@@ -0,0 +1,174 @@
+package self
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goRedis "github.com/go-redis/redis/v8"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	"github.com/donnyhardyanto/dxlib/redis"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib_module/module/user_management"
+)
+
+// testPreKeyRedis points the PreKeyRedis of the user management module at an in-memory Redis for the test
+func testPreKeyRedis(t *testing.T) (server *miniredis.Miniredis, preKeyRedis *redis.DXRedis) {
+	t.Helper()
+	server = miniredis.RunT(t)
+	connection := goRedis.NewRing(&goRedis.RingOptions{Addrs: map[string]string{"test": server.Addr()}})
+	preKeyRedis = &redis.DXRedis{NameId: "prekey_test", Connection: connection, Connected: true, Context: context.Background()}
+	previous := user_management.ModuleUserManagement.PreKeyRedis
+	user_management.ModuleUserManagement.PreKeyRedis = preKeyRedis
+	t.Cleanup(func() {
+		user_management.ModuleUserManagement.PreKeyRedis = previous
+		_ = connection.Close()
+	})
+	return server, preKeyRedis
+}
+
+// testOTPStore stores a code the way otpSend does, without sending it
+func testOTPStore(t *testing.T, preKeyRedis *redis.DXRedis, purpose string, userId int64, code string, ttl time.Duration) {
+	t.Helper()
+	salt := []byte("0123456789abcdef")
+	err := preKeyRedis.Set(otpKey(purpose, userId), utils.JSON{
+		"salt":       hex.EncodeToString(salt),
+		"code_hash":  otpHash(salt, code),
+		"channel":    string(OTPChannelEmail),
+		"expired_at": time.Now().Add(ttl).Unix(),
+	}, OTPTTL)
+	if err != nil {
+		t.Fatalf("Set() err = %v", err)
+	}
+	err = preKeyRedis.Delete(otpAttemptKey(purpose, userId))
+	if err != nil {
+		t.Fatalf("Delete() err = %v", err)
+	}
+}
+
+func TestOTPVerify(t *testing.T) {
+	_, preKeyRedis := testPreKeyRedis(t)
+	s := &DxmSelf{}
+	aepr := &api.DXAPIEndPointRequest{Context: context.Background()}
+
+	type attempt struct {
+		code                   string
+		wantVerificationResult bool
+	}
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		attempts []attempt
+	}{
+		{name: "right code", ttl: OTPTTL, attempts: []attempt{{code: "123456", wantVerificationResult: true}}},
+		{name: "right code is spent", ttl: OTPTTL, attempts: []attempt{{code: "123456", wantVerificationResult: true}, {code: "123456"}}},
+		{name: "wrong code then right code", ttl: OTPTTL, attempts: []attempt{{code: "654321"}, {code: "123456", wantVerificationResult: true}}},
+		{name: "right code after the last allowed attempt", ttl: OTPTTL, attempts: []attempt{{code: "000001"}, {code: "000002"}, {code: "000003"}, {code: "000004"}, {code: "123456", wantVerificationResult: true}}},
+		{name: "code is dropped after too many attempts", ttl: OTPTTL, attempts: []attempt{{code: "000001"}, {code: "000002"}, {code: "000003"}, {code: "000004"}, {code: "000005"}, {code: "123456"}}},
+		{name: "expired code", ttl: -time.Second, attempts: []attempt{{code: "123456"}}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userId := int64(i + 1)
+			testOTPStore(t, preKeyRedis, OTPPurposeStepUp, userId, "123456", tt.ttl)
+			for j, a := range tt.attempts {
+				verificationResult, err := s.OTPVerify(aepr, userId, OTPPurposeStepUp, a.code)
+				if err != nil {
+					t.Fatalf("attempt %d: OTPVerify() err = %v", j+1, err)
+				}
+				if verificationResult != a.wantVerificationResult {
+					t.Fatalf("attempt %d: OTPVerify(%s) = %v, want %v", j+1, a.code, verificationResult, a.wantVerificationResult)
+				}
+			}
+		})
+	}
+
+	t.Run("no code issued", func(t *testing.T) {
+		verificationResult, err := s.OTPVerify(aepr, 999, OTPPurposeStepUp, "123456")
+		if err != nil || verificationResult {
+			t.Errorf("OTPVerify() = %v, %v, want false, nil", verificationResult, err)
+		}
+	})
+
+	t.Run("code of another purpose", func(t *testing.T) {
+		testOTPStore(t, preKeyRedis, OTPPurposeLogin, 1000, "123456", OTPTTL)
+		verificationResult, err := s.OTPVerify(aepr, 1000, OTPPurposeStepUp, "123456")
+		if err != nil || verificationResult {
+			t.Errorf("OTPVerify() = %v, %v, want false, nil", verificationResult, err)
+		}
+	})
+}
+
+func TestOTPVerifyConcurrent(t *testing.T) {
+	_, preKeyRedis := testPreKeyRedis(t)
+	s := &DxmSelf{}
+	aepr := &api.DXAPIEndPointRequest{Context: context.Background()}
+
+	tests := []struct {
+		name            string
+		code            string
+		concurrency     int
+		wantVerifiedMax int
+		wantVerifiedMin int
+		wantCodeDropped bool
+	}{
+		{name: "right code used concurrently is accepted once", code: "123456", concurrency: 20, wantVerifiedMin: 1, wantVerifiedMax: 1},
+		{name: "concurrent guesses can not pass the attempt limit", code: "000000", concurrency: 20, wantCodeDropped: true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userId := int64(i + 1)
+			testOTPStore(t, preKeyRedis, OTPPurposeLogin, userId, "123456", OTPTTL)
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			verifiedCount := 0
+			for j := 0; j < tt.concurrency; j++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					verificationResult, err := s.OTPVerify(aepr, userId, OTPPurposeLogin, tt.code)
+					if err != nil {
+						t.Errorf("OTPVerify() err = %v", err)
+						return
+					}
+					if verificationResult {
+						mu.Lock()
+						verifiedCount++
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+			if (verifiedCount < tt.wantVerifiedMin) || (verifiedCount > tt.wantVerifiedMax) {
+				t.Errorf("verified %d times, want between %d and %d", verifiedCount, tt.wantVerifiedMin, tt.wantVerifiedMax)
+			}
+			if tt.wantCodeDropped {
+				verificationResult, err := s.OTPVerify(aepr, userId, OTPPurposeLogin, "123456")
+				if err != nil || verificationResult {
+					t.Errorf("OTPVerify() after %d guesses = %v, %v, want false, nil", tt.concurrency, verificationResult, err)
+				}
+			}
+		})
+	}
+}
+
+func TestOTPGenerate(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		code, err := otpGenerate()
+		if err != nil {
+			t.Fatalf("otpGenerate() err = %v", err)
+		}
+		if len(code) != OTPLength {
+			t.Fatalf("otpGenerate() = %s, want %d digits", code, OTPLength)
+		}
+		for _, c := range code {
+			if (c < '0') || (c > '9') {
+				t.Fatalf("otpGenerate() = %s, want digits only", code)
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package self
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib_module/module/user_management"
+)
+
+// selfLoginLockCheck refuses a locked user, login handlers call it before the password or login code is verified
+func selfLoginLockCheck(aepr *api.DXAPIEndPointRequest, user utils.JSON) (err error) {
+	isLocked, lockedUntil := user_management.UserIsLocked(user)
+	if isLocked {
+		return aepr.WriteResponseAndNewErrorf(http.StatusLocked, "", "USER_LOCKED:%s", lockedUntil.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// selfLoginLockCheckByLoginId refuses a locked user before OnAuthenticateUser verifies the password, a login id unknown to user_management is left to OnAuthenticateUser
+func selfLoginLockCheckByLoginId(aepr *api.DXAPIEndPointRequest, loginId string) (err error) {
+	_, user, err := user_management.ModuleUserManagement.User.SelectOne(&aepr.Log, nil, utils.JSON{
+		"loginid": loginId,
+	}, nil, nil)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+	return selfLoginLockCheck(aepr, user)
+}
+
+// selfLoginFailedRecord counts a wrong password or login code towards the lockout of the policy and answers 401
+func selfLoginFailedRecord(aepr *api.DXAPIEndPointRequest, userId int64, policy user_management.PasswordPolicy) (err error) {
+	isLocked, err := user_management.ModuleUserManagement.UserLoginFailedRecord(&aepr.Log, userId, policy)
+	if err != nil {
+		return err
+	}
+	if isLocked {
+		aepr.Log.Warnf("USER_LOCKED_AFTER_FAILED_LOGIN:%d", userId)
+	}
+	return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_CREDENTIAL")
+}
+
+// selfLoginPasswordPolicyApply applies the lockout and the maximum password age of the policy to a verified password login, the user is updated in place.
+// The lock is checked again for a user OnAuthenticateUser returned under another login id
+func selfLoginPasswordPolicyApply(aepr *api.DXAPIEndPointRequest, user utils.JSON, organizationId int64, verificationResult bool) (err error) {
+	err = selfLoginLockCheck(aepr, user)
+	if err != nil {
+		return err
+	}
+	userId := user["id"].(int64)
+	policy, err := user_management.ModuleUserManagement.PasswordPolicyGet(&aepr.Log, organizationId)
+	if err != nil {
+		return err
+	}
+
+	if !verificationResult {
+		return selfLoginFailedRecord(aepr, userId, policy)
+	}
+
+	err = user_management.ModuleUserManagement.UserLoginSucceededRecord(&aepr.Log, user)
+	if err != nil {
+		return err
+	}
+	user["login_failed_count"] = int64(0)
+	user["login_failed_first_at"] = nil
+
+	mustChangePassword, _ := user["must_change_password"].(bool)
+	if mustChangePassword {
+		return nil
+	}
+	isPasswordExpired, err := user_management.ModuleUserManagement.UserPasswordIsExpired(&aepr.Log, userId, policy)
+	if err != nil {
+		return err
+	}
+	if isPasswordExpired {
+		_, err = user_management.ModuleUserManagement.User.Update(utils.JSON{
+			"must_change_password": true,
+		}, utils.JSON{
+			"id": userId,
+		})
+		if err != nil {
+			return err
+		}
+		user["must_change_password"] = true
+	}
+	return nil
+}
+
+// selfPasswordPolicyCheck validates a new password against the policy of the organization and the password history of the user
+func selfPasswordPolicyCheck(aepr *api.DXAPIEndPointRequest, userId int64, organizationId int64, password string) (err error) {
+	policy, err := user_management.ModuleUserManagement.PasswordPolicyGet(&aepr.Log, organizationId)
+	if err != nil {
+		return err
+	}
+	err = policy.Validate(password)
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "INVALID_PASSWORD_FORMAT:%v", err.Error())
+	}
+	isReused, err := user_management.ModuleUserManagement.UserPasswordIsReused(&aepr.Log, userId, password, policy.HistoryCount)
+	if err != nil {
+		return err
+	}
+	if isReused {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "INVALID_PASSWORD_FORMAT:password must not be one of the last %d passwords", policy.HistoryCount)
+	}
+	return nil
+}
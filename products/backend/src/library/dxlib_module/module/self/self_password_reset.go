@@ -0,0 +1,202 @@
+package self
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib_module/module/audit_log"
+	"github.com/donnyhardyanto/dxlib_module/module/user_management"
+)
+
+const (
+	PasswordResetActivityRequest       = "PASSWORD_RESET_REQUEST"
+	PasswordResetActivityRedeem        = "PASSWORD_RESET_REDEEM"
+	PasswordResetActivitySessionRevoke = "PASSWORD_RESET_SESSION_REVOKE"
+)
+
+// passwordResetAuditLogRequest takes the fields of the request, so entries can still be written after the response
+func passwordResetAuditLogRequest(aepr *api.DXAPIEndPointRequest) utils.JSON {
+	return utils.JSON{
+		"request_id": aepr.Id,
+		"api_title":  aepr.EndPoint.Title,
+		"method":     aepr.Request.Method,
+		"api_url":    aepr.Request.URL.Path,
+		"ip_address": api.GetIPAddress(aepr.Request),
+	}
+}
+
+// passwordResetAuditLogInsert records a step of the password reset flow in audit_log.user_activity_log, the request entry itself has no user since nobody is logged in
+func passwordResetAuditLogInsert(l *dxlibLog.DXLog, request utils.JSON, user utils.JSON, activityName string, resultStatus string, resultMessage string) {
+	if audit_log.ModuleAuditLog.UserActivityLog == nil {
+		return
+	}
+	t := time.Now()
+	userActivityLog := utils.JSON{
+		"start_time":              t,
+		"end_time":                t,
+		"activity_name":           activityName,
+		"activity_result_status":  resultStatus,
+		"activity_result_message": resultMessage,
+	}
+	for k, v := range request {
+		userActivityLog[k] = v
+	}
+	if user != nil {
+		userActivityLog["user_id"] = user["id"]
+		userActivityLog["user_uid"] = user["uid"]
+		userActivityLog["user_loginid"] = user["loginid"]
+		userActivityLog["user_fullname"] = user["fullname"]
+	}
+	_, err := audit_log.ModuleAuditLog.UserActivityLog.Insert(l, userActivityLog)
+	if err != nil {
+		l.Errorf(err, "PASSWORD_RESET_AUDIT_LOG_ERROR:%s", activityName)
+	}
+}
+
+func passwordResetAuditLog(aepr *api.DXAPIEndPointRequest, user utils.JSON, activityName string, resultStatus string, resultMessage string) {
+	passwordResetAuditLogInsert(&aepr.Log, passwordResetAuditLogRequest(aepr), user, activityName, resultStatus, resultMessage)
+}
+
+/*
+  - Forgot password
+    SelfPasswordResetRequest d=PACK(LV(LOGINID_OR_EMAIL)) emails a reset link, it answers the same whether the user exists or not.
+    SelfPasswordReset d=PACK(LV(RESET_TOKEN),LV(NEW_PASSWORD)) sets the password and logs the user out everywhere.
+*/
+
+func (s *DxmSelf) SelfPasswordResetRequest(aepr *api.DXAPIEndPointRequest) (err error) {
+	parameter, err := api.Bind[SelfLoginParameter](aepr)
+	if err != nil {
+		return err
+	}
+	lvPayloadElements, _, _, err := user_management.ModuleUserManagement.PreKeyUnpack(parameter.PreKeyIndex, parameter.DataAsHexString)
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:%v", err.Error())
+	}
+	if len(lvPayloadElements) < 1 {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:PAYLOAD_ELEMENTS_MISSING")
+	}
+	userLoginIdOrEmail := string(lvPayloadElements[0].Value)
+
+	if user_management.ModuleUserManagement.OnUserPasswordResetTokenSend == nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusNotImplemented, "", "PASSWORD_RESET_SENDER_NOT_CONFIGURED")
+	}
+
+	// the user is looked up and the link is sent after the response, so an unknown user or a failing sender can not be told apart by the status
+	// or the time of the answer, failures are only logged and audited
+	l := dxlibLog.NewLog(&aepr.Log, context.WithoutCancel(aepr.Context), "PASSWORD_RESET_REQUEST")
+	request := passwordResetAuditLogRequest(aepr)
+	// OnUserPasswordResetTokenSend is application code, a panic in it must not take the server down
+	api.Manager.GoBackground(&l, func() {
+		selfPasswordResetSend(&l, request, userLoginIdOrEmail)
+	})
+
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"expired_in_second": int64(user_management.UserPasswordResetTokenTTL.Seconds()),
+	})
+	return nil
+}
+
+// selfPasswordResetSend runs after the response of SelfPasswordResetRequest was written
+func selfPasswordResetSend(l *dxlibLog.DXLog, request utils.JSON, userLoginIdOrEmail string) {
+	_, user, err := user_management.ModuleUserManagement.User.SelectOne(l, nil, utils.JSON{
+		"loginid": userLoginIdOrEmail,
+	}, nil, nil)
+	if err != nil {
+		l.Errorf(err, "PASSWORD_RESET_REQUEST_USER_SELECT_ERROR:%s", userLoginIdOrEmail)
+		return
+	}
+	if user == nil {
+		// email is not unique, an ambiguous address is treated as unknown
+		_, users, err := user_management.ModuleUserManagement.User.Select(l, nil, utils.JSON{
+			"email": userLoginIdOrEmail,
+		}, nil, nil, 2)
+		if err != nil {
+			l.Errorf(err, "PASSWORD_RESET_REQUEST_USER_SELECT_ERROR:%s", userLoginIdOrEmail)
+			return
+		}
+		if len(users) == 1 {
+			user = users[0]
+		}
+	}
+
+	switch {
+	case user == nil:
+		l.Warnf("PASSWORD_RESET_REQUEST_USER_NOT_FOUND:%s", userLoginIdOrEmail)
+		passwordResetAuditLogInsert(l, request, nil, PasswordResetActivityRequest, "REJECTED", "USER_NOT_FOUND")
+	case user["status"] != user_management.UserStatusActive:
+		passwordResetAuditLogInsert(l, request, user, PasswordResetActivityRequest, "REJECTED", "USER_NOT_ACTIVE")
+	case user["email"] == nil || user["email"] == "":
+		passwordResetAuditLogInsert(l, request, user, PasswordResetActivityRequest, "REJECTED", "USER_EMAIL_IS_EMPTY")
+	default:
+		err = user_management.ModuleUserManagement.UserPasswordResetTokenSend(l, user)
+		if err != nil {
+			l.Errorf(err, "PASSWORD_RESET_REQUEST_SEND_ERROR:%s", userLoginIdOrEmail)
+			passwordResetAuditLogInsert(l, request, user, PasswordResetActivityRequest, "FAILED", "RESET_TOKEN_SEND_FAILED")
+			return
+		}
+		passwordResetAuditLogInsert(l, request, user, PasswordResetActivityRequest, "SUCCESS", "RESET_TOKEN_SENT")
+	}
+}
+
+func (s *DxmSelf) SelfPasswordReset(aepr *api.DXAPIEndPointRequest) (err error) {
+	parameter, err := api.Bind[SelfLoginParameter](aepr)
+	if err != nil {
+		return err
+	}
+	lvPayloadElements, _, _, err := user_management.ModuleUserManagement.PreKeyUnpack(parameter.PreKeyIndex, parameter.DataAsHexString)
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:%v", err.Error())
+	}
+	if len(lvPayloadElements) < 2 {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:PAYLOAD_ELEMENTS_MISSING")
+	}
+	resetToken := string(lvPayloadElements[0].Value)
+	userPasswordNew := string(lvPayloadElements[1].Value)
+
+	user, err := user_management.ModuleUserManagement.UserPasswordResetTokenUser(&aepr.Log, resetToken)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		passwordResetAuditLog(aepr, nil, PasswordResetActivityRedeem, "REJECTED", "RESET_TOKEN_INVALID")
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "PASSWORD_RESET_TOKEN_INVALID")
+	}
+	organizationId, _ := user["organization_id"].(int64)
+	err = selfPasswordPolicyCheck(aepr, user["id"].(int64), organizationId, userPasswordNew)
+	if err != nil {
+		passwordResetAuditLog(aepr, user, PasswordResetActivityRedeem, "REJECTED", "INVALID_PASSWORD_FORMAT")
+		return err
+	}
+
+	// the sessions are revoked inside the transaction of the redeem, a failing revocation leaves the token and the password as they were
+	isSessionRevokeFailed := false
+	user, err = user_management.ModuleUserManagement.UserPasswordResetTokenRedeem(&aepr.Log, resetToken, userPasswordNew, func(userId int64) (err error) {
+		err = SessionRevokeAllOfUser(aepr, userId)
+		if err != nil {
+			isSessionRevokeFailed = true
+		}
+		return err
+	})
+	if err != nil {
+		if isSessionRevokeFailed {
+			passwordResetAuditLog(aepr, nil, PasswordResetActivitySessionRevoke, "FAILED", "SESSION_REVOKE_FAILED")
+		} else {
+			passwordResetAuditLog(aepr, nil, PasswordResetActivityRedeem, "FAILED", "PASSWORD_UPDATE_FAILED")
+		}
+		return err
+	}
+	if user == nil {
+		passwordResetAuditLog(aepr, nil, PasswordResetActivityRedeem, "REJECTED", "RESET_TOKEN_INVALID")
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "PASSWORD_RESET_TOKEN_INVALID")
+	}
+	aepr.Log.Infof("User password reset")
+	passwordResetAuditLog(aepr, user, PasswordResetActivityRedeem, "SUCCESS", "PASSWORD_CHANGED")
+	passwordResetAuditLog(aepr, user, PasswordResetActivitySessionRevoke, "SUCCESS", "ALL_SESSIONS_REVOKED")
+
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, nil)
+	return nil
+}
@@ -0,0 +1,76 @@
+package self
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib_module/module/user_management"
+	"github.com/pkg/errors"
+)
+
+const (
+	StreamTicketSize = 32
+	StreamTicketTTL  = 30 * time.Second
+	// StreamTicketQueryParameter carries the ticket on websocket and Server-Sent Events requests
+	StreamTicketQueryParameter = "ticket"
+)
+
+/*
+  - Stream tickets
+    Browsers can not set the Authorization header on a websocket handshake or an EventSource request, so the session key would end up in the URL,
+    where proxies and access logs keep it. SelfStreamTicketCreate trades the session of the caller for a ticket that lives StreamTicketTTL and is
+    spent by the first websocket or Server-Sent Events request that presents it.
+*/
+
+func streamTicketKey(ticket string) string {
+	return "SESSION_STREAM_TICKET_" + ticket
+}
+
+func (s *DxmSelf) SelfStreamTicketCreate(aepr *api.DXAPIEndPointRequest) (err error) {
+	sessionKey, ok := aepr.LocalData["session_key"].(string)
+	if !ok {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "SESSION_NOT_FOUND")
+	}
+	b := make([]byte, StreamTicketSize)
+	_, err = rand.Read(b)
+	if err != nil {
+		return errors.Wrap(err, "error occured")
+	}
+	ticket := base64.RawURLEncoding.EncodeToString(b)
+	err = user_management.ModuleUserManagement.SessionRedis.WithContext(aepr.Context).Set(streamTicketKey(ticket), utils.JSON{
+		"session_key": sessionKey,
+	}, StreamTicketTTL)
+	if err != nil {
+		return err
+	}
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"ticket":            ticket,
+		"expired_in_second": int64(StreamTicketTTL.Seconds()),
+	})
+	return nil
+}
+
+// streamTicketSpend returns the session key of the ticket and removes it, of concurrent requests with the same ticket only one gets the key
+func streamTicketSpend(aepr *api.DXAPIEndPointRequest, ticket string) (sessionKey string, err error) {
+	sessionRedis := user_management.ModuleUserManagement.SessionRedis.WithContext(aepr.Context)
+	streamTicket, err := sessionRedis.Get(streamTicketKey(ticket))
+	if err != nil {
+		return "", err
+	}
+	if streamTicket == nil {
+		return "", nil
+	}
+	isDeleted, err := sessionRedis.DeleteExisting(streamTicketKey(ticket))
+	if err != nil {
+		return "", err
+	}
+	if !isDeleted {
+		return "", nil
+	}
+	sessionKey, _ = streamTicket["session_key"].(string)
+	return sessionKey, nil
+}
@@ -0,0 +1,178 @@
+package self
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goRedis "github.com/go-redis/redis/v8"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	"github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/redis"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib_module/module/user_management"
+)
+
+// testSessionRedis points the SessionRedis of the user management module at an in-memory Redis for the test
+func testSessionRedis(t *testing.T) (server *miniredis.Miniredis, sessionRedis *redis.DXRedis) {
+	t.Helper()
+	server = miniredis.RunT(t)
+	connection := goRedis.NewRing(&goRedis.RingOptions{Addrs: map[string]string{"test": server.Addr()}})
+	sessionRedis = &redis.DXRedis{NameId: "session_test", Connection: connection, Connected: true, Context: context.Background()}
+	previous := user_management.ModuleUserManagement.SessionRedis
+	user_management.ModuleUserManagement.SessionRedis = sessionRedis
+	t.Cleanup(func() {
+		user_management.ModuleUserManagement.SessionRedis = previous
+		_ = connection.Close()
+	})
+	return server, sessionRedis
+}
+
+func testStreamRequest(endPointType api.DXAPIEndPointType, target string, authorization string) (aepr *api.DXAPIEndPointRequest, recorder *httptest.ResponseRecorder) {
+	a := &api.DXAPI{NameId: "test", ErrorResponseFormat: api.ErrorResponseFormatProblemJSON}
+	endPoint := &api.DXAPIEndPoint{Owner: a, Method: http.MethodGet, Uri: "/v1/self/stream", EndPointType: endPointType}
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+	recorder = httptest.NewRecorder()
+	var w http.ResponseWriter = recorder
+	aepr = &api.DXAPIEndPointRequest{
+		Id:             "r1",
+		Context:        context.Background(),
+		EndPoint:       endPoint,
+		Log:            log.NewLog(nil, context.Background(), "test"),
+		Request:        request,
+		ResponseWriter: &w,
+		LocalData:      map[string]any{},
+	}
+	return aepr, recorder
+}
+
+// testStreamTicketCreate creates a ticket of sessionKey through SelfStreamTicketCreate
+func testStreamTicketCreate(t *testing.T, sessionKey string) (ticket string) {
+	t.Helper()
+	aepr, recorder := testStreamRequest(api.EndPointTypeHTTPJSON, "/v1/self/stream_ticket/create", "")
+	aepr.LocalData["session_key"] = sessionKey
+	err := (&DxmSelf{}).SelfStreamTicketCreate(aepr)
+	if err != nil {
+		t.Fatalf("SelfStreamTicketCreate() err = %v", err)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200, body = %s", recorder.Code, recorder.Body.String())
+	}
+	var response utils.JSON
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Unmarshal() err = %v", err)
+	}
+	ticket, _ = response["ticket"].(string)
+	if ticket == "" {
+		t.Fatalf("body = %s, want a ticket", recorder.Body.String())
+	}
+	return ticket
+}
+
+func TestSelfStreamTicketCreateWithoutSession(t *testing.T) {
+	testSessionRedis(t)
+	aepr, recorder := testStreamRequest(api.EndPointTypeHTTPJSON, "/v1/self/stream_ticket/create", "")
+	_ = (&DxmSelf{}).SelfStreamTicketCreate(aepr)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", recorder.Code)
+	}
+}
+
+func TestAuthorizationHeader(t *testing.T) {
+	server, _ := testSessionRedis(t)
+	tests := []struct {
+		name              string
+		endPointType      api.DXAPIEndPointType
+		authorization     string
+		ticket            func(t *testing.T) string
+		wantAuthorization string
+		wantStatus        int
+	}{
+		{name: "header wins over a ticket", endPointType: api.EndPointTypeWS, authorization: "Bearer header", ticket: func(t *testing.T) string { return testStreamTicketCreate(t, "k1") }, wantAuthorization: "Bearer header"},
+		{name: "ticket of a websocket endpoint", endPointType: api.EndPointTypeWS, ticket: func(t *testing.T) string { return testStreamTicketCreate(t, "k1") }, wantAuthorization: "Bearer k1"},
+		{name: "ticket of a Server-Sent Events endpoint", endPointType: api.EndPointTypeSSE, ticket: func(t *testing.T) string { return testStreamTicketCreate(t, "k2") }, wantAuthorization: "Bearer k2"},
+		{name: "ticket of a JSON endpoint is ignored", endPointType: api.EndPointTypeHTTPJSON, ticket: func(t *testing.T) string { return testStreamTicketCreate(t, "k1") }},
+		{name: "no ticket", endPointType: api.EndPointTypeWS, ticket: func(t *testing.T) string { return "" }},
+		{name: "unknown ticket", endPointType: api.EndPointTypeWS, ticket: func(t *testing.T) string { return "unknown" }, wantStatus: http.StatusUnauthorized},
+		{
+			name: "spent ticket", endPointType: api.EndPointTypeWS, wantStatus: http.StatusUnauthorized,
+			ticket: func(t *testing.T) string {
+				ticket := testStreamTicketCreate(t, "k1")
+				sessionKey, err := streamTicketSpend(&api.DXAPIEndPointRequest{Context: context.Background()}, ticket)
+				if (err != nil) || (sessionKey != "k1") {
+					t.Fatalf("streamTicketSpend() = %s, %v, want k1", sessionKey, err)
+				}
+				return ticket
+			},
+		},
+		{
+			name: "expired ticket", endPointType: api.EndPointTypeSSE, wantStatus: http.StatusUnauthorized,
+			ticket: func(t *testing.T) string {
+				ticket := testStreamTicketCreate(t, "k1")
+				server.FastForward(StreamTicketTTL)
+				return ticket
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/v1/self/stream"
+			if ticket := tt.ticket(t); ticket != "" {
+				target += "?" + StreamTicketQueryParameter + "=" + ticket
+			}
+			aepr, recorder := testStreamRequest(tt.endPointType, target, tt.authorization)
+			authorization, err := authorizationHeader(aepr)
+			if tt.wantStatus != 0 {
+				if (err == nil) || (recorder.Code != tt.wantStatus) {
+					t.Fatalf("authorizationHeader() err = %v, status = %d, want status %d", err, recorder.Code, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("authorizationHeader() err = %v", err)
+			}
+			if authorization != tt.wantAuthorization {
+				t.Errorf("authorizationHeader() = %q, want %q", authorization, tt.wantAuthorization)
+			}
+		})
+	}
+}
+
+func TestStreamTicketSpendConcurrent(t *testing.T) {
+	testSessionRedis(t)
+	ticket := testStreamTicketCreate(t, "k1")
+	const n = 16
+	var wg sync.WaitGroup
+	sessionKeys := make(chan string, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sessionKey, err := streamTicketSpend(&api.DXAPIEndPointRequest{Context: context.Background()}, ticket)
+			if err != nil {
+				t.Errorf("streamTicketSpend() err = %v", err)
+			}
+			sessionKeys <- sessionKey
+		}()
+	}
+	wg.Wait()
+	close(sessionKeys)
+	spent := 0
+	for sessionKey := range sessionKeys {
+		if sessionKey == "k1" {
+			spent++
+		}
+	}
+	if spent != 1 {
+		t.Errorf("ticket was spent %d times, want 1", spent)
+	}
+}
@@ -0,0 +1,361 @@
+package self
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/redis"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib/utils/crypto/datablock"
+	"github.com/donnyhardyanto/dxlib/utils/crypto/totp"
+	utilsJSON "github.com/donnyhardyanto/dxlib/utils/json"
+	"github.com/donnyhardyanto/dxlib/utils/lv"
+	"github.com/donnyhardyanto/dxlib_module/module/general"
+	"github.com/donnyhardyanto/dxlib_module/module/user_management"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	TOTPChallengeTTL        = 3 * time.Minute
+	TOTPChallengeMaxAttempt = 5
+	TOTPQRCodeSize          = 256
+)
+
+/*
+  - TOTP login challenge
+    A password success of a user with TOTP enabled, or with a role whose organization_role.is_totp_required is set, answers
+    	{"is_totp_required":true,"is_totp_enrollment_required":BOOL,"d":PACK(LV(CHALLENGE))}
+    instead of the session. The session is created by SelfLoginTOTP with d=PACK(LV(CHALLENGE),LV(CODE)), CODE is a TOTP or a recovery code.
+    A user who still has to enroll calls SelfLoginTOTPEnroll with d=PACK(LV(CHALLENGE)) first, the first code then confirms the enrollment
+    and the recovery codes follow the session object as a second LV.
+*/
+
+// selfLoginPasswordVerified continues a login whose password is verified, the session is only created here when no TOTP is needed
+func (s *DxmSelf) selfLoginPasswordVerified(aepr *api.DXAPIEndPointRequest, preKeyIndex string, edB0PrivateKeyAsBytes []byte, sharedKey2AsBytes []byte, user utils.JSON,
+	userOrganizationMemberships []utils.JSON, userLoggedOrganizationId int64, userLoggedOrganizationUid string, userLoggedOrganization utils.JSON) (err error) {
+	userId, ok := user["id"].(int64)
+	if !ok {
+		return aepr.WriteResponseAndNewErrorf(500, "", "SHOULD_NOT_HAPPEN:USER_ID_NOT_FOUND_IN_USER")
+	}
+	isTOTPEnabled, err := user_management.ModuleUserManagement.UserTOTPIsEnabled(&aepr.Log, userId)
+	if err != nil {
+		return err
+	}
+	isTOTPRequired := isTOTPEnabled
+	if !isTOTPRequired {
+		isTOTPRequired, err = user_management.ModuleUserManagement.UserTOTPIsRequired(&aepr.Log, userId, userLoggedOrganizationId)
+		if err != nil {
+			return err
+		}
+	}
+	if !isTOTPRequired {
+		return s.selfLoginSessionCreate(aepr, preKeyIndex, edB0PrivateKeyAsBytes, sharedKey2AsBytes, user, userOrganizationMemberships,
+			userLoggedOrganizationId, userLoggedOrganizationUid, userLoggedOrganization)
+	}
+
+	challengeId, err := uuid.NewRandom()
+	if err != nil {
+		return errors.Wrap(err, "error occured")
+	}
+	challenge := "TOTP_CHALLENGE_" + challengeId.String()
+	err = user_management.ModuleUserManagement.PreKeyRedis.WithContext(aepr.Context).Set(challenge, utils.JSON{
+		"user_id":                       userId,
+		"organization_id":               userLoggedOrganizationId,
+		"organization_uid":              userLoggedOrganizationUid,
+		"user_organization_memberships": userOrganizationMemberships,
+		"is_totp_enabled":               isTOTPEnabled,
+		"expired_at":                    time.Now().Add(TOTPChallengeTTL).Unix(),
+	}, TOTPChallengeTTL)
+	if err != nil {
+		return err
+	}
+
+	lvChallenge, err := lv.NewLV([]byte(challenge))
+	if err != nil {
+		return err
+	}
+	dataBlockEnvelopeAsHexString, err := datablock.PackLVPayload(preKeyIndex, edB0PrivateKeyAsBytes, sharedKey2AsBytes, lvChallenge)
+	if err != nil {
+		return err
+	}
+
+	aepr.Log.Infof("TOTP_CHALLENGE_CREATED:user_id=%d", userId)
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"is_totp_required":            true,
+		"is_totp_enrollment_required": !isTOTPEnabled,
+		"d":                           dataBlockEnvelopeAsHexString,
+	})
+	return nil
+}
+
+// totpChallengeAttemptKey holds the plain integer attempt counter of a challenge
+func totpChallengeAttemptKey(challenge string) string {
+	return challenge + "_ATTEMPT"
+}
+
+// totpChallengeGet answers 401 for a missing or exhausted challenge, each call counts as an attempt.
+// The counter is incremented atomically, so concurrent codes and recovery codes can not pass TOTPChallengeMaxAttempt.
+func totpChallengeGet(aepr *api.DXAPIEndPointRequest, challenge string) (challengeData utils.JSON, userId int64, err error) {
+	preKeyRedis := user_management.ModuleUserManagement.PreKeyRedis.WithContext(aepr.Context)
+	challengeData, err = preKeyRedis.Get(challenge)
+	if err != nil {
+		return nil, 0, err
+	}
+	if challengeData == nil {
+		return nil, 0, aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "TOTP_CHALLENGE_NOT_FOUND")
+	}
+	expiredAt, err := utilsJSON.GetInt64(challengeData, "expired_at")
+	if err != nil {
+		return nil, 0, err
+	}
+	ttl := time.Until(time.Unix(expiredAt, 0))
+	attemptCount := int64(0)
+	if ttl > 0 {
+		// the counter expires with the challenge, a retry must not extend it
+		attemptCount, err = preKeyRedis.Incr(totpChallengeAttemptKey(challenge), ttl)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	if (attemptCount > TOTPChallengeMaxAttempt) || (ttl <= 0) {
+		err = totpChallengeDelete(preKeyRedis, challenge)
+		if err != nil {
+			return nil, 0, err
+		}
+		return nil, 0, aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "TOTP_CHALLENGE_NOT_FOUND")
+	}
+	userId, err = utilsJSON.GetInt64(challengeData, "user_id")
+	if err != nil {
+		return nil, 0, err
+	}
+	return challengeData, userId, nil
+}
+
+func totpChallengeDelete(preKeyRedis *redis.DXRedis, challenge string) (err error) {
+	err = preKeyRedis.Delete(challenge)
+	if err != nil {
+		return err
+	}
+	return preKeyRedis.Delete(totpChallengeAttemptKey(challenge))
+}
+
+// totpEnrollment starts an enrollment and returns the secret with its otpauth URI and the URI as a base64 QR code PNG
+func totpEnrollment(l *dxlibLog.DXLog, userId int64) (enrollment utils.JSON, err error) {
+	_, user, err := user_management.ModuleUserManagement.User.ShouldGetById(l, userId)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := user_management.ModuleUserManagement.UserTOTPEnrollBegin(l, userId)
+	if err != nil {
+		return nil, err
+	}
+	issuer, err := general.ModuleGeneral.Property.GetAsStringDefault(l, "SYSTEM-NAME", "")
+	if err != nil {
+		return nil, err
+	}
+	uri := totp.URI(issuer, user["loginid"].(string), secret)
+	qrCodePNG, err := qrcode.Encode(uri, qrcode.Medium, TOTPQRCodeSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "error occured")
+	}
+	return utils.JSON{
+		"secret":             secret,
+		"uri":                uri,
+		"qr_code_png_base64": base64.StdEncoding.EncodeToString(qrCodePNG),
+	}, nil
+}
+
+// SelfLoginTOTPEnroll starts the enrollment of a user who got a challenge only because a role requires TOTP
+func (s *DxmSelf) SelfLoginTOTPEnroll(aepr *api.DXAPIEndPointRequest) (err error) {
+	parameter, err := api.Bind[SelfLoginParameter](aepr)
+	if err != nil {
+		return err
+	}
+
+	lvPayloadElements, sharedKey2AsBytes, edB0PrivateKeyAsBytes, err := user_management.ModuleUserManagement.PreKeyUnpack(parameter.PreKeyIndex, parameter.DataAsHexString)
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:%v", err.Error())
+	}
+	challenge := string(lvPayloadElements[0].Value)
+
+	challengeData, userId, err := totpChallengeGet(aepr, challenge)
+	if err != nil {
+		return err
+	}
+	if challengeData["is_totp_enabled"].(bool) {
+		return aepr.WriteResponseAndNewErrorf(http.StatusConflict, "", "TOTP_ALREADY_ENABLED")
+	}
+
+	enrollment, err := totpEnrollment(&aepr.Log, userId)
+	if err != nil {
+		return err
+	}
+	enrollmentJSON, err := json.Marshal(enrollment)
+	if err != nil {
+		return err
+	}
+	lvEnrollment, err := lv.NewLV(enrollmentJSON)
+	if err != nil {
+		return err
+	}
+	dataBlockEnvelopeAsHexString, err := datablock.PackLVPayload(parameter.PreKeyIndex, edB0PrivateKeyAsBytes, sharedKey2AsBytes, lvEnrollment)
+	if err != nil {
+		return err
+	}
+
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"d": dataBlockEnvelopeAsHexString,
+	})
+	return nil
+}
+
+// SelfLoginTOTP is the second login step, it creates the session once the code of the challenge is valid
+func (s *DxmSelf) SelfLoginTOTP(aepr *api.DXAPIEndPointRequest) (err error) {
+	parameter, err := api.Bind[SelfLoginParameter](aepr)
+	if err != nil {
+		return err
+	}
+
+	lvPayloadElements, sharedKey2AsBytes, edB0PrivateKeyAsBytes, err := user_management.ModuleUserManagement.PreKeyUnpack(parameter.PreKeyIndex, parameter.DataAsHexString)
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:%v", err.Error())
+	}
+	if len(lvPayloadElements) < 2 {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "UNPACK_ERROR:PAYLOAD_ELEMENTS_MISSING")
+	}
+	challenge := string(lvPayloadElements[0].Value)
+	code := string(lvPayloadElements[1].Value)
+
+	challengeData, userId, err := totpChallengeGet(aepr, challenge)
+	if err != nil {
+		return err
+	}
+
+	var lvExtras []*lv.LV
+	if challengeData["is_totp_enabled"].(bool) {
+		verificationResult, err := user_management.ModuleUserManagement.UserTOTPVerify(&aepr.Log, userId, code)
+		if err != nil {
+			return err
+		}
+		if !verificationResult {
+			return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_TOTP_CODE")
+		}
+	} else {
+		recoveryCodes, err := user_management.ModuleUserManagement.UserTOTPEnrollConfirm(&aepr.Log, userId, code)
+		if err != nil {
+			return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_TOTP_CODE:%v", err.Error())
+		}
+		recoveryCodesJSON, err := json.Marshal(recoveryCodes)
+		if err != nil {
+			return err
+		}
+		lvRecoveryCodes, err := lv.NewLV(recoveryCodesJSON)
+		if err != nil {
+			return err
+		}
+		lvExtras = append(lvExtras, lvRecoveryCodes)
+	}
+
+	// the challenge is single use, of concurrent requests with valid codes only the one removing it gets a session
+	preKeyRedis := user_management.ModuleUserManagement.PreKeyRedis.WithContext(aepr.Context)
+	isSpent, err := preKeyRedis.DeleteExisting(challenge)
+	if err != nil {
+		return err
+	}
+	err = preKeyRedis.Delete(totpChallengeAttemptKey(challenge))
+	if err != nil {
+		return err
+	}
+	if !isSpent {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "TOTP_CHALLENGE_NOT_FOUND")
+	}
+
+	_, user, err := user_management.ModuleUserManagement.User.ShouldGetById(&aepr.Log, userId)
+	if err != nil {
+		return err
+	}
+	userLoggedOrganizationId, err := utilsJSON.GetInt64(challengeData, "organization_id")
+	if err != nil {
+		return err
+	}
+	userLoggedOrganizationUid, err := utilsJSON.GetString(challengeData, "organization_uid")
+	if err != nil {
+		return err
+	}
+	_, userLoggedOrganization, err := user_management.ModuleUserManagement.Organization.ShouldGetById(&aepr.Log, userLoggedOrganizationId)
+	if err != nil {
+		return err
+	}
+
+	return s.selfLoginSessionCreate(aepr, parameter.PreKeyIndex, edB0PrivateKeyAsBytes, sharedKey2AsBytes, user, challengeData["user_organization_memberships"],
+		userLoggedOrganizationId, userLoggedOrganizationUid, userLoggedOrganization, lvExtras...)
+}
+
+func (s *DxmSelf) SelfTOTPEnroll(aepr *api.DXAPIEndPointRequest) (err error) {
+	userId := aepr.LocalData["user_id"].(int64)
+	enrollment, err := totpEnrollment(&aepr.Log, userId)
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusConflict, "", "TOTP_ENROLL_FAILED:%v", err.Error())
+	}
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, enrollment)
+	return nil
+}
+
+type SelfTOTPCodeParameter struct {
+	Code string `param:"code,required" description:"TOTP code, or a recovery code"`
+}
+
+// SelfTOTPConfirm enables the enrollment with a first code, the recovery codes are only shown in this response
+func (s *DxmSelf) SelfTOTPConfirm(aepr *api.DXAPIEndPointRequest) (err error) {
+	parameter, err := api.Bind[SelfTOTPCodeParameter](aepr)
+	if err != nil {
+		return err
+	}
+	userId := aepr.LocalData["user_id"].(int64)
+	recoveryCodes, err := user_management.ModuleUserManagement.UserTOTPEnrollConfirm(&aepr.Log, userId, parameter.Code)
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "TOTP_CONFIRM_FAILED:%v", err.Error())
+	}
+	aepr.Log.Infof("User TOTP enabled")
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"recovery_codes": recoveryCodes,
+	})
+	return nil
+}
+
+// SelfTOTPDisable needs a valid code and is refused while a role of the user in the logged organization requires TOTP
+func (s *DxmSelf) SelfTOTPDisable(aepr *api.DXAPIEndPointRequest) (err error) {
+	parameter, err := api.Bind[SelfTOTPCodeParameter](aepr)
+	if err != nil {
+		return err
+	}
+	userId := aepr.LocalData["user_id"].(int64)
+	organizationId := aepr.LocalData["organization_id"].(int64)
+	isTOTPRequired, err := user_management.ModuleUserManagement.UserTOTPIsRequired(&aepr.Log, userId, organizationId)
+	if err != nil {
+		return err
+	}
+	if isTOTPRequired {
+		return aepr.WriteResponseAndNewErrorf(http.StatusForbidden, "", "TOTP_REQUIRED_BY_ROLE")
+	}
+	verificationResult, err := user_management.ModuleUserManagement.UserTOTPVerify(&aepr.Log, userId, parameter.Code)
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "TOTP_DISABLE_FAILED:%v", err.Error())
+	}
+	if !verificationResult {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnauthorized, "", "INVALID_TOTP_CODE")
+	}
+	err = user_management.ModuleUserManagement.UserTOTPDisable(&aepr.Log, userId)
+	if err != nil {
+		return err
+	}
+	aepr.Log.Infof("User TOTP disabled")
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, nil)
+	return nil
+}
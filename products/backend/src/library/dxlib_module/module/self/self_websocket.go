@@ -0,0 +1,45 @@
+package self
+
+import (
+	"slices"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	"github.com/donnyhardyanto/dxlib_module/module/general"
+)
+
+var SelfWSSubscribableChannels = []string{general.WSChannelAnnouncement}
+
+type SelfWSMessage struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel,omitempty"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (s *DxmSelf) SelfWSLoop(aepr *api.DXAPIEndPointRequest) (err error) {
+	c := aepr.WSConnection
+	for {
+		m, err := api.WSReceiveJSON[SelfWSMessage](c)
+		if err != nil {
+			return err
+		}
+		switch m.Type {
+		case "ping":
+			err = c.WriteJSON(SelfWSMessage{Type: "pong"})
+		case "subscribe":
+			if !slices.Contains(SelfWSSubscribableChannels, m.Channel) {
+				err = c.WriteJSON(SelfWSMessage{Type: "error", Channel: m.Channel, Data: "CHANNEL_NOT_FOUND"})
+				break
+			}
+			c.Subscribe(m.Channel)
+			err = c.WriteJSON(SelfWSMessage{Type: "subscribed", Channel: m.Channel})
+		case "unsubscribe":
+			c.Unsubscribe(m.Channel)
+			err = c.WriteJSON(SelfWSMessage{Type: "unsubscribed", Channel: m.Channel})
+		default:
+			err = c.WriteJSON(SelfWSMessage{Type: "error", Data: "MESSAGE_TYPE_NOT_SUPPORTED:" + m.Type})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
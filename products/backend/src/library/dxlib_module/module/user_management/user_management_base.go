@@ -1,6 +1,7 @@
 package user_management
 
 import (
+	"context"
 	"fmt"
 	"github.com/donnyhardyanto/dxlib/api"
 	"github.com/donnyhardyanto/dxlib/database"
@@ -9,8 +10,11 @@ import (
 	"github.com/donnyhardyanto/dxlib/redis"
 	"github.com/donnyhardyanto/dxlib/table"
 	"github.com/donnyhardyanto/dxlib/utils"
+	security "github.com/donnyhardyanto/dxlib/utils/security"
 	"github.com/donnyhardyanto/dxlib_module/module/push_notification"
+	"github.com/pkg/errors"
 	"strings"
+	"time"
 )
 
 const (
@@ -31,8 +35,12 @@ type DxmUserManagement struct {
 	UserOrganizationMembershipType       UserOrganizationMembershipType
 	SessionRedis                         *redis.DXRedis
 	PreKeyRedis                          *redis.DXRedis
+	PasswordHashArgon2idParameter        security.Argon2idParameter
 	User                                 *table.DXTable
 	UserPassword                         *table.DXTable
+	UserTOTP                             *table.DXTable
+	UserTOTPRecoveryCode                 *table.DXTable
+	UserPasswordResetToken               *table.DXTable
 	UserMessage                          *table.DXTable
 	Role                                 *table.DXTable
 	Organization                         *table.DXTable
@@ -43,7 +51,7 @@ type DxmUserManagement struct {
 	UserRoleMembership                   *table.DXTable
 	MenuItem                             *table.DXTable
 	OnUserAfterCreate                    func(aepr *api.DXAPIEndPointRequest, dtx *database.DXDatabaseTx, user utils.JSON, userPassword string) (err error)
-	OnUserResetPassword                  func(aepr *api.DXAPIEndPointRequest, dtx *database.DXDatabaseTx, user utils.JSON, userPassword string) (err error)
+	OnUserPasswordResetTokenSend         func(l *log.DXLog, user utils.JSON, resetToken string, ttl time.Duration) (err error)
 	OnUserRoleMembershipAfterCreate      func(aepr *api.DXAPIEndPointRequest, dtx *database.DXDatabaseTx, userRoleMembership utils.JSON, organizationId int64) (err error)
 	OnUserRoleMembershipBeforeSoftDelete func(aepr *api.DXAPIEndPointRequest, dtx *database.DXDatabaseTx, userRoleMembership utils.JSON) (err error)
 	OnUserRoleMembershipBeforeHardDelete func(aepr *api.DXAPIEndPointRequest, dtx *database.DXDatabaseTx, userRoleMembership utils.JSON) (err error)
@@ -57,6 +65,15 @@ func (um *DxmUserManagement) Init(databaseNameId string) {
 	um.UserPassword = table.Manager.NewTable(databaseNameId, "user_management.user_password",
 		"user_management.user_password",
 		"user_management.user_password", "id", "id", "uid", "data")
+	um.UserTOTP = table.Manager.NewTable(databaseNameId, "user_management.user_totp",
+		"user_management.user_totp",
+		"user_management.user_totp", "id", "id", "uid", "data")
+	um.UserTOTPRecoveryCode = table.Manager.NewTable(databaseNameId, "user_management.user_totp_recovery_code",
+		"user_management.user_totp_recovery_code",
+		"user_management.user_totp_recovery_code", "id", "id", "uid", "data")
+	um.UserPasswordResetToken = table.Manager.NewTable(databaseNameId, "user_management.user_password_reset_token",
+		"user_management.user_password_reset_token",
+		"user_management.user_password_reset_token", "id", "id", "uid", "data")
 	um.Role = table.Manager.NewTable(databaseNameId, "user_management.role",
 		"user_management.role",
 		"user_management.role", "nameid", "id", "uid", "data")
@@ -87,6 +104,27 @@ func (um *DxmUserManagement) Init(databaseNameId string) {
 	um.UserMessage = table.Manager.NewTable(databaseNameId, "user_management.user_message",
 		"user_management.user_message",
 		"user_management.user_message", "id", "id", "uid", "data")
+	um.RegisterHealthCheck("session_redis", true, 0, func(ctx context.Context) (err error) {
+		return redisHealthCheck(ctx, "session", um.SessionRedis)
+	})
+	um.RegisterHealthCheck("prekey_redis", true, 0, func(ctx context.Context) (err error) {
+		return redisHealthCheck(ctx, "prekey", um.PreKeyRedis)
+	})
+}
+
+// redisHealthCheck fails when the Redis the module depends on was not assigned after Init or does not answer a ping
+func redisHealthCheck(ctx context.Context, nameId string, r *redis.DXRedis) (err error) {
+	if r == nil {
+		return errors.Errorf("REDIS_NOT_ASSIGNED:%s", nameId)
+	}
+	if (r.Connection == nil) || !r.Connected {
+		return errors.Errorf("REDIS_NOT_CONNECTED:%s", r.NameId)
+	}
+	err = r.Connection.Ping(ctx).Err()
+	if err != nil {
+		return errors.Wrap(err, "error occured")
+	}
+	return nil
 }
 
 func (um *DxmUserManagement) UserMessageCreateAllApplication(l *log.DXLog, userId int64, templateTitle, templateBody string, templateData utils.JSON, attachedData map[string]string) (err error) {
@@ -127,6 +165,9 @@ var ModuleUserManagement DxmUserManagement
 
 func init() {
 	ModuleUserManagement = DxmUserManagement{
+		DXModule: dxlibModule.DXModule{
+			NameId: "user_management",
+		},
 		UserOrganizationMembershipType: UserOrganizationMembershipTypeMultipleOrganizationPerUser,
 	}
 }
@@ -0,0 +1,200 @@
+package user_management
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/donnyhardyanto/dxlib/database"
+	"github.com/donnyhardyanto/dxlib/database/database_type"
+	"github.com/donnyhardyanto/dxlib/health"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+/*
+testSQLScript stands in for the database of a test, every statement the module sends must contain the Contains of the next step and gets
+its Rows or RowsAffected. BEGIN, COMMIT and ROLLBACK are steps too, so a test states where a transaction ends.
+*/
+
+type testSQLStep struct {
+	Contains     string
+	Columns      []string
+	Rows         [][]driver.Value
+	RowsAffected int64
+}
+
+type testSQLStatement struct {
+	Query string
+	Args  []any
+}
+
+type testSQLScript struct {
+	mu         sync.Mutex
+	t          *testing.T
+	steps      []testSQLStep
+	Statements []testSQLStatement
+}
+
+func (s *testSQLScript) next(query string, args []driver.NamedValue) (step testSQLStep, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statement := testSQLStatement{Query: query}
+	for _, a := range args {
+		statement.Args = append(statement.Args, a.Value)
+	}
+	s.Statements = append(s.Statements, statement)
+	if len(s.steps) == 0 {
+		s.t.Errorf("unexpected statement: %s", query)
+		return step, errors.Errorf("TEST_SQL_UNEXPECTED_STATEMENT:%s", query)
+	}
+	step = s.steps[0]
+	if !strings.Contains(query, step.Contains) {
+		s.t.Errorf("statement %d = %s, want it to contain %s", len(s.Statements), query, step.Contains)
+		return step, errors.Errorf("TEST_SQL_STATEMENT_MISMATCH:%s", query)
+	}
+	s.steps = s.steps[1:]
+	return step, nil
+}
+
+// AssertDone fails the test when steps of the script were not reached
+func (s *testSQLScript) AssertDone() {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, step := range s.steps {
+		s.t.Errorf("statement containing %s was not sent", step.Contains)
+	}
+}
+
+// Statement returns the first recorded statement containing contains
+func (s *testSQLScript) Statement(contains string) (statement testSQLStatement, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, statement := range s.Statements {
+		if strings.Contains(statement.Query, contains) {
+			return statement, true
+		}
+	}
+	return statement, false
+}
+
+type testSQLConnector struct {
+	script *testSQLScript
+}
+
+func (c *testSQLConnector) Connect(context.Context) (driver.Conn, error) {
+	return &testSQLConn{script: c.script}, nil
+}
+
+func (c *testSQLConnector) Driver() driver.Driver {
+	return nil
+}
+
+type testSQLConn struct {
+	script *testSQLScript
+}
+
+func (c *testSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("TEST_SQL_PREPARE_NOT_SUPPORTED")
+}
+
+func (c *testSQLConn) Close() error {
+	return nil
+}
+
+func (c *testSQLConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *testSQLConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	_, err := c.script.next("BEGIN", nil)
+	if err != nil {
+		return nil, err
+	}
+	return &testSQLTx{script: c.script}, nil
+}
+
+func (c *testSQLConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *testSQLConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	step, err := c.script.next(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(step.RowsAffected), nil
+}
+
+func (c *testSQLConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	step, err := c.script.next(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &testSQLRows{columns: step.Columns, rows: step.Rows}, nil
+}
+
+type testSQLTx struct {
+	script *testSQLScript
+}
+
+func (tx *testSQLTx) Commit() error {
+	_, err := tx.script.next("COMMIT", nil)
+	return err
+}
+
+func (tx *testSQLTx) Rollback() error {
+	_, err := tx.script.next("ROLLBACK", nil)
+	return err
+}
+
+type testSQLRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *testSQLRows) Columns() []string {
+	return r.columns
+}
+
+func (r *testSQLRows) Close() error {
+	return nil
+}
+
+func (r *testSQLRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// testDatabase returns a user management module whose tables run against the script
+func testDatabase(t *testing.T, steps ...testSQLStep) (um *DxmUserManagement, script *testSQLScript) {
+	t.Helper()
+	script = &testSQLScript{t: t, steps: steps}
+	connection := sqlx.NewDb(sql.OpenDB(&testSQLConnector{script: script}), "postgres")
+	connection.SetMaxOpenConns(1)
+	databaseNameId := "user_management_test"
+	database.Manager.Databases[databaseNameId] = &database.DXDatabase{
+		NameId:       databaseNameId,
+		DatabaseType: database_type.PostgreSQL,
+		Connection:   connection,
+		Connected:    true,
+	}
+	t.Cleanup(func() {
+		delete(database.Manager.Databases, databaseNameId)
+		health.Manager.UnregisterCheck("module." + um.NameId + ".session_redis")
+		health.Manager.UnregisterCheck("module." + um.NameId + ".prekey_redis")
+		_ = connection.Close()
+	})
+	um = &DxmUserManagement{PasswordHashArgon2idParameter: testPasswordHashArgon2idParameter}
+	um.Init(databaseNameId)
+	return um, script
+}
@@ -0,0 +1,184 @@
+package user_management
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	"github.com/donnyhardyanto/dxlib/database"
+	"github.com/donnyhardyanto/dxlib/database/protected/db"
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib/utils/lv"
+	security "github.com/donnyhardyanto/dxlib/utils/security"
+	"github.com/pkg/errors"
+)
+
+const (
+	PasswordHashSchemeArgon2id         = "ARGON2ID"
+	PasswordHashSchemeArgon2idOutdated = "ARGON2ID_OUTDATED"
+	PasswordHashSchemeLegacySHA512     = "LEGACY_SHA512"
+	PasswordHashSchemeLegacyBcrypt     = "LEGACY_BCRYPT"
+	PasswordHashSchemeUnknown          = "UNKNOWN"
+)
+
+// Legacy hashes are the hex encoded LV(salt),LV(method),LV(hash) of the first releases, method 1 is SHA-512 over the password only and method 2 is bcrypt
+const (
+	passwordHashLegacyMethodSHA512 byte = 1
+	passwordHashLegacyMethodBcrypt byte = 2
+)
+
+func (um *DxmUserManagement) passwordHashArgon2idParameter() security.Argon2idParameter {
+	if um.PasswordHashArgon2idParameter.MemoryKiB == 0 {
+		return security.DefaultArgon2idParameter
+	}
+	return um.PasswordHashArgon2idParameter
+}
+
+func (um *DxmUserManagement) passwordHashCreate(password string) (hashedString string, err error) {
+	return security.HashArgon2id([]byte(password), um.passwordHashArgon2idParameter())
+}
+
+func passwordHashLegacyDecode(hashedPasswordAsHexString string) (method byte, hashedPasswordBlock []byte, err error) {
+	hashedPasswordAsBytes, err := hex.DecodeString(hashedPasswordAsHexString)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "error occured")
+	}
+
+	lvHashedPassword := lv.LV{}
+	err = lvHashedPassword.UnmarshalBinary(hashedPasswordAsBytes)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	lvSeparateElements, err := lvHashedPassword.Expand()
+	if err != nil {
+		return 0, nil, err
+	}
+	if lvSeparateElements == nil {
+		return 0, nil, errors.New("lvSeparateElements.IS_NIL")
+	}
+	if len(lvSeparateElements) < 3 {
+		return 0, nil, errors.New("lvSeparateElements.IS_NOT_3")
+	}
+	if len(lvSeparateElements[1].Value) < 1 {
+		return 0, nil, errors.New("lvSaltMethod.IS_EMPTY")
+	}
+	return lvSeparateElements[1].Value[0], lvSeparateElements[2].Value, nil
+}
+
+// passwordHashScheme names the scheme of a stored hash, an Argon2id hash with other parameters than the configured ones is outdated
+func (um *DxmUserManagement) passwordHashScheme(hashedString string) string {
+	if strings.HasPrefix(hashedString, security.Argon2idPrefix) {
+		isOutdated, err := security.Argon2idIsOutdated(hashedString, um.passwordHashArgon2idParameter())
+		if err != nil {
+			return PasswordHashSchemeUnknown
+		}
+		if isOutdated {
+			return PasswordHashSchemeArgon2idOutdated
+		}
+		return PasswordHashSchemeArgon2id
+	}
+	method, _, err := passwordHashLegacyDecode(hashedString)
+	if err != nil {
+		return PasswordHashSchemeUnknown
+	}
+	switch method {
+	case passwordHashLegacyMethodSHA512:
+		return PasswordHashSchemeLegacySHA512
+	case passwordHashLegacyMethodBcrypt:
+		return PasswordHashSchemeLegacyBcrypt
+	default:
+		return PasswordHashSchemeUnknown
+	}
+}
+
+func (um *DxmUserManagement) passwordHashVerify(tryPassword string, hashedString string) (verificationResult bool, err error) {
+	tryPasswordAsBytes := []byte(tryPassword)
+	if strings.HasPrefix(hashedString, security.Argon2idPrefix) {
+		return security.VerifyArgon2id(tryPasswordAsBytes, hashedString)
+	}
+
+	method, hashedPasswordBlock, err := passwordHashLegacyDecode(hashedString)
+	if err != nil {
+		return false, err
+	}
+	switch method {
+	case passwordHashLegacyMethodSHA512:
+		return subtle.ConstantTimeCompare(security.HashSHA512(tryPasswordAsBytes), hashedPasswordBlock) == 1, nil
+	case passwordHashLegacyMethodBcrypt:
+		return security.VerifyBcrypt(tryPasswordAsBytes, hashedPasswordBlock), nil
+	default:
+		return false, errors.Errorf("Unknown salt method %d", method)
+	}
+}
+
+// userPasswordRehashIfNeeded replaces the value of a verified user_password row when it is not Argon2id with the configured parameters.
+// The row is updated in place so the password age and history stay as they are.
+func (um *DxmUserManagement) userPasswordRehashIfNeeded(l *dxlibLog.DXLog, userPasswordRow utils.JSON, password string) (err error) {
+	if um.passwordHashScheme(userPasswordRow["value"].(string)) == PasswordHashSchemeArgon2id {
+		return nil
+	}
+	hashedString, err := um.passwordHashCreate(password)
+	if err != nil {
+		return err
+	}
+	_, err = um.UserPassword.Update(utils.JSON{
+		"value": hashedString,
+	}, utils.JSON{
+		"id": userPasswordRow["id"],
+	})
+	if err != nil {
+		return err
+	}
+	l.Infof("USER_PASSWORD_REHASHED:%v", userPasswordRow["user_id"])
+	return nil
+}
+
+// UserPasswordHashReport counts the users by the scheme of their current password, users on a legacy scheme move to Argon2id at their next login
+func (um *DxmUserManagement) UserPasswordHashReport(aepr *api.DXAPIEndPointRequest) (err error) {
+	if um.UserPassword.Database == nil {
+		um.UserPassword.Database = database.Manager.Databases[um.UserPassword.DatabaseNameId]
+	}
+	err = um.UserPassword.Database.EnsureConnection()
+	if err != nil {
+		return err
+	}
+
+	// only the latest row of each user is its current password, older rows are the password history
+	_, currentUserPasswords, err := db.QueryRows(um.UserPassword.Database.Connection, um.UserPassword.FieldTypeMapping,
+		"select distinct on (user_id) user_id, value from user_management.user_password where is_deleted = false order by user_id, id desc", nil)
+	if err != nil {
+		return err
+	}
+
+	schemeCounts := map[string]int64{
+		PasswordHashSchemeArgon2id:         0,
+		PasswordHashSchemeArgon2idOutdated: 0,
+		PasswordHashSchemeLegacySHA512:     0,
+		PasswordHashSchemeLegacyBcrypt:     0,
+		PasswordHashSchemeUnknown:          0,
+	}
+	for _, currentUserPassword := range currentUserPasswords {
+		schemeCounts[um.passwordHashScheme(currentUserPassword["value"].(string))]++
+	}
+
+	parameter := um.passwordHashArgon2idParameter()
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"password_hash_report": utils.JSON{
+			"total_user":           int64(len(currentUserPasswords)),
+			"argon2id":             schemeCounts[PasswordHashSchemeArgon2id],
+			"argon2id_outdated":    schemeCounts[PasswordHashSchemeArgon2idOutdated],
+			"legacy_sha512":        schemeCounts[PasswordHashSchemeLegacySHA512],
+			"legacy_bcrypt":        schemeCounts[PasswordHashSchemeLegacyBcrypt],
+			"unknown":              schemeCounts[PasswordHashSchemeUnknown],
+			"legacy_total":         schemeCounts[PasswordHashSchemeLegacySHA512] + schemeCounts[PasswordHashSchemeLegacyBcrypt],
+			"argon2id_memory_kib":  parameter.MemoryKiB,
+			"argon2id_time":        parameter.Time,
+			"argon2id_parallelism": parameter.Parallelism,
+		},
+	})
+	return nil
+}
@@ -0,0 +1,159 @@
+package user_management
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/donnyhardyanto/dxlib/utils/lv"
+	security "github.com/donnyhardyanto/dxlib/utils/security"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testPasswordHashArgon2idParameter = security.Argon2idParameter{
+	MemoryKiB:   1024,
+	Time:        1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// testPasswordHashLegacy builds a hash the way the first releases stored it, LV(salt),LV(method),LV(hash) as hex
+func testPasswordHashLegacy(t *testing.T, method byte, hashedPasswordBlock []byte) string {
+	t.Helper()
+	lvSalt, err := lv.NewLV([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewLV() err = %v", err)
+	}
+	lvMethod, err := lv.NewLV([]byte{method})
+	if err != nil {
+		t.Fatalf("NewLV() err = %v", err)
+	}
+	lvHash, err := lv.NewLV(hashedPasswordBlock)
+	if err != nil {
+		t.Fatalf("NewLV() err = %v", err)
+	}
+	lvHashedPassword, err := lv.CombineLV(lvSalt, lvMethod, lvHash)
+	if err != nil {
+		t.Fatalf("CombineLV() err = %v", err)
+	}
+	hashedPasswordAsHexString, err := lvHashedPassword.AsHexString()
+	if err != nil {
+		t.Fatalf("AsHexString() err = %v", err)
+	}
+	return hashedPasswordAsHexString
+}
+
+func TestPasswordHashVerify(t *testing.T) {
+	um := &DxmUserManagement{PasswordHashArgon2idParameter: testPasswordHashArgon2idParameter}
+	outdatedUm := &DxmUserManagement{PasswordHashArgon2idParameter: security.Argon2idParameter{MemoryKiB: 2048, Time: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}}
+
+	argon2idHash, err := um.passwordHashCreate("S3cret!pass")
+	if err != nil {
+		t.Fatalf("passwordHashCreate() err = %v", err)
+	}
+	// security.HashBcrypt uses the maximum cost, the minimum one is verified the same way
+	bcryptBlock, err := bcrypt.GenerateFromPassword([]byte("S3cret!pass"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() err = %v", err)
+	}
+	legacySHA512Hash := testPasswordHashLegacy(t, passwordHashLegacyMethodSHA512, security.HashSHA512([]byte("S3cret!pass")))
+	legacyBcryptHash := testPasswordHashLegacy(t, passwordHashLegacyMethodBcrypt, bcryptBlock)
+	legacyUnknownHash := testPasswordHashLegacy(t, 9, []byte("whatever"))
+
+	tests := []struct {
+		name                   string
+		um                     *DxmUserManagement
+		password               string
+		hashedString           string
+		wantScheme             string
+		wantVerificationResult bool
+		wantErr                bool
+	}{
+		{name: "argon2id", um: um, password: "S3cret!pass", hashedString: argon2idHash, wantScheme: PasswordHashSchemeArgon2id, wantVerificationResult: true},
+		{name: "argon2id wrong password", um: um, password: "S3cret!pasS", hashedString: argon2idHash, wantScheme: PasswordHashSchemeArgon2id},
+		{name: "argon2id with other parameters", um: outdatedUm, password: "S3cret!pass", hashedString: argon2idHash, wantScheme: PasswordHashSchemeArgon2idOutdated, wantVerificationResult: true},
+		{name: "legacy sha512", um: um, password: "S3cret!pass", hashedString: legacySHA512Hash, wantScheme: PasswordHashSchemeLegacySHA512, wantVerificationResult: true},
+		{name: "legacy sha512 wrong password", um: um, password: "S3cret!pasS", hashedString: legacySHA512Hash, wantScheme: PasswordHashSchemeLegacySHA512},
+		{name: "legacy bcrypt", um: um, password: "S3cret!pass", hashedString: legacyBcryptHash, wantScheme: PasswordHashSchemeLegacyBcrypt, wantVerificationResult: true},
+		{name: "legacy bcrypt wrong password", um: um, password: "S3cret!pasS", hashedString: legacyBcryptHash, wantScheme: PasswordHashSchemeLegacyBcrypt},
+		{name: "legacy unknown method", um: um, password: "S3cret!pass", hashedString: legacyUnknownHash, wantScheme: PasswordHashSchemeUnknown, wantErr: true},
+		{name: "not a hash", um: um, password: "S3cret!pass", hashedString: "not-hex", wantScheme: PasswordHashSchemeUnknown, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if scheme := tt.um.passwordHashScheme(tt.hashedString); scheme != tt.wantScheme {
+				t.Errorf("passwordHashScheme() = %s, want %s", scheme, tt.wantScheme)
+			}
+			verificationResult, err := tt.um.passwordHashVerify(tt.password, tt.hashedString)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("passwordHashVerify() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if verificationResult != tt.wantVerificationResult {
+				t.Errorf("passwordHashVerify() = %v, want %v", verificationResult, tt.wantVerificationResult)
+			}
+		})
+	}
+}
+
+func TestPasswordHashArgon2idParameter(t *testing.T) {
+	tests := []struct {
+		name string
+		um   *DxmUserManagement
+		want security.Argon2idParameter
+	}{
+		{name: "not configured", um: &DxmUserManagement{}, want: security.DefaultArgon2idParameter},
+		{name: "configured", um: &DxmUserManagement{PasswordHashArgon2idParameter: testPasswordHashArgon2idParameter}, want: testPasswordHashArgon2idParameter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.um.passwordHashArgon2idParameter(); got != tt.want {
+				t.Errorf("passwordHashArgon2idParameter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	format := regexp.MustCompile(`^[a-hjkmnp-z2-9]{5}-[a-hjkmnp-z2-9]{5}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		recoveryCode, err := generateRecoveryCode()
+		if err != nil {
+			t.Fatalf("generateRecoveryCode() err = %v", err)
+		}
+		if !format.MatchString(recoveryCode) {
+			t.Fatalf("generateRecoveryCode() = %s, want xxxxx-xxxxx without ambiguous letters", recoveryCode)
+		}
+		if seen[recoveryCode] {
+			t.Fatalf("generateRecoveryCode() returned %s twice", recoveryCode)
+		}
+		seen[recoveryCode] = true
+		if len(normalizeRecoveryCode(recoveryCode)) != TOTPRecoveryCodeLength {
+			t.Fatalf("len(normalizeRecoveryCode(%s)) != %d", recoveryCode, TOTPRecoveryCodeLength)
+		}
+	}
+}
+
+func TestUserTOTPRecoveryCodeHash(t *testing.T) {
+	hash := userTOTPRecoveryCodeHash(1, normalizeRecoveryCode("abcde-fghjk"))
+	tests := []struct {
+		name         string
+		userId       int64
+		recoveryCode string
+		wantSame     bool
+	}{
+		{name: "same code typed the same", userId: 1, recoveryCode: "abcde-fghjk", wantSame: true},
+		{name: "uppercase with spaces", userId: 1, recoveryCode: "  ABCDE-FGHJK ", wantSame: true},
+		{name: "without the dash", userId: 1, recoveryCode: "abcdefghjk", wantSame: true},
+		{name: "other user", userId: 2, recoveryCode: "abcde-fghjk", wantSame: false},
+		{name: "other code", userId: 1, recoveryCode: "abcde-fghjm", wantSame: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := userTOTPRecoveryCodeHash(tt.userId, normalizeRecoveryCode(tt.recoveryCode))
+			if (got == hash) != tt.wantSame {
+				t.Errorf("userTOTPRecoveryCodeHash(%d, %q) same = %v, want %v", tt.userId, tt.recoveryCode, got == hash, tt.wantSame)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package user_management
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/table"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib_module/module/general"
+	"github.com/pkg/errors"
+)
+
+const PasswordPolicyPropertyNameId = "PASSWORD_POLICY"
+
+// PasswordPolicy is read from the PASSWORD_POLICY property, an organization overrides single keys in its password_policy column. Zero counts disable history, expiry and lockout.
+type PasswordPolicy struct {
+	MinLength             int    `json:"min_length"`
+	RequireUppercase      bool   `json:"require_uppercase"`
+	RequireLowercase      bool   `json:"require_lowercase"`
+	RequireNumber         bool   `json:"require_number"`
+	RequireSpecial        bool   `json:"require_special"`
+	ForbidSpecial         bool   `json:"forbid_special"`
+	DenylistFile          string `json:"denylist_file"`
+	HistoryCount          int    `json:"history_count"`
+	MaxAgeDay             int    `json:"max_age_day"`
+	LockoutMaxAttempt     int    `json:"lockout_max_attempt"`
+	LockoutWindowSecond   int    `json:"lockout_window_second"`
+	LockoutDurationSecond int    `json:"lockout_duration_second"`
+}
+
+// DefaultPasswordPolicy applies when the property does not exist, the format rules are the ones PasswordFormatValidation always had
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:             8,
+	RequireUppercase:      true,
+	RequireLowercase:      true,
+	RequireNumber:         true,
+	RequireSpecial:        false,
+	ForbidSpecial:         true,
+	DenylistFile:          "",
+	HistoryCount:          5,
+	MaxAgeDay:             90,
+	LockoutMaxAttempt:     5,
+	LockoutWindowSecond:   900,
+	LockoutDurationSecond: 1800,
+}
+
+func (p *PasswordPolicy) merge(v utils.JSON) (err error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "error occured")
+	}
+	err = json.Unmarshal(b, p)
+	if err != nil {
+		return errors.Errorf("PASSWORD_POLICY_INVALID:%v", err)
+	}
+	return nil
+}
+
+// PasswordPolicyGet returns the policy of the organization, organizationId 0 gives the global one
+func (um *DxmUserManagement) PasswordPolicyGet(l *dxlibLog.DXLog, organizationId int64) (policy PasswordPolicy, err error) {
+	policy = DefaultPasswordPolicy
+
+	_, property, err := general.ModuleGeneral.Property.SelectOne(l, nil, utils.JSON{
+		"nameid": PasswordPolicyPropertyNameId,
+	}, nil)
+	if err != nil {
+		return policy, err
+	}
+	if property != nil {
+		v, err := table.GetAs[map[string]any](l, "JSON", property)
+		if err != nil {
+			return policy, err
+		}
+		err = policy.merge(v)
+		if err != nil {
+			return policy, err
+		}
+	}
+
+	if organizationId == 0 {
+		return policy, nil
+	}
+	_, organization, err := um.Organization.GetById(l, organizationId)
+	if err != nil {
+		return policy, err
+	}
+	if (organization == nil) || (organization["password_policy"] == nil) {
+		return policy, nil
+	}
+	v, err := utils.GetJSONFromV(organization["password_policy"])
+	if err != nil {
+		return policy, err
+	}
+	err = policy.merge(v)
+	if err != nil {
+		return policy, err
+	}
+	return policy, nil
+}
+
+// Validate checks the format rules and the denylist, the history needs the user and is checked by UserPasswordIsReused
+func (p PasswordPolicy) Validate(password string) (err error) {
+	if len(password) < p.MinLength {
+		return errors.Errorf("password must be at least %d characters long", p.MinLength)
+	}
+
+	hasUpper := false
+	hasLower := false
+	hasNumber := false
+	hasSpecial := false
+
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsNumber(char):
+			hasNumber = true
+		case !unicode.IsLetter(char) && !unicode.IsNumber(char):
+			hasSpecial = true
+		}
+	}
+
+	if p.RequireUppercase && !hasUpper {
+		return errors.Errorf("password must contain at least one uppercase letter")
+	}
+	if p.RequireLowercase && !hasLower {
+		return errors.Errorf("password must contain at least one lowercase letter")
+	}
+	if p.RequireNumber && !hasNumber {
+		return errors.Errorf("password must contain at least one number")
+	}
+	if p.RequireSpecial && !hasSpecial {
+		return errors.Errorf("password must contain at least one special character")
+	}
+	if p.ForbidSpecial && hasSpecial {
+		return errors.Errorf("password must not contain special characters")
+	}
+
+	if p.DenylistFile != "" {
+		denylist, err := passwordDenylistLoad(p.DenylistFile)
+		if err != nil {
+			return err
+		}
+		_, isDenied := denylist[strings.ToLower(password)]
+		if isDenied {
+			return errors.Errorf("password is too common or known to be breached")
+		}
+	}
+	return nil
+}
+
+var (
+	passwordDenylistsMutex sync.Mutex
+	passwordDenylists      = map[string]map[string]struct{}{}
+)
+
+// passwordDenylistLoad reads a file with one password per line once, the comparison is case-insensitive
+func passwordDenylistLoad(filename string) (denylist map[string]struct{}, err error) {
+	passwordDenylistsMutex.Lock()
+	defer passwordDenylistsMutex.Unlock()
+
+	denylist, ok := passwordDenylists[filename]
+	if ok {
+		return denylist, nil
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "PASSWORD_DENYLIST_FILE_CAN_NOT_BE_OPENED:%s", filename)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	denylist = map[string]struct{}{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		denylist[strings.ToLower(line)] = struct{}{}
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "PASSWORD_DENYLIST_FILE_CAN_NOT_BE_READ:%s", filename)
+	}
+	passwordDenylists[filename] = denylist
+	return denylist, nil
+}
+
+// UserPasswordIsReused compares the password with the last historyCount entries of user_password
+func (um *DxmUserManagement) UserPasswordIsReused(l *dxlibLog.DXLog, userId int64, password string, historyCount int) (isReused bool, err error) {
+	if historyCount <= 0 {
+		return false, nil
+	}
+	_, userPasswords, err := um.UserPassword.Select(l, nil, utils.JSON{
+		"user_id": userId,
+	}, nil, map[string]string{"id": "DESC"}, historyCount)
+	if err != nil {
+		return false, err
+	}
+	for _, userPassword := range userPasswords {
+		isReused, err = um.passwordHashVerify(password, userPassword["value"].(string))
+		if err != nil {
+			return false, err
+		}
+		if isReused {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// UserPasswordIsExpired reports whether the current password of the user is older than the maximum age of the policy
+func (um *DxmUserManagement) UserPasswordIsExpired(l *dxlibLog.DXLog, userId int64, policy PasswordPolicy) (isExpired bool, err error) {
+	if policy.MaxAgeDay <= 0 {
+		return false, nil
+	}
+	_, userPassword, err := um.UserPassword.SelectOne(l, nil, utils.JSON{
+		"user_id": userId,
+	}, nil, map[string]string{"id": "DESC"})
+	if err != nil {
+		return false, err
+	}
+	if userPassword == nil {
+		return false, nil
+	}
+	createdAt, ok := userPassword["created_at"].(time.Time)
+	if !ok {
+		return false, nil
+	}
+	return time.Since(createdAt) > time.Duration(policy.MaxAgeDay)*24*time.Hour, nil
+}
@@ -0,0 +1,92 @@
+package user_management
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/donnyhardyanto/dxlib/utils"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	denylistFile := filepath.Join(t.TempDir(), "denylist.txt")
+	err := os.WriteFile(denylistFile, []byte("Password1\nQwerty123\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withDenylist := DefaultPasswordPolicy
+	withDenylist.DenylistFile = denylistFile
+	requireSpecial := DefaultPasswordPolicy
+	requireSpecial.RequireSpecial = true
+	requireSpecial.ForbidSpecial = false
+	relaxed := PasswordPolicy{MinLength: 4}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		wantErr  bool
+	}{
+		{name: "default accepts mixed case and number", policy: DefaultPasswordPolicy, password: "Secret123", wantErr: false},
+		{name: "default rejects short", policy: DefaultPasswordPolicy, password: "Se1", wantErr: true},
+		{name: "default rejects missing uppercase", policy: DefaultPasswordPolicy, password: "secret123", wantErr: true},
+		{name: "default rejects missing lowercase", policy: DefaultPasswordPolicy, password: "SECRET123", wantErr: true},
+		{name: "default rejects missing number", policy: DefaultPasswordPolicy, password: "SecretSecret", wantErr: true},
+		{name: "default forbids special", policy: DefaultPasswordPolicy, password: "Secret123!", wantErr: true},
+		{name: "require special accepts special", policy: requireSpecial, password: "Secret123!", wantErr: false},
+		{name: "require special rejects without special", policy: requireSpecial, password: "Secret123", wantErr: true},
+		{name: "denylist is case-insensitive", policy: withDenylist, password: "PASSWORD1", wantErr: true},
+		{name: "denylist accepts others", policy: withDenylist, password: "Secret123", wantErr: false},
+		{name: "relaxed accepts lowercase only", policy: relaxed, password: "abcd", wantErr: false},
+		{name: "relaxed still checks length", policy: relaxed, password: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       utils.JSON
+		want    func(p PasswordPolicy) bool
+		wantErr bool
+	}{
+		{
+			name: "override keeps the other keys",
+			v:    utils.JSON{"min_length": 12},
+			want: func(p PasswordPolicy) bool {
+				return (p.MinLength == 12) && (p.LockoutMaxAttempt == DefaultPasswordPolicy.LockoutMaxAttempt) && p.RequireUppercase
+			},
+		},
+		{
+			name: "zero lockout disables it",
+			v:    utils.JSON{"lockout_max_attempt": 0},
+			want: func(p PasswordPolicy) bool {
+				return (p.LockoutMaxAttempt == 0) && (p.MinLength == DefaultPasswordPolicy.MinLength)
+			},
+		},
+		{
+			name:    "wrong type is invalid",
+			v:       utils.JSON{"min_length": "twelve"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultPasswordPolicy
+			err := p.merge(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("merge error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !tt.want(p) {
+				t.Errorf("merge(%v) = %+v", tt.v, p)
+			}
+		})
+	}
+}
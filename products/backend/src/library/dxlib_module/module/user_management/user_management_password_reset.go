@@ -0,0 +1,165 @@
+package user_management
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/database"
+	"github.com/donnyhardyanto/dxlib/database/protected/db"
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	UserPasswordResetTokenSize = 32
+	UserPasswordResetTokenTTL  = 30 * time.Minute
+)
+
+// userPasswordResetTokenHash is a plain SHA-256, the token is random enough that it does not need a salted hash and the digest can be looked up directly
+func userPasswordResetTokenHash(resetToken string) string {
+	sum := sha256.Sum256([]byte(resetToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// UserPasswordResetTokenCreate replaces the unused reset tokens of the user with a new one, only its hash is stored
+func (um *DxmUserManagement) UserPasswordResetTokenCreate(l *dxlibLog.DXLog, userId int64) (resetToken string, err error) {
+	b := make([]byte, UserPasswordResetTokenSize)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "error occured")
+	}
+	resetToken = base64.RawURLEncoding.EncodeToString(b)
+
+	err = um.UserPasswordResetToken.Database.Tx(l, sql.LevelReadCommitted, func(tx *database.DXDatabaseTx) (err2 error) {
+		_, err2 = um.UserPasswordResetToken.TxSoftDelete(tx, utils.JSON{
+			"user_id": userId,
+			"c1":      db.SQLExpression{Expression: "used_at IS NULL"},
+		})
+		if err2 != nil {
+			return err2
+		}
+		_, err2 = um.UserPasswordResetToken.TxInsert(tx, utils.JSON{
+			"user_id":    userId,
+			"value":      userPasswordResetTokenHash(resetToken),
+			"expired_at": time.Now().UTC().Add(UserPasswordResetTokenTTL),
+		})
+		return err2
+	})
+	if err != nil {
+		return "", err
+	}
+	return resetToken, nil
+}
+
+// UserPasswordResetTokenSend issues a reset token for the user and hands it to OnUserPasswordResetTokenSend, the password itself is left untouched.
+// It writes no response, so it can also run after the response was written.
+func (um *DxmUserManagement) UserPasswordResetTokenSend(l *dxlibLog.DXLog, user utils.JSON) (err error) {
+	if um.OnUserPasswordResetTokenSend == nil {
+		return errors.New("PASSWORD_RESET_SENDER_NOT_CONFIGURED")
+	}
+	resetToken, err := um.UserPasswordResetTokenCreate(l, user["id"].(int64))
+	if err != nil {
+		return err
+	}
+	return um.OnUserPasswordResetTokenSend(l, user, resetToken, UserPasswordResetTokenTTL)
+}
+
+// userPasswordResetTokenGet finds an unused and unexpired reset token of an active user, both are nil otherwise
+func (um *DxmUserManagement) userPasswordResetTokenGet(l *dxlibLog.DXLog, resetToken string) (userPasswordResetToken utils.JSON, user utils.JSON, err error) {
+	_, userPasswordResetToken, err = um.UserPasswordResetToken.SelectOne(l, nil, utils.JSON{
+		"value":      userPasswordResetTokenHash(resetToken),
+		"c1":         db.SQLExpression{Expression: "used_at IS NULL"},
+		"c2":         db.SQLExpression{Expression: "expired_at > now()"},
+		"is_deleted": false,
+	}, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if userPasswordResetToken == nil {
+		return nil, nil, nil
+	}
+	_, user, err = um.User.ShouldGetById(l, userPasswordResetToken["user_id"].(int64))
+	if err != nil {
+		return nil, nil, err
+	}
+	if user["status"] != UserStatusActive {
+		return nil, nil, nil
+	}
+	return userPasswordResetToken, user, nil
+}
+
+// UserPasswordResetTokenUser returns the user of a valid reset token or nil, so the new password can be checked against the policy of the user before the token is spent
+func (um *DxmUserManagement) UserPasswordResetTokenUser(l *dxlibLog.DXLog, resetToken string) (user utils.JSON, err error) {
+	_, user, err = um.userPasswordResetTokenGet(l, resetToken)
+	return user, err
+}
+
+// UserPasswordResetTokenRedeem spends the reset token and stores the new password, must_change_password and a login lockout are cleared since the user proved the mailbox.
+// An unknown, used or expired token gives a nil user. onRedeemed runs inside the transaction once the token is spent, when it fails nothing is committed,
+// so e.g. the sessions of the user are revoked before the new password can be used.
+func (um *DxmUserManagement) UserPasswordResetTokenRedeem(l *dxlibLog.DXLog, resetToken string, newPassword string, onRedeemed func(userId int64) (err error)) (user utils.JSON, err error) {
+	userPasswordResetToken, user, err := um.userPasswordResetTokenGet(l, resetToken)
+	if err != nil {
+		return nil, err
+	}
+	if userPasswordResetToken == nil {
+		return nil, nil
+	}
+	userId := user["id"].(int64)
+
+	isRedeemed := false
+	err = um.UserPasswordResetToken.Database.Tx(l, sql.LevelReadCommitted, func(tx *database.DXDatabaseTx) (err2 error) {
+		t := time.Now().UTC()
+		// the condition on used_at makes a concurrent redeem of the same token fail
+		result, err2 := um.UserPasswordResetToken.TxUpdate(tx, utils.JSON{
+			"used_at":          t,
+			"last_modified_at": t,
+		}, utils.JSON{
+			"id": userPasswordResetToken["id"],
+			"c1": db.SQLExpression{Expression: "used_at IS NULL"},
+		})
+		if err2 != nil {
+			return err2
+		}
+		rowsAffected, err2 := result.RowsAffected()
+		if err2 != nil {
+			return errors.Wrap(err2, "error occured")
+		}
+		if rowsAffected != 1 {
+			return nil
+		}
+		isRedeemed = true
+
+		err2 = um.UserPasswordTxCreate(tx, userId, newPassword)
+		if err2 != nil {
+			return err2
+		}
+		_, err2 = um.User.TxUpdate(tx, utils.JSON{
+			"must_change_password":  false,
+			"locked_until":          nil,
+			"login_failed_count":    0,
+			"login_failed_first_at": nil,
+		}, utils.JSON{
+			"id": userId,
+		})
+		if err2 != nil {
+			return err2
+		}
+		if onRedeemed != nil {
+			return onRedeemed(userId)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !isRedeemed {
+		return nil, nil
+	}
+	return user, nil
+}
@@ -0,0 +1,153 @@
+package user_management
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	testUserPasswordResetTokenColumns = []string{"id", "user_id", "value", "is_deleted"}
+	testUserColumns                   = []string{"id", "loginid", "status", "is_deleted"}
+)
+
+func TestUserPasswordResetTokenCreate(t *testing.T) {
+	um, script := testDatabase(t,
+		testSQLStep{Contains: "BEGIN"},
+		testSQLStep{Contains: "update user_management.user_password_reset_token set is_deleted", RowsAffected: 1},
+		testSQLStep{Contains: "INSERT INTO user_management.user_password_reset_token", Columns: []string{"id"}, Rows: [][]driver.Value{{int64(7)}}},
+		testSQLStep{Contains: "COMMIT"},
+	)
+	l := dxlibLog.NewLog(nil, context.Background(), "test")
+
+	resetToken, err := um.UserPasswordResetTokenCreate(&l, 1)
+	if err != nil {
+		t.Fatalf("UserPasswordResetTokenCreate() err = %v", err)
+	}
+	script.AssertDone()
+	if len(resetToken) < UserPasswordResetTokenSize {
+		t.Errorf("len(resetToken) = %d, want at least %d", len(resetToken), UserPasswordResetTokenSize)
+	}
+
+	statement, _ := script.Statement("INSERT INTO user_management.user_password_reset_token")
+	isHashStored := false
+	for _, arg := range statement.Args {
+		if arg == resetToken {
+			t.Errorf("the reset token itself was stored")
+		}
+		if arg == userPasswordResetTokenHash(resetToken) {
+			isHashStored = true
+		}
+	}
+	if !isHashStored {
+		t.Errorf("INSERT args = %v, want the hash of the reset token", statement.Args)
+	}
+}
+
+func TestUserPasswordResetTokenRedeem(t *testing.T) {
+	tokenRow := []driver.Value{int64(7), int64(1), userPasswordResetTokenHash("token"), false}
+	selectToken := testSQLStep{Contains: "user_management.user_password_reset_token", Columns: testUserPasswordResetTokenColumns, Rows: [][]driver.Value{tokenRow}}
+	selectUser := func(status string) testSQLStep {
+		return testSQLStep{Contains: "user_management.v_user", Columns: testUserColumns, Rows: [][]driver.Value{{int64(1), "user1", status, false}}}
+	}
+	errRevoke := errors.New("REVOKE_FAILED")
+
+	tests := []struct {
+		name              string
+		steps             []testSQLStep
+		onRedeemedErr     error
+		wantUser          bool
+		wantErr           bool
+		wantOnRedeemed    bool
+		wantPasswordSaved bool
+	}{
+		{
+			name:  "unknown, used or expired token",
+			steps: []testSQLStep{{Contains: "user_management.user_password_reset_token", Columns: testUserPasswordResetTokenColumns}},
+		},
+		{
+			name:  "token of an inactive user",
+			steps: []testSQLStep{selectToken, selectUser(UserStatusSuspend)},
+		},
+		{
+			name: "valid token",
+			steps: []testSQLStep{
+				selectToken,
+				selectUser(UserStatusActive),
+				{Contains: "BEGIN"},
+				{Contains: "used_at IS NULL", RowsAffected: 1},
+				{Contains: "INSERT INTO user_management.user_password", Columns: []string{"id"}, Rows: [][]driver.Value{{int64(9)}}},
+				{Contains: "update user_management.user set", RowsAffected: 1},
+				{Contains: "COMMIT"},
+			},
+			wantUser: true, wantOnRedeemed: true, wantPasswordSaved: true,
+		},
+		{
+			name: "token spent by a concurrent redeem",
+			steps: []testSQLStep{
+				selectToken,
+				selectUser(UserStatusActive),
+				{Contains: "BEGIN"},
+				{Contains: "used_at IS NULL", RowsAffected: 0},
+				{Contains: "COMMIT"},
+			},
+		},
+		{
+			name: "onRedeemed fails",
+			steps: []testSQLStep{
+				selectToken,
+				selectUser(UserStatusActive),
+				{Contains: "BEGIN"},
+				{Contains: "used_at IS NULL", RowsAffected: 1},
+				{Contains: "INSERT INTO user_management.user_password", Columns: []string{"id"}, Rows: [][]driver.Value{{int64(9)}}},
+				{Contains: "update user_management.user set", RowsAffected: 1},
+				{Contains: "ROLLBACK"},
+			},
+			onRedeemedErr: errRevoke, wantErr: true, wantOnRedeemed: true, wantPasswordSaved: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			um, script := testDatabase(t, tt.steps...)
+			l := dxlibLog.NewLog(nil, context.Background(), "test")
+			isOnRedeemedCalled := false
+			user, err := um.UserPasswordResetTokenRedeem(&l, "token", "N3w!password", func(userId int64) (err error) {
+				isOnRedeemedCalled = true
+				if userId != 1 {
+					t.Errorf("onRedeemed(%d), want user 1", userId)
+				}
+				return tt.onRedeemedErr
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UserPasswordResetTokenRedeem() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			script.AssertDone()
+			if (user != nil) != tt.wantUser {
+				t.Errorf("UserPasswordResetTokenRedeem() user = %v, want user %v", user, tt.wantUser)
+			}
+			if isOnRedeemedCalled != tt.wantOnRedeemed {
+				t.Errorf("onRedeemed called = %v, want %v", isOnRedeemedCalled, tt.wantOnRedeemed)
+			}
+			_, isPasswordSaved := script.Statement("INSERT INTO user_management.user_password")
+			if isPasswordSaved != tt.wantPasswordSaved {
+				t.Errorf("password saved = %v, want %v", isPasswordSaved, tt.wantPasswordSaved)
+			}
+			statement, _ := script.Statement("user_management.user_password_reset_token")
+			isHashLookedUp := false
+			for _, arg := range statement.Args {
+				if arg == "token" {
+					t.Errorf("the reset token itself was looked up")
+				}
+				if arg == userPasswordResetTokenHash("token") {
+					isHashLookedUp = true
+				}
+			}
+			if !isHashLookedUp {
+				t.Errorf("SELECT args = %v, want the hash of the reset token", statement.Args)
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package user_management
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/database"
+	"github.com/donnyhardyanto/dxlib/database/protected/db"
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/donnyhardyanto/dxlib/utils/crypto/totp"
+	"github.com/pkg/errors"
+)
+
+const (
+	TOTPRecoveryCodeCount  = 10
+	TOTPRecoveryCodeLength = 10
+)
+
+// UserTOTPEnrollBegin stores a new secret pending confirmation, an already enabled TOTP must be disabled first
+func (um *DxmUserManagement) UserTOTPEnrollBegin(l *dxlibLog.DXLog, userId int64) (secret string, err error) {
+	_, userTOTP, err := um.UserTOTP.SelectOne(l, nil, utils.JSON{
+		"user_id": userId,
+	}, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	if (userTOTP != nil) && (userTOTP["is_enabled"].(bool)) {
+		return "", errors.New("TOTP_ALREADY_ENABLED")
+	}
+	secret, err = totp.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+	if userTOTP == nil {
+		_, err = um.UserTOTP.Insert(l, utils.JSON{
+			"user_id": userId,
+			"secret":  secret,
+		})
+	} else {
+		_, err = um.UserTOTP.UpdateOne(l, userTOTP["id"].(int64), utils.JSON{
+			"secret":           secret,
+			"last_used_step":   0,
+			"last_modified_at": time.Now().UTC(),
+		})
+	}
+	if err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// UserTOTPEnrollConfirm enables the pending secret once the first code is valid and returns new recovery codes, only their hashes are stored
+func (um *DxmUserManagement) UserTOTPEnrollConfirm(l *dxlibLog.DXLog, userId int64, code string) (recoveryCodes []string, err error) {
+	_, userTOTP, err := um.UserTOTP.SelectOne(l, nil, utils.JSON{
+		"user_id": userId,
+	}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if userTOTP == nil {
+		return nil, errors.New("TOTP_NOT_ENROLLED")
+	}
+	if userTOTP["is_enabled"].(bool) {
+		return nil, errors.New("TOTP_ALREADY_ENABLED")
+	}
+	isValid, step, err := totp.Verify(userTOTP["secret"].(string), code, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !isValid {
+		return nil, errors.New("TOTP_CODE_INVALID")
+	}
+
+	recoveryCodes = make([]string, TOTPRecoveryCodeCount)
+	for i := range recoveryCodes {
+		recoveryCodes[i], err = generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = um.UserTOTP.Database.Tx(l, sql.LevelReadCommitted, func(tx *database.DXDatabaseTx) (err2 error) {
+		t := time.Now().UTC()
+		_, err2 = um.UserTOTP.TxUpdate(tx, utils.JSON{
+			"is_enabled":       true,
+			"confirmed_at":     t,
+			"last_used_step":   step,
+			"last_modified_at": t,
+		}, utils.JSON{
+			"id": userTOTP["id"],
+		})
+		if err2 != nil {
+			return err2
+		}
+		return um.txUserTOTPRecoveryCodeReplace(tx, userId, recoveryCodes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+func (um *DxmUserManagement) txUserTOTPRecoveryCodeReplace(tx *database.DXDatabaseTx, userId int64, recoveryCodes []string) (err error) {
+	_, err = um.UserTOTPRecoveryCode.TxSoftDelete(tx, utils.JSON{
+		"user_id": userId,
+	})
+	if err != nil {
+		return err
+	}
+	for _, recoveryCode := range recoveryCodes {
+		_, err = um.UserTOTPRecoveryCode.TxInsert(tx, utils.JSON{
+			"user_id": userId,
+			"value":   userTOTPRecoveryCodeHash(userId, normalizeRecoveryCode(recoveryCode)),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UserTOTPDisable removes the secret and the recovery codes of the user
+func (um *DxmUserManagement) UserTOTPDisable(l *dxlibLog.DXLog, userId int64) (err error) {
+	return um.UserTOTP.Database.Tx(l, sql.LevelReadCommitted, func(tx *database.DXDatabaseTx) (err2 error) {
+		_, err2 = um.UserTOTP.TxHardDelete(tx, utils.JSON{
+			"user_id": userId,
+		})
+		if err2 != nil {
+			return err2
+		}
+		_, err2 = um.UserTOTPRecoveryCode.TxSoftDelete(tx, utils.JSON{
+			"user_id": userId,
+		})
+		return err2
+	})
+}
+
+func (um *DxmUserManagement) UserTOTPIsEnabled(l *dxlibLog.DXLog, userId int64) (isEnabled bool, err error) {
+	_, userTOTP, err := um.UserTOTP.SelectOne(l, nil, utils.JSON{
+		"user_id":    userId,
+		"is_enabled": true,
+	}, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	return userTOTP != nil, nil
+}
+
+// UserTOTPIsRequired reports whether one of the roles of the user in the organization has is_totp_required set
+func (um *DxmUserManagement) UserTOTPIsRequired(l *dxlibLog.DXLog, userId int64, organizationId int64) (isRequired bool, err error) {
+	_, userRoleMemberships, err := um.UserRoleMembership.Select(l, nil, utils.JSON{
+		"user_id":         userId,
+		"organization_id": organizationId,
+		"is_deleted":      false,
+	}, nil, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	for _, userRoleMembership := range userRoleMemberships {
+		_, organizationRole, err := um.OrganizationRoles.SelectOne(l, nil, utils.JSON{
+			"organization_id":  organizationId,
+			"role_id":          userRoleMembership["role_id"],
+			"is_totp_required": true,
+			"is_deleted":       false,
+		}, nil, nil)
+		if err != nil {
+			return false, err
+		}
+		if organizationRole != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// UserTOTPVerify accepts a current TOTP code, which can not be used twice, or an unused recovery code which is then spent
+func (um *DxmUserManagement) UserTOTPVerify(l *dxlibLog.DXLog, userId int64, code string) (verificationResult bool, err error) {
+	_, userTOTP, err := um.UserTOTP.SelectOne(l, nil, utils.JSON{
+		"user_id":    userId,
+		"is_enabled": true,
+	}, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	if userTOTP == nil {
+		return false, errors.New("TOTP_NOT_ENABLED")
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		isValid, step, err := totp.Verify(userTOTP["secret"].(string), code, time.Now())
+		if err != nil {
+			return false, err
+		}
+		if !isValid {
+			return false, nil
+		}
+		// the condition on last_used_step makes a concurrent replay of the same code fail
+		result, err := um.UserTOTP.Update(utils.JSON{
+			"last_used_step":   step,
+			"last_modified_at": time.Now().UTC(),
+		}, utils.JSON{
+			"id": userTOTP["id"],
+			"c1": db.SQLExpression{Expression: fmt.Sprintf("last_used_step < %d", step)},
+		})
+		if err != nil {
+			return false, err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return false, errors.Wrap(err, "error occured")
+		}
+		return rowsAffected == 1, nil
+	}
+
+	return um.userTOTPRecoveryCodeUse(l, userId, code)
+}
+
+// userTOTPRecoveryCodeHash is a plain SHA-256 since the codes are random, it is bound to the user and found with a single lookup
+func userTOTPRecoveryCodeHash(userId int64, recoveryCode string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", userId, recoveryCode)))
+	return hex.EncodeToString(sum[:])
+}
+
+// userTOTPRecoveryCodeUse spends the recovery code with one conditional update, so a wrong code costs a single indexed query
+func (um *DxmUserManagement) userTOTPRecoveryCodeUse(l *dxlibLog.DXLog, userId int64, recoveryCode string) (verificationResult bool, err error) {
+	recoveryCode = normalizeRecoveryCode(recoveryCode)
+	if len(recoveryCode) != TOTPRecoveryCodeLength {
+		return false, nil
+	}
+	isUsed, err := um.userTOTPRecoveryCodeSpend(utils.JSON{
+		"user_id": userId,
+		"value":   userTOTPRecoveryCodeHash(userId, recoveryCode),
+	})
+	if err != nil || isUsed {
+		return isUsed, err
+	}
+	return um.userTOTPRecoveryCodeLegacyUse(l, userId, recoveryCode)
+}
+
+func (um *DxmUserManagement) userTOTPRecoveryCodeSpend(where utils.JSON) (isUsed bool, err error) {
+	t := time.Now().UTC()
+	where["c1"] = db.SQLExpression{Expression: "used_at IS NULL"}
+	result, err := um.UserTOTPRecoveryCode.Update(utils.JSON{
+		"used_at":          t,
+		"last_modified_at": t,
+	}, where)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "error occured")
+	}
+	return rowsAffected == 1, nil
+}
+
+// userTOTPRecoveryCodeLegacyUse accepts codes stored with the legacy salted SHA-512 of passwordHashCreate before the codes got their own hash,
+// those are cheap to compare. Other schemes are skipped, so a wrong code never runs a memory hard hash.
+func (um *DxmUserManagement) userTOTPRecoveryCodeLegacyUse(l *dxlibLog.DXLog, userId int64, recoveryCode string) (verificationResult bool, err error) {
+	_, userTOTPRecoveryCodes, err := um.UserTOTPRecoveryCode.Select(l, nil, utils.JSON{
+		"user_id":    userId,
+		"c1":         db.SQLExpression{Expression: "used_at IS NULL"},
+		"is_deleted": false,
+	}, nil, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	for _, userTOTPRecoveryCode := range userTOTPRecoveryCodes {
+		value := userTOTPRecoveryCode["value"].(string)
+		if um.passwordHashScheme(value) != PasswordHashSchemeLegacySHA512 {
+			continue
+		}
+		verificationResult, err = um.passwordHashVerify(recoveryCode, value)
+		if err != nil {
+			return false, err
+		}
+		if verificationResult {
+			return um.userTOTPRecoveryCodeSpend(utils.JSON{
+				"id": userTOTPRecoveryCode["id"],
+			})
+		}
+	}
+	return false, nil
+}
+
+// generateRecoveryCode returns a code formatted as xxxxx-xxxxx, the dash and the case are ignored on use
+func generateRecoveryCode() (string, error) {
+	const letterBytes = "abcdefghjkmnpqrstuvwxyz23456789"
+	b := make([]byte, TOTPRecoveryCodeLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(letterBytes))))
+		if err != nil {
+			return "", errors.Wrap(err, "error occured")
+		}
+		b[i] = letterBytes[n.Int64()]
+	}
+	return string(b[:TOTPRecoveryCodeLength/2]) + "-" + string(b[TOTPRecoveryCodeLength/2:]), nil
+}
+
+func normalizeRecoveryCode(recoveryCode string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(recoveryCode), "-", ""))
+}
@@ -13,10 +13,8 @@ import (
 	"github.com/donnyhardyanto/dxlib/utils"
 	"github.com/donnyhardyanto/dxlib/utils/crypto/datablock"
 	"github.com/donnyhardyanto/dxlib/utils/lv"
-	security "github.com/donnyhardyanto/dxlib/utils/security"
 	"github.com/pkg/errors"
 	"github.com/tealeg/xlsx"
-	"github.com/teris-io/shortid"
 	"io"
 	"math/rand"
 	"net/http"
@@ -32,24 +30,32 @@ func (um *DxmUserManagement) UserCreateBulk(aepr *api.DXAPIEndPointRequest) (err
 	}
 	defer bs.Close()
 
-	// Read the entire request body into a buffer
+	// Read the entire request body into a buffer, unless already consumed by the request pre-processing
 	var buf bytes.Buffer
-	_, err = io.Copy(&buf, bs)
-	if err != nil {
-		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "FAILED_TO_READ_REQUEST_BODY:%s=%v", "UserCreateBulk", err.Error())
+	if aepr.RequestBodyAsBytes != nil {
+		buf.Write(aepr.RequestBodyAsBytes)
+	} else {
+		_, err = io.Copy(&buf, bs)
+		if err != nil {
+			return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "FAILED_TO_READ_REQUEST_BODY:%s=%v", "UserCreateBulk", err.Error())
+		}
 	}
 
 	// Determine the file type and parse accordingly
 	contentType := aepr.Request.Header.Get("Content-Type")
 	if strings.Contains(contentType, "csv") {
-		err = um.parseAndCreateUsersFromCSV(&buf, aepr)
+		err = um.parseAndCreateUsersFromCSV(&aepr.Log, &buf, nil)
 	} else if strings.Contains(contentType, "excel") || strings.Contains(contentType, "spreadsheetml") {
-		err = um.parseAndCreateUsersFromXLSX(&buf, aepr)
+		err = um.parseAndCreateUsersFromXLSX(&aepr.Log, &buf, nil)
 	} else {
 		return aepr.WriteResponseAndNewErrorf(http.StatusUnsupportedMediaType, "UNSUPPORTED_FILE_TYPE:%s", contentType)
 	}
 
 	if err != nil {
+		var userCreateBulkError *UserCreateBulkError
+		if errors.As(err, &userCreateBulkError) {
+			return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "%s", userCreateBulkError.Message)
+		}
 		return errors.Wrap(err, "error occurred")
 	}
 
@@ -57,7 +63,23 @@ func (um *DxmUserManagement) UserCreateBulk(aepr *api.DXAPIEndPointRequest) (err
 	return nil
 }
 
-func (um *DxmUserManagement) parseAndCreateUsersFromCSV(buf *bytes.Buffer, aepr *api.DXAPIEndPointRequest) error {
+// UserCreateBulkError is a row that stopped a bulk create, Code is stable for clients while Message keeps the detail of the synchronous response
+type UserCreateBulkError struct {
+	Code    string
+	Row     int
+	Message string
+}
+
+func (e *UserCreateBulkError) Error() string {
+	return e.Message
+}
+
+func userCreateBulkErrorf(code string, row int, format string, v ...any) error {
+	return &UserCreateBulkError{Code: code, Row: row, Message: fmt.Sprintf(format, v...)}
+}
+
+// parseAndCreateUsersFromCSV creates a user per row, onProgress is called after each created row when set
+func (um *DxmUserManagement) parseAndCreateUsersFromCSV(l *dxlibLog.DXLog, buf *bytes.Buffer, onProgress func(row int, createdCount int)) error {
 	// Create a new reader with comma as delimiter
 	reader := csv.NewReader(buf)
 	reader.Comma = ';'          // Set comma as delimiter
@@ -67,8 +89,7 @@ func (um *DxmUserManagement) parseAndCreateUsersFromCSV(buf *bytes.Buffer, aepr
 	// Read header row
 	headers, err := reader.Read()
 	if err != nil {
-		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity,
-			"FAILED_TO_READ_CSV_HEADERS: %s", err.Error())
+		return userCreateBulkErrorf("FAILED_TO_READ_CSV_HEADERS", 1, "FAILED_TO_READ_CSV_HEADERS: %s", err.Error())
 	}
 
 	// Clean headers - trim spaces and empty fields
@@ -82,6 +103,7 @@ func (um *DxmUserManagement) parseAndCreateUsersFromCSV(buf *bytes.Buffer, aepr
 
 	// Process each row
 	lineNum := 1 // Keep track of line numbers for error reporting
+	createdCount := 0
 	for {
 		lineNum++
 		record, err := reader.Read()
@@ -89,8 +111,7 @@ func (um *DxmUserManagement) parseAndCreateUsersFromCSV(buf *bytes.Buffer, aepr
 			break
 		}
 		if err != nil {
-			return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "",
-				"FAILED_TO_PARSE_CSV_LINE_%d: %s", lineNum, err.Error())
+			return userCreateBulkErrorf("FAILED_TO_PARSE_CSV_LINE", lineNum, "FAILED_TO_PARSE_CSV_LINE_%d: %s", lineNum, err.Error())
 		}
 
 		// Create user data map
@@ -112,25 +133,30 @@ func (um *DxmUserManagement) parseAndCreateUsersFromCSV(buf *bytes.Buffer, aepr
 		}
 
 		// Create user
-		err = um.doUserCreate(&aepr.Log, userData)
+		err = um.doUserCreate(l, userData)
 		if err != nil {
-			return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "",
-				"FAILED_TO_CREATE_USER_LINE_%d: %s", lineNum, err.Error())
+			return userCreateBulkErrorf("FAILED_TO_CREATE_USER", lineNum, "FAILED_TO_CREATE_USER_LINE_%d: %s", lineNum, err.Error())
+		}
+		createdCount++
+		if onProgress != nil {
+			onProgress(lineNum, createdCount)
 		}
 	}
 
 	return nil
 }
 
-func (um *DxmUserManagement) parseAndCreateUsersFromXLSX(buf *bytes.Buffer, aepr *api.DXAPIEndPointRequest) error {
+// parseAndCreateUsersFromXLSX creates a user per row of every sheet, onProgress is called after each created row when set
+func (um *DxmUserManagement) parseAndCreateUsersFromXLSX(l *dxlibLog.DXLog, buf *bytes.Buffer, onProgress func(row int, createdCount int)) error {
 	xlFile, err := xlsx.OpenBinary(buf.Bytes())
 	if err != nil {
-		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "FAILED_TO_PARSE_XLSX: %s", err.Error())
+		return userCreateBulkErrorf("FAILED_TO_PARSE_XLSX", 0, "FAILED_TO_PARSE_XLSX: %s", err.Error())
 	}
 
+	createdCount := 0
 	for _, sheet := range xlFile.Sheets {
 		if len(sheet.Rows) < 2 {
-			return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "XLSX_FILE_MUST_HAVE_HEADER_AND_DATA")
+			return userCreateBulkErrorf("XLSX_FILE_MUST_HAVE_HEADER_AND_DATA", 0, "XLSX_FILE_MUST_HAVE_HEADER_AND_DATA")
 		}
 
 		// Validate and extract headers
@@ -138,7 +164,7 @@ func (um *DxmUserManagement) parseAndCreateUsersFromXLSX(buf *bytes.Buffer, aepr
 		for _, cell := range sheet.Rows[0].Cells {
 			header := strings.TrimSpace(cell.String())
 			if header == "" {
-				return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "EMPTY_HEADER_NOT_ALLOWED")
+				return userCreateBulkErrorf("EMPTY_HEADER_NOT_ALLOWED", 1, "EMPTY_HEADER_NOT_ALLOWED")
 			}
 			headers = append(headers, header)
 		}
@@ -167,8 +193,7 @@ func (um *DxmUserManagement) parseAndCreateUsersFromXLSX(buf *bytes.Buffer, aepr
 					if numVal, err := cell.Float(); err == nil {
 						userData[headers[i]] = numVal
 					} else {
-						return aepr.WriteResponseAndNewErrorf(
-							http.StatusUnprocessableEntity, "",
+						return userCreateBulkErrorf("INVALID_NUMERIC_VALUE", rowIdx+2,
 							"INVALID_NUMERIC_VALUE_AT_ROW_%d_COLUMN_%s: %q",
 							rowIdx+2,
 							headers[i],
@@ -184,22 +209,24 @@ func (um *DxmUserManagement) parseAndCreateUsersFromXLSX(buf *bytes.Buffer, aepr
 				continue
 			}
 
-			if err = um.doUserCreate(&aepr.Log, userData); err != nil {
+			if err = um.doUserCreate(l, userData); err != nil {
 				// Check for specific PostgreSQL errors
 				if strings.Contains(err.Error(), "invalid input syntax for type double precision") {
-					return aepr.WriteResponseAndNewErrorf(
-						http.StatusUnprocessableEntity, "",
+					return userCreateBulkErrorf("INVALID_NUMERIC_VALUE", rowIdx+2,
 						"INVALID_NUMERIC_VALUE_AT_ROW_%d: Please ensure all numeric fields contain valid numbers",
 						rowIdx+2,
 					)
 				}
-				return aepr.WriteResponseAndNewErrorf(
-					http.StatusUnprocessableEntity, "",
+				return userCreateBulkErrorf("FAILED_TO_CREATE_USER", rowIdx+2,
 					"FAILED_TO_CREATE_USER_AT_ROW_%d: %s",
 					rowIdx+2,
 					err.Error(),
 				)
 			}
+			createdCount++
+			if onProgress != nil {
+				onProgress(rowIdx+2, createdCount)
+			}
 		}
 	}
 
@@ -488,36 +515,41 @@ func (um *DxmUserManagement) UserList(aepr *api.DXAPIEndPointRequest) (err error
 	return nil
 }
 
+type UserCreateParameter struct {
+	OrganizationId        int64   `param:"organization_id,required" description:"Organization that user belong to at first time create"`
+	RoleId                int64   `param:"role_id,required" description:"Role that user belong to at first time create"`
+	LoginId               string  `param:"loginid,required" description:"Loginid"`
+	Email                 string  `param:"email,type=email,required" description:"Email"`
+	Fullname              string  `param:"fullname,required" description:"Fullname"`
+	Phonenumber           string  `param:"phonenumber,type=phonenumber,required" description:"Phonenumber"`
+	Attribute             string  `param:"attribute" description:"Attribute"`
+	IdentityNumber        *string `param:"identity_number" description:"identity_number"`
+	IdentityType          *string `param:"identity_type" description:"identity_type"`
+	Gender                *string `param:"gender" description:"gender"`
+	AddressOnIdentityCard *string `param:"address_on_identity_card" description:"address_on_identity_card"`
+	MembershipNumber      string  `param:"membership_number" description:"Attribute"`
+	PasswordI             string  `param:"password_i,required" description:"Password block"`
+	PasswordD             string  `param:"password_d,required" description:"Password block"`
+}
+
 func (um *DxmUserManagement) UserCreate(aepr *api.DXAPIEndPointRequest) (err error) {
-	organizationId, ok := aepr.ParameterValues["organization_id"].Value.(int64)
-	if !ok {
-		return aepr.WriteResponseAndLogAsErrorf(http.StatusBadRequest, "ORGANIZATION_ID_MISSING", "")
+	parameter, err := api.Bind[UserCreateParameter](aepr)
+	if err != nil {
+		return err
 	}
+	organizationId := parameter.OrganizationId
 	_, _, err = um.Organization.ShouldGetById(&aepr.Log, organizationId)
 	if err != nil {
 		return aepr.WriteResponseAndLogAsErrorf(http.StatusBadRequest, "ORGANIZATION_NOT_FOUND", "")
 	}
 
-	roleId, ok := aepr.ParameterValues["role_id"].Value.(int64)
-	if !ok {
-		return aepr.WriteResponseAndLogAsErrorf(http.StatusBadRequest, "ROLE_ID_MISSING", "")
-	}
+	roleId := parameter.RoleId
 	_, _, err = um.Role.ShouldGetById(&aepr.Log, roleId)
 	if err != nil {
 		return aepr.WriteResponseAndLogAsErrorf(http.StatusBadRequest, "ROLE_NOT_FOUND", "")
 	}
 
-	passwordI, ok := aepr.ParameterValues["password_i"].Value.(string)
-	if !ok {
-		return aepr.WriteResponseAndLogAsErrorf(http.StatusBadRequest, "PASSWORD_PREKEY_INDEX_MISSING", "")
-	}
-
-	passwordD, ok := aepr.ParameterValues["password_d"].Value.(string)
-	if !ok {
-		return aepr.WriteResponseAndLogAsErrorf(http.StatusBadRequest, "PASSWORD_DATA_BLOCK_MISSING", "")
-	}
-
-	lvPayloadElements, _, _, err := um.PreKeyUnpack(passwordI, passwordD)
+	lvPayloadElements, _, _, err := um.PreKeyUnpack(parameter.PasswordI, parameter.PasswordD)
 	if err != nil {
 		return err
 	}
@@ -525,51 +557,43 @@ func (um *DxmUserManagement) UserCreate(aepr *api.DXAPIEndPointRequest) (err err
 	lvPayloadPassword := lvPayloadElements[0]
 	userPassword := string(lvPayloadPassword.Value)
 
-	attribute, ok := aepr.ParameterValues["attribute"].Value.(string)
-	if !ok {
-		attribute = ""
+	// a password set by an administrator follows the same policy as one the user chooses
+	policy, err := um.PasswordPolicyGet(&aepr.Log, organizationId)
+	if err != nil {
+		return err
+	}
+	err = policy.Validate(userPassword)
+	if err != nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "INVALID_PASSWORD_FORMAT:%v", err.Error())
 	}
 
-	loginId := aepr.ParameterValues["loginid"].Value.(string)
-	email := aepr.ParameterValues["email"].Value.(string)
-	fullname := aepr.ParameterValues["fullname"].Value.(string)
-	phonenumber := aepr.ParameterValues["phonenumber"].Value.(string)
+	loginId := parameter.LoginId
+	membershipNumber := parameter.MembershipNumber
 	status := UserStatusActive
 
 	p := utils.JSON{
 		"loginid":              loginId,
-		"email":                email,
-		"fullname":             fullname,
-		"phonenumber":          phonenumber,
+		"email":                parameter.Email,
+		"fullname":             parameter.Fullname,
+		"phonenumber":          parameter.Phonenumber,
 		"status":               status,
-		"attribute":            attribute,
+		"attribute":            parameter.Attribute,
 		"must_change_password": false,
 		"is_avatar_exist":      false,
 	}
 
-	identityNumber, ok := aepr.ParameterValues["identity_number"].Value.(string)
-	if ok {
-		p["identity_number"] = identityNumber
-	}
-
-	identityType, ok := aepr.ParameterValues["identity_type"].Value.(string)
-	if ok {
-		p["identity_type"] = identityType
+	// the optional columns are only written when sent, a nil field was absent or null
+	if parameter.IdentityNumber != nil {
+		p["identity_number"] = *parameter.IdentityNumber
 	}
-
-	gender, ok := aepr.ParameterValues["gender"].Value.(string)
-	if ok {
-		p["gender"] = gender
+	if parameter.IdentityType != nil {
+		p["identity_type"] = *parameter.IdentityType
 	}
-
-	addressOnIdentityCard, ok := aepr.ParameterValues["gender"].Value.(string)
-	if ok {
-		p["address_on_identity_card"] = addressOnIdentityCard
+	if parameter.Gender != nil {
+		p["gender"] = *parameter.Gender
 	}
-
-	membershipNumber, ok := aepr.ParameterValues["membership_number"].Value.(string)
-	if !ok {
-		membershipNumber = ""
+	if parameter.AddressOnIdentityCard != nil {
+		p["address_on_identity_card"] = *parameter.AddressOnIdentityCard
 	}
 
 	var userId int64
@@ -908,105 +932,6 @@ func (um *DxmUserManagement) TxUserPasswordCreate(tx *database.DXDatabaseTx, use
 	return nil
 }
 
-func hashBlock(saltValue []byte, saltMethod byte, data []byte) ([]byte, error) {
-	passwordBlock := append(saltValue, saltMethod)
-	passwordBlock = append(passwordBlock, data...)
-
-	var hashPasswordBlock []byte
-	switch saltMethod {
-	case 1:
-		hashPasswordBlock = security.HashSHA512(data)
-	case 2:
-		hashPasswordBlock, err := security.HashBcrypt(data)
-		if err != nil {
-			return hashPasswordBlock, err
-		}
-	default:
-		return hashPasswordBlock, errors.New(fmt.Sprintf("Unknown salt method %d", saltMethod))
-	}
-	return hashPasswordBlock, nil
-}
-
-func (um *DxmUserManagement) passwordHashCreate(password string) (hashedString string, err error) {
-	salt := shortid.MustGenerate()[:8]
-	passwordAsBytes := []byte(password)
-
-	lvSalt, err := lv.NewLV([]byte(salt))
-	if err != nil {
-		return "", err
-	}
-
-	var saltMethod byte
-	saltMethod = 1 // 1: sha512
-	saltMethodAsByte := []byte{saltMethod}
-
-	lvSaltMethod, err := lv.NewLV(saltMethodAsByte)
-	if err != nil {
-		return "", err
-	}
-
-	hashPasswordBlock, err := hashBlock(lvSalt.Value, lvSaltMethod.Value[0], passwordAsBytes)
-
-	lvHashedPasswordBlock, err := lv.NewLV(hashPasswordBlock)
-	if err != nil {
-		return "", err
-	}
-
-	lvHashedPassword, err := lv.CombineLV(lvSalt, lvSaltMethod, lvHashedPasswordBlock)
-	if err != nil {
-		return "", err
-	}
-
-	lvHashedPasswordAsBytes, err := lvHashedPassword.MarshalBinary()
-	if err != nil {
-		return "", err
-	}
-
-	hashPasswordBlockAsHexString := hex.EncodeToString(lvHashedPasswordAsBytes)
-	return hashPasswordBlockAsHexString, nil
-}
-
-func (um *DxmUserManagement) passwordHashVerify(tryPassword string, hashedPasswordAsHexString string) (verificationResult bool, err error) {
-	hashedPasswordAsBytes, err := hex.DecodeString(hashedPasswordAsHexString)
-	if err != nil {
-		return false, err
-	}
-
-	lvHashedPassword := lv.LV{}
-	err = lvHashedPassword.UnmarshalBinary(hashedPasswordAsBytes)
-	if err != nil {
-		return false, err
-	}
-
-	lvSeparateElements, err := lvHashedPassword.Expand()
-	if err != nil {
-		return false, err
-	}
-
-	if lvSeparateElements == nil {
-		return false, errors.New("lvSeparateElements.IS_NIL")
-	}
-
-	if len(lvSeparateElements) < 3 {
-		return false, errors.New("lvSeparateElements.IS_NOT_3")
-	}
-
-	lvSalt := lvSeparateElements[0]
-	lvSaltMethod := lvSeparateElements[1]
-	saltMethod := lvSaltMethod.Value[0]
-	lvHashedUserPasswordBlock := lvSeparateElements[2]
-
-	tryPasswordAsBytes := []byte(tryPassword)
-
-	tryHashPasswordBlock, err := hashBlock(lvSalt.Value, saltMethod, tryPasswordAsBytes)
-	if err != nil {
-		return false, err
-	}
-
-	verificationResult = bytes.Equal(tryHashPasswordBlock, lvHashedUserPasswordBlock.Value)
-	return verificationResult, nil
-}
-
 func (um *DxmUserManagement) UserPasswordVerify(l *dxlibLog.DXLog, userId int64, tryPassword string) (verificationResult bool, err error) {
 	_, userPasswordRow, err := um.UserPassword.SelectOne(l, nil, utils.JSON{
 		"user_id": userId,
@@ -1021,6 +946,13 @@ func (um *DxmUserManagement) UserPasswordVerify(l *dxlibLog.DXLog, userId int64,
 	if err != nil {
 		return false, err
 	}
+	if verificationResult {
+		// the plain password is only known here, a failed upgrade must not fail the login
+		err = um.userPasswordRehashIfNeeded(l, userPasswordRow, tryPassword)
+		if err != nil {
+			l.Warnf("USER_PASSWORD_REHASH_ERROR:%d:%s", userId, err.Error())
+		}
+	}
 	return verificationResult, nil
 }
 
@@ -1126,8 +1058,12 @@ func generateRandomString(n int) string {
 	return string(b)
 }
 
+// UserResetPassword emails the user a reset link instead of a new password, the current password stays valid until the link is redeemed
 func (um *DxmUserManagement) UserResetPassword(aepr *api.DXAPIEndPointRequest) (err error) {
 	_, userId, err := aepr.GetParameterValueAsInt64("user_id")
+	if err != nil {
+		return err
+	}
 	_, user, err := um.User.SelectOne(&aepr.Log, nil, utils.JSON{
 		"id": userId,
 	}, nil, nil)
@@ -1135,39 +1071,24 @@ func (um *DxmUserManagement) UserResetPassword(aepr *api.DXAPIEndPointRequest) (
 		return err
 	}
 	if user == nil {
-		return errors.New("USER_NOT_FOUND")
+		return aepr.WriteResponseAndNewErrorf(http.StatusNotFound, "", "USER_NOT_FOUND")
+	}
+	if um.OnUserPasswordResetTokenSend == nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusNotImplemented, "", "PASSWORD_RESET_SENDER_NOT_CONFIGURED")
+	}
+	if (user["email"] == nil) || (user["email"] == "") {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "USER_EMAIL_IS_EMPTY")
 	}
 
-	userPasswordNew := generateRandomString(10)
-
-	d := database.Manager.Databases[um.DatabaseNameId]
-	err = d.Tx(&aepr.Log, sql.LevelReadCommitted, func(tx *database.DXDatabaseTx) (err error) {
-
-		err = um.UserPasswordTxCreate(tx, userId, userPasswordNew)
-		if err != nil {
-			return err
-		}
-		aepr.Log.Infof("User password changed")
-
-		_, err = um.User.TxUpdate(tx, utils.JSON{
-			"must_change_password": true,
-		}, utils.JSON{
-			"id": userId,
-		})
-		if err != nil {
-			return err
-		}
-
-		if um.OnUserResetPassword != nil {
-			err = um.OnUserResetPassword(aepr, tx, user, userPasswordNew)
-			if err != nil {
-				return err
-			}
-		}
+	err = um.UserPasswordResetTokenSend(&aepr.Log, user)
+	if err != nil {
+		return err
+	}
+	aepr.Log.Infof("User password reset token sent")
 
-		return nil
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"expired_in_second": int64(UserPasswordResetTokenTTL.Seconds()),
 	})
-
 	return nil
 }
 
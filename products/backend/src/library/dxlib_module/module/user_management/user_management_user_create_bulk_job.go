@@ -0,0 +1,174 @@
+package user_management
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	UserCreateBulkJobTTL = 24 * time.Hour
+	// UserCreateBulkJobPollInterval is how often a progress stream looks for new events of a running job
+	UserCreateBulkJobPollInterval = 500 * time.Millisecond
+)
+
+const (
+	UserCreateBulkJobEventProgress = "progress"
+	UserCreateBulkJobEventDone     = "done"
+	UserCreateBulkJobEventError    = "error"
+)
+
+/*
+  - Bulk create job
+    UserCreateBulkJobStart takes the same CSV or XLSX body as UserCreateBulk, answers a job_uid right away and creates the users in the background.
+    The events of the job are appended to a list in SessionRedis, so UserCreateBulkJobProgress can stream them from any instance as Server-Sent Events.
+    The event id is the index in that list, an EventSource that reconnects with Last-Event-ID gets only the events after it.
+    An error event carries a stable code and the row, the detail of the failure is only logged.
+*/
+
+func userCreateBulkJobKey(jobUid string) string {
+	return "USER_CREATE_BULK_JOB_" + jobUid
+}
+
+func userCreateBulkJobEventKey(jobUid string) string {
+	return userCreateBulkJobKey(jobUid) + "_EVENT"
+}
+
+func (um *DxmUserManagement) UserCreateBulkJobStart(aepr *api.DXAPIEndPointRequest) (err error) {
+	var buf bytes.Buffer
+	if aepr.RequestBodyAsBytes != nil {
+		buf.Write(aepr.RequestBodyAsBytes)
+	} else {
+		_, err = io.Copy(&buf, aepr.Request.Body)
+		if err != nil {
+			return aepr.WriteResponseAndNewErrorf(http.StatusUnprocessableEntity, "", "FAILED_TO_READ_REQUEST_BODY:%s=%v", "UserCreateBulkJobStart", err.Error())
+		}
+	}
+	contentType := aepr.Request.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "csv") && !strings.Contains(contentType, "excel") && !strings.Contains(contentType, "spreadsheetml") {
+		return aepr.WriteResponseAndNewErrorf(http.StatusUnsupportedMediaType, "", "UNSUPPORTED_FILE_TYPE:%s", contentType)
+	}
+
+	jobId, err := uuid.NewRandom()
+	if err != nil {
+		return errors.Wrap(err, "error occured")
+	}
+	jobUid := jobId.String()
+	userId, _ := aepr.LocalData["user_id"].(int64)
+	err = um.SessionRedis.WithContext(aepr.Context).Set(userCreateBulkJobKey(jobUid), utils.JSON{
+		"user_id": strconv.FormatInt(userId, 10),
+	}, UserCreateBulkJobTTL)
+	if err != nil {
+		return err
+	}
+
+	l := dxlibLog.NewLog(&aepr.Log, context.WithoutCancel(aepr.Context), "USER_CREATE_BULK_JOB_"+jobUid)
+	api.Manager.GoBackground(&l, func() {
+		um.userCreateBulkJobRun(&l, jobUid, contentType, &buf)
+	})
+
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"job_uid": jobUid,
+	})
+	return nil
+}
+
+func (um *DxmUserManagement) userCreateBulkJobEventAdd(l *dxlibLog.DXLog, jobUid string, event string, data utils.JSON) {
+	_, err := um.SessionRedis.WithContext(l.Context).RPush(userCreateBulkJobEventKey(jobUid), utils.JSON{
+		"event": event,
+		"data":  data,
+	}, UserCreateBulkJobTTL)
+	if err != nil {
+		l.Errorf(err, "USER_CREATE_BULK_JOB_EVENT_ADD_ERROR:%s", event)
+	}
+}
+
+func (um *DxmUserManagement) userCreateBulkJobRun(l *dxlibLog.DXLog, jobUid string, contentType string, buf *bytes.Buffer) {
+	onProgress := func(row int, createdCount int) {
+		um.userCreateBulkJobEventAdd(l, jobUid, UserCreateBulkJobEventProgress, utils.JSON{
+			"row":           row,
+			"created_count": createdCount,
+		})
+	}
+	// a panic on a bad row ends the job with an error event like any other failure
+	err := api.CallRecovered(func() (err error) {
+		if strings.Contains(contentType, "csv") {
+			return um.parseAndCreateUsersFromCSV(l, buf, onProgress)
+		}
+		return um.parseAndCreateUsersFromXLSX(l, buf, onProgress)
+	})
+	if err != nil {
+		l.Errorf(err, "USER_CREATE_BULK_JOB_FAILED:%s:%+v", jobUid, err)
+		code := "USER_CREATE_BULK_FAILED"
+		row := 0
+		var userCreateBulkError *UserCreateBulkError
+		if errors.As(err, &userCreateBulkError) {
+			code = userCreateBulkError.Code
+			row = userCreateBulkError.Row
+		}
+		um.userCreateBulkJobEventAdd(l, jobUid, UserCreateBulkJobEventError, utils.JSON{
+			"code": code,
+			"row":  row,
+		})
+		return
+	}
+	um.userCreateBulkJobEventAdd(l, jobUid, UserCreateBulkJobEventDone, utils.JSON{})
+}
+
+// UserCreateBulkJobProgress streams the events of a job of the logged user until its done or error event
+func (um *DxmUserManagement) UserCreateBulkJobProgress(aepr *api.DXAPIEndPointRequest) (err error) {
+	_, jobUid, err := aepr.GetParameterValueAsString("job_uid")
+	if err != nil {
+		return err
+	}
+	sessionRedis := um.SessionRedis.WithContext(aepr.Context)
+	job, err := sessionRedis.Get(userCreateBulkJobKey(jobUid))
+	if err != nil {
+		return err
+	}
+	userId, _ := aepr.LocalData["user_id"].(int64)
+	if (job == nil) || (job["user_id"] != strconv.FormatInt(userId, 10)) {
+		return aepr.WriteResponseAndNewErrorf(http.StatusNotFound, "", "USER_CREATE_BULK_JOB_NOT_FOUND")
+	}
+
+	nextEventIndex := int64(0)
+	lastEventId, err := strconv.ParseInt(aepr.SSELastEventId(), 10, 64)
+	if (err == nil) && (lastEventId >= 0) {
+		nextEventIndex = lastEventId + 1
+	}
+
+	ticker := time.NewTicker(UserCreateBulkJobPollInterval)
+	defer ticker.Stop()
+	for {
+		events, err := sessionRedis.LRange(userCreateBulkJobEventKey(jobUid), nextEventIndex)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			eventName, _ := event["event"].(string)
+			err = aepr.SSESendEvent(strconv.FormatInt(nextEventIndex, 10), eventName, event["data"])
+			if err != nil {
+				return err
+			}
+			nextEventIndex++
+			if (eventName == UserCreateBulkJobEventDone) || (eventName == UserCreateBulkJobEventError) {
+				return nil
+			}
+		}
+		select {
+		case <-aepr.SSEStream.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
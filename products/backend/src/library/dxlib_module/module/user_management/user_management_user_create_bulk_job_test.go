@@ -0,0 +1,55 @@
+package user_management
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goRedis "github.com/go-redis/redis/v8"
+
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/redis"
+)
+
+func TestUserCreateBulkJobRun(t *testing.T) {
+	server := miniredis.RunT(t)
+	connection := goRedis.NewRing(&goRedis.RingOptions{Addrs: map[string]string{"test": server.Addr()}})
+	defer func() {
+		_ = connection.Close()
+	}()
+	um := &DxmUserManagement{SessionRedis: &redis.DXRedis{NameId: "session_test", Connection: connection, Connected: true, Context: context.Background()}}
+	l := dxlibLog.NewLog(nil, context.Background(), "test")
+
+	tests := []struct {
+		name      string
+		buf       *bytes.Buffer
+		wantEvent string
+		wantCode  string
+	}{
+		// a nil buffer panics on the first read, as a bad row would deep in the parser
+		{name: "panic ends the job with an error event", buf: nil, wantEvent: UserCreateBulkJobEventError, wantCode: "USER_CREATE_BULK_FAILED"},
+		{name: "empty file ends the job with an error event", buf: &bytes.Buffer{}, wantEvent: UserCreateBulkJobEventError, wantCode: "FAILED_TO_READ_CSV_HEADERS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobUid := tt.name
+			um.userCreateBulkJobRun(&l, jobUid, "text/csv", tt.buf)
+			events, err := um.SessionRedis.LRange(userCreateBulkJobEventKey(jobUid), 0)
+			if err != nil {
+				t.Fatalf("LRange() err = %v", err)
+			}
+			if len(events) == 0 {
+				t.Fatalf("no event, want %s", tt.wantEvent)
+			}
+			lastEvent := events[len(events)-1]
+			if lastEvent["event"] != tt.wantEvent {
+				t.Errorf("last event = %v, want %s", lastEvent["event"], tt.wantEvent)
+			}
+			data, _ := lastEvent["data"].(map[string]any)
+			if data["code"] != tt.wantCode {
+				t.Errorf("code = %v, want %s", data["code"], tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package user_management
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/donnyhardyanto/dxlib/api"
+	"github.com/donnyhardyanto/dxlib/database/protected/db"
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/utils"
+	"github.com/pkg/errors"
+)
+
+// UserIsLocked reads the lock from a user row, a lock ends by itself at locked_until
+func UserIsLocked(user utils.JSON) (isLocked bool, lockedUntil time.Time) {
+	lockedUntil, ok := user["locked_until"].(time.Time)
+	if !ok {
+		return false, time.Time{}
+	}
+	return time.Now().Before(lockedUntil), lockedUntil
+}
+
+// UserLoginFailedRecord counts a failed login inside the lockout window of the policy and locks the user once the maximum is reached.
+// Both steps are single statements so concurrent failed logins are all counted.
+func (um *DxmUserManagement) UserLoginFailedRecord(l *dxlibLog.DXLog, userId int64, policy PasswordPolicy) (isLocked bool, err error) {
+	if policy.LockoutMaxAttempt <= 0 {
+		return false, nil
+	}
+	isWindowExpired := fmt.Sprintf("(login_failed_first_at IS NULL OR login_failed_first_at < now() - interval '%d seconds')", policy.LockoutWindowSecond)
+	_, err = um.User.Update(utils.JSON{
+		"login_failed_count":    db.SQLExpression{Expression: fmt.Sprintf("login_failed_count = CASE WHEN %s THEN 1 ELSE login_failed_count + 1 END", isWindowExpired)},
+		"login_failed_first_at": db.SQLExpression{Expression: fmt.Sprintf("login_failed_first_at = CASE WHEN %s THEN now() ELSE login_failed_first_at END", isWindowExpired)},
+	}, utils.JSON{
+		"id": userId,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	result, err := um.User.Update(utils.JSON{
+		"locked_until":          db.SQLExpression{Expression: fmt.Sprintf("locked_until = now() + interval '%d seconds'", policy.LockoutDurationSecond)},
+		"login_failed_count":    0,
+		"login_failed_first_at": nil,
+	}, utils.JSON{
+		"id": userId,
+		"c1": db.SQLExpression{Expression: fmt.Sprintf("login_failed_count >= %d", policy.LockoutMaxAttempt)},
+	})
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "error occured")
+	}
+	return rowsAffected == 1, nil
+}
+
+// UserLoginSucceededRecord clears the failed login count of the user
+func (um *DxmUserManagement) UserLoginSucceededRecord(l *dxlibLog.DXLog, user utils.JSON) (err error) {
+	loginFailedCount, _ := user["login_failed_count"].(int64)
+	if loginFailedCount == 0 {
+		return nil
+	}
+	_, err = um.User.Update(utils.JSON{
+		"login_failed_count":    0,
+		"login_failed_first_at": nil,
+	}, utils.JSON{
+		"id": user["id"],
+	})
+	return err
+}
+
+func (um *DxmUserManagement) UserLockRead(aepr *api.DXAPIEndPointRequest) (err error) {
+	_, userId, err := aepr.GetParameterValueAsInt64("user_id")
+	if err != nil {
+		return err
+	}
+	_, user, err := um.User.SelectOne(&aepr.Log, nil, utils.JSON{
+		"id": userId,
+	}, nil, nil)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusNotFound, "", "USER_NOT_FOUND")
+	}
+	organizationId, _ := user["organization_id"].(int64)
+	policy, err := um.PasswordPolicyGet(&aepr.Log, organizationId)
+	if err != nil {
+		return err
+	}
+	isPasswordExpired, err := um.UserPasswordIsExpired(&aepr.Log, userId, policy)
+	if err != nil {
+		return err
+	}
+
+	isLocked, lockedUntil := UserIsLocked(user)
+	userLock := utils.JSON{
+		"user_id":               userId,
+		"is_locked":             isLocked,
+		"locked_until":          nil,
+		"login_failed_count":    user["login_failed_count"],
+		"login_failed_first_at": user["login_failed_first_at"],
+		"must_change_password":  user["must_change_password"],
+		"is_password_expired":   isPasswordExpired,
+	}
+	if isLocked {
+		userLock["locked_until"] = lockedUntil
+	}
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, utils.JSON{
+		"user_lock": userLock,
+	})
+	return nil
+}
+
+func (um *DxmUserManagement) UserUnlock(aepr *api.DXAPIEndPointRequest) (err error) {
+	_, userId, err := aepr.GetParameterValueAsInt64("user_id")
+	if err != nil {
+		return err
+	}
+	_, user, err := um.User.SelectOne(&aepr.Log, nil, utils.JSON{
+		"id": userId,
+	}, nil, nil)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return aepr.WriteResponseAndNewErrorf(http.StatusNotFound, "", "USER_NOT_FOUND")
+	}
+	_, err = um.User.Update(utils.JSON{
+		"locked_until":          nil,
+		"login_failed_count":    0,
+		"login_failed_first_at": nil,
+	}, utils.JSON{
+		"id": userId,
+	})
+	if err != nil {
+		return err
+	}
+	aepr.Log.Infof("User unlocked")
+
+	aepr.WriteResponseAsJSON(http.StatusOK, nil, nil)
+	return nil
+}
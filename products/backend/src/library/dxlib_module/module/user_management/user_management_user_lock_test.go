@@ -0,0 +1,111 @@
+package user_management
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	dxlibLog "github.com/donnyhardyanto/dxlib/log"
+	"github.com/donnyhardyanto/dxlib/utils"
+)
+
+func TestUserIsLocked(t *testing.T) {
+	lockedUntil := time.Now().Add(30 * time.Minute)
+	expiredAt := time.Now().Add(-time.Second)
+
+	tests := []struct {
+		name            string
+		user            utils.JSON
+		wantIsLocked    bool
+		wantLockedUntil time.Time
+	}{
+		{name: "never locked", user: utils.JSON{"locked_until": nil}, wantIsLocked: false},
+		{name: "column missing", user: utils.JSON{}, wantIsLocked: false},
+		{name: "locked", user: utils.JSON{"locked_until": lockedUntil}, wantIsLocked: true, wantLockedUntil: lockedUntil},
+		{name: "lock ended by itself", user: utils.JSON{"locked_until": expiredAt}, wantIsLocked: false, wantLockedUntil: expiredAt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isLocked, gotLockedUntil := UserIsLocked(tt.user)
+			if isLocked != tt.wantIsLocked {
+				t.Errorf("isLocked = %v, want %v", isLocked, tt.wantIsLocked)
+			}
+			if !gotLockedUntil.Equal(tt.wantLockedUntil) {
+				t.Errorf("lockedUntil = %v, want %v", gotLockedUntil, tt.wantLockedUntil)
+			}
+		})
+	}
+}
+
+func TestUserLoginFailedRecord(t *testing.T) {
+	policy := PasswordPolicy{LockoutMaxAttempt: 5, LockoutWindowSecond: 900, LockoutDurationSecond: 1800}
+	countStep := testSQLStep{Contains: "interval '900 seconds'", RowsAffected: 1}
+
+	tests := []struct {
+		name         string
+		policy       PasswordPolicy
+		steps        []testSQLStep
+		wantIsLocked bool
+	}{
+		{name: "lockout disabled", policy: PasswordPolicy{LockoutMaxAttempt: 0, LockoutWindowSecond: 900, LockoutDurationSecond: 1800}},
+		{
+			name:   "failed login below the maximum",
+			policy: policy,
+			steps:  []testSQLStep{countStep, {Contains: "login_failed_count >= 5", RowsAffected: 0}},
+		},
+		{
+			name:         "failed login reaching the maximum locks the user",
+			policy:       policy,
+			steps:        []testSQLStep{countStep, {Contains: "login_failed_count >= 5", RowsAffected: 1}},
+			wantIsLocked: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			um, script := testDatabase(t, tt.steps...)
+			l := dxlibLog.NewLog(nil, context.Background(), "test")
+			isLocked, err := um.UserLoginFailedRecord(&l, 1, tt.policy)
+			if err != nil {
+				t.Fatalf("UserLoginFailedRecord() err = %v", err)
+			}
+			script.AssertDone()
+			if isLocked != tt.wantIsLocked {
+				t.Errorf("isLocked = %v, want %v", isLocked, tt.wantIsLocked)
+			}
+			// counting is a single statement, concurrent failed logins are all counted
+			if statement, ok := script.Statement("interval '900 seconds'"); ok {
+				if !strings.Contains(statement.Query, "login_failed_count + 1") {
+					t.Errorf("count statement = %s, want it to increment in the database", statement.Query)
+				}
+			}
+			if statement, ok := script.Statement("login_failed_count >= 5"); ok {
+				if !strings.Contains(statement.Query, "interval '1800 seconds'") {
+					t.Errorf("lock statement = %s, want the lockout duration", statement.Query)
+				}
+			}
+		})
+	}
+}
+
+func TestUserLoginSucceededRecord(t *testing.T) {
+	tests := []struct {
+		name  string
+		user  utils.JSON
+		steps []testSQLStep
+	}{
+		{name: "no failed login", user: utils.JSON{"id": int64(1), "login_failed_count": int64(0)}},
+		{name: "failed logins are cleared", user: utils.JSON{"id": int64(1), "login_failed_count": int64(3)}, steps: []testSQLStep{{Contains: "update user_management.user set", RowsAffected: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			um, script := testDatabase(t, tt.steps...)
+			l := dxlibLog.NewLog(nil, context.Background(), "test")
+			err := um.UserLoginSucceededRecord(&l, tt.user)
+			if err != nil {
+				t.Fatalf("UserLoginSucceededRecord() err = %v", err)
+			}
+			script.AssertDone()
+		})
+	}
+}